@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/hb-chen/opskills/internal/config"
+	"github.com/hb-chen/opskills/internal/skill"
+	"github.com/hb-chen/opskills/internal/skill/direct"
 	"github.com/hb-chen/opskills/internal/skill/mcp/servers"
 	"github.com/hb-chen/opskills/pkg/logger"
 )
 
 func main() {
 	skillsDir := flag.String("skills-dir", "./skills", "Skills directory")
+	watch := flag.Bool("watch", false, "Reload the skill when its files change")
+	configFile := flag.String("config", "", "Agent config file, whose skills sandbox and interpreters apply to scripts (default is ./configs/config.yaml)")
 	flag.Parse()
 
 	// Initialize logger (using zap)
@@ -25,6 +31,11 @@ func main() {
 		logger.Fatalf("Failed to create server: %v", err)
 	}
 
+	// Scripts run with the sandbox and interpreters of the agent
+	if err := configure(server, *configFile, *skillsDir); err != nil {
+		logger.Fatalf("Failed to configure server: %v", err)
+	}
+
 	// Get MCP server
 	mcpServer := server.GetServer()
 
@@ -41,6 +52,13 @@ func main() {
 		cancel()
 	}()
 
+	// Watch skill files for changes
+	if *watch {
+		if err := server.Watch(ctx); err != nil {
+			logger.Fatalf("Failed to watch skills: %v", err)
+		}
+	}
+
 	// Serve on stdio
 	logger.Info("MCP Server ready (stdio)")
 	if err := mcpServer.Serve(ctx, os.Stdin, os.Stdout); err != nil {
@@ -52,5 +70,36 @@ func main() {
 	logger.Info("Server stopped")
 }
 
+// configure applies the skills sandbox and interpreters of the agent config to the scripts of server
+func configure(server *servers.KubeKeyServer, configFile, skillsDir string) error {
+	config.Init()
+	if configFile != "" {
+		config.Viper().SetConfigFile(configFile)
+	} else {
+		config.Viper().AddConfigPath(".")
+		config.Viper().AddConfigPath("./configs")
+		config.Viper().SetConfigType("yaml")
+		config.Viper().SetConfigName("config")
+	}
+	if err := config.Viper().ReadInConfig(); err != nil {
+		logger.Warnf("Config file not found: %v", err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
 
-
+	sandbox, err := direct.NewSandbox(cfg.Skills.Sandbox, skillsDir)
+	if err != nil {
+		return err
+	}
+	if err := server.SetSandbox(sandbox); err != nil {
+		return fmt.Errorf("invalid skills sandbox: %w", err)
+	}
+	skillsConfig, _, err := skill.LoadConfigFiles(append([]string{cfg.Skills.Config}, cfg.Skills.Includes...)...)
+	if err != nil {
+		return err
+	}
+	server.SetInterpreters(skillsConfig.Interpreters())
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hb-chen/opskills/internal/config"
+	"github.com/hb-chen/opskills/internal/llm"
+)
+
+var promptsDir string
+
+// promptsCmd represents the prompts command
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Manage LLM prompt templates",
+	Long:  `Inspect and validate the LLM prompt templates used by the Ops Agent`,
+}
+
+// promptsLintCmd renders every prompt template with sample data
+var promptsLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Render every prompt template with sample data",
+	Long: `Render the embedded prompt templates and every override found in the prompts
+directory (including per-skill overrides) with sample data, reporting parse and
+render errors.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		dir := cfg.LLM.Prompts.Dir
+		if promptsDir != "" {
+			dir = promptsDir
+		}
+
+		prompts, err := llm.NewPromptRegistry(dir)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		out := cmd.OutOrStdout()
+		for _, result := range prompts.Lint() {
+			name := result.Name
+			if result.Skill != "" {
+				name = result.Skill + "/" + result.Name
+			}
+
+			status := "ok"
+			if !result.OK() {
+				status = "error"
+				failed++
+			} else if result.Warning != "" {
+				status = "warn"
+			}
+
+			fmt.Fprintf(out, "%-5s %-30s version=%s source=%s\n", status, name, result.Version, result.Source)
+			if result.Error != "" {
+				fmt.Fprintf(out, "      %s\n", result.Error)
+			}
+			if result.Warning != "" {
+				fmt.Fprintf(out, "      %s\n", result.Warning)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d prompt template(s) failed to render", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	promptsLintCmd.Flags().StringVar(&promptsDir, "dir", "", "prompt templates directory (overrides config file)")
+
+	promptsCmd.AddCommand(promptsLintCmd)
+	rootCmd.AddCommand(promptsCmd)
+}
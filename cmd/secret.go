@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hb-chen/opskills/internal/config"
+	"github.com/hb-chen/opskills/internal/secret"
+)
+
+// secretCmd represents the secret command
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage the encrypted secret file",
+	Long: `Create the key of the encrypted secret file and add secrets to it.
+
+Skill params reference secrets as secret://<path>[#field], e.g. secret://ssh/prod-root
+or secret://registry/harbor#password. References are resolved from the providers
+configured in secrets.providers right before a step runs; the LLM only sees the
+references, and the values are redacted from the output.`,
+}
+
+// secretKeygenCmd prints a new key
+var secretKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Print a new key for the encrypted secret file",
+	Long: `Print a new random AES-256 key, base64 encoded. Store it in the key_file of the
+file provider (readable by the agent only) or in its key_env variable:
+
+  opskills-agent secret keygen > configs/secrets.key && chmod 600 configs/secrets.key`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := secret.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), key)
+		return nil
+	},
+}
+
+// secretSetCmd encrypts a secret into the secret file
+var secretSetCmd = &cobra.Command{
+	Use:   "set <path>[#field]",
+	Short: "Encrypt a secret read from stdin into the secret file",
+	Long: `Read a value from stdin (the first line, or everything with --multiline) and store
+it encrypted in the file of the first file provider of secrets.providers:
+
+  read -rs PASSWORD && echo "$PASSWORD" | opskills-agent secret set ssh/prod-root
+  opskills-agent secret set registry/harbor#password < password.txt`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := secretFileProvider()
+		if err != nil {
+			return err
+		}
+		ref, _ := secret.ParseRef(secret.Scheme + strings.TrimPrefix(args[0], secret.Scheme))
+
+		value, err := readSecretValue(cmd.InOrStdin(), secretSetMultiline)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("empty value on stdin")
+		}
+
+		if err := provider.Set(ref.Path, ref.Field, value); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "stored %s\n", ref)
+		return nil
+	},
+}
+
+// secretListCmd lists the secrets of the secret file
+var secretListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the references of the secrets in the secret file, without their values",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := secretFileProvider()
+		if err != nil {
+			return err
+		}
+		refs, err := provider.List()
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			fmt.Fprintln(cmd.OutOrStdout(), ref)
+		}
+		return nil
+	},
+}
+
+var secretSetMultiline bool
+
+func init() {
+	secretSetCmd.Flags().BoolVar(&secretSetMultiline, "multiline", false, "read the whole of stdin (e.g. a private key) instead of the first line")
+
+	secretCmd.AddCommand(secretKeygenCmd, secretSetCmd, secretListCmd)
+	rootCmd.AddCommand(secretCmd)
+}
+
+// secretFileProvider returns the first file provider of the config
+func secretFileProvider() (*secret.FileProvider, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, p := range cfg.Secrets.Providers {
+		if p.Type == "file" {
+			return newFileSecretProvider(p)
+		}
+	}
+	return nil, fmt.Errorf("no file provider in secrets.providers")
+}
+
+// readSecretValue reads the first line of r, or all of it
+func readSecretValue(r io.Reader, all bool) (string, error) {
+	if all {
+		data, err := io.ReadAll(r)
+		return string(data), err
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -5,16 +5,27 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/hb-chen/opskills/internal/agent"
+	"github.com/hb-chen/opskills/internal/api"
+	"github.com/hb-chen/opskills/internal/artifact"
 	"github.com/hb-chen/opskills/internal/config"
 	"github.com/hb-chen/opskills/internal/graph"
 	"github.com/hb-chen/opskills/internal/llm"
+	"github.com/hb-chen/opskills/internal/maintenance"
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/redact"
+	"github.com/hb-chen/opskills/internal/runbook"
+	"github.com/hb-chen/opskills/internal/schedule"
+	"github.com/hb-chen/opskills/internal/secret"
 	"github.com/hb-chen/opskills/internal/server"
 	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/skill/direct"
+	"github.com/hb-chen/opskills/internal/skill/native"
 	"github.com/hb-chen/opskills/internal/tracer"
 	"github.com/hb-chen/opskills/pkg/logger"
 	"github.com/spf13/cobra"
@@ -47,20 +58,50 @@ var serveCmd = &cobra.Command{
 
 		// Create context
 		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
 
 		// Setup signal handling
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 		// Initialize Pipeline
-		pipeline, err := initPipeline(cfg)
+		components, err := initPipeline(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to initialize pipeline: %w", err)
 		}
 
+		// Watch skills for changes
+		if cfg.Skills.Watch {
+			if err := components.reloader.Watch(ctx); err != nil {
+				return fmt.Errorf("failed to watch skills: %w", err)
+			}
+		}
+
+		// Create gRPC service
+		service := api.NewService(components.pipeline)
+		service.SetSkillReloader(components.reloader)
+		service.SetSkillIndex(components.skillIndex)
+		service.SetArtifacts(components.artifacts)
+		service.SetRunbooks(components.runbooks)
+
+		// Run the tasks of schedules
+		if cfg.Schedules.Enabled {
+			scheduler, err := schedule.New(cfg.Schedules.File, cfg.Schedules.History, service.RunScheduled)
+			if err != nil {
+				return fmt.Errorf("failed to load schedules: %w", err)
+			}
+			service.SetScheduler(scheduler)
+			scheduler.Start()
+			defer scheduler.Stop()
+			logger.Infof("Schedules are kept in %s", cfg.Schedules.File)
+		}
+
+		// Remove expired workspaces and artifacts
+		go components.artifacts.Run(ctx, time.Hour)
+
 		// Start servers (gRPC and HTTP with Web UI)
 		go func() {
-			if err := server.Serve(ctx, cfg, pipeline); err != nil {
+			if err := server.Serve(ctx, cfg, service); err != nil {
 				logger.Errorf("Server error: %v", err)
 				cancel()
 			}
@@ -87,15 +128,35 @@ func init() {
 	rootCmd.AddCommand(serveCmd)
 }
 
-// initPipeline initializes the agent pipeline
-func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
+// agentComponents holds the pipeline and the parts of it the API service uses
+type agentComponents struct {
+	pipeline   *agent.Pipeline
+	reloader   *skill.Reloader
+	skillIndex *skill.SkillIndex // nil when retrieval is disabled
+	artifacts  *artifact.Store
+	runbooks   *runbook.Catalog
+}
+
+// initPipeline initializes the agent pipeline and the components around it
+func initPipeline(cfg *config.Config) (*agentComponents, error) {
 	// Load skills
 	skillsDir := cfg.Skills.Dir
 	if skillsDir == "" {
 		skillsDir = "./skills"
 	}
 
+	// Pinned versions and the lockfile select and verify installed skill versions
+	skillsConfig, err := loadSkillsConfig(cfg.Skills)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skills config: %w", err)
+	}
+	if err := skillsConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid skills config: %w", err)
+	}
+
 	loader := skill.NewLoader(skillsDir)
+	loader.SetPins(skillsConfig.Pins())
+	loader.SetLockfile(cfg.Skills.Lockfile)
 	skills, err := loader.LoadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load skills: %w", err)
@@ -109,6 +170,16 @@ func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
 		}
 	}
 
+	// Built-in Go skills, kept across reloads
+	builtins := cfg.Skills.Builtins
+	if !config.Viper().IsSet("skills.builtins") {
+		builtins = native.BuiltinNames()
+	}
+	if err := native.Register(registry, builtins); err != nil {
+		return nil, fmt.Errorf("failed to register built-in skills: %w", err)
+	}
+	reloader := skill.NewReloader(loader, registry)
+
 	// Create LLM client
 	apiKey := cfg.LLM.APIKey
 	if apiKey == "" {
@@ -123,10 +194,90 @@ func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
 		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
+	// Load prompt templates
+	prompts, err := llm.NewPromptRegistry(cfg.LLM.Prompts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	// Create output digester for validation and replan prompts
+	digester := llm.NewDigester(llm.DigestConfig{
+		HeadLines:            cfg.LLM.Digest.HeadLines,
+		TailLines:            cfg.LLM.Digest.TailLines,
+		MaxErrorLines:        cfg.LLM.Digest.MaxErrorLines,
+		SummarizeAboveTokens: cfg.LLM.Digest.SummarizeAboveTokens,
+		DefaultBudget:        cfg.LLM.Digest.DefaultBudget,
+		Budgets:              cfg.LLM.Digest.Budgets,
+	}, llmClient)
+	digester.SetPrompts(prompts)
+
+	// Create redactor
+	redactor, err := newRedactor(cfg.Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create redactor: %w", err)
+	}
+
+	// Create skill router
+	// All skill execution goes through the router, which redacts the results
+	executor := direct.NewDirectExecutor(30 * time.Minute) // 30 minutes timeout
+	executor.SetInterpreters(skillsConfig.Interpreters())
+	sandbox, err := newSandbox(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := executor.SetSandbox(sandbox); err != nil {
+		return nil, fmt.Errorf("invalid skills sandbox: %w", err)
+	}
+	router := skill.NewRouter(executor, skillsConfig, registry)
+	logRoutes(router, registry, skillsConfig)
+	router.SetRedactor(redactor)
+	secrets, err := newSecretResolver(cfg.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret providers: %w", err)
+	}
+	router.SetSecrets(secrets)
+
+	// Create artifact store, holding the workspace and artifacts of each task
+	artifacts, err := artifact.NewStore(cfg.Artifacts.Dir, cfg.Artifacts.Retention)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load policy rules, evaluated before plans and steps run
+	rules, err := newPolicyEngine(cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load maintenance windows and freezes, checked before mutating steps run
+	calendar, err := newMaintenanceCalendar(cfg.Maintenance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load runbooks, run as is or picked by the planner
+	runbooks, err := newRunbookCatalog(cfg.Runbooks)
+	if err != nil {
+		return nil, err
+	}
+
+	components := &agentComponents{reloader: reloader, artifacts: artifacts, runbooks: runbooks}
+
 	// Create agents
 	planner := agent.NewPlanningAgent(llmClient, registry)
-	executor := direct.NewDirectExecutor(30 * time.Minute) // 30 minutes timeout
-	executorAgent := agent.NewExecutorAgent(executor, registry)
+	planner.SetPrompts(prompts)
+	planner.SetSkillContextBudget(cfg.Skills.PromptBudget)
+	planner.SetRunbooks(runbooks)
+	if cfg.Skills.Retrieval.Enabled {
+		index, err := newSkillIndex(cfg, registry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create skill index: %w", err)
+		}
+		planner.SetSkillIndex(index)
+		components.skillIndex = index
+	}
+	executorAgent := agent.NewExecutorAgent(router, registry)
+	executorAgent.SetArtifacts(artifacts)
 
 	// Check if checkpoint or tracing is enabled
 	// Both are independent features:
@@ -137,12 +288,23 @@ func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
 
 	// If either checkpoint or tracing is enabled, use the new graph builder
 	if useCheckpoint || useTracing {
-		// Create skill router for graph builder
-		skillConfig := skill.GetDefaultConfig()
-		router := skill.NewRouter(executor, skillConfig, registry)
-
 		// Create graph builder
 		builder := graph.NewOpsGraphBuilder(router, llmClient)
+		builder.SetPrompts(prompts)
+		builder.SetDigester(digester)
+		builder.SetPlanner(planner)
+		builder.SetArtifacts(artifacts)
+		builder.SetReplanPolicy(graph.ReplanPolicy{
+			MaxReplans:    cfg.Agent.Replan.MaxReplans,
+			MaxSteps:      cfg.Agent.Replan.MaxSteps,
+			KeepCompleted: cfg.Agent.Replan.KeepCompleted,
+		})
+		if !graph.ValidRollbackMode(cfg.Agent.Rollback.Mode) {
+			return nil, fmt.Errorf("invalid agent.rollback.mode %q: use off, auto or approval", cfg.Agent.Rollback.Mode)
+		}
+		builder.SetRollbackPolicy(graph.RollbackPolicy{Mode: cfg.Agent.Rollback.Mode})
+		builder.SetPolicy(rules)
+		builder.SetMaintenance(calendar)
 
 		// Set up tracing if enabled
 		if useTracing {
@@ -151,6 +313,14 @@ func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
 				logTracer := tracer.NewLogTracer(cfg.Agent.Tracing.Log.Level)
 				tracers = append(tracers, logTracer)
 			}
+			if cfg.Agent.Tracing.StepLogs.Enabled {
+				stepLogTracer, err := tracer.NewStepLogTracer(cfg.Agent.Tracing.StepLogs.Dir)
+				if err != nil {
+					return nil, err
+				}
+				tracers = append(tracers, stepLogTracer)
+				logger.Infof("Step logs are written to %s", cfg.Agent.Tracing.StepLogs.Dir)
+			}
 
 			if len(tracers) > 0 {
 				multiTracer := tracer.NewMultiTracer(tracers...)
@@ -178,7 +348,10 @@ func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
 				logger.Info("Tracing is also enabled")
 			}
 
-			return pipeline, nil
+			pipeline.SetArtifacts(artifacts)
+			pipeline.SetDefaultEnvironment(cfg.Policy.DefaultEnvironment)
+			components.pipeline = pipeline
+			return components, nil
 		}
 
 		// Tracing enabled but checkpoint disabled: use memory checkpoint store
@@ -197,12 +370,207 @@ func initPipeline(cfg *config.Config) (*agent.Pipeline, error) {
 
 		logger.Info("Pipeline initialized with tracing support (memory checkpoint store, no persistence)")
 
-		return pipeline, nil
+		pipeline.SetArtifacts(artifacts)
+		pipeline.SetDefaultEnvironment(cfg.Policy.DefaultEnvironment)
+		components.pipeline = pipeline
+		return components, nil
 	}
 
 	// Create pipeline without checkpoint or tracing (legacy mode)
+	// The legacy graph has no policy node, rules must not be silently ignored
+	if rules.Rules() > 0 {
+		return nil, fmt.Errorf("policy rules in %s require the graph pipeline, enable checkpoint or tracing", cfg.Policy.File)
+	}
+	if calendar.Entries() > 0 {
+		return nil, fmt.Errorf("maintenance windows in %s require the graph pipeline, enable checkpoint or tracing", cfg.Maintenance.File)
+	}
 	pipeline := agent.NewPipeline(planner, executorAgent)
 	logger.Info("Pipeline initialized in legacy mode (no checkpoint, no tracing)")
 
-	return pipeline, nil
+	pipeline.SetArtifacts(artifacts)
+	pipeline.SetDefaultEnvironment(cfg.Policy.DefaultEnvironment)
+	components.pipeline = pipeline
+	return components, nil
+}
+
+// newMaintenanceCalendar loads and compiles the maintenance windows and freezes
+func newMaintenanceCalendar(cfg config.Maintenance) (*maintenance.Calendar, error) {
+	file, err := maintenance.LoadFile(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := maintenance.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance windows in %s: %w", cfg.File, err)
+	}
+	if calendar.Entries() > 0 {
+		logger.Infof("Loaded %d maintenance windows and freezes from %s (outside windows: %s)",
+			calendar.Entries(), cfg.File, calendar.OutsideWindow())
+	}
+	return calendar, nil
+}
+
+// newRunbookCatalog loads the runbooks of the runbook directory
+func newRunbookCatalog(cfg config.Runbooks) (*runbook.Catalog, error) {
+	catalog, err := runbook.Load(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid runbooks in %s: %w", cfg.Dir, err)
+	}
+	if catalog.Len() > 0 {
+		logger.Infof("Loaded %d runbooks from %s", catalog.Len(), cfg.Dir)
+	}
+	return catalog, nil
+}
+
+// newPolicyEngine loads and compiles the rules of the policy file
+func newPolicyEngine(cfg config.Policy) (*policy.Engine, error) {
+	file, err := policy.LoadFile(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := policy.New(file.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy rules in %s: %w", cfg.File, err)
+	}
+	if engine.Rules() > 0 {
+		logger.Infof("Loaded %d policy rules from %s", engine.Rules(), cfg.File)
+	}
+	return engine, nil
+}
+
+// loadSkillsConfig reads and merges the skills config and its includes,
+// missing files are empty configs
+func loadSkillsConfig(cfg config.Skills) (*skill.Config, error) {
+	skillsConfig, files, err := skill.LoadConfigFiles(append([]string{cfg.Config}, cfg.Includes...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		logger.Debugf("Skills config read from %s", strings.Join(files, ", "))
+	}
+	return skillsConfig, nil
+}
+
+// logRoutes logs how each skill is executed
+func logRoutes(router *skill.Router, registry *skill.Registry, skillsConfig *skill.Config) {
+	skills := registry.List()
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+	for _, s := range skills {
+		logger.Infof("Skill %s: %s", s.Name, router.Route(s))
+	}
+	for name := range skillsConfig.Skills {
+		if !registry.Exists(name) {
+			logger.Warnf("Skills config has settings for %s, which is not loaded", name)
+		}
+	}
+}
+
+// newSkillIndex creates the index selecting the skills of planning prompts
+func newSkillIndex(cfg *config.Config, registry *skill.Registry) (*skill.SkillIndex, error) {
+	retrieval := cfg.Skills.Retrieval
+	index := skill.NewSkillIndex(registry, retrieval.TopK)
+	if !retrieval.Embeddings.Enabled {
+		logger.Infof("Skill retrieval enabled (BM25, top %d)", index.TopK())
+		return index, nil
+	}
+
+	embedder, err := llm.NewEmbedder(cfg.LLM.Provider, cfg.LLM.APIKey, cfg.LLM.URL, retrieval.Embeddings.Model)
+	if err != nil {
+		return nil, err
+	}
+	index.SetEmbedder(embedder, retrieval.Embeddings.Weight)
+	logger.Infof("Skill retrieval enabled (BM25 with embeddings, top %d)", index.TopK())
+	return index, nil
+}
+
+// newSandbox creates the restrictions of skill scripts from config
+func newSandbox(cfg *config.Config) (direct.Sandbox, error) {
+	sandbox, err := direct.NewSandbox(cfg.Skills.Sandbox, cfg.Skills.Dir)
+	if err != nil {
+		return sandbox, err
+	}
+	if sandbox.Namespaces {
+		logger.Infof("Skill scripts run in user and mount namespaces, %s is read-only", sandbox.ReadOnlyPaths[0])
+	}
+	if sandbox.RunAs != "" {
+		logger.Infof("Skill scripts run as %s", sandbox.RunAs)
+	}
+	return sandbox, nil
+}
+
+// newSecretResolver creates the resolver of secret references from the configured providers
+func newSecretResolver(cfg config.Secrets) (*secret.Resolver, error) {
+	var providers []secret.SecretProvider
+	for i, p := range cfg.Providers {
+		switch p.Type {
+		case "env":
+			providers = append(providers, secret.NewEnvProvider(p.Prefix))
+		case "file":
+			provider, err := newFileSecretProvider(p)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
+		case "vault":
+			tokenEnv := p.TokenEnv
+			if tokenEnv == "" {
+				tokenEnv = "VAULT_TOKEN"
+			}
+			provider, err := secret.NewVaultProvider(secret.VaultOptions{
+				Addr:      p.Addr,
+				Token:     os.Getenv(tokenEnv),
+				Mount:     p.Mount,
+				KVVersion: p.KVVersion,
+				Namespace: p.Namespace,
+				Timeout:   p.Timeout,
+			})
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
+		default:
+			return nil, fmt.Errorf("secret provider %d has unknown type %q", i, p.Type)
+		}
+	}
+
+	resolver := secret.NewResolver(providers...)
+	if len(providers) > 0 {
+		logger.Infof("Secret references are resolved from: %s", strings.Join(resolver.Providers(), ", "))
+	}
+	return resolver, nil
+}
+
+// newFileSecretProvider creates the provider of an encrypted secret file
+func newFileSecretProvider(p config.SecretProvider) (*secret.FileProvider, error) {
+	if p.Path == "" {
+		return nil, fmt.Errorf("file secret provider needs a path")
+	}
+	key, err := secret.LoadKey(p.KeyFile, p.KeyEnv)
+	if err != nil {
+		return nil, err
+	}
+	return secret.NewFileProvider(p.Path, key)
+}
+
+// newRedactor creates the redactor from config. When redaction is disabled it has no
+// detectors and only removes the resolved secrets.
+func newRedactor(cfg config.Redaction) (*redact.Redactor, error) {
+	if !cfg.Enabled {
+		logger.Warn("Redaction is disabled, skill output is stored and sent to the LLM verbatim, except resolved secrets")
+		return redact.New(cfg.Replacement), nil
+	}
+
+	patterns := make([]redact.Pattern, len(cfg.Patterns))
+	for i, p := range cfg.Patterns {
+		patterns[i] = redact.Pattern{Name: p.Name, Regex: p.Regex}
+	}
+
+	return redact.NewFromOptions(redact.Options{
+		Replacement:     cfg.Replacement,
+		Patterns:        patterns,
+		DisableDefaults: cfg.DisableDefaults,
+		Entropy:         cfg.Entropy.Enabled,
+		EntropyMinLen:   cfg.Entropy.MinLength,
+		EntropyMinBits:  cfg.Entropy.Threshold,
+	})
 }
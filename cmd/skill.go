@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hb-chen/opskills/internal/config"
+	"github.com/hb-chen/opskills/internal/skill"
+	"github.com/hb-chen/opskills/internal/skill/install"
+	"github.com/hb-chen/opskills/internal/skill/skilltest"
+)
+
+var skillInstallOpts install.Options
+
+// skillCmd represents the skill command
+var skillCmd = &cobra.Command{
+	Use:   "skill",
+	Short: "Manage installed skills",
+	Long: `Install, upgrade, list, remove, lint and test skills.
+
+Skills are installed side by side into <skills.dir>/<name>@<version>. The agent
+loads the version pinned in the skills config (skills.config), or else the highest
+installed version. Checksums of installed versions are recorded in the lockfile
+(skills.lock) and verified whenever skills are loaded.`,
+}
+
+// skillInstallCmd installs a skill from a tarball, a directory or a git repository
+var skillInstallCmd = &cobra.Command{
+	Use:   "install <source>",
+	Short: "Install a skill from a tarball, a directory or a local git repository",
+	Long: `Install a skill from a local source:
+
+  ./my-skill                 directory containing SKILL.md
+  ./my-skill-1.2.0.tar.gz    tarball (.tar, .tar.gz or .tgz)
+  git+./repo#v1.2.0          local git repository at a branch, tag or commit
+                             (also detected for paths containing .git)
+
+The version is read from the SKILL.md frontmatter or marketplace.json.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+
+		entry, err := installer.Install(args[0], skillInstallOpts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "installed %s %s (%s)\n", entry.Name, entry.Version, entry.Digest)
+		if skillInstallOpts.Pin {
+			fmt.Fprintf(cmd.OutOrStdout(), "pinned %s to %s\n", entry.Name, entry.Version)
+		}
+		return nil
+	},
+}
+
+// skillUpgradeCmd installs the newest version of a skill
+var skillUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <name> [source]",
+	Short: "Install the newest version of a skill and move its pin",
+	Long: `Fetch a skill again from its recorded source (or the given source) and install
+it if the version is newer than every installed version. Older versions are kept;
+a pin in the skills config is moved to the new version.`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+
+		previous, entry, err := installer.Upgrade(args[0], source, skillInstallOpts)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case previous == entry:
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is up to date (%s)\n", entry.Name, entry.Version)
+		case previous == nil:
+			fmt.Fprintf(cmd.OutOrStdout(), "installed %s %s (%s)\n", entry.Name, entry.Version, entry.Digest)
+		default:
+			fmt.Fprintf(cmd.OutOrStdout(), "upgraded %s %s -> %s (%s)\n", entry.Name, previous.Version, entry.Version, entry.Digest)
+		}
+		return nil
+	},
+}
+
+var skillRemoveForce bool
+
+// skillRemoveCmd removes installed skill versions
+var skillRemoveCmd = &cobra.Command{
+	Use:   "remove <name>[@version]",
+	Short: "Remove one or all installed versions of a skill",
+	Long: `Remove an installed skill version, or every installed version when no version is
+given. Pinned versions are only removed with --force, which also removes the pin.
+Skills copied into the skills directory by hand are never removed.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+
+		name, version, _ := strings.Cut(args[0], "@")
+		removed, err := installer.Remove(name, version, skillRemoveForce)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range removed {
+			fmt.Fprintf(cmd.OutOrStdout(), "removed %s %s\n", entry.Name, entry.Version)
+		}
+		return nil
+	},
+}
+
+// skillListCmd lists skills and verifies installed versions
+var skillListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List skills and verify the checksums of installed versions",
+	Long: `List every installed skill version and every skill copied by hand, with its
+pin, whether the agent loads it, and the result of the lockfile checksum
+verification. Fails if an installed version does not match the lockfile.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installer, err := newInstaller()
+		if err != nil {
+			return err
+		}
+
+		installed, err := installer.List()
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tACTIVE\tPINNED\tINTEGRITY\tSOURCE")
+		for _, item := range installed {
+			integrity, source := "ok", item.Source
+			switch {
+			case !item.Managed:
+				integrity, source = "-", "local ("+item.Dir+")"
+			case item.Err != nil:
+				integrity = "FAILED"
+				failed++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				item.Name, orDash(item.Version), yesNo(item.Active), yesNo(item.Pinned), integrity, source)
+		}
+		w.Flush()
+
+		for _, item := range installed {
+			if item.Err != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "\n%s %s: %v", item.Name, item.Version, item.Err)
+			}
+		}
+		if failed > 0 {
+			fmt.Fprintln(cmd.OutOrStdout())
+			return fmt.Errorf("%d installed skill version(s) failed the integrity check", failed)
+		}
+		return nil
+	},
+}
+
+var skillLintFormat string
+
+// skillLintCmd validates skills
+var skillLintCmd = &cobra.Command{
+	Use:   "lint [path...]",
+	Short: "Validate skills (frontmatter, referenced files, scripts, marketplace.json, examples)",
+	Long: `Validate the SKILL.md frontmatter against the schema, check that every file referenced
+in the SKILL.md body exists, that scripts are executable and pass bash -n, and that
+marketplace.json and the example YAML files are valid.
+
+Paths may be skill directories or any file inside a skill, so the command can be
+used as a pre-commit hook. Without paths every skill in skills.dir is linted.
+Exits non-zero if any error is found; --format json prints a machine-readable report.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if skillLintFormat != "text" && skillLintFormat != "json" {
+			return fmt.Errorf("unknown format %q, expected text or json", skillLintFormat)
+		}
+
+		var dirs []string
+		if len(args) == 0 {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			if dirs, err = skill.FindSkillDirs(cfg.Skills.Dir); err != nil {
+				return fmt.Errorf("failed to find skills: %w", err)
+			}
+		} else {
+			seen := make(map[string]bool)
+			for _, arg := range args {
+				dir, err := skill.SkillDirOf(arg)
+				if err != nil {
+					return err
+				}
+				if !seen[dir] {
+					seen[dir] = true
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+
+		report := skill.LintSkills(dirs...)
+		if skillLintFormat == "json" {
+			if err := report.WriteJSON(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+		} else {
+			report.WriteText(cmd.OutOrStdout())
+		}
+
+		if !report.OK() {
+			// The report already describes the errors
+			cmd.SilenceErrors = true
+			return fmt.Errorf("%d lint error(s)", report.Errors)
+		}
+		return nil
+	},
+}
+
+var (
+	skillTestFormat  string
+	skillTestRun     string
+	skillTestTimeout time.Duration
+	skillTestKeep    bool
+)
+
+// skillTestCmd runs the test cases shipped with skills
+var skillTestCmd = &cobra.Command{
+	Use:   "test [skill|path...]",
+	Short: "Run skill test cases against stubbed commands",
+	Long: `Run the test cases in <skill>/tests/*.yaml. Each case runs a skill action through the
+direct executor in a temporary workspace, with fake commands (stubs) first on PATH
+that print scripted output and exit codes, and checks the exit code, the output and
+the stub invocations.
+
+Arguments are skill names or paths inside skill directories; without arguments
+every loaded skill with tests is run.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if skillTestFormat != "text" && skillTestFormat != "json" {
+			return fmt.Errorf("unknown format %q, expected text or json", skillTestFormat)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		skillsConfig, err := loadSkillsConfig(cfg.Skills)
+		if err != nil {
+			return fmt.Errorf("failed to read skills config: %w", err)
+		}
+
+		runner := skilltest.NewRunner(skillTestTimeout)
+		runner.SetKeepWorkspace(skillTestKeep)
+		runner.SetInterpreters(skillsConfig.Interpreters())
+		if skillTestRun != "" {
+			filter, err := regexp.Compile(skillTestRun)
+			if err != nil {
+				return fmt.Errorf("invalid --run pattern: %w", err)
+			}
+			runner.SetFilter(filter)
+		}
+
+		skills, err := resolveTestSkills(args)
+		if err != nil {
+			return err
+		}
+		for _, s := range skills {
+			if err := runner.RunSkill(s); err != nil {
+				return err
+			}
+		}
+
+		report := runner.Report()
+		if skillTestFormat == "json" {
+			if err := report.WriteJSON(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+		} else {
+			report.WriteText(cmd.OutOrStdout())
+		}
+
+		if !report.OK() {
+			// The report already describes the failures
+			cmd.SilenceErrors = true
+			return fmt.Errorf("%d test case(s) failed", report.Failed)
+		}
+		return nil
+	},
+}
+
+// resolveTestSkills returns the skills named or located by args, or every loaded skill
+func resolveTestSkills(args []string) ([]*skill.Skill, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	pins, err := install.ReadPins(cfg.Skills.Config)
+	if err != nil {
+		return nil, err
+	}
+	loader := skill.NewLoader(cfg.Skills.Dir)
+	loader.SetPins(pins)
+	loader.SetLockfile(cfg.Skills.Lockfile)
+
+	var loaded []*skill.Skill
+	if _, err := os.Stat(cfg.Skills.Dir); err == nil {
+		if loaded, err = loader.LoadAll(); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) == 0 {
+		return loaded, nil
+	}
+
+	var skills []*skill.Skill
+	for _, arg := range args {
+		if _, err := os.Stat(arg); err == nil {
+			dir, err := skill.SkillDirOf(arg)
+			if err != nil {
+				return nil, err
+			}
+			s, err := skill.ParseSKILL(filepath.Join(dir, "SKILL.md"))
+			if err != nil {
+				return nil, err
+			}
+			skills = append(skills, s)
+			continue
+		}
+
+		found := false
+		for _, s := range loaded {
+			if s.Name == arg {
+				skills = append(skills, s)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("skill not found: %s", arg)
+		}
+	}
+	return skills, nil
+}
+
+// newInstaller creates an installer from the skills configuration
+func newInstaller() (*install.Installer, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return install.NewInstaller(cfg.Skills.Dir, cfg.Skills.Lockfile, cfg.Skills.Config), nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	for _, c := range []*cobra.Command{skillInstallCmd, skillUpgradeCmd} {
+		c.Flags().StringVar(&skillInstallOpts.Path, "path", "", "skill directory inside the source, if it contains several skills")
+		c.Flags().StringVar(&skillInstallOpts.SHA256, "sha256", "", "expected sha256 of a tarball source")
+		c.Flags().BoolVar(&skillInstallOpts.Force, "force", false, "reinstall a version that is already installed")
+	}
+	skillInstallCmd.Flags().BoolVar(&skillInstallOpts.Pin, "pin", false, "pin the installed version in the skills config")
+	skillRemoveCmd.Flags().BoolVar(&skillRemoveForce, "force", false, "also remove a pinned version and its pin")
+	skillLintCmd.Flags().StringVar(&skillLintFormat, "format", "text", "output format: text or json")
+	skillTestCmd.Flags().StringVar(&skillTestFormat, "format", "text", "output format: text or json")
+	skillTestCmd.Flags().StringVar(&skillTestRun, "run", "", "only run cases whose name matches this regular expression")
+	skillTestCmd.Flags().DurationVar(&skillTestTimeout, "timeout", skilltest.DefaultTimeout, "timeout per case")
+	skillTestCmd.Flags().BoolVar(&skillTestKeep, "keep", false, "keep the workspace of every case for inspection")
+
+	skillCmd.AddCommand(skillInstallCmd, skillUpgradeCmd, skillRemoveCmd, skillListCmd, skillLintCmd, skillTestCmd)
+	rootCmd.AddCommand(skillCmd)
+}
@@ -5,33 +5,40 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hb-chen/opskills/internal/artifact"
 	"github.com/hb-chen/opskills/internal/skill"
-	"github.com/hb-chen/opskills/internal/skill/direct"
 	"github.com/hb-chen/opskills/internal/state"
+	"github.com/hb-chen/opskills/pkg/logger"
 )
 
 // ExecutorAgent executes steps using skills
 // It implements the graph.Executor interface
 type ExecutorAgent struct {
-	executor *direct.DirectExecutor
-	registry *skill.Registry
+	router    *skill.Router
+	registry  *skill.Registry
+	artifacts *artifact.Store // Optional, collects the artifacts of steps
 }
 
 // NewExecutorAgent creates a new executor agent
-func NewExecutorAgent(executor *direct.DirectExecutor, registry *skill.Registry) *ExecutorAgent {
+// Steps are executed through the router, so execution mode and redaction apply
+func NewExecutorAgent(router *skill.Router, registry *skill.Registry) *ExecutorAgent {
 	return &ExecutorAgent{
-		executor: executor,
+		router:   router,
 		registry: registry,
 	}
 }
 
+// SetArtifacts sets the store collecting the artifacts steps leave in the task workspace
+func (a *ExecutorAgent) SetArtifacts(store *artifact.Store) {
+	a.artifacts = store
+}
+
 // Execute executes a single step
 func (a *ExecutorAgent) Execute(ctx context.Context, step *state.Step) (*state.StepResult, error) {
 	startTime := time.Now()
 
-	// Get the skill
-	skill, err := a.registry.Get(step.SkillName)
-	if err != nil {
+	// Check the skill exists
+	if _, err := a.registry.Get(step.SkillName); err != nil {
 		return &state.StepResult{
 			StepID:  step.ID,
 			Success: false,
@@ -53,27 +60,72 @@ func (a *ExecutorAgent) Execute(ctx context.Context, step *state.Step) (*state.S
 
 	// Execute the skill
 	// ExecutionParams is a type alias for map[string]interface{}, so we can pass execParams directly
-	result, err := a.executor.Execute(skill, execParams)
+	if handler := skill.OutputHandlerFrom(ctx); handler != nil {
+		// Tag the streamed lines with the step producing them
+		ctx = skill.WithOutputHandler(ctx, func(line skill.OutputLine) {
+			line.StepID = step.ID
+			handler(line)
+		})
+	}
+	// Retry transient failures according to the action and step retry policies
+	result, err := a.router.ExecuteWithRetry(ctx, step.SkillName, execParams, step.Retry)
+	artifacts := a.collectArtifacts(ctx, step, result)
 	if err != nil {
 		duration := time.Since(startTime)
+		errorMsg := err.Error()
+		redactions := 0
+		route := ""
+		attempts := 0
+		if result != nil {
+			errorMsg = result.Error
+			redactions = result.Redactions
+			route = result.Route
+			attempts = result.Attempts
+		}
 		return &state.StepResult{
-			StepID:   step.ID,
-			Success:  false,
-			Error:    result.Error,
-			Duration: duration.String(),
+			StepID:     step.ID,
+			Success:    false,
+			Error:      errorMsg,
+			Duration:   duration.String(),
+			Redactions: redactions,
+			Artifacts:  artifacts,
+			Route:      route,
+			Attempts:   attempts,
 		}, err
 	}
 
 	duration := time.Since(startTime)
 	return &state.StepResult{
-		StepID:   step.ID,
-		Success:  result.Success,
-		Output:   result.Output,
-		Error:    result.Error,
-		Duration: duration.String(),
+		StepID:     step.ID,
+		Success:    result.Success,
+		Output:     result.Output,
+		Error:      result.Error,
+		Duration:   duration.String(),
+		Redactions: result.Redactions,
+		Artifacts:  artifacts,
+		Route:      result.Route,
+		Attempts:   result.Attempts,
+		Simulated:  result.DryRun,
+		Skipped:    result.Skipped,
 	}, nil
 }
 
+// collectArtifacts copies the artifacts reported by a step into the store
+func (a *ExecutorAgent) collectArtifacts(ctx context.Context, step *state.Step, result *skill.ExecutionResult) []*state.Artifact {
+	if a.artifacts == nil || result == nil || len(result.Artifacts) == 0 {
+		return nil
+	}
+	taskID, ok := a.artifacts.TaskOf(skill.WorkspaceFrom(ctx))
+	if !ok {
+		return nil
+	}
+	artifacts, err := a.artifacts.Collect(taskID, step.ID, result.Artifacts)
+	if err != nil {
+		logger.Warnf("Failed to collect the artifacts of step %d: %v", step.ID, err)
+	}
+	return artifacts
+}
+
 // ExecutePlan executes all steps in a plan
 func (a *ExecutorAgent) ExecutePlan(ctx context.Context, plan *state.Plan) ([]*state.StepResult, error) {
 	results := make([]*state.StepResult, 0, len(plan.Steps))
@@ -86,6 +138,7 @@ func (a *ExecutorAgent) ExecutePlan(ctx context.Context, plan *state.Plan) ([]*s
 			Action:      planStep.Action,
 			Description: planStep.Description,
 			Params:      planStep.Params,
+			Retry:       planStep.Retry,
 			Status:      "pending",
 		}
 
@@ -79,7 +79,7 @@ func (a *ExecutorAgentLangGraph) Execute(ctx context.Context, step *state.Step)
 		execParams[k] = v
 	}
 
-	result, err := a.router.Execute(step.SkillName, execParams)
+	result, err := a.router.ExecuteContext(ctx, step.SkillName, execParams)
 	if err != nil {
 		return &state.StepResult{
 			StepID:  step.ID,
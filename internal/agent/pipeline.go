@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hb-chen/opskills/internal/artifact"
 	"github.com/hb-chen/opskills/internal/graph"
+	"github.com/hb-chen/opskills/internal/redact"
+	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/state"
 	langgraph "github.com/smallnest/langgraphgo/graph"
 )
@@ -17,6 +20,9 @@ type Pipeline struct {
 	planner         *PlanningAgent
 	executor        *ExecutorAgent
 	useCheckpoint   bool
+	artifacts       *artifact.Store // Optional, tasks get no workspace when unset
+
+	defaultEnvironment string // Environment of tasks submitted without one
 }
 
 // NewPipeline creates a new pipeline with legacy graph
@@ -42,10 +48,159 @@ func NewPipelineWithCheckpoint(checkpointGraph *graph.CheckpointableGraph, plann
 	}
 }
 
+// SetArtifacts sets the store giving each task a workspace and keeping its artifacts
+func (p *Pipeline) SetArtifacts(store *artifact.Store) {
+	p.artifacts = store
+}
+
+// SetDefaultEnvironment sets the environment of tasks submitted without one
+func (p *Pipeline) SetDefaultEnvironment(env string) {
+	p.defaultEnvironment = env
+}
+
+// TaskOptions are the settings of a task besides its query
+type TaskOptions struct {
+	Mode        string // See state.ModeExecute, empty for state.ModeExecute
+	Requester   string // Identity of whoever submitted the task, evaluated by policy rules
+	Environment string // Target environment of the task, evaluated by policy rules
+
+	// Labels select the maintenance windows and freezes of the task, e.g. cluster: prod-a
+	Labels map[string]string
+	// OverrideWindow asks to run outside maintenance windows, once approved
+	OverrideWindow bool
+	// Plan is run as is instead of planning the query, e.g. the plan of a schedule
+	Plan *state.Plan
+}
+
 // Execute executes a task through the pipeline
-func (p *Pipeline) Execute(ctx context.Context, query string, taskID string) (*state.State, error) {
+func (p *Pipeline) Execute(ctx context.Context, query, taskID string, opts TaskOptions) (*state.State, error) {
+	if !state.ValidMode(opts.Mode) {
+		return nil, fmt.Errorf("unknown task mode %q", opts.Mode)
+	}
+	if opts.Mode == "" {
+		opts.Mode = state.ModeExecute
+	}
+	if opts.Environment == "" {
+		opts.Environment = p.defaultEnvironment
+	}
+	ctx, err := p.withTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	finalState, err := p.execute(ctx, query, taskID, opts)
+	if finalState != nil && p.artifacts != nil {
+		finalState.Artifacts, _ = p.artifacts.List(taskID)
+	}
+	return finalState, err
+}
+
+// ExecuteApproved executes the plan of a task held by policy rules requiring
+// approval, once its approval is granted, from the state of the task
+func (p *Pipeline) ExecuteApproved(ctx context.Context, taskState *state.State) (*state.State, error) {
+	if !p.useCheckpoint || p.checkpointGraph == nil {
+		return nil, fmt.Errorf("approval requires the graph pipeline, enable checkpoint or tracing")
+	}
+	if taskState.Approval == nil || taskState.Approval.Status != state.ApprovalApproved {
+		return nil, fmt.Errorf("task %s is not approved", taskState.TaskID)
+	}
+	return p.admit(ctx, taskState)
+}
+
+// ExecuteScheduled executes the remaining steps of a task held until a maintenance
+// window opens, from the state of the task. It is held again when no window is open.
+func (p *Pipeline) ExecuteScheduled(ctx context.Context, taskState *state.State) (*state.State, error) {
+	if !p.useCheckpoint || p.checkpointGraph == nil {
+		return nil, fmt.Errorf("maintenance windows require the graph pipeline, enable checkpoint or tracing")
+	}
+	if taskState.Maintenance == nil || taskState.Maintenance.Status != state.MaintenanceScheduled {
+		return nil, fmt.Errorf("task %s is not scheduled", taskState.TaskID)
+	}
+	return p.admit(ctx, taskState)
+}
+
+// admit runs a held task from the policy node, which admits its plan again
+func (p *Pipeline) admit(ctx context.Context, taskState *state.State) (*state.State, error) {
+	ctx, err := p.withTask(ctx, taskState.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	runnable, err := p.checkpointGraph.Graph.CompileCheckpointable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile checkpointable graph: %w", err)
+	}
+
+	config := &langgraph.Config{
+		Configurable: map[string]any{
+			"thread_id": taskState.TaskID,
+		},
+		ResumeFrom: []string{"policy"},
+	}
+	finalState, err := p.invoke(ctx, runnable, graph.StateToMap(taskState), config, taskState.TaskID)
+	if p.artifacts != nil {
+		finalState.Artifacts, _ = p.artifacts.List(taskState.TaskID)
+	}
+	return finalState, err
+}
+
+// Rollback runs the compensations of a failed task once approved, from the final
+// state of the task. Failed compensations run again.
+func (p *Pipeline) Rollback(ctx context.Context, taskState *state.State) (*state.State, error) {
+	if !p.useCheckpoint || p.checkpointGraph == nil {
+		return nil, fmt.Errorf("rollback requires the graph pipeline, enable checkpoint or tracing")
+	}
+	if taskState.Rollback == nil {
+		return nil, fmt.Errorf("task %s has no compensation to run", taskState.TaskID)
+	}
+	ctx, err := p.withTask(ctx, taskState.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	runnable, err := p.checkpointGraph.Graph.CompileCheckpointable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile checkpointable graph: %w", err)
+	}
+	stateMap := graph.StateToMap(taskState)
+	stateMap["rollback_approved"] = true
+	config := &langgraph.Config{
+		Configurable: map[string]any{
+			"thread_id": taskState.TaskID,
+		},
+		ResumeFrom: []string{"rollback"},
+	}
+
+	resultMap, err := runnable.InvokeWithConfig(ctx, stateMap, config)
+	finalState := p.mapToState(resultMap, taskState.TaskID)
+	if err != nil {
+		finalState.Error = err.Error()
+	}
+	if p.artifacts != nil {
+		finalState.Artifacts, _ = p.artifacts.List(taskState.TaskID)
+	}
+	finalState.UpdatedAt = time.Now().Format(time.RFC3339)
+	return finalState, err
+}
+
+// withTask scopes the secrets resolved by the steps of a task to this run of it,
+// they are redacted from the output of all of them and forgotten with ctx, and sets
+// the workspace of the task in ctx, when tasks get one
+func (p *Pipeline) withTask(ctx context.Context, taskID string) (context.Context, error) {
+	ctx = skill.WithKnownSecrets(ctx, redact.NewKnown())
+	if p.artifacts == nil {
+		return ctx, nil
+	}
+	workspace, err := p.artifacts.Workspace(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return skill.WithWorkspace(ctx, workspace), nil
+}
+
+func (p *Pipeline) execute(ctx context.Context, query, taskID string, opts TaskOptions) (*state.State, error) {
 	if p.useCheckpoint && p.checkpointGraph != nil {
-		return p.executeWithCheckpoint(ctx, query, taskID)
+		return p.executeWithCheckpoint(ctx, query, taskID, opts)
 	}
 
 	// Use legacy graph execution
@@ -54,12 +209,28 @@ func (p *Pipeline) Execute(ctx context.Context, query string, taskID string) (*s
 		return nil, fmt.Errorf("pipeline graph not initialized: both checkpoint and legacy graphs are nil")
 	}
 
+	// The legacy graph has no plan check, dry runs only need the context
+	if opts.Mode == state.ModePlanOnly {
+		return nil, fmt.Errorf("plan_only mode requires the graph pipeline, enable checkpoint or tracing")
+	}
+	if opts.Plan != nil {
+		return nil, fmt.Errorf("fixed plans require the graph pipeline, enable checkpoint or tracing")
+	}
+	if opts.Mode == state.ModeDryRun {
+		ctx = skill.WithDryRun(ctx)
+	}
+
 	// Initialize state
 	initialState := &state.State{
 		Query:     query,
 		TaskID:    taskID,
 		StartedAt: time.Now().Format(time.RFC3339),
 		UpdatedAt: time.Now().Format(time.RFC3339),
+		Mode:      opts.Mode,
+
+		Requester:   opts.Requester,
+		Environment: opts.Environment,
+		Labels:      opts.Labels,
 	}
 
 	// Execute graph starting from planning node
@@ -75,7 +246,7 @@ func (p *Pipeline) Execute(ctx context.Context, query string, taskID string) (*s
 }
 
 // executeWithCheckpoint executes using langgraphgo CheckpointableStateGraph with checkpoint support
-func (p *Pipeline) executeWithCheckpoint(ctx context.Context, query string, taskID string) (*state.State, error) {
+func (p *Pipeline) executeWithCheckpoint(ctx context.Context, query, taskID string, opts TaskOptions) (*state.State, error) {
 	// Compile checkpointable graph
 	runnable, err := p.checkpointGraph.Graph.CompileCheckpointable()
 	if err != nil {
@@ -84,10 +255,19 @@ func (p *Pipeline) executeWithCheckpoint(ctx context.Context, query string, task
 
 	// Convert initial state to map format
 	initialStateMap := map[string]any{
-		"query":      query,
-		"task_id":    taskID,
-		"started_at": time.Now().Format(time.RFC3339),
-		"updated_at": time.Now().Format(time.RFC3339),
+		"query":       query,
+		"task_id":     taskID,
+		"mode":        opts.Mode,
+		"requester":   opts.Requester,
+		"environment": opts.Environment,
+		"labels":      opts.Labels,
+		"started_at":  time.Now().Format(time.RFC3339),
+		"updated_at":  time.Now().Format(time.RFC3339),
+
+		"override_window": opts.OverrideWindow,
+	}
+	if opts.Plan != nil {
+		initialStateMap["plan"] = graph.PlanToMap(opts.Plan)
 	}
 
 	// Create config with thread_id (taskID) for checkpoint tracking
@@ -98,12 +278,13 @@ func (p *Pipeline) executeWithCheckpoint(ctx context.Context, query string, task
 		},
 	}
 
-	// Execute graph with checkpoint support and replanning loop
-	// Checkpoint will automatically save state at each node
-	maxReplans := 3
-	replanCount := 0
-	currentState := initialStateMap
+	return p.invoke(ctx, runnable, initialStateMap, config, taskID)
+}
 
+// invoke runs the checkpointable graph from config with the replanning loop.
+// Checkpoint will automatically save state at each node. The validation node
+// enforces the replan limits and the planning node keeps the completed steps.
+func (p *Pipeline) invoke(ctx context.Context, runnable *langgraph.CheckpointableRunnable[map[string]any], currentState map[string]any, config *langgraph.Config, taskID string) (*state.State, error) {
 	for {
 		resultMap, err := runnable.InvokeWithConfig(ctx, currentState, config)
 		if err != nil {
@@ -114,39 +295,15 @@ func (p *Pipeline) executeWithCheckpoint(ctx context.Context, query string, task
 			return finalState, err
 		}
 
-		// Check if replanning is needed
-		replanNeeded := false
-		if val, ok := resultMap["replan_needed"]; ok {
-			if b, ok := val.(bool); ok {
-				replanNeeded = b
-			}
-		}
-
-		// Check if we have a final result (validation passed)
-		// If we have a successful final result, we can exit the loop
-		if val, ok := resultMap["final_result"]; ok {
-			if finalMap, ok := val.(map[string]any); ok {
-				if success, ok := finalMap["success"].(bool); ok && success {
-					// Validation passed, exit loop
-					finalState := p.mapToState(resultMap, taskID)
-					finalState.UpdatedAt = time.Now().Format(time.RFC3339)
-					return finalState, nil
-				}
+		// Run the graph again from planning when replanning is needed. The graph
+		// would otherwise resume from its latest checkpoint, the validation node.
+		if replanNeeded, _ := resultMap["replan_needed"].(bool); replanNeeded {
+			config = &langgraph.Config{
+				Configurable: map[string]any{
+					"thread_id": taskID,
+				},
+				ResumeFrom: []string{"planning"},
 			}
-		}
-
-		// If replanning is needed and we haven't exceeded max replans
-		if replanNeeded && replanCount < maxReplans {
-			replanCount++
-			// Update replan count in state
-			resultMap["replan_count"] = replanCount
-			// Clear plan to force regeneration
-			delete(resultMap, "plan")
-			delete(resultMap, "steps")
-			delete(resultMap, "results")
-			resultMap["current_step"] = 0
-			resultMap["replan_needed"] = false // Reset flag
-			// Continue loop with updated state
 			currentState = resultMap
 			continue
 		}
@@ -158,28 +315,10 @@ func (p *Pipeline) executeWithCheckpoint(ctx context.Context, query string, task
 	}
 }
 
-// mapToState converts a map[string]any to State (simplified conversion)
+// mapToState converts the map state of the graph to State
 func (p *Pipeline) mapToState(stateMap map[string]any, taskID string) *state.State {
-	s := &state.State{
-		TaskID: taskID,
-	}
-
-	if query, ok := stateMap["query"].(string); ok {
-		s.Query = query
-	}
-	if startedAt, ok := stateMap["started_at"].(string); ok {
-		s.StartedAt = startedAt
-	}
-	if updatedAt, ok := stateMap["updated_at"].(string); ok {
-		s.UpdatedAt = updatedAt
-	}
-	if err, ok := stateMap["error"].(string); ok {
-		s.Error = err
-	}
-
-	// Note: Full conversion would require converting plan, steps, results, etc.
-	// This is a simplified version - full implementation would use the builder's conversion functions
-
+	s := graph.StateFromMap(stateMap)
+	s.TaskID = taskID
 	return s
 }
 
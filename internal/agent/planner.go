@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 
 	"github.com/hb-chen/opskills/internal/llm"
+	"github.com/hb-chen/opskills/internal/retry"
+	"github.com/hb-chen/opskills/internal/runbook"
 	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/state"
 )
@@ -14,83 +19,273 @@ import (
 type PlanningAgent struct {
 	llmClient *llm.Client
 	registry  *skill.Registry
+	prompts   *llm.PromptRegistry
+	index     *skill.SkillIndex // Selects the skills relevant to a query, nil lists every skill
+	runbooks  *runbook.Catalog  // Runbooks the planner may pick instead of planning steps
+
+	// Instructions, scripts and references of the skills in prompts
+	skillContext *SkillContext
+
+	// Skill list rendered into prompts, rebuilt when the registry changes
+	skillCache []llm.SkillInfo
+	cacheGen   uint64 // Bumped on every registry change
+	cacheMu    sync.RWMutex
 }
 
 // NewPlanningAgent creates a new planning agent
 func NewPlanningAgent(llmClient *llm.Client, registry *skill.Registry) *PlanningAgent {
-	return &PlanningAgent{
+	a := &PlanningAgent{
 		llmClient: llmClient,
 		registry:  registry,
+		prompts:   llm.DefaultPrompts(),
+
+		skillContext: NewSkillContext(DefaultSkillContextBudget),
 	}
+	registry.Subscribe(a.handleRegistryEvent)
+	return a
+}
+
+// handleRegistryEvent invalidates the skill cache when skills change
+func (a *PlanningAgent) handleRegistryEvent(event skill.RegistryEvent) {
+	a.cacheMu.Lock()
+	a.skillCache = nil
+	a.cacheGen++
+	a.cacheMu.Unlock()
+}
+
+// SetPrompts sets the prompt registry used to render the planning and replan prompts
+func (a *PlanningAgent) SetPrompts(p *llm.PromptRegistry) {
+	a.prompts = p
+}
+
+// SetSkillIndex limits the skills in prompts to the ones the index selects for the query
+func (a *PlanningAgent) SetSkillIndex(index *skill.SkillIndex) {
+	a.index = index
+}
+
+// SetRunbooks sets the runbooks listed in the planning prompt, a runbook picked by
+// the LLM is rendered into the plan
+func (a *PlanningAgent) SetRunbooks(catalog *runbook.Catalog) {
+	a.runbooks = catalog
+}
+
+// SetSkillContextBudget sets the tokens of instructions and references per skill in prompts
+func (a *PlanningAgent) SetSkillContextBudget(tokens int) {
+	a.skillContext = NewSkillContext(tokens)
 }
 
 // Plan generates an execution plan from a user query
 func (a *PlanningAgent) Plan(ctx context.Context, query string) (*state.Plan, error) {
-	// Get available skills
-	skills := a.registry.List()
-	if len(skills) == 0 {
-		return nil, fmt.Errorf("no skills available")
-	}
-
 	// Prepare skill information for prompt
-	skillInfos := make([]llm.SkillInfo, len(skills))
-	for i, s := range skills {
-		skillInfos[i] = llm.SkillInfo{
-			Name:        s.Name,
-			Description: s.Description,
-		}
+	skillInfos, err := a.skillInfos(ctx, query)
+	if err != nil {
+		return nil, err
 	}
 
 	// Format planning prompt
 	promptData := llm.PlanningPromptData{
-		Skills: skillInfos,
-		Query:  query,
+		Skills:   skillInfos,
+		Query:    query,
+		Runbooks: a.runbookInfos(),
+	}
+	prompt, err := a.prompts.FormatPlanningPrompt(selectedSkill(skillInfos), promptData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render planning prompt: %w", err)
 	}
-	prompt := llm.FormatPlanningPrompt(promptData)
 
 	// Generate plan using LLM
-	response, err := a.llmClient.Generate(ctx, prompt)
+	response, err := a.llmClient.Generate(ctx, prompt.Text)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate plan: %w", err)
 	}
 
+	// A runbook picked by the LLM is rendered with the inputs it set
+	if name, inputs, ok := parseRunbookChoice(response); ok {
+		r, err := a.runbooks.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("planner picked runbook %q: %w", name, err)
+		}
+		plan, err := r.Render(inputs)
+		if err != nil {
+			return nil, fmt.Errorf("planner picked runbook %s: %w", name, err)
+		}
+		plan.Prompt = promptInfo(prompt)
+		return plan, nil
+	}
+
 	// Parse JSON response
 	plan, err := parsePlanResponse(response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse plan response: %w", err)
 	}
+	plan.Prompt = promptInfo(prompt)
 
 	return plan, nil
 }
 
-// parsePlanResponse parses the LLM response into a Plan
-func parsePlanResponse(response string) (*state.Plan, error) {
-	// Try to extract JSON from response (LLM might add extra text)
-	// Look for JSON object in the response
-	startIdx := -1
-	endIdx := -1
-	braceCount := 0
-
-	for i, char := range response {
-		if char == '{' {
-			if startIdx == -1 {
-				startIdx = i
+// runbookInfos returns the runbooks for the planning prompt
+func (a *PlanningAgent) runbookInfos() []llm.RunbookInfo {
+	runbooks := a.runbooks.List()
+	infos := make([]llm.RunbookInfo, len(runbooks))
+	for i, r := range runbooks {
+		info := llm.RunbookInfo{Name: r.Name, Description: r.Description}
+		for _, input := range r.Inputs {
+			inputType := input.Type
+			if inputType == "" {
+				inputType = runbook.TypeString
 			}
-			braceCount++
-		} else if char == '}' {
-			braceCount--
-			if braceCount == 0 && startIdx != -1 {
-				endIdx = i + 1
-				break
+			info.Inputs = append(info.Inputs, llm.ActionParamInfo{
+				Name:        input.Name,
+				Type:        inputType,
+				Description: input.Description,
+				Required:    input.Required && input.Default == "",
+			})
+		}
+		for _, step := range r.Steps {
+			summary := step.SkillName + " " + step.Action
+			if step.Description != "" {
+				summary += ": " + step.Description
 			}
+			info.Steps = append(info.Steps, summary)
 		}
+		infos[i] = info
 	}
+	return infos
+}
 
-	if startIdx == -1 || endIdx == -1 {
-		return nil, fmt.Errorf("no valid JSON found in response")
+// Replan generates a new execution plan from the outcome of a previous plan
+func (a *PlanningAgent) Replan(ctx context.Context, query string, replan *state.ReplanContext) (*state.Plan, error) {
+	if replan == nil {
+		return a.Plan(ctx, query)
 	}
 
-	jsonStr := response[startIdx:endIdx]
+	skillInfos, err := a.skillInfos(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := a.prompts.FormatReplanPrompt(replan.Skill, llm.ReplanPromptData{
+		Skills:         skillInfos,
+		Query:          query,
+		ReplanReason:   replan.Reason,
+		PlanSummary:    replan.PlanSummary,
+		ResultsSummary: replan.ResultsSummary,
+		Failure:        replan.Failure,
+		KeptSteps:      replan.KeptSteps,
+		NextStepID:     replan.NextStepID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render replan prompt: %w", err)
+	}
+
+	response, err := a.llmClient.Generate(ctx, prompt.Text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate plan: %w", err)
+	}
+
+	plan, err := parsePlanResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plan response: %w", err)
+	}
+	plan.Prompt = promptInfo(prompt)
+
+	return plan, nil
+}
+
+// selectedSkill returns the skill of a prompt listing skills, when there is only one
+func selectedSkill(skills []llm.SkillInfo) string {
+	if len(skills) != 1 {
+		return ""
+	}
+	return skills[0].Name
+}
+
+// promptInfo identifies the template of a rendered prompt
+func promptInfo(prompt *llm.RenderedPrompt) *state.PromptInfo {
+	return &state.PromptInfo{
+		Name:    prompt.Name,
+		Version: prompt.Version,
+		Source:  prompt.Source,
+	}
+}
+
+// skillInfos returns the skills for the prompts of a query
+func (a *PlanningAgent) skillInfos(ctx context.Context, query string) ([]llm.SkillInfo, error) {
+	if a.index != nil {
+		skills, err := a.index.Select(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		skillInfos := make([]llm.SkillInfo, len(skills))
+		for i, s := range skills {
+			skillInfos[i] = a.skillContext.Info(s)
+		}
+		return skillInfos, nil
+	}
+
+	a.cacheMu.RLock()
+	cached, gen := a.skillCache, a.cacheGen
+	a.cacheMu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	skills := a.registry.List()
+	if len(skills) == 0 {
+		return nil, fmt.Errorf("no skills available")
+	}
+
+	// Stable order keeps prompts identical between runs
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+
+	skillInfos := make([]llm.SkillInfo, len(skills))
+	for i, s := range skills {
+		skillInfos[i] = a.skillContext.Info(s)
+	}
+
+	// Don't cache a list built while the registry changed
+	a.cacheMu.Lock()
+	if a.cacheGen == gen {
+		a.skillCache = skillInfos
+	}
+	a.cacheMu.Unlock()
+
+	return skillInfos, nil
+}
+
+// parseRunbookChoice parses an LLM response picking a runbook, ok is false for plans
+func parseRunbookChoice(response string) (string, map[string]string, bool) {
+	jsonStr, err := extractJSON(response)
+	if err != nil {
+		return "", nil, false
+	}
+	var choice struct {
+		Runbook string         `json:"runbook"`
+		Inputs  map[string]any `json:"inputs"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &choice); err != nil || choice.Runbook == "" {
+		return "", nil, false
+	}
+
+	inputs := make(map[string]string, len(choice.Inputs))
+	for name, value := range choice.Inputs {
+		switch v := value.(type) {
+		case nil:
+		case float64:
+			inputs[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			inputs[name] = fmt.Sprintf("%v", v)
+		}
+	}
+	return choice.Runbook, inputs, true
+}
+
+// parsePlanResponse parses the LLM response into a Plan
+func parsePlanResponse(response string) (*state.Plan, error) {
+	jsonStr, err := extractJSON(response)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse JSON
 	var planData struct {
@@ -100,6 +295,7 @@ func parsePlanResponse(response string) (*state.Plan, error) {
 			Action      string                 `json:"action"`
 			Description string                 `json:"description"`
 			Params      map[string]interface{} `json:"params"`
+			Retry       *retry.Policy          `json:"retry"`
 		} `json:"steps"`
 	}
 
@@ -119,11 +315,39 @@ func parsePlanResponse(response string) (*state.Plan, error) {
 			Action:      step.Action,
 			Description: step.Description,
 			Params:      step.Params,
+			Retry:       step.Retry,
 		}
 	}
 
 	return plan, nil
 }
 
+// extractJSON returns the first JSON object of an LLM response
+func extractJSON(response string) (string, error) {
+	// Try to extract JSON from response (LLM might add extra text)
+	// Look for JSON object in the response
+	startIdx := -1
+	endIdx := -1
+	braceCount := 0
+
+	for i, char := range response {
+		if char == '{' {
+			if startIdx == -1 {
+				startIdx = i
+			}
+			braceCount++
+		} else if char == '}' {
+			braceCount--
+			if braceCount == 0 && startIdx != -1 {
+				endIdx = i + 1
+				break
+			}
+		}
+	}
 
+	if startIdx == -1 || endIdx == -1 {
+		return "", fmt.Errorf("no valid JSON found in response")
+	}
 
+	return response[startIdx:endIdx], nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -64,15 +65,49 @@ func (r *MarkdownReporter) buildReport(taskID string, s *state.State) string {
 	b.WriteString(fmt.Sprintf("- **Query**: %s\n", s.Query))
 	b.WriteString(fmt.Sprintf("- **Started At**: %s\n", s.StartedAt))
 	b.WriteString(fmt.Sprintf("- **Updated At**: %s\n", s.UpdatedAt))
+	if s.Mode != "" {
+		fmt.Fprintf(&b, "- **Mode**: `%s`\n", s.Mode)
+	}
+	if s.Plan != nil && s.Plan.Runbook != "" {
+		fmt.Fprintf(&b, "- **Runbook**: `%s`\n", s.Plan.Runbook)
+	}
+	if s.Requester != "" {
+		fmt.Fprintf(&b, "- **Requester**: %s\n", s.Requester)
+	}
+	if s.Environment != "" {
+		fmt.Fprintf(&b, "- **Environment**: `%s`\n", s.Environment)
+	}
+	if len(s.Labels) > 0 {
+		labels := make([]string, 0, len(s.Labels))
+		for k, v := range s.Labels {
+			labels = append(labels, fmt.Sprintf("`%s=%s`", k, v))
+		}
+		sort.Strings(labels)
+		fmt.Fprintf(&b, "- **Labels**: %s\n", strings.Join(labels, ", "))
+	}
 	if s.Error != "" {
 		b.WriteString("- **Status**: ❌ Failed\n")
 		fmt.Fprintf(&b, "- **Error**: %s\n", s.Error)
 	} else if s.FinalResult != nil && s.FinalResult.Success {
 		b.WriteString("- **Status**: ✅ Success\n")
+	} else if s.FinalResult != nil {
+		b.WriteString("- **Status**: ❌ Failed\n")
+	} else if s.Approval != nil && s.Approval.Status == state.ApprovalPending {
+		b.WriteString("- **Status**: ✋ Pending Approval\n")
+	} else if s.Maintenance != nil && s.Maintenance.Status == state.MaintenanceScheduled {
+		fmt.Fprintf(&b, "- **Status**: 🕒 Scheduled for %s\n", s.Maintenance.NotBefore)
 	} else {
 		b.WriteString("- **Status**: ⏳ In Progress\n")
 	}
 	b.WriteString("\n")
+	if state.Simulated(s.Mode) {
+		b.WriteString("> 🧪 **Simulated**: this task ran in ")
+		if s.Mode == state.ModePlanOnly {
+			b.WriteString("plan-only mode, no step was executed and nothing was changed.\n\n")
+		} else {
+			b.WriteString("dry-run mode, results are simulated and nothing was changed.\n\n")
+		}
+	}
 
 	// Planning Phase
 	b.WriteString("## Planning Phase\n\n")
@@ -105,6 +140,11 @@ func (r *MarkdownReporter) buildReport(taskID string, s *state.State) string {
 	}
 	b.WriteString("\n")
 
+	// Policy
+	if len(s.PolicyDecisions) > 0 || s.Approval != nil || s.Maintenance != nil {
+		r.writePolicy(&b, s)
+	}
+
 	// Execution Phase
 	b.WriteString("## Execution Phase\n\n")
 	if len(s.Steps) == 0 {
@@ -132,9 +172,14 @@ func (r *MarkdownReporter) buildReport(taskID string, s *state.State) string {
 				if stepResult.Duration != "" {
 					b.WriteString(fmt.Sprintf("- **Duration**: %s\n", stepResult.Duration))
 				}
-				if stepResult.Success {
+				switch {
+				case stepResult.Skipped:
+					b.WriteString("- **Result**: ⏭️ Skipped (dry run not supported)\n")
+				case stepResult.Success && stepResult.Simulated:
+					b.WriteString("- **Result**: 🧪 Simulated\n")
+				case stepResult.Success:
 					b.WriteString("- **Result**: ✅ Success\n")
-				} else {
+				default:
 					b.WriteString("- **Result**: ❌ Failed\n")
 				}
 				if stepResult.Output != "" {
@@ -175,6 +220,11 @@ func (r *MarkdownReporter) buildReport(taskID string, s *state.State) string {
 	}
 	b.WriteString("\n")
 
+	// Rollback Phase
+	if s.Rollback != nil {
+		r.writeRollback(&b, s.Rollback)
+	}
+
 	// Timeline
 	b.WriteString("## Timeline\n\n")
 	b.WriteString("| Event | Timestamp |\n")
@@ -205,6 +255,81 @@ func (r *MarkdownReporter) buildReport(taskID string, s *state.State) string {
 	return b.String()
 }
 
+// writeRollback writes the compensations of the completed steps, latest first
+func (r *MarkdownReporter) writeRollback(b *strings.Builder, rollback *state.Rollback) {
+	b.WriteString("## Rollback Phase\n\n")
+	fmt.Fprintf(b, "**Status**: %s\n\n", r.formatStatus(rollback.Status))
+	if rollback.Reason != "" {
+		fmt.Fprintf(b, "**Reason**: %s\n\n", rollback.Reason)
+	}
+	if rollback.ApprovedBy != "" {
+		fmt.Fprintf(b, "✅ **Approved** by %s at %s\n\n", rollback.ApprovedBy, rollback.ApprovedAt)
+	}
+	for _, c := range rollback.Steps {
+		fmt.Fprintf(b, "### Compensate Step %d\n\n", c.StepID)
+		fmt.Fprintf(b, "- **Skill**: `%s`\n", c.SkillName)
+		if c.Action != "" {
+			fmt.Fprintf(b, "- **Action**: `%s`\n", c.Action)
+		}
+		for k, v := range c.Params {
+			fmt.Fprintf(b, "  - `%s`: `%v`\n", k, v)
+		}
+		fmt.Fprintf(b, "- **Status**: %s\n", r.formatStatus(c.Status))
+		if c.Result != nil {
+			if c.Result.Duration != "" {
+				fmt.Fprintf(b, "- **Duration**: %s\n", c.Result.Duration)
+			}
+			if c.Result.Output != "" {
+				fmt.Fprintf(b, "- **Output**:\n```\n%s\n```\n", c.Result.Output)
+			}
+			if c.Result.Error != "" {
+				fmt.Fprintf(b, "- **Error**:\n```\n%s\n```\n", c.Result.Error)
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+// writePolicy writes the policy decisions on the plan and its steps, the approval
+// and the maintenance hold
+func (r *MarkdownReporter) writePolicy(b *strings.Builder, s *state.State) {
+	b.WriteString("## Policy\n\n")
+	if len(s.PolicyDecisions) > 0 {
+		b.WriteString("| Stage | Step | Rule | Effect | Reason |\n")
+		b.WriteString("|-------|------|------|--------|--------|\n")
+		for _, d := range s.PolicyDecisions {
+			step, rule := "-", "-"
+			if d.StepID > 0 {
+				step = fmt.Sprintf("%d", d.StepID)
+			}
+			if d.RuleID != "" {
+				rule = "`" + d.RuleID + "`"
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", d.Stage, step, rule, d.Effect, d.Reason)
+		}
+		b.WriteString("\n")
+	}
+	if a := s.Approval; a != nil {
+		if a.Status == state.ApprovalApproved {
+			fmt.Fprintf(b, "✅ **Approved** by %s at %s\n\n", a.ApprovedBy, a.ApprovedAt)
+			if a.Comment != "" {
+				fmt.Fprintf(b, "**Comment**: %s\n\n", a.Comment)
+			}
+		} else {
+			fmt.Fprintf(b, "✋ **Waiting for approval** since %s: %s\n\n", a.RequestedAt, a.Reason)
+		}
+	}
+	if m := s.Maintenance; m != nil {
+		if m.Status == state.MaintenanceScheduled {
+			fmt.Fprintf(b, "🕒 **Scheduled** until a maintenance window opens: %s\n\n", m.Reason)
+		} else {
+			fmt.Fprintf(b, "⛔ **Rejected** outside the maintenance windows: %s\n\n", m.Reason)
+		}
+	}
+	b.WriteString("\n")
+}
+
 // formatStatus formats the status with emoji
 func (r *MarkdownReporter) formatStatus(status string) string {
 	switch status {
@@ -216,6 +341,10 @@ func (r *MarkdownReporter) formatStatus(status string) string {
 		return "✅ Completed"
 	case "failed":
 		return "❌ Failed"
+	case "pending_approval":
+		return "✋ Pending Approval"
+	case "held":
+		return "✋ Held"
 	default:
 		return status
 	}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hb-chen/opskills/internal/llm"
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// DefaultSkillContextBudget is the number of tokens of instructions and references per skill in prompts
+const DefaultSkillContextBudget = 1500
+
+// minReferenceTokens is the smallest budget worth spending on a reference,
+// below it references are only listed
+const minReferenceTokens = 50
+
+// referencesDir is the directory of a skill holding reference docs
+const referencesDir = "references"
+
+// SkillContext renders the context of a skill for prompts: its actions, scripts,
+// SKILL.md instructions and references, trimmed to a token budget.
+// References are read the first time a skill is rendered and cached until the skill changes.
+type SkillContext struct {
+	budget int
+	cache  map[string]*skillReferences // Skill name -> references
+	mu     sync.Mutex
+}
+
+// skillReferences are the reference docs of one version of a skill
+type skillReferences struct {
+	digest     string
+	references []llm.ReferenceInfo
+}
+
+// NewSkillContext creates a skill context renderer, budget <= 0 only lists scripts and references
+func NewSkillContext(budget int) *SkillContext {
+	return &SkillContext{
+		budget: budget,
+		cache:  make(map[string]*skillReferences),
+	}
+}
+
+// Info returns the prompt information of a skill
+func (c *SkillContext) Info(s *skill.Skill) llm.SkillInfo {
+	info := llm.SkillInfo{
+		Name:        s.Name,
+		Description: s.Description,
+	}
+	for _, action := range s.Actions {
+		actionInfo := llm.ActionInfo{
+			Name:        action.Name,
+			Description: action.Description,
+			Usage:       action.Usage,
+		}
+		if !action.Retryable() {
+			actionInfo.Retry = "not idempotent, never retried"
+		} else if action.Retry.Attempts() > 1 {
+			actionInfo.Retry = fmt.Sprintf("up to %d attempts", action.Retry.Attempts())
+		}
+		if c := action.Compensate; c != nil {
+			actionInfo.Rollback = c.Action
+			if refs := c.References(); len(refs) > 0 {
+				sort.Strings(refs)
+				actionInfo.Rollback += ", uses params " + strings.Join(refs, ", ")
+			}
+		}
+		for _, param := range action.Params {
+			actionInfo.Params = append(actionInfo.Params, llm.ActionParamInfo{
+				Name:        param.Name,
+				Type:        param.Type,
+				Description: param.Description,
+				Required:    param.Required,
+			})
+		}
+		info.Actions = append(info.Actions, actionInfo)
+		if action.Script != "" {
+			info.Scripts = append(info.Scripts, action.Script)
+		}
+	}
+	sort.Strings(info.Scripts)
+
+	// Instructions come first, the rest of the budget goes to references in order
+	remaining := c.budget
+	if remaining > 0 && s.Instructions != "" {
+		info.Instructions = llm.TruncateTokens(s.Instructions, remaining)
+		remaining -= llm.EstimateTokens(info.Instructions)
+	}
+	for _, ref := range c.references(s) {
+		if remaining >= minReferenceTokens {
+			ref.Content = llm.TruncateTokens(ref.Content, remaining)
+			remaining -= llm.EstimateTokens(ref.Content)
+		} else {
+			ref.Content = ""
+		}
+		info.References = append(info.References, ref)
+	}
+
+	return info
+}
+
+// references returns the reference docs of a skill, reading them on first use
+func (c *SkillContext) references(s *skill.Skill) []llm.ReferenceInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.cache[s.Name]; ok && cached.digest == s.Digest {
+		return cached.references
+	}
+	if s.BasePath == "" {
+		return nil // Native skills have no files
+	}
+
+	files, _ := filepath.Glob(filepath.Join(s.BasePath, referencesDir, "*.md"))
+	sort.Strings(files)
+
+	references := make([]llm.ReferenceInfo, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		references = append(references, llm.ReferenceInfo{
+			Path:    filepath.ToSlash(filepath.Join(referencesDir, filepath.Base(file))),
+			Content: string(data),
+		})
+	}
+
+	c.cache[s.Name] = &skillReferences{digest: s.Digest, references: references}
+	return references
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hb-chen/opskills/internal/agent"
+	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/state"
 	"github.com/hb-chen/opskills/pkg/logger"
 )
@@ -35,6 +37,11 @@ func (h *Handler) HandleRun(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Query parameter is required", http.StatusBadRequest)
 		return
 	}
+	mode := r.URL.Query().Get("mode")
+	if !state.ValidMode(mode) {
+		http.Error(w, "Unknown mode, use execute, plan_only or dry_run", http.StatusBadRequest)
+		return
+	}
 
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
@@ -54,6 +61,27 @@ func (h *Handler) HandleRun(w http.ResponseWriter, r *http.Request) {
 	h.sendSSE(w, flusher, "update", map[string]string{"step": "正在初始化任务..."})
 	h.sendSSE(w, flusher, "log", map[string]string{"message": fmt.Sprintf("任务 ID: %s", taskID)})
 	h.sendSSE(w, flusher, "log", map[string]string{"message": fmt.Sprintf("查询: %s", query)})
+	if state.Simulated(mode) {
+		h.sendSSE(w, flusher, "log", map[string]string{"message": fmt.Sprintf("模式: %s（模拟执行，不会产生任何变更）", mode)})
+	}
+
+	// Stream the output of running steps as it is written.
+	// Lines arriving after the response is complete are dropped.
+	var outputMu sync.Mutex
+	outputDone := false
+	onOutput := func(line skill.OutputLine) {
+		outputMu.Lock()
+		defer outputMu.Unlock()
+		if outputDone {
+			return
+		}
+		h.sendSSEResult(w, flusher, "output", map[string]interface{}{
+			"step_id": line.StepID,
+			"stream":  line.Stream,
+			"text":    line.Text,
+			"time":    line.Time.Format(time.RFC3339Nano),
+		})
+	}
 
 	// Execute task in goroutine
 	resultChan := make(chan *state.State, 1)
@@ -63,11 +91,15 @@ func (h *Handler) HandleRun(w http.ResponseWriter, r *http.Request) {
 		defer close(resultChan)
 		defer close(errChan)
 
-		ctx := r.Context()
+		ctx := skill.WithOutputHandler(r.Context(), onOutput)
 		h.sendSSE(w, flusher, "update", map[string]string{"step": "正在执行任务..."})
 		h.sendSSE(w, flusher, "log", map[string]string{"message": "开始执行 Pipeline..."})
 
-		state, err := h.pipeline.Execute(ctx, query, taskID)
+		state, err := h.pipeline.Execute(ctx, query, taskID, agent.TaskOptions{
+			Mode:        mode,
+			Requester:   r.Header.Get(RequesterHeader),
+			Environment: r.URL.Query().Get("environment"),
+		})
 		if err != nil {
 			errChan <- err
 			return
@@ -80,23 +112,35 @@ func (h *Handler) HandleRun(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	// Wait for result or error
+	var res *state.State
+	var err error
 	select {
-	case res := <-resultChan:
-		if res != nil {
-			h.sendSSE(w, flusher, "log", map[string]string{"message": "任务执行完成"})
-			
-			// Send result
-			resultData := map[string]interface{}{
-				"state": res,
-			}
-			h.sendSSEResult(w, flusher, "result", resultData)
-		}
-	case err := <-errChan:
-		if err != nil {
-			h.sendSSE(w, flusher, "error", map[string]string{"message": err.Error()})
+	case res = <-resultChan:
+		if res == nil {
+			// The task failed, both channels are closed and the error is buffered
+			err = <-errChan
 		}
+	case err = <-errChan:
 	case <-r.Context().Done():
-		h.sendSSE(w, flusher, "error", map[string]string{"message": "请求已取消"})
+		err = fmt.Errorf("请求已取消")
+	}
+
+	// Stop streaming output before writing the final events
+	outputMu.Lock()
+	outputDone = true
+	outputMu.Unlock()
+
+	switch {
+	case res != nil:
+		h.sendSSE(w, flusher, "log", map[string]string{"message": "任务执行完成"})
+
+		// Send result
+		resultData := map[string]interface{}{
+			"state": res,
+		}
+		h.sendSSEResult(w, flusher, "result", resultData)
+	case err != nil:
+		h.sendSSE(w, flusher, "error", map[string]string{"message": err.Error()})
 	}
 }
 
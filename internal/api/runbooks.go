@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hb-chen/opskills/internal/agent"
+	"github.com/hb-chen/opskills/internal/runbook"
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/hb-chen/opskills/pkg/logger"
+	"github.com/hb-chen/opskills/proto/common"
+	"github.com/hb-chen/opskills/proto/ops"
+	"google.golang.org/protobuf/types/known/anypb"
+	"gopkg.in/yaml.v3"
+)
+
+// SetRunbooks sets the runbooks managed and run by the runbook RPCs
+func (s *Service) SetRunbooks(catalog *runbook.Catalog) {
+	s.runbooks = catalog
+}
+
+// CreateRunbook saves a runbook from its definition
+func (s *Service) CreateRunbook(ctx context.Context, req *ops.CreateRunbookRequest) (*common.Response, error) {
+	if s.runbooks == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Runbooks are disabled",
+		}, nil
+	}
+	if req.Definition == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "definition is required",
+		}, nil
+	}
+
+	r, err := runbook.Parse([]byte(req.Definition))
+	if err != nil {
+		return &common.Response{
+			Code:    400,
+			Message: fmt.Sprintf("Invalid runbook: %v", err),
+		}, nil
+	}
+	return s.saveRunbook(ctx, r)
+}
+
+// ListRunbooks lists the runbooks
+func (s *Service) ListRunbooks(ctx context.Context, req *ops.ListRunbooksRequest) (*common.Response, error) {
+	if s.runbooks == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Runbooks are disabled",
+		}, nil
+	}
+
+	list := &ops.RunbookList{}
+	for _, r := range s.runbooks.List() {
+		list.Runbooks = append(list.Runbooks, runbookToProto(r))
+	}
+	list.Total = int32(len(list.Runbooks))
+
+	anyData, err := anypb.New(list)
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal runbooks data",
+		}, nil
+	}
+
+	return &common.Response{
+		Code:    200,
+		Message: "Runbooks retrieved successfully",
+		Data:    anyData,
+	}, nil
+}
+
+// DeleteRunbook deletes a runbook
+func (s *Service) DeleteRunbook(ctx context.Context, req *ops.DeleteRunbookRequest) (*common.Response, error) {
+	if s.runbooks == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Runbooks are disabled",
+		}, nil
+	}
+	if req.Name == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "name is required",
+		}, nil
+	}
+
+	err := s.runbooks.Delete(req.Name)
+	if errors.Is(err, runbook.ErrNotFound) {
+		return &common.Response{
+			Code:    404,
+			Message: "Runbook not found",
+		}, nil
+	}
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Failed to delete runbook: %v", err),
+		}, nil
+	}
+	logger.Infof("Runbook %s deleted by %q", req.Name, requesterFrom(ctx))
+
+	return &common.Response{
+		Code:    200,
+		Message: "Runbook deleted successfully",
+	}, nil
+}
+
+// RunRunbook submits a task running the steps of a runbook with its inputs, without planning
+func (s *Service) RunRunbook(ctx context.Context, req *ops.RunRunbookRequest) (*common.Response, error) {
+	if s.runbooks == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Runbooks are disabled",
+		}, nil
+	}
+	if !state.ValidMode(req.Mode) {
+		return &common.Response{
+			Code:    400,
+			Message: fmt.Sprintf("Unknown mode %q: use execute, plan_only or dry_run", req.Mode),
+		}, nil
+	}
+
+	r, err := s.runbooks.Get(req.Name)
+	if err != nil {
+		return &common.Response{
+			Code:    404,
+			Message: "Runbook not found",
+		}, nil
+	}
+	plan, err := r.Render(req.Inputs)
+	if err != nil {
+		return &common.Response{
+			Code:    400,
+			Message: fmt.Sprintf("Invalid inputs: %v", err),
+		}, nil
+	}
+
+	return s.submit(ctx, runbookQuery(r, req.Inputs), agent.TaskOptions{
+		Mode:           req.Mode,
+		Requester:      requesterFrom(ctx),
+		Environment:    req.Environment,
+		Labels:         req.Labels,
+		OverrideWindow: req.OverrideWindow,
+		Plan:           plan,
+	})
+}
+
+// PromoteTask saves the plan of a completed task as a runbook
+func (s *Service) PromoteTask(ctx context.Context, req *ops.PromoteTaskRequest) (*common.Response, error) {
+	if s.runbooks == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Runbooks are disabled",
+		}, nil
+	}
+	if req.TaskId == "" || req.Name == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "task_id and name are required",
+		}, nil
+	}
+
+	taskState, exists := s.task(req.TaskId)
+	if !exists {
+		return &common.Response{
+			Code:    404,
+			Message: "Task not found",
+		}, nil
+	}
+	if taskStatus(taskState) != "completed" || state.Simulated(taskState.Mode) {
+		return &common.Response{
+			Code:    400,
+			Message: "Only the plans of completed tasks that were executed can be promoted",
+		}, nil
+	}
+
+	description := req.Description
+	if description == "" {
+		description = taskState.Query
+	}
+	r, err := runbook.FromPlan(req.Name, description, taskState.Plan, req.Inputs)
+	if err != nil {
+		return &common.Response{
+			Code:    400,
+			Message: fmt.Sprintf("Failed to promote task: %v", err),
+		}, nil
+	}
+	logger.Infof("Task %s promoted to runbook %s", req.TaskId, r.Name)
+	return s.saveRunbook(ctx, r)
+}
+
+// saveRunbook saves a new runbook and returns it
+func (s *Service) saveRunbook(ctx context.Context, r *runbook.Runbook) (*common.Response, error) {
+	err := s.runbooks.Save(r)
+	if errors.Is(err, runbook.ErrExists) {
+		return &common.Response{
+			Code:    409,
+			Message: fmt.Sprintf("Runbook %s already exists", r.Name),
+		}, nil
+	}
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Failed to save runbook: %v", err),
+		}, nil
+	}
+	logger.Infof("Runbook %s saved by %q", r.Name, requesterFrom(ctx))
+
+	anyData, err := anypb.New(runbookToProto(r))
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal runbook data",
+		}, nil
+	}
+
+	return &common.Response{
+		Code:    201,
+		Message: "Runbook saved successfully",
+		Data:    anyData,
+	}, nil
+}
+
+// runbookQuery describes a runbook run as the query of its task
+func runbookQuery(r *runbook.Runbook, inputs map[string]string) string {
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]string, len(names))
+	for i, name := range names {
+		args[i] = name + "=" + inputs[name]
+	}
+	query := "Run runbook " + r.Name
+	if r.Description != "" {
+		query += " (" + r.Description + ")"
+	}
+	if len(args) > 0 {
+		query += " with " + strings.Join(args, ", ")
+	}
+	return query
+}
+
+func runbookToProto(r *runbook.Runbook) *ops.Runbook {
+	rb := &ops.Runbook{
+		Name:        r.Name,
+		Description: r.Description,
+	}
+	for _, input := range r.Inputs {
+		inputType := input.Type
+		if inputType == "" {
+			inputType = runbook.TypeString
+		}
+		rb.Inputs = append(rb.Inputs, &ops.RunbookInput{
+			Name:        input.Name,
+			Type:        inputType,
+			Description: input.Description,
+			Required:    input.Required,
+			Default:     input.Default,
+		})
+	}
+	if definition, err := yaml.Marshal(r); err == nil {
+		rb.Definition = string(definition)
+	}
+	return rb
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/agent"
+	"github.com/hb-chen/opskills/internal/schedule"
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/hb-chen/opskills/pkg/logger"
+	"github.com/hb-chen/opskills/proto/common"
+	"github.com/hb-chen/opskills/proto/ops"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// SetScheduler sets the scheduler managed by the schedule RPCs, its runs are
+// executed by RunScheduled
+func (s *Service) SetScheduler(scheduler *schedule.Scheduler) {
+	s.scheduler = scheduler
+}
+
+// RunScheduled runs the task of a schedule run like a submitted task, until it is
+// completed or failed, and returns the status of the task. A task held for approval
+// or a maintenance window keeps the run running, so that the concurrency policy of
+// the schedule applies to it; it is cancelled when ctx is.
+func (s *Service) RunScheduled(ctx context.Context, taskID string, sched *schedule.Schedule) (string, error) {
+	query := sched.Query
+	if query == "" {
+		query = fmt.Sprintf("Scheduled run of %s", sched.Name)
+	}
+	logger.Infof("Submitting task %s (%s) for schedule %s: %s", taskID, sched.Mode, sched.Name, query)
+
+	finalState, err := s.pipeline.Execute(ctx, query, taskID, agent.TaskOptions{
+		Mode:        sched.Mode,
+		Requester:   sched.Requester,
+		Environment: sched.Environment,
+		Labels:      sched.Labels,
+		Plan:        sched.Plan,
+	})
+	s.finish(taskID, finalState, err)
+	if finalState == nil {
+		return "failed", err
+	}
+	if status := taskStatus(finalState); err != nil || (status != "pending_approval" && status != "scheduled") {
+		return status, err
+	}
+	return s.waitHeld(ctx, taskID)
+}
+
+// waitHeld waits until a held task ends, and cancels it if it is still held when ctx
+// is done, e.g. when the next run of its schedule replaces it
+func (s *Service) waitHeld(ctx context.Context, taskID string) (string, error) {
+	select {
+	case <-s.endOf(taskID):
+	case <-ctx.Done():
+		s.updateTask(taskID, func(taskState *state.State) *common.Response {
+			if status := taskStatus(taskState); status != "pending_approval" && status != "scheduled" {
+				return &common.Response{
+					Code:    409,
+					Message: "Task is no longer held",
+				}
+			}
+			cancelTask(taskState, "Task cancelled, its schedule run was cancelled", "The held task was cancelled with its schedule run")
+			return nil
+		})
+		// A task resumed meanwhile ends on its own
+		<-s.endOf(taskID)
+	}
+
+	taskState, _ := s.task(taskID)
+	if taskState.Error != "" {
+		return taskStatus(taskState), errors.New(taskState.Error)
+	}
+	return taskStatus(taskState), nil
+}
+
+// CreateSchedule creates a schedule running a task on a cron expression
+func (s *Service) CreateSchedule(ctx context.Context, req *ops.CreateScheduleRequest) (*common.Response, error) {
+	if s.scheduler == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Schedules are disabled",
+		}, nil
+	}
+
+	sched := &schedule.Schedule{
+		Name:        req.Name,
+		Cron:        req.Cron,
+		Timezone:    req.Timezone,
+		Query:       req.Query,
+		Mode:        req.Mode,
+		Environment: req.Environment,
+		Labels:      req.Labels,
+		Concurrency: req.Concurrency,
+		Requester:   requesterFrom(ctx),
+	}
+	if req.Plan != "" {
+		var plan state.Plan
+		if err := json.Unmarshal([]byte(req.Plan), &plan); err != nil {
+			return &common.Response{
+				Code:    400,
+				Message: fmt.Sprintf("Invalid plan: %v", err),
+			}, nil
+		}
+		sched.Plan = &plan
+	}
+
+	created, err := s.scheduler.Create(sched)
+	if err != nil {
+		return &common.Response{
+			Code:    400,
+			Message: fmt.Sprintf("Invalid schedule: %v", err),
+		}, nil
+	}
+	logger.Infof("Schedule %s (%s) created by %q: %s", created.Name, created.ID, created.Requester, created.Cron)
+
+	anyData, err := anypb.New(scheduleToProto(created))
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal schedule data",
+		}, nil
+	}
+
+	return &common.Response{
+		Code:    201,
+		Message: "Schedule created successfully",
+		Data:    anyData,
+	}, nil
+}
+
+// ListSchedules lists the schedules and their latest runs
+func (s *Service) ListSchedules(ctx context.Context, req *ops.ListSchedulesRequest) (*common.Response, error) {
+	if s.scheduler == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Schedules are disabled",
+		}, nil
+	}
+
+	list := &ops.ScheduleList{}
+	for _, sched := range s.scheduler.List() {
+		list.Schedules = append(list.Schedules, scheduleToProto(sched))
+	}
+	list.Total = int32(len(list.Schedules))
+
+	anyData, err := anypb.New(list)
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal schedules data",
+		}, nil
+	}
+
+	return &common.Response{
+		Code:    200,
+		Message: "Schedules retrieved successfully",
+		Data:    anyData,
+	}, nil
+}
+
+// DeleteSchedule deletes a schedule, its running task is left to end
+func (s *Service) DeleteSchedule(ctx context.Context, req *ops.DeleteScheduleRequest) (*common.Response, error) {
+	if s.scheduler == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Schedules are disabled",
+		}, nil
+	}
+	if req.ScheduleId == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "schedule_id is required",
+		}, nil
+	}
+
+	err := s.scheduler.Delete(req.ScheduleId)
+	if errors.Is(err, schedule.ErrNotFound) {
+		return &common.Response{
+			Code:    404,
+			Message: "Schedule not found",
+		}, nil
+	}
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Failed to delete schedule: %v", err),
+		}, nil
+	}
+	logger.Infof("Schedule %s deleted by %q", req.ScheduleId, requesterFrom(ctx))
+
+	return &common.Response{
+		Code:    200,
+		Message: "Schedule deleted successfully",
+	}, nil
+}
+
+func scheduleToProto(sched *schedule.Schedule) *ops.Schedule {
+	s := &ops.Schedule{
+		ScheduleId:  sched.ID,
+		Name:        sched.Name,
+		Cron:        sched.Cron,
+		Timezone:    sched.Timezone,
+		Query:       sched.Query,
+		Mode:        sched.Mode,
+		Environment: sched.Environment,
+		Labels:      sched.Labels,
+		Concurrency: sched.Concurrency,
+		Requester:   sched.Requester,
+		CreatedAt:   sched.CreatedAt,
+	}
+	if sched.Plan != nil {
+		if planJSON, err := json.Marshal(sched.Plan); err == nil {
+			s.Plan = string(planJSON)
+		}
+	}
+	if !sched.NextRun.IsZero() {
+		s.NextRun = sched.NextRun.Format(time.RFC3339)
+	}
+	for _, run := range sched.Runs {
+		s.Runs = append(s.Runs, &ops.ScheduleRun{
+			TaskId:      run.TaskID,
+			ScheduledAt: run.ScheduledAt,
+			StartedAt:   run.StartedAt,
+			FinishedAt:  run.FinishedAt,
+			Status:      run.Status,
+			Error:       run.Error,
+		})
+	}
+	return s
+}
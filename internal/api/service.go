@@ -3,22 +3,47 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hb-chen/opskills/internal/agent"
+	"github.com/hb-chen/opskills/internal/artifact"
+	"github.com/hb-chen/opskills/internal/graph"
+	"github.com/hb-chen/opskills/internal/runbook"
+	"github.com/hb-chen/opskills/internal/schedule"
+	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/state"
 	"github.com/hb-chen/opskills/pkg/logger"
 	"github.com/hb-chen/opskills/proto/common"
 	"github.com/hb-chen/opskills/proto/ops"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
+// RequesterHeader carries the identity of the caller, set by the proxy authenticating
+// users; it is evaluated by the policy rules. The server does not authenticate it:
+// without a proxy overwriting it, callers choose their own identity.
+const RequesterHeader = "X-Opskills-Requester"
+
 // Service implements the OpsService gRPC service
 type Service struct {
 	ops.UnimplementedOpsServiceServer
-	pipeline *agent.Pipeline
-	states   map[string]*state.State // In-memory state storage (should be replaced with proper storage)
+	pipeline  *agent.Pipeline
+	states    map[string]*state.State // In-memory state storage (should be replaced with proper storage)
+	statesMu  sync.RWMutex            // Tasks are stored from their pipeline goroutines
+	reloader  *skill.Reloader
+	index     *skill.SkillIndex
+	artifacts *artifact.Store
+	scheduler *schedule.Scheduler
+	runbooks  *runbook.Catalog
+	ended     map[string]chan struct{} // Closed when their task ends, see endOf
 }
 
 // NewService creates a new OpsService implementation
@@ -26,9 +51,30 @@ func NewService(pipeline *agent.Pipeline) *Service {
 	return &Service{
 		pipeline: pipeline,
 		states:   make(map[string]*state.State),
+		ended:    make(map[string]chan struct{}),
 	}
 }
 
+// SetSkillReloader sets the reloader used by ReloadSkills
+func (s *Service) SetSkillReloader(r *skill.Reloader) {
+	s.reloader = r
+}
+
+// SetSkillIndex sets the index explained by ExplainSkillSelection
+func (s *Service) SetSkillIndex(index *skill.SkillIndex) {
+	s.index = index
+}
+
+// SetArtifacts sets the store served by GetArtifact
+func (s *Service) SetArtifacts(store *artifact.Store) {
+	s.artifacts = store
+}
+
+// Pipeline returns the agent pipeline
+func (s *Service) Pipeline() *agent.Pipeline {
+	return s.pipeline
+}
+
 // SubmitTask submits a new Ops task
 func (s *Service) SubmitTask(ctx context.Context, req *ops.SubmitTaskRequest) (*common.Response, error) {
 	if req.Query == "" {
@@ -37,6 +83,27 @@ func (s *Service) SubmitTask(ctx context.Context, req *ops.SubmitTaskRequest) (*
 			Message: "Query is required",
 		}, nil
 	}
+	if !state.ValidMode(req.Mode) {
+		return &common.Response{
+			Code:    400,
+			Message: fmt.Sprintf("Unknown mode %q: use execute, plan_only or dry_run", req.Mode),
+		}, nil
+	}
+
+	return s.submit(ctx, req.Query, agent.TaskOptions{
+		Mode:           req.Mode,
+		Requester:      requesterFrom(ctx),
+		Environment:    req.Environment,
+		Labels:         req.Labels,
+		OverrideWindow: req.OverrideWindow,
+	})
+}
+
+// submit executes a new task asynchronously and returns it as pending
+func (s *Service) submit(ctx context.Context, query string, opts agent.TaskOptions) (*common.Response, error) {
+	if opts.Mode == "" {
+		opts.Mode = state.ModeExecute
+	}
 
 	// Generate task ID
 	taskID := uuid.New().String()
@@ -44,30 +111,28 @@ func (s *Service) SubmitTask(ctx context.Context, req *ops.SubmitTaskRequest) (*
 		taskID = uuid.New().String()
 	}
 
-	logger.Infof("Submitting task %s: %s", taskID, req.Query)
+	logger.Infof("Submitting task %s (%s) for %q: %s", taskID, opts.Mode, opts.Requester, query)
 
-	// Execute task asynchronously
+	// Execute task asynchronously, it outlives the request submitting it
 	go func() {
-		state, err := s.pipeline.Execute(ctx, req.Query, taskID)
-
-		// Store state
-		s.states[taskID] = state
-
-		if err != nil {
-			logger.Errorf("Task %s failed: %v", taskID, err)
-		} else {
-			logger.Infof("Task %s completed", taskID)
-		}
+		state, err := s.pipeline.Execute(context.WithoutCancel(ctx), query, taskID, opts)
+		s.finish(taskID, state, err)
 	}()
 
 	// Create response data
 	taskData := &ops.Task{
 		TaskId:    taskID,
-		Query:     req.Query,
+		Query:     query,
 		Status:    "pending",
+		Mode:      opts.Mode,
+		Requester: opts.Requester,
+		Labels:    opts.Labels,
 		CreatedAt: time.Now().Format(time.RFC3339),
 		UpdatedAt: time.Now().Format(time.RFC3339),
 	}
+	if opts.Plan != nil {
+		taskData.Runbook = opts.Plan.Runbook
+	}
 
 	anyData, err := anypb.New(taskData)
 	if err != nil {
@@ -96,7 +161,7 @@ func (s *Service) GetTaskStatus(ctx context.Context, req *ops.GetTaskStatusReque
 	logger.Debugf("Querying status for task %s", req.TaskId)
 
 	// Get state from storage
-	state, exists := s.states[req.TaskId]
+	state, exists := s.task(req.TaskId)
 	if !exists {
 		return &common.Response{
 			Code:    404,
@@ -104,20 +169,8 @@ func (s *Service) GetTaskStatus(ctx context.Context, req *ops.GetTaskStatusReque
 		}, nil
 	}
 
-	// Determine status
-	status := "running"
-	if state.FinalResult != nil {
-		if state.FinalResult.Success {
-			status = "completed"
-		} else {
-			status = "failed"
-		}
-	} else if state.Error != "" {
-		status = "failed"
-	}
-
 	// Convert state to proto Task
-	task := stateToProtoTask(req.TaskId, state, status)
+	task := stateToProtoTask(req.TaskId, state, taskStatus(state))
 
 	anyData, err := anypb.New(task)
 	if err != nil {
@@ -138,19 +191,11 @@ func (s *Service) GetTaskStatus(ctx context.Context, req *ops.GetTaskStatusReque
 func (s *Service) ListTasks(ctx context.Context, req *ops.ListTasksRequest) (*common.Response, error) {
 	// Simple implementation: return all tasks
 	// In production, this should support pagination and filtering
-	tasks := make([]*ops.Task, 0, len(s.states))
-
-	for taskID, state := range s.states {
-		status := "running"
-		if state.FinalResult != nil {
-			if state.FinalResult.Success {
-				status = "completed"
-			} else {
-				status = "failed"
-			}
-		} else if state.Error != "" {
-			status = "failed"
-		}
+	states := s.tasks()
+	tasks := make([]*ops.Task, 0, len(states))
+
+	for taskID, state := range states {
+		status := taskStatus(state)
 
 		// Apply filters
 		if req.Status != "" && status != req.Status {
@@ -202,46 +247,581 @@ func (s *Service) CancelTask(ctx context.Context, req *ops.CancelTaskRequest) (*
 		}, nil
 	}
 
-	// Check if task exists
-	taskState, exists := s.states[req.TaskId]
-	if !exists {
+	_, rejected := s.updateTask(req.TaskId, func(taskState *state.State) *common.Response {
+		// Check if task is still running
+		if taskState.FinalResult != nil {
+			return &common.Response{
+				Code:    400,
+				Message: "Task is already completed",
+			}
+		}
+
+		// Mark task as cancelled
+		cancelTask(taskState, "Task cancelled by user", "Task was cancelled by user request")
+		return nil
+	})
+	if rejected != nil {
+		return rejected, nil
+	}
+
+	return &common.Response{
+		Code:    200,
+		Message: "Task cancelled successfully",
+	}, nil
+}
+
+// GetArtifact returns an artifact of a task and its content
+func (s *Service) GetArtifact(ctx context.Context, req *ops.GetArtifactRequest) (*common.Response, error) {
+	if s.artifacts == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Artifacts are disabled",
+		}, nil
+	}
+	if req.TaskId == "" || req.Name == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "task_id and name are required",
+		}, nil
+	}
+
+	a, path, err := s.artifacts.Get(req.TaskId, req.Name, int(req.StepId))
+	if errors.Is(err, fs.ErrNotExist) {
 		return &common.Response{
 			Code:    404,
-			Message: "Task not found",
+			Message: "Artifact not found",
+		}, nil
+	}
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Failed to get artifact: %v", err),
 		}, nil
 	}
 
-	// Check if task is still running
-	if taskState.FinalResult != nil {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Failed to read artifact: %v", err),
+		}, nil
+	}
+
+	anyData, err := anypb.New(&ops.ArtifactContent{
+		Artifact: artifactToProto(a),
+		Content:  content,
+	})
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal artifact",
+		}, nil
+	}
+
+	return &common.Response{
+		Code:    200,
+		Message: "Success",
+		Data:    anyData,
+	}, nil
+}
+
+// ReloadSkills re-parses the skills directory and applies the changes
+func (s *Service) ReloadSkills(ctx context.Context, req *ops.ReloadSkillsRequest) (*common.Response, error) {
+	if s.reloader == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Skill reload is not available",
+		}, nil
+	}
+
+	result, err := s.reloader.Reload()
+	if err != nil {
+		logger.Errorf("Skill reload failed: %v", err)
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Skill reload failed: %v", err),
+		}, nil
+	}
+
+	reloadData := &ops.ReloadSkillsResult{
+		SkillCount: int32(s.reloader.Registry().Count()),
+	}
+	for _, event := range result.Events {
+		reloadData.Changes = append(reloadData.Changes, &ops.SkillChange{
+			Name: event.Name,
+			Type: string(event.Type),
+		})
+	}
+	for _, loadErr := range result.Errors {
+		reloadData.Errors = append(reloadData.Errors, &ops.SkillLoadError{
+			Path:  loadErr.Path,
+			Name:  loadErr.Name,
+			Error: loadErr.Err.Error(),
+		})
+	}
+
+	anyData, err := anypb.New(reloadData)
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal reload result",
+		}, nil
+	}
+
+	return &common.Response{
+		Code:    200,
+		Message: fmt.Sprintf("Skills reloaded: %d changed, %d rejected", len(result.Events), len(result.Errors)),
+		Data:    anyData,
+	}, nil
+}
+
+// ExplainSkillSelection shows how the skills are ranked for a query and which ones the planner gets
+func (s *Service) ExplainSkillSelection(ctx context.Context, req *ops.ExplainSkillSelectionRequest) (*common.Response, error) {
+	if s.index == nil {
+		return &common.Response{
+			Code:    503,
+			Message: "Skill retrieval is disabled, the planner gets every skill",
+		}, nil
+	}
+	if req.Query == "" {
 		return &common.Response{
 			Code:    400,
-			Message: "Task is already completed",
+			Message: "Query is required",
 		}, nil
 	}
 
-	// Mark task as cancelled
-	taskState.Error = "Task cancelled by user"
-	taskState.FinalResult = &state.FinalResult{
-		Success: false,
-		Error:   "Task cancelled by user",
-		Summary: "Task was cancelled by user request",
+	selections, err := s.index.Explain(ctx, req.Query)
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: fmt.Sprintf("Failed to rank skills: %v", err),
+		}, nil
+	}
+
+	result := &ops.SkillSelectionResult{
+		Query: req.Query,
+		TopK:  int32(s.index.TopK()),
+	}
+	selected := 0
+	for _, selection := range selections {
+		candidate := &ops.SkillCandidate{
+			Name:       selection.ID,
+			Score:      selection.Score,
+			Bm25:       selection.BM25,
+			Similarity: selection.Similarity,
+			Selected:   selection.Selected,
+			Reason:     selection.Reason,
+		}
+		for _, match := range selection.Matches {
+			candidate.Matches = append(candidate.Matches, &ops.TermMatch{
+				Term:  match.Term,
+				Field: match.Field,
+				Score: match.Score,
+			})
+		}
+		if selection.Selected {
+			selected++
+		}
+		result.Candidates = append(result.Candidates, candidate)
+	}
+
+	anyData, err := anypb.New(result)
+	if err != nil {
+		return &common.Response{
+			Code:    500,
+			Message: "Failed to marshal skill selection",
+		}, nil
 	}
-	taskState.UpdatedAt = time.Now().Format(time.RFC3339)
 
 	return &common.Response{
 		Code:    200,
-		Message: "Task cancelled successfully",
+		Message: fmt.Sprintf("%d of %d skills selected", selected, len(selections)),
+		Data:    anyData,
+	}, nil
+}
+
+// RollbackTask approves the rollback of a failed task and runs its compensations
+func (s *Service) RollbackTask(ctx context.Context, req *ops.RollbackTaskRequest) (*common.Response, error) {
+	if req.TaskId == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "task_id is required",
+		}, nil
+	}
+
+	// Pending compensations run, failed ones run again. Checking and starting is
+	// atomic, concurrent calls run the compensations once.
+	approver := requesterFrom(ctx)
+	taskState, rejected := s.updateTask(req.TaskId, func(taskState *state.State) *common.Response {
+		rollback := taskState.Rollback
+		if rollback == nil {
+			return &common.Response{
+				Code:    400,
+				Message: "Task has no compensation to run",
+			}
+		}
+		if rollback.Status != state.RollbackPendingApproval && rollback.Status != state.RollbackFailed {
+			return &common.Response{
+				Code:    400,
+				Message: fmt.Sprintf("Rollback is %s", rollback.Status),
+			}
+		}
+
+		// Rollbacks are approved like plans, by someone other than the requester
+		if approver == "" {
+			return &common.Response{
+				Code:    403,
+				Message: fmt.Sprintf("Approving a rollback needs the identity of the approver in %s", RequesterHeader),
+			}
+		}
+		if approver == taskState.Requester {
+			return &common.Response{
+				Code:    403,
+				Message: "A rollback cannot be approved by the requester of the task",
+			}
+		}
+		rollback.Status = state.RollbackRunning
+		rollback.ApprovedBy = approver
+		rollback.ApprovedAt = time.Now().Format(time.RFC3339)
+		return nil
+	})
+	if rejected != nil {
+		return rejected, nil
+	}
+	logger.Infof("Rolling back task %s, approved by %q", req.TaskId, approver)
+
+	// Run the compensations asynchronously, like tasks
+	go s.rollback(context.WithoutCancel(ctx), req.TaskId, taskState)
+
+	return &common.Response{
+		Code:    202,
+		Message: "Rollback approved",
+	}, nil
+}
+
+// ApproveTask approves the plan of a task held by policy rules requiring approval and executes it.
+// Approving a task scheduled for a maintenance window overrides the window and executes it now.
+func (s *Service) ApproveTask(ctx context.Context, req *ops.ApproveTaskRequest) (*common.Response, error) {
+	if req.TaskId == "" {
+		return &common.Response{
+			Code:    400,
+			Message: "task_id is required",
+		}, nil
+	}
+
+	// Checking and approving is atomic, concurrent approvals run the task once
+	approver := requesterFrom(ctx)
+	resumeRollback := false
+	taskState, rejected := s.updateTask(req.TaskId, func(taskState *state.State) *common.Response {
+		pending := taskStatus(taskState) == "pending_approval"
+		scheduled := waitingForWindow(taskState)
+		if !pending && !scheduled {
+			return &common.Response{
+				Code:    400,
+				Message: "Task is not waiting for approval or a maintenance window",
+			}
+		}
+
+		// Approvals need an identity, and the requester of a task cannot approve it
+		if approver == "" {
+			return &common.Response{
+				Code:    403,
+				Message: fmt.Sprintf("Approving a task needs the identity of the approver in %s", RequesterHeader),
+			}
+		}
+		if approver == taskState.Requester {
+			return &common.Response{
+				Code:    403,
+				Message: "A task cannot be approved by its requester",
+			}
+		}
+
+		approval := taskState.Approval
+		if scheduled {
+			// Rules approved before stay approved, the scheduled run is skipped
+			approval = &state.Approval{
+				Steps:       graph.ApprovalSteps(taskState.Steps, taskState.Rollback),
+				Reason:      taskState.Maintenance.Reason,
+				RequestedAt: taskState.Maintenance.HeldAt,
+			}
+			if taskState.Approval != nil {
+				approval.Rules = append(approval.Rules, taskState.Approval.Rules...)
+			}
+			approval.Rules = append(approval.Rules, graph.MaintenanceRule)
+			taskState.Approval = approval
+			taskState.Maintenance = nil
+		}
+		approval.Status = state.ApprovalApproved
+		approval.ApprovedBy = approver
+		approval.ApprovedAt = time.Now().Format(time.RFC3339)
+		approval.Comment = req.Comment
+
+		if rollbackHeld(taskState) {
+			resumeRollback = true
+			taskState.Rollback.Status = state.RollbackRunning
+		}
+		return nil
+	})
+	if rejected != nil {
+		return rejected, nil
+	}
+	logger.Infof("Task %s approved by %q", req.TaskId, approver)
+
+	// Execute the plan, or resume the held rollback, asynchronously like tasks
+	go func() {
+		if resumeRollback {
+			s.rollback(context.WithoutCancel(ctx), req.TaskId, taskState)
+			return
+		}
+		finalState, err := s.pipeline.ExecuteApproved(context.WithoutCancel(ctx), taskState)
+		s.finish(req.TaskId, finalState, err)
+	}()
+
+	return &common.Response{
+		Code:    202,
+		Message: "Task approved",
 	}, nil
 }
 
+// finish stores the state of a task after a run, and schedules the next run of
+// tasks held until a maintenance window opens
+func (s *Service) finish(taskID string, finalState *state.State, err error) {
+	if finalState != nil {
+		s.setTask(taskID, finalState)
+	}
+	switch {
+	case err != nil:
+		logger.Errorf("Task %s failed: %v", taskID, err)
+	case taskStatus(finalState) == "scheduled":
+		logger.Infof("Task %s scheduled: %s", taskID, finalState.Maintenance.Reason)
+		s.resumeAt(taskID, finalState.Maintenance.NotBefore)
+	default:
+		logger.Infof("Task %s completed", taskID)
+	}
+}
+
+// rollback runs the compensations of a task and stores its state. A rollback held
+// until a maintenance window opens is resumed then.
+func (s *Service) rollback(ctx context.Context, taskID string, taskState *state.State) {
+	finalState, err := s.pipeline.Rollback(ctx, taskState)
+	if err != nil || finalState.Rollback == nil {
+		logger.Errorf("Rollback of task %s failed: %v", taskID, err)
+		s.updateTask(taskID, func(taskState *state.State) *common.Response {
+			if taskState.Rollback != nil {
+				taskState.Rollback.Status = state.RollbackFailed
+			}
+			return nil
+		})
+		return
+	}
+	s.setTask(taskID, finalState)
+	logger.Infof("Rollback of task %s %s", taskID, finalState.Rollback.Status)
+	if waitingForWindow(finalState) {
+		s.resumeAt(taskID, finalState.Maintenance.NotBefore)
+	}
+}
+
+// task returns the state of a task
+func (s *Service) task(taskID string) (*state.State, bool) {
+	s.statesMu.RLock()
+	defer s.statesMu.RUnlock()
+	taskState, exists := s.states[taskID]
+	return taskState, exists
+}
+
+// tasks returns a copy of the states of the tasks by ID
+func (s *Service) tasks() map[string]*state.State {
+	s.statesMu.RLock()
+	defer s.statesMu.RUnlock()
+	return maps.Clone(s.states)
+}
+
+// setTask stores the state of a task
+func (s *Service) setTask(taskID string, taskState *state.State) {
+	s.statesMu.Lock()
+	defer s.statesMu.Unlock()
+	s.store(taskID, taskState)
+}
+
+// store stores the state of a task and signals its end, the lock must be held
+func (s *Service) store(taskID string, taskState *state.State) {
+	s.states[taskID] = taskState
+	if ended, ok := s.ended[taskID]; ok && taskEnded(taskState) {
+		close(ended)
+		delete(s.ended, taskID)
+	}
+}
+
+// endOf returns a channel closed once a task is completed or failed
+func (s *Service) endOf(taskID string) <-chan struct{} {
+	s.statesMu.Lock()
+	defer s.statesMu.Unlock()
+	if ended, ok := s.ended[taskID]; ok {
+		return ended
+	}
+	ended := make(chan struct{})
+	if taskState, ok := s.states[taskID]; ok && taskEnded(taskState) {
+		close(ended)
+		return ended
+	}
+	s.ended[taskID] = ended
+	return ended
+}
+
+// updateTask checks and changes the state of a task atomically. update gets a copy
+// of the state, which is stored unless update rejects the change with a response;
+// readers of the previous state never see it change. It returns the stored copy.
+func (s *Service) updateTask(taskID string, update func(taskState *state.State) *common.Response) (*state.State, *common.Response) {
+	s.statesMu.Lock()
+	defer s.statesMu.Unlock()
+	current, exists := s.states[taskID]
+	if !exists {
+		return nil, &common.Response{
+			Code:    404,
+			Message: "Task not found",
+		}
+	}
+	taskState := copyTask(current)
+	if rejected := update(taskState); rejected != nil {
+		return nil, rejected
+	}
+	s.store(taskID, taskState)
+	return taskState, nil
+}
+
+// copyTask copies the state of a task, with its approval, maintenance hold and
+// rollback, which are changed by approvals and rollbacks
+func copyTask(taskState *state.State) *state.State {
+	c := *taskState
+	if taskState.Approval != nil {
+		approval := *taskState.Approval
+		approval.Rules = slices.Clone(approval.Rules)
+		approval.Steps = slices.Clone(approval.Steps)
+		c.Approval = &approval
+	}
+	if taskState.Maintenance != nil {
+		hold := *taskState.Maintenance
+		c.Maintenance = &hold
+	}
+	if taskState.Rollback != nil {
+		rollback := *taskState.Rollback
+		c.Rollback = &rollback
+	}
+	return &c
+}
+
+// resumeAt runs a scheduled task, or its held rollback, again when its maintenance
+// window opens. Tasks cancelled, approved or rescheduled in the meantime are left alone.
+func (s *Service) resumeAt(taskID, notBefore string) {
+	at, err := time.Parse(time.RFC3339, notBefore)
+	if err != nil {
+		logger.Errorf("Task %s cannot be resumed at %q: %v", taskID, notBefore, err)
+		return
+	}
+	time.AfterFunc(time.Until(at), func() {
+		// The task is claimed atomically, an approval meanwhile runs it only once
+		var taskState *state.State
+		_, rejected := s.updateTask(taskID, func(current *state.State) *common.Response {
+			if !waitingForWindow(current) || current.Maintenance.NotBefore != notBefore {
+				return &common.Response{
+					Code:    409,
+					Message: "Task is no longer scheduled for this window",
+				}
+			}
+			taskState = copyTask(current)
+			current.Maintenance = nil
+			if rollbackHeld(current) {
+				current.Rollback.Status = state.RollbackRunning
+			}
+			return nil
+		})
+		if rejected != nil {
+			return
+		}
+		if rollbackHeld(taskState) {
+			logger.Infof("Resuming the rollback of task %s, maintenance window %s is open", taskID, taskState.Maintenance.Window)
+			s.rollback(context.Background(), taskID, taskState)
+			return
+		}
+		logger.Infof("Resuming task %s, maintenance window %s is open", taskID, taskState.Maintenance.Window)
+		finalState, err := s.pipeline.ExecuteScheduled(context.Background(), taskState)
+		s.finish(taskID, finalState, err)
+	})
+}
+
+// requesterFrom returns the identity of the caller, set in the X-Opskills-Requester
+// header by the proxy authenticating users, empty when unknown
+func requesterFrom(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RequesterHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// waitingForWindow reports whether a task, or its held rollback, is scheduled for
+// a maintenance window
+func waitingForWindow(s *state.State) bool {
+	if s.Maintenance == nil || s.Maintenance.Status != state.MaintenanceScheduled {
+		return false
+	}
+	return taskStatus(s) == "scheduled" || rollbackHeld(s)
+}
+
+// rollbackHeld reports whether the rollback of a task waits for ApproveTask or a
+// maintenance window
+func rollbackHeld(s *state.State) bool {
+	return s.Rollback != nil && s.Rollback.Status == state.RollbackHeld
+}
+
+// taskEnded reports whether a task is completed or failed, a held rollback does not
+// keep it running
+func taskEnded(s *state.State) bool {
+	status := taskStatus(s)
+	return status == "completed" || status == "failed"
+}
+
+// cancelTask marks a task as cancelled, the held runs of cancelled tasks are skipped
+func cancelTask(s *state.State, reason, summary string) {
+	s.Error = reason
+	s.FinalResult = &state.FinalResult{
+		Success: false,
+		Error:   reason,
+		Summary: summary,
+	}
+	s.UpdatedAt = time.Now().Format(time.RFC3339)
+}
+
+// taskStatus returns the status of a task from its state
+func taskStatus(s *state.State) string {
+	switch {
+	case s.FinalResult != nil && s.FinalResult.Success:
+		return "completed"
+	case s.FinalResult != nil || s.Error != "":
+		return "failed"
+	case s.Approval != nil && s.Approval.Status == state.ApprovalPending:
+		return "pending_approval"
+	case s.Maintenance != nil && s.Maintenance.Status == state.MaintenanceScheduled:
+		return "scheduled"
+	}
+	return "running"
+}
+
 // stateToProtoTask converts state.State to proto.Task
 func stateToProtoTask(taskID string, s *state.State, status string) *ops.Task {
 	task := &ops.Task{
 		TaskId:    taskID,
 		Query:     s.Query,
 		Status:    status,
+		Mode:      s.Mode,
 		CreatedAt: s.StartedAt,
-		UpdatedAt: s.UpdatedAt,
+
+		Requester:   s.Requester,
+		Environment: s.Environment,
+		UpdatedAt:   s.UpdatedAt,
 	}
 
 	if s.Error != "" {
@@ -254,20 +834,134 @@ func stateToProtoTask(taskID string, s *state.State, status string) *ops.Task {
 		if err == nil {
 			task.Plan = string(planJSON)
 		}
+		task.Runbook = s.Plan.Runbook
 	}
 
 	// Convert results
 	if s.Results != nil {
 		task.Results = make([]*ops.StepResult, len(s.Results))
 		for i, result := range s.Results {
-			task.Results[i] = &ops.StepResult{
-				StepId:  int32(result.StepID),
-				Success: result.Success,
-				Output:  result.Output,
-				Error:   result.Error,
-			}
+			task.Results[i] = stepResultToProto(result)
+		}
+	}
+
+	for _, revision := range s.Revisions {
+		r := &ops.PlanRevision{
+			Revision:  int32(revision.Revision),
+			Reason:    revision.Reason,
+			CreatedAt: revision.CreatedAt,
+		}
+		if planJSON, err := json.Marshal(revision.Plan); err == nil {
+			r.Plan = string(planJSON)
+		}
+		for _, id := range revision.KeptSteps {
+			r.KeptSteps = append(r.KeptSteps, int32(id))
+		}
+		for _, result := range revision.Superseded {
+			r.Superseded = append(r.Superseded, stepResultToProto(result))
+		}
+		task.Revisions = append(task.Revisions, r)
+	}
+
+	for _, a := range s.Artifacts {
+		task.Artifacts = append(task.Artifacts, artifactToProto(a))
+	}
+
+	if s.Rollback != nil {
+		task.Rollback = rollbackToProto(s.Rollback)
+	}
+
+	for _, d := range s.PolicyDecisions {
+		task.PolicyDecisions = append(task.PolicyDecisions, &ops.PolicyDecision{
+			RuleId: d.RuleID,
+			Effect: d.Effect,
+			Stage:  d.Stage,
+			StepId: int32(d.StepID),
+			Reason: d.Reason,
+			Time:   d.Time,
+		})
+	}
+	if a := s.Approval; a != nil {
+		task.Approval = &ops.Approval{
+			Status:      a.Status,
+			Rules:       a.Rules,
+			Reason:      a.Reason,
+			RequestedAt: a.RequestedAt,
+			ApprovedBy:  a.ApprovedBy,
+			ApprovedAt:  a.ApprovedAt,
+			Comment:     a.Comment,
+		}
+	}
+	task.Labels = s.Labels
+	if m := s.Maintenance; m != nil {
+		task.Maintenance = &ops.MaintenanceHold{
+			Status:    m.Status,
+			Reason:    m.Reason,
+			Window:    m.Window,
+			NotBefore: m.NotBefore,
+			StepId:    int32(m.StepID),
+			HeldAt:    m.HeldAt,
 		}
 	}
 
 	return task
 }
+
+// rollbackToProto converts state.Rollback to proto.Rollback
+func rollbackToProto(rollback *state.Rollback) *ops.Rollback {
+	r := &ops.Rollback{
+		Status:     rollback.Status,
+		Reason:     rollback.Reason,
+		CreatedAt:  rollback.CreatedAt,
+		UpdatedAt:  rollback.UpdatedAt,
+		ApprovedBy: rollback.ApprovedBy,
+		ApprovedAt: rollback.ApprovedAt,
+	}
+	for _, c := range rollback.Steps {
+		compensation := &ops.Compensation{
+			StepId:    int32(c.StepID),
+			SkillName: c.SkillName,
+			Action:    c.Action,
+			Status:    c.Status,
+		}
+		if len(c.Params) > 0 {
+			if params, err := json.Marshal(c.Params); err == nil {
+				compensation.Params = string(params)
+			}
+		}
+		if c.Result != nil {
+			compensation.Result = stepResultToProto(c.Result)
+		}
+		r.Steps = append(r.Steps, compensation)
+	}
+	return r
+}
+
+// stepResultToProto converts state.StepResult to proto.StepResult
+func stepResultToProto(result *state.StepResult) *ops.StepResult {
+	r := &ops.StepResult{
+		StepId:    int32(result.StepID),
+		Success:   result.Success,
+		Output:    result.Output,
+		Error:     result.Error,
+		Route:     result.Route,
+		Attempts:  int32(result.Attempts),
+		Simulated: result.Simulated,
+		Skipped:   result.Skipped,
+	}
+	for _, a := range result.Artifacts {
+		r.Artifacts = append(r.Artifacts, artifactToProto(a))
+	}
+	return r
+}
+
+// artifactToProto converts state.Artifact to proto.Artifact
+func artifactToProto(a *state.Artifact) *ops.Artifact {
+	return &ops.Artifact{
+		Name:      a.Name,
+		StepId:    int32(a.StepID),
+		Size:      a.Size,
+		Sha256:    a.SHA256,
+		CreatedAt: a.CreatedAt,
+	}
+}
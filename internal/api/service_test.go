@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/agent"
+	"github.com/hb-chen/opskills/internal/graph"
+	"github.com/hb-chen/opskills/internal/llm"
+	"github.com/hb-chen/opskills/internal/skill"
+	"github.com/hb-chen/opskills/internal/skill/direct"
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/hb-chen/opskills/proto/ops"
+	"google.golang.org/grpc/metadata"
+)
+
+// newTestService returns a service running the skills of dir, whose results the
+// validation LLM always accepts
+func newTestService(t *testing.T, dir string) *Service {
+	t.Helper()
+	skills, err := skill.NewLoader(dir).LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := skill.NewRegistry()
+	for _, s := range skills {
+		if err := registry.Register(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := skill.NewRouter(direct.NewDirectExecutor(time.Minute), nil, registry)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","created":0,"model":"test","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"success\": true, \"reason\": \"done\"}"}}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := llm.NewClient("openai", "test", srv.URL, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpointGraph, err := graph.NewOpsGraphBuilder(router, client).BuildWithCheckpointer("memory", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewService(agent.NewPipelineWithCheckpoint(checkpointGraph, nil, nil))
+}
+
+func TestSubmittedTaskOutlivesRequest(t *testing.T) {
+	dir := t.TempDir()
+	scripts := filepath.Join(dir, "waiter", "scripts")
+	if err := os.MkdirAll(scripts, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "waiter", "SKILL.md"), []byte("---\nname: waiter\ndescription: Waits\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scripts, "wait.sh"), []byte("#!/bin/sh\nsleep 0.5\necho done\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestService(t, dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := s.submit(ctx, "wait", agent.TaskOptions{Plan: &state.Plan{
+		Steps: []*state.PlanStep{{ID: 1, SkillName: "waiter", Action: "wait"}},
+	}})
+	// The handler returns, cancelling the request context
+	cancel()
+	if err != nil || resp.Code != 202 {
+		t.Fatalf("submit = %v, %v", resp, err)
+	}
+	task := &ops.Task{}
+	if err := resp.Data.UnmarshalTo(task); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-s.endOf(task.TaskId):
+	case <-time.After(10 * time.Second):
+		t.Fatal("task did not end")
+	}
+	taskState, _ := s.task(task.TaskId)
+	if len(taskState.Results) != 1 || !taskState.Results[0].Success {
+		t.Fatalf("results = %+v, error %q, want the step to succeed", taskState.Results, taskState.Error)
+	}
+	if got := taskState.Results[0].Output; !strings.Contains(got, "done") {
+		t.Errorf("output = %q, want the script to run to its end", got)
+	}
+}
+
+func TestRollbackTaskNeedsAnotherApprover(t *testing.T) {
+	tests := []struct {
+		name     string
+		approver string
+		code     int32
+	}{
+		{"no identity", "", 403},
+		{"requester of the task", "bob", 403},
+		{"another approver", "alice", 202},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(agent.NewPipeline(nil, nil))
+			s.setTask("task-1", &state.State{
+				TaskID:    "task-1",
+				Requester: "bob",
+				Error:     "step 1 failed",
+				Rollback:  &state.Rollback{Status: state.RollbackPendingApproval},
+			})
+
+			ctx := context.Background()
+			if tt.approver != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RequesterHeader, tt.approver))
+			}
+			resp, err := s.RollbackTask(ctx, &ops.RollbackTaskRequest{TaskId: "task-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tt.code {
+				t.Fatalf("code = %d (%s), want %d", resp.Code, resp.Message, tt.code)
+			}
+			if tt.code != 202 {
+				return
+			}
+			// Wait for the rollback to run, then check who approved it
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				taskState, _ := s.task("task-1")
+				if taskState.Rollback.Status != state.RollbackRunning {
+					if taskState.Rollback.ApprovedBy != tt.approver || taskState.Rollback.ApprovedAt == "" {
+						t.Errorf("approved by %q at %q, want %q", taskState.Rollback.ApprovedBy, taskState.Rollback.ApprovedAt, tt.approver)
+					}
+					return
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("rollback still running")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// Package artifact manages task workspaces and the files steps leave in them
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/hb-chen/opskills/pkg/logger"
+)
+
+// manifestFile lists the artifacts of a task
+const manifestFile = "artifacts.json"
+
+// Store keeps one directory per task:
+//
+//	<dir>/<task id>/workspace/                 working directory of the scripts (SKILL_WORKSPACE)
+//	<dir>/<task id>/artifacts/step-<id>/<name> artifacts copied out of the workspace
+//	<dir>/<task id>/artifacts.json             name, step, size and checksum of each artifact
+//
+// Task directories untouched for longer than the retention are removed by Cleanup.
+type Store struct {
+	dir       string
+	retention time.Duration // 0 keeps tasks forever
+	mu        sync.Mutex
+}
+
+// NewStore creates a store under dir
+func NewStore(dir string, retention time.Duration) (*Store, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	return &Store{dir: abs, retention: retention}, nil
+}
+
+// Workspace returns the workspace of a task, creating it if needed
+func (s *Store) Workspace(taskID string) (string, error) {
+	taskDir, err := s.taskDir(taskID)
+	if err != nil {
+		return "", err
+	}
+	workspace := filepath.Join(taskDir, "workspace")
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		return "", fmt.Errorf("failed to create workspace: %w", err)
+	}
+	touch(taskDir)
+	return workspace, nil
+}
+
+// TaskOf returns the task of a workspace returned by Workspace
+func (s *Store) TaskOf(workspace string) (string, bool) {
+	if workspace == "" || filepath.Base(workspace) != "workspace" {
+		return "", false
+	}
+	taskDir := filepath.Dir(workspace)
+	if filepath.Dir(taskDir) != s.dir {
+		return "", false
+	}
+	return filepath.Base(taskDir), true
+}
+
+// Collect copies files of the task workspace produced by a step into the store
+// and records their checksums. names are paths relative to the workspace;
+// missing files and paths outside the workspace are skipped with a warning.
+func (s *Store) Collect(taskID string, stepID int, names []string) ([]*state.Artifact, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	workspace, err := s.Workspace(taskID)
+	if err != nil {
+		return nil, err
+	}
+	taskDir := filepath.Dir(workspace)
+	// Symlinked directories of a name are resolved, and must stay in the workspace
+	root, err := filepath.EvalSymlinks(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	manifest, err := readManifest(taskDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var collected []*state.Artifact
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name, ok := cleanName(name)
+		if !ok {
+			logger.Warnf("Skipping artifact %q of task %s step %d: outside the workspace", name, taskID, stepID)
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		source := filepath.Join(workspace, name)
+		info, err := os.Lstat(source) // Symlinks could point outside the workspace
+		if err != nil || !info.Mode().IsRegular() {
+			logger.Warnf("Skipping artifact %s of task %s step %d: not a file", name, taskID, stepID)
+			continue
+		}
+		source, err = filepath.EvalSymlinks(source)
+		if err != nil || !within(root, source) {
+			logger.Warnf("Skipping artifact %s of task %s step %d: outside the workspace", name, taskID, stepID)
+			continue
+		}
+
+		target := filepath.Join(taskDir, "artifacts", fmt.Sprintf("step-%d", stepID), name)
+		size, sum, err := copyFile(source, target)
+		if err != nil {
+			return collected, fmt.Errorf("failed to collect artifact %s: %w", name, err)
+		}
+
+		artifact := &state.Artifact{
+			Name:      name,
+			StepID:    stepID,
+			Size:      size,
+			SHA256:    sum,
+			CreatedAt: time.Now().Format(time.RFC3339),
+		}
+		manifest = replaceArtifact(manifest, artifact)
+		collected = append(collected, artifact)
+	}
+
+	if err := writeManifest(taskDir, manifest); err != nil {
+		return collected, err
+	}
+	return collected, nil
+}
+
+// List returns the artifacts of a task, by step then name
+func (s *Store) List(taskID string) ([]*state.Artifact, error) {
+	taskDir, err := s.taskDir(taskID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readManifest(taskDir)
+}
+
+// Get returns an artifact of a task and the path of its content.
+// stepID 0 selects the artifact of the last step that produced name.
+func (s *Store) Get(taskID, name string, stepID int) (*state.Artifact, string, error) {
+	artifacts, err := s.List(taskID)
+	if err != nil {
+		return nil, "", err
+	}
+	name, _ = cleanName(name)
+
+	var found *state.Artifact
+	for _, a := range artifacts {
+		if a.Name == name && (stepID == 0 || a.StepID == stepID) {
+			found = a
+		}
+	}
+	if found == nil {
+		return nil, "", os.ErrNotExist
+	}
+
+	taskDir, _ := s.taskDir(taskID)
+	return found, filepath.Join(taskDir, "artifacts", fmt.Sprintf("step-%d", found.StepID), found.Name), nil
+}
+
+// Cleanup removes the tasks untouched for longer than the retention and returns how many were removed
+func (s *Store) Cleanup() (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	cutoff := time.Now().Add(-s.retention)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove task %s: %w", entry.Name(), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Run removes expired tasks every interval until ctx is done
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if removed, err := s.Cleanup(); err != nil {
+			logger.Warnf("Artifact cleanup failed: %v", err)
+		} else if removed > 0 {
+			logger.Infof("Removed the workspaces and artifacts of %d expired task(s)", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// taskDir returns the directory of a task
+func (s *Store) taskDir(taskID string) (string, error) {
+	if taskID == "" || taskID != filepath.Base(taskID) || taskID == "." || taskID == ".." {
+		return "", fmt.Errorf("invalid task id %q", taskID)
+	}
+	return filepath.Join(s.dir, taskID), nil
+}
+
+// cleanName returns a workspace relative path in canonical form, false if it leaves the workspace
+func cleanName(name string) (string, bool) {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return name, false
+	}
+	return filepath.ToSlash(name), true
+}
+
+// within reports whether path is dir or inside of it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	rel, ok := cleanName(rel)
+	return ok || rel == "."
+}
+
+// copyFile copies source to target and returns the size and SHA-256 of the content
+func copyFile(source, target string) (int64, string, error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, "", err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return 0, "", err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// replaceArtifact adds an artifact to a manifest, replacing the one of the same step and name
+func replaceArtifact(manifest []*state.Artifact, artifact *state.Artifact) []*state.Artifact {
+	for i, a := range manifest {
+		if a.Name == artifact.Name && a.StepID == artifact.StepID {
+			manifest[i] = artifact
+			return manifest
+		}
+	}
+	manifest = append(manifest, artifact)
+	sort.SliceStable(manifest, func(i, j int) bool {
+		if manifest[i].StepID != manifest[j].StepID {
+			return manifest[i].StepID < manifest[j].StepID
+		}
+		return manifest[i].Name < manifest[j].Name
+	})
+	return manifest
+}
+
+func readManifest(taskDir string) ([]*state.Artifact, error) {
+	data, err := os.ReadFile(filepath.Join(taskDir, manifestFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact manifest: %w", err)
+	}
+	var artifacts []*state.Artifact
+	if err := json.Unmarshal(data, &artifacts); err != nil {
+		return nil, fmt.Errorf("invalid artifact manifest: %w", err)
+	}
+	return artifacts, nil
+}
+
+func writeManifest(taskDir string, artifacts []*state.Artifact) error {
+	data, err := json.MarshalIndent(artifacts, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(taskDir, manifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write artifact manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(taskDir, manifestFile)); err != nil {
+		return fmt.Errorf("failed to write artifact manifest: %w", err)
+	}
+	touch(taskDir)
+	return nil
+}
+
+// touch marks a task as used, delaying its expiry
+func touch(dir string) {
+	now := time.Now()
+	os.Chtimes(dir, now, now)
+}
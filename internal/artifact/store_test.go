@@ -0,0 +1,97 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectStaysInWorkspace(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspace, err := store.Workspace("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	files := map[string]string{
+		filepath.Join(workspace, "report.txt"):          "report",
+		filepath.Join(workspace, "logs", "step.log"):    "log",
+		filepath.Join(filepath.Dir(workspace), "x.txt"): "task directory",
+		filepath.Join(outside, "secret.txt"):            "secret",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"secret-link.txt": filepath.Join(outside, "secret.txt"),
+		"report-link.txt": "report.txt",
+		"outside-dir":     outside,
+		"task-dir":        "..",
+		"logs-dir":        "logs",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(workspace, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string // Name of the collected artifact, empty if skipped
+	}{
+		{"report.txt", "report.txt"},
+		{"logs/step.log", "logs/step.log"},
+		{"logs/../report.txt", "report.txt"},
+		{"./report.txt", "report.txt"},
+		{"../x.txt", ""},
+		{"logs/../../x.txt", ""},
+		{"../../task-1/x.txt", ""},
+		{filepath.Join(outside, "secret.txt"), ""},
+		{"/etc/passwd", ""},
+		{".", ""},
+		{"..", ""},
+		{"secret-link.txt", ""},
+		{"report-link.txt", ""},
+		{"outside-dir/secret.txt", ""},
+		{"task-dir/x.txt", ""},
+		{"task-dir/artifacts.json", ""},
+		{"logs-dir/step.log", "logs-dir/step.log"},
+		{"missing.txt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collected, err := store.Collect("task-1", 1, []string{tt.name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.want == "" && len(collected) > 0:
+				t.Fatalf("Collect(%q) = %s, want it skipped", tt.name, collected[0].Name)
+			case tt.want != "" && (len(collected) != 1 || collected[0].Name != tt.want):
+				t.Fatalf("Collect(%q) = %v, want %s", tt.name, collected, tt.want)
+			case tt.want == "":
+				return
+			}
+			artifact, path, err := store.Get("task-1", tt.want, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !within(filepath.Join(filepath.Dir(workspace), "artifacts"), path) || artifact.Size == 0 {
+				t.Errorf("artifact %+v stored at %s", artifact, path)
+			}
+		})
+	}
+
+	if _, err := store.Collect("../task-1", 1, []string{"report.txt"}); err == nil {
+		t.Error("Collect() of task ../task-1 succeeded")
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -39,15 +41,167 @@ type Log struct {
 
 // LLM configuration
 type LLM struct {
-	Provider string `mapstructure:"provider" yaml:"provider"`
-	APIKey   string `mapstructure:"api_key" yaml:"api_key"`
-	Model    string `mapstructure:"model" yaml:"model"`
-	URL      string `mapstructure:"url" yaml:"url"` // Custom LLM service URL
+	Provider string  `mapstructure:"provider" yaml:"provider"`
+	APIKey   string  `mapstructure:"api_key" yaml:"api_key"`
+	Model    string  `mapstructure:"model" yaml:"model"`
+	URL      string  `mapstructure:"url" yaml:"url"` // Custom LLM service URL
+	Prompts  Prompts `mapstructure:"prompts" yaml:"prompts"`
+	Digest   Digest  `mapstructure:"digest" yaml:"digest"`
+}
+
+// Digest configuration
+// Controls how step output is condensed before it is put into validation and replan prompts
+type Digest struct {
+	HeadLines            int            `mapstructure:"head_lines" yaml:"head_lines"`
+	TailLines            int            `mapstructure:"tail_lines" yaml:"tail_lines"`
+	MaxErrorLines        int            `mapstructure:"max_error_lines" yaml:"max_error_lines"`
+	SummarizeAboveTokens int            `mapstructure:"summarize_above_tokens" yaml:"summarize_above_tokens"` // 0 disables LLM summarization
+	DefaultBudget        int            `mapstructure:"default_budget" yaml:"default_budget"`                 // Tokens for all step outputs in one prompt
+	Budgets              map[string]int `mapstructure:"budgets" yaml:"budgets"`                               // Per-model override of default_budget
+}
+
+// Prompts configuration
+// Templates in Dir override the embedded defaults; Dir/skills/<skill>/ holds per-skill overrides
+type Prompts struct {
+	Dir string `mapstructure:"dir" yaml:"dir"`
 }
 
 // Skills configuration
 type Skills struct {
-	Dir string `mapstructure:"dir" yaml:"dir"`
+	Dir      string `mapstructure:"dir" yaml:"dir"`
+	Watch    bool   `mapstructure:"watch" yaml:"watch"`       // Reload skills when files in Dir change
+	Config   string `mapstructure:"config" yaml:"config"`     // Skills config (execution modes, version pins)
+	Lockfile string `mapstructure:"lockfile" yaml:"lockfile"` // Checksums of installed skill versions
+
+	// More skills and MCP server configs (paths or globs) merged after Config, later files win
+	Includes []string `mapstructure:"includes" yaml:"includes"`
+
+	// Built-in Go skills to register (http-check, file-template, wait), all when unset
+	Builtins []string `mapstructure:"builtins" yaml:"builtins"`
+
+	// Tokens of SKILL.md instructions and references per skill in prompts, negative only lists them
+	PromptBudget int `mapstructure:"prompt_budget" yaml:"prompt_budget"`
+
+	Retrieval Retrieval `mapstructure:"retrieval" yaml:"retrieval"`
+	Sandbox   Sandbox   `mapstructure:"sandbox" yaml:"sandbox"`
+}
+
+// Sandbox configuration
+// Restricts the environment, resources and privileges of skill scripts
+type Sandbox struct {
+	EnvAllowlist []string      `mapstructure:"env_allowlist" yaml:"env_allowlist"` // Agent environment passed to scripts, "PREFIX*" matches a prefix
+	Limits       SandboxLimits `mapstructure:"limits" yaml:"limits"`
+	RunAs        string        `mapstructure:"run_as" yaml:"run_as"`         // User scripts run as, the agent must run as root
+	Namespaces   bool          `mapstructure:"namespaces" yaml:"namespaces"` // Unprivileged user and mount namespaces, skills read-only
+	MaxOutputMB  int           `mapstructure:"max_output_mb" yaml:"max_output_mb"`
+}
+
+// SandboxLimits are resource limits of each script process, 0 for none
+type SandboxLimits struct {
+	CPUSeconds uint64 `mapstructure:"cpu_seconds" yaml:"cpu_seconds"`
+	MemoryMB   uint64 `mapstructure:"memory_mb" yaml:"memory_mb"`
+	OpenFiles  uint64 `mapstructure:"open_files" yaml:"open_files"`
+	Processes  uint64 `mapstructure:"processes" yaml:"processes"`
+}
+
+// Retrieval configuration
+// Selects the skills put into planning prompts from an index of SKILL.md, references and actions
+type Retrieval struct {
+	Enabled    bool       `mapstructure:"enabled" yaml:"enabled"`
+	TopK       int        `mapstructure:"top_k" yaml:"top_k"` // Skills per planning prompt
+	Embeddings Embeddings `mapstructure:"embeddings" yaml:"embeddings"`
+}
+
+// Embeddings configuration
+// Combines embedding similarity with BM25 to rank skills
+type Embeddings struct {
+	Enabled bool    `mapstructure:"enabled" yaml:"enabled"`
+	Model   string  `mapstructure:"model" yaml:"model"`
+	Weight  float64 `mapstructure:"weight" yaml:"weight"` // Share of the similarity in the score, 0 to 1
+}
+
+// Redaction configuration
+// Secrets are removed from skill output before it reaches state, prompts, traces and reports
+type Redaction struct {
+	Enabled         bool               `mapstructure:"enabled" yaml:"enabled"`
+	Replacement     string             `mapstructure:"replacement" yaml:"replacement"`
+	DisableDefaults bool               `mapstructure:"disable_defaults" yaml:"disable_defaults"` // Drop the built-in patterns
+	Patterns        []RedactionPattern `mapstructure:"patterns" yaml:"patterns"`
+	Entropy         EntropyConfig      `mapstructure:"entropy" yaml:"entropy"`
+}
+
+// Artifacts configuration
+// Each task gets a workspace; the files its steps declare or emit are kept as artifacts
+type Artifacts struct {
+	Dir       string        `mapstructure:"dir" yaml:"dir"`             // Workspaces and artifacts are kept in <dir>/<task id>
+	Retention time.Duration `mapstructure:"retention" yaml:"retention"` // Tasks untouched for longer are removed, 0 keeps them
+}
+
+// Policy configuration
+// Plans and steps are evaluated against the rules of the policy file before they run
+type Policy struct {
+	File               string `mapstructure:"file" yaml:"file"`                               // Missing file means no rules
+	DefaultEnvironment string `mapstructure:"default_environment" yaml:"default_environment"` // Environment of tasks submitted without one
+}
+
+// Maintenance configuration
+// Mutating steps run only inside the maintenance windows of the file, never during its freezes
+type Maintenance struct {
+	File string `mapstructure:"file" yaml:"file"` // Missing file means no windows and no freezes
+}
+
+// Schedules configuration
+// Schedules run tasks on cron expressions, they and their run history are kept in the file
+type Schedules struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
+	File    string `mapstructure:"file" yaml:"file"`       // Schedules and their runs, created if missing
+	History int    `mapstructure:"history" yaml:"history"` // Runs kept per schedule, 0 keeps them all
+}
+
+// Runbooks configuration
+// Runbooks are fixed step sequences with typed inputs, run without planning
+type Runbooks struct {
+	Dir string `mapstructure:"dir" yaml:"dir"` // One <name>.yaml per runbook, created when a runbook is saved
+}
+
+// Secrets configuration
+// Params referencing secret://<path>[#field] are resolved from the providers, in order
+type Secrets struct {
+	Providers []SecretProvider `mapstructure:"providers" yaml:"providers"`
+}
+
+// SecretProvider configures one secret store
+type SecretProvider struct {
+	Type string `mapstructure:"type" yaml:"type"` // env, file or vault
+
+	// env
+	Prefix string `mapstructure:"prefix" yaml:"prefix"` // Defaults to OPSKILLS_SECRET_
+
+	// file
+	Path    string `mapstructure:"path" yaml:"path"`         // Encrypted YAML file
+	KeyFile string `mapstructure:"key_file" yaml:"key_file"` // Base64 AES-256 key
+	KeyEnv  string `mapstructure:"key_env" yaml:"key_env"`   // Variable holding the key, preferred over key_file
+
+	// vault
+	Addr      string        `mapstructure:"addr" yaml:"addr"`
+	TokenEnv  string        `mapstructure:"token_env" yaml:"token_env"` // Defaults to VAULT_TOKEN
+	Mount     string        `mapstructure:"mount" yaml:"mount"`         // KV mount, defaults to secret
+	KVVersion int           `mapstructure:"kv_version" yaml:"kv_version"`
+	Namespace string        `mapstructure:"namespace" yaml:"namespace"`
+	Timeout   time.Duration `mapstructure:"timeout" yaml:"timeout"`
+}
+
+// RedactionPattern is a named regex; if it has a (?P<secret>...) group only that group is redacted
+type RedactionPattern struct {
+	Name  string `mapstructure:"name" yaml:"name"`
+	Regex string `mapstructure:"regex" yaml:"regex"`
+}
+
+// EntropyConfig configures detection of random-looking tokens
+type EntropyConfig struct {
+	Enabled   bool    `mapstructure:"enabled" yaml:"enabled"`
+	MinLength int     `mapstructure:"min_length" yaml:"min_length"`
+	Threshold float64 `mapstructure:"threshold" yaml:"threshold"` // Bits per character
 }
 
 // Checkpoint configuration - independent from tracing
@@ -64,6 +218,7 @@ type Tracing struct {
 	Enabled  bool             `mapstructure:"enabled" yaml:"enabled"`
 	Markdown MarkdownConfig  `mapstructure:"markdown" yaml:"markdown"`
 	Log      LogTracingConfig `mapstructure:"log" yaml:"log"`
+	StepLogs StepLogsConfig   `mapstructure:"step_logs" yaml:"step_logs"`
 }
 
 type MarkdownConfig struct {
@@ -75,6 +230,24 @@ type LogTracingConfig struct {
 	Level string `mapstructure:"level" yaml:"level"` // minimal, standard, detailed
 }
 
+// StepLogsConfig persists the full output of each step
+type StepLogsConfig struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
+	Dir     string `mapstructure:"dir" yaml:"dir"` // Logs are written to <dir>/<task id>/step-<id>.log
+}
+
+// ReplanConfig limits replanning after a failed or invalid execution
+type ReplanConfig struct {
+	MaxReplans    int  `mapstructure:"max_replans" yaml:"max_replans"`       // 0 disables replanning
+	MaxSteps      int  `mapstructure:"max_steps" yaml:"max_steps"`           // Steps of a plan revision, 0 for no limit
+	KeepCompleted bool `mapstructure:"keep_completed" yaml:"keep_completed"` // Continue after the completed steps instead of rerunning them
+}
+
+// RollbackConfig decides whether the completed steps of a failed task are compensated
+type RollbackConfig struct {
+	Mode string `mapstructure:"mode" yaml:"mode"` // off, auto or approval (waits for RollbackTask)
+}
+
 // Config represents the application configuration
 type Config struct {
 	Server Server `mapstructure:"server" yaml:"server"`
@@ -82,12 +255,23 @@ type Config struct {
 	LLM    LLM    `mapstructure:"llm" yaml:"llm"`
 	Skills Skills `mapstructure:"skills" yaml:"skills"`
 	Agent  Agent  `mapstructure:"agent" yaml:"agent"`
+
+	Redaction Redaction `mapstructure:"redaction" yaml:"redaction"`
+	Artifacts Artifacts `mapstructure:"artifacts" yaml:"artifacts"`
+	Secrets   Secrets   `mapstructure:"secrets" yaml:"secrets"`
+	Policy    Policy    `mapstructure:"policy" yaml:"policy"`
+
+	Maintenance Maintenance `mapstructure:"maintenance" yaml:"maintenance"`
+	Schedules   Schedules   `mapstructure:"schedules" yaml:"schedules"`
+	Runbooks    Runbooks    `mapstructure:"runbooks" yaml:"runbooks"`
 }
 
 // Agent configuration
 type Agent struct {
 	Checkpoint CheckpointConfig `mapstructure:"checkpoint" yaml:"checkpoint"`
 	Tracing    Tracing          `mapstructure:"tracing" yaml:"tracing"`
+	Replan     ReplanConfig     `mapstructure:"replan" yaml:"replan"`
+	Rollback   RollbackConfig   `mapstructure:"rollback" yaml:"rollback"`
 }
 
 // LoadConfig loads configuration from viper
@@ -114,10 +298,90 @@ func LoadConfig() (*Config, error) {
 	if cfg.Skills.Dir == "" {
 		cfg.Skills.Dir = "./skills"
 	}
+	if !Viper().IsSet("skills.watch") {
+		cfg.Skills.Watch = true
+	}
+	if cfg.Skills.Config == "" {
+		cfg.Skills.Config = "./configs/skills.yaml"
+	}
+	if !Viper().IsSet("skills.includes") {
+		cfg.Skills.Includes = []string{"./configs/mcp-servers.yaml"}
+	}
+	if cfg.Skills.Lockfile == "" {
+		cfg.Skills.Lockfile = "./configs/skills.lock"
+	}
+	if cfg.Skills.PromptBudget == 0 {
+		cfg.Skills.PromptBudget = 1500
+	}
+	if !Viper().IsSet("skills.retrieval.enabled") {
+		cfg.Skills.Retrieval.Enabled = true
+	}
+	if cfg.Skills.Retrieval.TopK == 0 {
+		cfg.Skills.Retrieval.TopK = 5
+	}
+	if cfg.Skills.Retrieval.Embeddings.Model == "" {
+		cfg.Skills.Retrieval.Embeddings.Model = "text-embedding-3-small"
+	}
+	if cfg.Skills.Retrieval.Embeddings.Weight == 0 {
+		cfg.Skills.Retrieval.Embeddings.Weight = 0.5
+	}
+	if !Viper().IsSet("skills.sandbox.env_allowlist") {
+		cfg.Skills.Sandbox.EnvAllowlist = []string{"PATH", "HOME", "USER", "LANG", "LC_*", "TZ", "TMPDIR", "TERM"}
+	}
+	if cfg.Skills.Sandbox.MaxOutputMB == 0 {
+		cfg.Skills.Sandbox.MaxOutputMB = 16
+	}
 	if cfg.LLM.Provider == "" {
 		cfg.LLM.Provider = "openai"
 	}
 
+	// Set default redaction config
+	if !Viper().IsSet("redaction.enabled") {
+		cfg.Redaction.Enabled = true
+	}
+	if !Viper().IsSet("redaction.entropy.enabled") {
+		cfg.Redaction.Entropy.Enabled = true
+	}
+
+	// Set default artifacts config
+	if cfg.Artifacts.Dir == "" {
+		cfg.Artifacts.Dir = "./data/artifacts"
+	}
+	if !Viper().IsSet("artifacts.retention") {
+		cfg.Artifacts.Retention = 7 * 24 * time.Hour
+	}
+
+	// Set default secrets config
+	if !Viper().IsSet("secrets.providers") {
+		cfg.Secrets.Providers = []SecretProvider{{Type: "env"}}
+	}
+
+	// Set default policy config
+	if cfg.Policy.File == "" {
+		cfg.Policy.File = "./configs/policies.yaml"
+	}
+
+	// Set default maintenance config
+	if cfg.Maintenance.File == "" {
+		cfg.Maintenance.File = "./configs/maintenance.yaml"
+	}
+
+	// Set default schedules config
+	if !Viper().IsSet("schedules.enabled") {
+		cfg.Schedules.Enabled = true
+	}
+	if cfg.Schedules.File == "" {
+		cfg.Schedules.File = "./data/schedules.json"
+	}
+	if !Viper().IsSet("schedules.history") {
+		cfg.Schedules.History = 50
+	}
+
+	// Set default runbooks config
+	if cfg.Runbooks.Dir == "" {
+		cfg.Runbooks.Dir = "./runbooks"
+	}
+
 	// Set default checkpoint config
 	// Only set defaults if keys were not explicitly set in config
 	if !Viper().IsSet("agent.checkpoint.enabled") {
@@ -144,6 +408,28 @@ func LoadConfig() (*Config, error) {
 	if cfg.Agent.Tracing.Log.Level == "" {
 		cfg.Agent.Tracing.Log.Level = "standard"
 	}
+	if !Viper().IsSet("agent.tracing.step_logs.enabled") {
+		cfg.Agent.Tracing.StepLogs.Enabled = true
+	}
+	if cfg.Agent.Tracing.StepLogs.Dir == "" {
+		cfg.Agent.Tracing.StepLogs.Dir = "./data/logs"
+	}
+
+	// Set default replan config
+	if !Viper().IsSet("agent.replan.max_replans") {
+		cfg.Agent.Replan.MaxReplans = 3
+	}
+	if !Viper().IsSet("agent.replan.max_steps") {
+		cfg.Agent.Replan.MaxSteps = 50
+	}
+	if !Viper().IsSet("agent.replan.keep_completed") {
+		cfg.Agent.Replan.KeepCompleted = true
+	}
+
+	// Set default rollback config
+	if cfg.Agent.Rollback.Mode == "" {
+		cfg.Agent.Rollback.Mode = "approval"
+	}
 
 	return cfg, nil
 }
@@ -8,10 +8,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hb-chen/opskills/internal/artifact"
 	"github.com/hb-chen/opskills/internal/llm"
+	"github.com/hb-chen/opskills/internal/maintenance"
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/retry"
 	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/state"
 	"github.com/hb-chen/opskills/internal/tracer"
+	"github.com/hb-chen/opskills/pkg/logger"
 	"github.com/smallnest/langgraphgo/graph"
 	"github.com/smallnest/langgraphgo/store"
 	"github.com/smallnest/langgraphgo/store/file"
@@ -26,6 +31,14 @@ type OpsGraphBuilder struct {
 	skillRouter *skill.Router
 	llmClient   *llm.Client
 	tracer      tracer.ExecutionTracer // Optional tracer for execution tracking
+	prompts     *llm.PromptRegistry
+	digester    *llm.Digester
+	planner     Planner         // Optional, a placeholder plan is generated when unset
+	artifacts   *artifact.Store // Optional, collects the artifacts of steps
+	replan      ReplanPolicy
+	rollback    RollbackPolicy
+	policy      *policy.Engine        // Optional, plans and steps run without policy checks when unset
+	maintenance *maintenance.Calendar // Optional, mutating steps run at any time when unset
 }
 
 // NewOpsGraphBuilder creates a new graph builder
@@ -33,6 +46,10 @@ func NewOpsGraphBuilder(skillRouter *skill.Router, llmClient *llm.Client) *OpsGr
 	return &OpsGraphBuilder{
 		skillRouter: skillRouter,
 		llmClient:   llmClient,
+		prompts:     llm.DefaultPrompts(),
+		digester:    llm.NewDigester(llm.DigestConfig{}, llmClient),
+		replan:      DefaultReplanPolicy,
+		rollback:    DefaultRollbackPolicy,
 	}
 }
 
@@ -41,28 +58,78 @@ func (b *OpsGraphBuilder) SetTracer(t tracer.ExecutionTracer) {
 	b.tracer = t
 }
 
+// SetPrompts sets the prompt registry used to render LLM prompts
+func (b *OpsGraphBuilder) SetPrompts(p *llm.PromptRegistry) {
+	b.prompts = p
+}
+
+// SetDigester sets the digester used to condense step output for prompts
+func (b *OpsGraphBuilder) SetDigester(d *llm.Digester) {
+	b.digester = d
+}
+
+// SetPlanner sets the planner used by the planning node
+// If the planner also implements Replanner, it is used for replanning
+func (b *OpsGraphBuilder) SetPlanner(p Planner) {
+	b.planner = p
+}
+
+// SetArtifacts sets the store collecting the artifacts steps leave in the task workspace
+func (b *OpsGraphBuilder) SetArtifacts(store *artifact.Store) {
+	b.artifacts = store
+}
+
+// SetReplanPolicy sets the limits of replanning and whether completed steps are kept
+func (b *OpsGraphBuilder) SetReplanPolicy(policy ReplanPolicy) {
+	b.replan = policy
+}
+
+// SetRollbackPolicy sets whether the completed steps of failed tasks are compensated
+func (b *OpsGraphBuilder) SetRollbackPolicy(policy RollbackPolicy) {
+	b.rollback = policy
+}
+
+// SetPolicy sets the policy engine admitting plans and authorizing steps
+func (b *OpsGraphBuilder) SetPolicy(engine *policy.Engine) {
+	b.policy = engine
+}
+
+// SetMaintenance sets the maintenance windows and freezes mutating steps must respect
+func (b *OpsGraphBuilder) SetMaintenance(calendar *maintenance.Calendar) {
+	b.maintenance = calendar
+}
+
 // Build creates a new StateGraph using langgraphgo
 func (b *OpsGraphBuilder) Build() (*graph.StateGraph[map[string]any], error) {
 	// Create state graph
 	g := graph.NewStateGraph[map[string]any]()
 
 	// Set up schema with reducers
+	// Nodes return the full list of results, kept across replans, so it is overwritten
 	schema := graph.NewMapSchema()
 	schema.RegisterReducer("messages", graph.AddMessages)
-	schema.RegisterReducer("results", graph.AppendReducer)
 	g.SetSchema(schema)
 
 	// Add nodes
 	g.AddNode("planning", "Planning node: generates execution plan", b.createPlanningNode())
 	g.AddNode("execution", "Execution node: executes plan steps", b.createExecutionNode())
 	g.AddNode("validation", "Validation node: validates execution results", b.createValidationNode())
+	g.AddNode("rollback", "Rollback node: compensates the completed steps of a failed task", b.createRollbackNode())
+	g.AddNode("plan_check", "Plan check node: checks the plan of plan-only tasks", b.createPlanCheckNode())
+	g.AddNode("policy", "Policy node: admits the plan, or holds it for approval or a maintenance window", b.createPolicyNode())
 
 	// Define edges
-	g.AddEdge("planning", "execution")
-	g.AddEdge("execution", "validation")
-	// Validation node always routes to END
+	// Planning routes to plan_check in plan-only mode, else to policy when rules or
+	// maintenance windows are set
+	g.AddConditionalEdge("planning", b.routeAfterPlanning)
+	g.AddEdge("plan_check", graph.END)
+	g.AddConditionalEdge("policy", b.routeAfterPolicy)
+	// Execution routes to END when a step ends the task or holds it for approval or a window
+	g.AddConditionalEdge("execution", b.routeAfterExecution)
+	// Validation node routes to END, or to rollback when the task failed for good
 	// Replanning is handled by Pipeline layer checking replan_needed flag
-	g.AddEdge("validation", graph.END)
+	g.AddConditionalEdge("validation", b.routeAfterValidation)
+	g.AddEdge("rollback", graph.END)
 	g.SetEntryPoint("planning")
 
 	return g, nil
@@ -74,22 +141,31 @@ func (b *OpsGraphBuilder) BuildCheckpointable(config graph.CheckpointConfig) (*g
 	g := graph.NewCheckpointableStateGraphWithConfig[map[string]any](config)
 
 	// Set up schema with reducers
+	// Nodes return the full list of results, kept across replans, so it is overwritten
 	schema := graph.NewMapSchema()
 	schema.RegisterReducer("messages", graph.AddMessages)
-	schema.RegisterReducer("results", graph.AppendReducer)
 	g.SetSchema(schema)
 
 	// Add nodes
 	g.AddNode("planning", "Planning node: generates execution plan", b.createPlanningNode())
 	g.AddNode("execution", "Execution node: executes plan steps", b.createExecutionNode())
 	g.AddNode("validation", "Validation node: validates execution results", b.createValidationNode())
+	g.AddNode("rollback", "Rollback node: compensates the completed steps of a failed task", b.createRollbackNode())
+	g.AddNode("plan_check", "Plan check node: checks the plan of plan-only tasks", b.createPlanCheckNode())
+	g.AddNode("policy", "Policy node: admits the plan, or holds it for approval or a maintenance window", b.createPolicyNode())
 
 	// Define edges
-	g.AddEdge("planning", "execution")
-	g.AddEdge("execution", "validation")
-	// Validation node always routes to END
+	// Planning routes to plan_check in plan-only mode, else to policy when rules or
+	// maintenance windows are set
+	g.AddConditionalEdge("planning", b.routeAfterPlanning)
+	g.AddEdge("plan_check", graph.END)
+	g.AddConditionalEdge("policy", b.routeAfterPolicy)
+	// Execution routes to END when a step ends the task or holds it for approval or a window
+	g.AddConditionalEdge("execution", b.routeAfterExecution)
+	// Validation node routes to END, or to rollback when the task failed for good
 	// Replanning is handled by Pipeline layer checking replan_needed flag
-	g.AddEdge("validation", graph.END)
+	g.AddConditionalEdge("validation", b.routeAfterValidation)
+	g.AddEdge("rollback", graph.END)
 	g.SetEntryPoint("planning")
 
 	return g, nil
@@ -163,18 +239,27 @@ func (b *OpsGraphBuilder) createPlanningNode() LangGraphNodeFunc {
 		// Convert map to AgentState for easier manipulation
 		agentState := b.mapToAgentState(stateMap)
 
-		// Check if replanning is needed
-		if agentState.ReplanNeeded {
-			// Clear existing plan to force regeneration
-			agentState.Plan = nil
-			agentState.Steps = nil
-			agentState.Results = nil
-			agentState.CurrentStep = 0
-			agentState.ReplanNeeded = false // Reset flag after handling
+		// A plan given with the task, e.g. by a schedule, becomes its first revision
+		// as is, without planning, and is not replanned unless it opts in
+		if agentState.Plan != nil && len(agentState.Revisions) == 0 {
+			agentState.Plan.Fixed = true
+			err := b.revisePlan(agentState, agentState.Plan)
+			if err != nil {
+				agentState.PlanError = err.Error()
+				agentState.Error = fmt.Sprintf("planning failed: %v", err)
+				if b.tracer != nil {
+					b.tracer.TraceError(ctx, taskID, nodeName, err)
+				}
+			}
+			if b.tracer != nil {
+				b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
+			}
+			return b.agentStateToMap(agentState), err
 		}
 
 		// If plan already exists and no replan needed, skip
-		if agentState.Plan != nil {
+		// When replanning, ReplanContext tells the planner what the current plan did
+		if agentState.Plan != nil && !agentState.ReplanNeeded {
 			if b.tracer != nil {
 				b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
 			}
@@ -220,7 +305,7 @@ func (b *OpsGraphBuilder) createPlanningNode() LangGraphNodeFunc {
 
 		llmStartTime := time.Now()
 		// Use planning agent to generate plan
-		plan, err := b.generatePlan(ctx, query)
+		plan, err := b.generatePlan(ctx, query, agentState.ReplanContext)
 		llmDuration := time.Since(llmStartTime)
 
 		if err != nil {
@@ -233,26 +318,30 @@ func (b *OpsGraphBuilder) createPlanningNode() LangGraphNodeFunc {
 			return b.agentStateToMap(agentState), err
 		}
 
-		// Trace LLM response
-		if b.tracer != nil {
-			planSummary := fmt.Sprintf("Generated plan with %d steps", len(plan.Steps))
-			b.tracer.TraceLLMResponse(ctx, taskID, planSummary, llmDuration)
-		}
-
-		agentState.Plan = plan
+		// Make the plan the next revision, after the kept steps when replanning
 		agentState.Query = query
+		if err := b.revisePlan(agentState, plan); err != nil {
+			agentState.PlanError = err.Error()
+			agentState.Error = fmt.Sprintf("planning failed: %v", err)
+			if b.tracer != nil {
+				b.tracer.TraceError(ctx, taskID, nodeName, err)
+				b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
+			}
+			return b.agentStateToMap(agentState), err
+		}
 
-		// Convert plan steps to execution steps
-		agentState.Steps = make([]*state.Step, len(plan.Steps))
-		for i, ps := range plan.Steps {
-			agentState.Steps[i] = &state.Step{
-				ID:          ps.ID,
-				SkillName:   ps.SkillName,
-				Action:      ps.Action,
-				Description: ps.Description,
-				Params:      ps.Params,
-				Status:      "pending",
+		// Trace the prompt and the LLM response
+		if b.tracer != nil {
+			if plan.Prompt != nil {
+				b.tracer.TracePrompt(ctx, taskID, plan.Prompt.Name, plan.Prompt.Version, plan.Prompt.Source)
 			}
+			revision := agentState.Revisions[len(agentState.Revisions)-1]
+			planSummary := fmt.Sprintf("Generated plan with %d steps", len(plan.Steps))
+			if revision.Revision > 0 {
+				planSummary = fmt.Sprintf("Generated plan revision %d with %d new steps, kept %d completed steps",
+					revision.Revision, len(plan.Steps), len(revision.KeptSteps))
+			}
+			b.tracer.TraceLLMResponse(ctx, taskID, planSummary, llmDuration)
 		}
 
 		// Trace node end
@@ -293,6 +382,11 @@ func (b *OpsGraphBuilder) createExecutionNode() LangGraphNodeFunc {
 			agentState.Results = make([]*state.StepResult, 0)
 		}
 
+		// Dry runs only execute the actions supporting it
+		if agentState.Mode == state.ModeDryRun {
+			ctx = skill.WithDryRun(ctx)
+		}
+
 		// Execute pending steps
 		for i := agentState.CurrentStep; i < len(agentState.Steps); i++ {
 			step := agentState.Steps[i]
@@ -304,6 +398,29 @@ func (b *OpsGraphBuilder) createExecutionNode() LangGraphNodeFunc {
 			step.Status = "running"
 			agentState.CurrentStep = i
 
+			// Policy rules are checked again right before the step runs, and mutating
+			// steps wait for a maintenance window. Blocked steps end or hold the task,
+			// they are not replanned.
+			reason := b.authorizeStep(ctx, agentState, step)
+			if reason == "" {
+				reason = b.holdStep(ctx, agentState, step)
+			}
+			if reason != "" {
+				step.Status = "pending"
+				if agentState.FinalResult != nil {
+					step.Status = "failed"
+					agentState.Results = append(agentState.Results, &state.StepResult{
+						StepID: step.ID,
+						Error:  reason,
+					})
+					agentState.Error = agentState.FinalResult.Error
+				}
+				if b.tracer != nil {
+					b.tracer.TraceError(ctx, taskID, nodeName, fmt.Errorf("step %d %s", step.ID, reason))
+				}
+				break
+			}
+
 			// Trace step start
 			if b.tracer != nil {
 				b.tracer.TraceStepStart(ctx, taskID, step)
@@ -314,17 +431,32 @@ func (b *OpsGraphBuilder) createExecutionNode() LangGraphNodeFunc {
 			for k, v := range step.Params {
 				execParams[k] = v
 			}
+			if step.Action != "" {
+				execParams["action"] = step.Action
+			}
 
-			result, err := b.skillRouter.Execute(step.SkillName, execParams)
+			// Retry transient failures before escalating to validation and replanning
+			result, err := b.skillRouter.ExecuteWithRetry(b.withStepOutput(ctx, taskID, step), step.SkillName, execParams, step.Retry)
 			stepDuration := time.Since(stepStartTime)
+			artifacts := b.collectArtifacts(taskID, step, result)
+			route := ""
+			attempts := 0
+			if result != nil {
+				route = result.Route
+				attempts = result.Attempts
+			}
 
 			if err != nil {
 				step.Status = "failed"
 				stepResult := &state.StepResult{
-					StepID:   step.ID,
-					Success:  false,
-					Error:    err.Error(),
-					Duration: stepDuration.String(),
+					StepID:    step.ID,
+					Success:   false,
+					Error:     err.Error(),
+					Duration:  stepDuration.String(),
+					Artifacts: artifacts,
+					Route:     route,
+					Attempts:  attempts,
+					Simulated: result != nil && result.DryRun,
 				}
 				agentState.Results = append(agentState.Results, stepResult)
 				agentState.Error = fmt.Sprintf("step %d failed: %v", step.ID, err)
@@ -344,16 +476,24 @@ func (b *OpsGraphBuilder) createExecutionNode() LangGraphNodeFunc {
 			step.Status = "completed"
 			output := ""
 			errorMsg := ""
+			redactions := 0
 			if result != nil {
 				output = result.Output
 				errorMsg = result.Error
+				redactions = result.Redactions
 			}
 			stepResult := &state.StepResult{
-				StepID:   step.ID,
-				Success:  result != nil && result.Success,
-				Output:   output,
-				Error:    errorMsg,
-				Duration: stepDuration.String(),
+				StepID:     step.ID,
+				Success:    result != nil && result.Success,
+				Output:     output,
+				Error:      errorMsg,
+				Duration:   stepDuration.String(),
+				Redactions: redactions,
+				Artifacts:  artifacts,
+				Route:      route,
+				Attempts:   attempts,
+				Simulated:  result != nil && result.DryRun,
+				Skipped:    result != nil && result.Skipped,
 			}
 			agentState.Results = append(agentState.Results, stepResult)
 
@@ -372,6 +512,36 @@ func (b *OpsGraphBuilder) createExecutionNode() LangGraphNodeFunc {
 	}
 }
 
+// collectArtifacts copies the artifacts reported by a step into the store
+func (b *OpsGraphBuilder) collectArtifacts(taskID string, step *state.Step, result *skill.ExecutionResult) []*state.Artifact {
+	if b.artifacts == nil || result == nil || len(result.Artifacts) == 0 {
+		return nil
+	}
+	artifacts, err := b.artifacts.Collect(taskID, step.ID, result.Artifacts)
+	if err != nil {
+		logger.Warnf("Failed to collect the artifacts of step %d: %v", step.ID, err)
+	}
+	return artifacts
+}
+
+// withStepOutput returns a context streaming the output lines of a step to the tracer
+// and to the output handler already set in ctx (e.g. the SSE stream of the task)
+func (b *OpsGraphBuilder) withStepOutput(ctx context.Context, taskID string, step *state.Step) context.Context {
+	parent := skill.OutputHandlerFrom(ctx)
+	if b.tracer == nil && parent == nil {
+		return ctx
+	}
+	return skill.WithOutputHandler(ctx, func(line skill.OutputLine) {
+		line.StepID = step.ID
+		if b.tracer != nil {
+			b.tracer.TraceStepOutput(ctx, taskID, step.ID, line.Stream, line.Text, line.Time)
+		}
+		if parent != nil {
+			parent(line)
+		}
+	})
+}
+
 // createValidationNode creates the validation node function with enhanced validation and replanning support
 func (b *OpsGraphBuilder) createValidationNode() LangGraphNodeFunc {
 	return func(ctx context.Context, stateMap map[string]any) (map[string]any, error) {
@@ -387,6 +557,15 @@ func (b *OpsGraphBuilder) createValidationNode() LangGraphNodeFunc {
 		// Convert map to AgentState
 		agentState := b.mapToAgentState(stateMap)
 
+		// Dry runs are reported as they ran, without validation nor replanning
+		if agentState.Mode == state.ModeDryRun {
+			agentState.FinalResult = dryRunResult(agentState)
+			if b.tracer != nil {
+				b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
+			}
+			return b.agentStateToMap(agentState), nil
+		}
+
 		// 1. Check if all steps are completed
 		allCompleted := true
 		hasFailures := false
@@ -414,7 +593,7 @@ func (b *OpsGraphBuilder) createValidationNode() LangGraphNodeFunc {
 		// 3. Determine if replanning is needed
 		needsReplan := false
 		replanReason := ""
-		maxReplans := 3 // Maximum number of replan attempts
+		maxReplans := b.replan.MaxReplans
 
 		if hasFailures {
 			needsReplan = true
@@ -429,6 +608,12 @@ func (b *OpsGraphBuilder) createValidationNode() LangGraphNodeFunc {
 			}
 		}
 
+		// Fixed plans fail instead of being revised, unless they opt in
+		fixed := agentState.Plan != nil && agentState.Plan.Fixed && !agentState.Plan.Replan
+		if fixed {
+			needsReplan = false
+		}
+
 		// 4. Check replan count limit
 		replanCount := agentState.ReplanCount
 		if needsReplan && replanCount < maxReplans {
@@ -436,12 +621,9 @@ func (b *OpsGraphBuilder) createValidationNode() LangGraphNodeFunc {
 			agentState.ReplanNeeded = true
 			agentState.ReplanReason = replanReason
 			agentState.ReplanCount = replanCount + 1
+			agentState.ReplanContext = b.replanContext(ctx, agentState, replanReason)
 
-			// Clear current plan and steps to allow replanning
-			agentState.Plan = nil
-			agentState.Steps = nil
-			agentState.Results = nil
-			agentState.CurrentStep = 0
+			// The planning node keeps the completed steps and plans the rest
 			agentState.Error = "" // Clear previous errors
 
 			// Trace replanning decision
@@ -465,13 +647,17 @@ func (b *OpsGraphBuilder) createValidationNode() LangGraphNodeFunc {
 			if hasFailures {
 				errorMsg = "Some steps failed during execution"
 			}
-			if replanCount >= maxReplans {
+			if needsReplan && replanCount >= maxReplans {
 				errorMsg = fmt.Sprintf("Validation failed after %d replan attempts: %s", replanCount, errorMsg)
 			}
+			summary := fmt.Sprintf("Validation failed after %d replan attempts", replanCount)
+			if fixed {
+				summary = "The fixed plan failed, it is not replanned"
+			}
 			agentState.FinalResult = &state.FinalResult{
 				Success: false,
 				Error:   errorMsg,
-				Summary: fmt.Sprintf("Validation failed after %d replan attempts", replanCount),
+				Summary: summary,
 			}
 		}
 
@@ -494,40 +680,26 @@ type ValidationResult struct {
 
 // validateResults validates execution results using LLM
 func (b *OpsGraphBuilder) validateResults(ctx context.Context, agentState *state.AgentState) *ValidationResult {
-	// Build plan summary
-	planSummary := ""
-	if agentState.Plan != nil {
-		for i, step := range agentState.Plan.Steps {
-			planSummary += fmt.Sprintf("Step %d: %s - %s\n", i+1, step.SkillName, step.Description)
-		}
-	}
-
-	// Build results summary
-	resultsSummary := ""
-	for i, result := range agentState.Results {
-		status := "✅ Success"
-		if !result.Success {
-			status = "❌ Failed"
-		}
-		resultsSummary += fmt.Sprintf("Step %d: %s\n", i+1, status)
-		if result.Output != "" {
-			resultsSummary += fmt.Sprintf("  Output: %s\n", truncateString(result.Output, 200))
-		}
-		if result.Error != "" {
-			resultsSummary += fmt.Sprintf("  Error: %s\n", result.Error)
-		}
-	}
-
 	// Build validation prompt
 	promptData := llm.ValidationPromptData{
 		Query:          agentState.Query,
-		PlanSummary:    planSummary,
-		ResultsSummary: resultsSummary,
+		PlanSummary:    b.summarizePlan(agentState.Plan),
+		ResultsSummary: b.summarizeResults(ctx, agentState),
+	}
+	prompt, err := b.prompts.FormatValidationPrompt(promptSkill(agentState), promptData)
+	if err != nil {
+		return &ValidationResult{
+			Success:      false,
+			Reason:       fmt.Sprintf("Validation failed: %v", err),
+			ShouldReplan: false,
+		}
+	}
+	if b.tracer != nil {
+		b.tracer.TracePrompt(ctx, agentState.TaskID, prompt.Name, prompt.Version, prompt.Source)
 	}
-	prompt := llm.FormatValidationPrompt(promptData)
 
 	// Use LLM to evaluate results
-	response, err := b.llmClient.Generate(ctx, prompt)
+	response, err := b.llmClient.Generate(ctx, prompt.Text)
 	if err != nil {
 		return &ValidationResult{
 			Success:    false,
@@ -634,19 +806,62 @@ func extractJSONField(jsonStr, fieldName string) string {
 	return ""
 }
 
-// truncateString truncates a string to max length
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// summarizePlan formats the plan steps for prompts
+func (b *OpsGraphBuilder) summarizePlan(plan *state.Plan) string {
+	planSummary := ""
+	if plan != nil {
+		for _, step := range plan.Steps {
+			planSummary += fmt.Sprintf("Step %d: %s - %s\n", step.ID, step.SkillName, step.Description)
+		}
 	}
-	return s[:maxLen] + "..."
+	return planSummary
 }
 
+// summarizeResults formats the step results for prompts, digesting each
+// output and error to fit the model's token budget
+func (b *OpsGraphBuilder) summarizeResults(ctx context.Context, agentState *state.AgentState) string {
+	results := agentState.Results
+	skills := make(map[int]string, len(agentState.Steps))
+	for _, step := range agentState.Steps {
+		skills[step.ID] = step.SkillName
+	}
+
+	// Output and error of every step share the budget
+	budget := b.digester.StepBudget(2 * len(results))
 
-// generatePlan generates a plan using LLM (temporary, will be replaced with prebuilt agent)
-func (b *OpsGraphBuilder) generatePlan(ctx context.Context, query string) (*state.Plan, error) {
-	// This is a temporary implementation
-	// Will be replaced with prebuilt.CreateReactAgent in the next step
+	resultsSummary := ""
+	for _, result := range results {
+		status := "✅ Success"
+		if !result.Success {
+			status = "❌ Failed"
+		}
+		resultsSummary += fmt.Sprintf("Step %d: %s\n", result.StepID, status)
+		if result.Output != "" {
+			resultsSummary += fmt.Sprintf("  Output:\n%s\n", indent(b.digester.Digest(ctx, skills[result.StepID], result.Output, budget)))
+		}
+		if result.Error != "" {
+			resultsSummary += fmt.Sprintf("  Error:\n%s\n", indent(b.digester.Digest(ctx, skills[result.StepID], result.Error, budget)))
+		}
+	}
+	return resultsSummary
+}
+
+// indent indents every line of s for nesting in a summary
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    ")
+}
+
+// generatePlan generates a plan using the planner, replanning from the previous
+// plan's outcome when replan is set
+func (b *OpsGraphBuilder) generatePlan(ctx context.Context, query string, replan *state.ReplanContext) (*state.Plan, error) {
+	if b.planner != nil {
+		if replanner, ok := b.planner.(Replanner); ok && replan != nil {
+			return replanner.Replan(ctx, query, replan)
+		}
+		return b.planner.Plan(ctx, query)
+	}
+
+	// No planner configured: placeholder plan
 	return &state.Plan{
 		Steps: []*state.PlanStep{
 			{
@@ -673,6 +888,9 @@ func (b *OpsGraphBuilder) mapToAgentState(stateMap map[string]any) *state.AgentS
 		ReplanNeeded: getBool(stateMap, "replan_needed"),
 		ReplanReason: getString(stateMap, "replan_reason"),
 		ReplanCount:  getInt(stateMap, "replan_count"),
+		Mode:         getString(stateMap, "mode"),
+		Requester:    getString(stateMap, "requester"),
+		Environment:  getString(stateMap, "environment"),
 	}
 
 	// Convert plan
@@ -703,6 +921,30 @@ func (b *OpsGraphBuilder) mapToAgentState(stateMap map[string]any) *state.AgentS
 		}
 	}
 
+	// Convert replan context
+	if replanVal, ok := stateMap["replan_context"]; ok {
+		if replanMap, ok := replanVal.(map[string]any); ok {
+			agentState.ReplanContext = &state.ReplanContext{
+				Reason:         getString(replanMap, "reason"),
+				PlanSummary:    getString(replanMap, "plan_summary"),
+				ResultsSummary: getString(replanMap, "results_summary"),
+				Failure:        getString(replanMap, "failure"),
+				Skill:          getString(replanMap, "skill"),
+				KeptSteps:      getInts(replanMap, "kept_steps"),
+				NextStepID:     getInt(replanMap, "next_step_id"),
+			}
+		}
+	}
+
+	agentState.Revisions = b.mapToRevisions(stateMap["revisions"])
+	agentState.Rollback = b.mapToRollback(stateMap["rollback"])
+	agentState.RollbackApproved = getBool(stateMap, "rollback_approved")
+	agentState.PolicyDecisions = b.mapToPolicyDecisions(stateMap["policy_decisions"])
+	agentState.Approval = b.mapToApproval(stateMap["approval"])
+	agentState.Labels = getStringMap(stateMap, "labels")
+	agentState.OverrideWindow = getBool(stateMap, "override_window")
+	agentState.Maintenance = b.mapToMaintenanceHold(stateMap["maintenance"])
+
 	// Messages are handled by langgraphgo's AddMessages reducer
 	// They are kept in the map and managed by the reducer
 
@@ -723,6 +965,11 @@ func (b *OpsGraphBuilder) agentStateToMap(agentState *state.AgentState) map[stri
 	stateMap["replan_needed"] = agentState.ReplanNeeded
 	stateMap["replan_reason"] = agentState.ReplanReason
 	stateMap["replan_count"] = agentState.ReplanCount
+	stateMap["mode"] = agentState.Mode
+	stateMap["requester"] = agentState.Requester
+	stateMap["environment"] = agentState.Environment
+	stateMap["labels"] = agentState.Labels
+	stateMap["override_window"] = agentState.OverrideWindow
 
 	if agentState.Plan != nil {
 		stateMap["plan"] = b.planToMap(agentState.Plan)
@@ -736,15 +983,109 @@ func (b *OpsGraphBuilder) agentStateToMap(agentState *state.AgentState) map[stri
 		stateMap["results"] = b.stepResultsToMap(agentState.Results)
 	}
 
+	if agentState.ReplanContext != nil {
+		stateMap["replan_context"] = map[string]any{
+			"reason":          agentState.ReplanContext.Reason,
+			"plan_summary":    agentState.ReplanContext.PlanSummary,
+			"results_summary": agentState.ReplanContext.ResultsSummary,
+			"failure":         agentState.ReplanContext.Failure,
+			"skill":           agentState.ReplanContext.Skill,
+			"kept_steps":      agentState.ReplanContext.KeptSteps,
+			"next_step_id":    agentState.ReplanContext.NextStepID,
+		}
+	}
+
+	if agentState.Revisions != nil {
+		stateMap["revisions"] = b.revisionsToMap(agentState.Revisions)
+	}
+
 	if agentState.FinalResult != nil {
 		stateMap["final_result"] = b.finalResultToMap(agentState.FinalResult)
 	}
 
+	if agentState.Rollback != nil {
+		stateMap["rollback"] = b.rollbackToMap(agentState.Rollback)
+	}
+	stateMap["rollback_approved"] = agentState.RollbackApproved
+
+	if agentState.PolicyDecisions != nil {
+		stateMap["policy_decisions"] = b.policyDecisionsToMap(agentState.PolicyDecisions)
+	}
+	if agentState.Approval != nil {
+		stateMap["approval"] = b.approvalToMap(agentState.Approval)
+	}
+	// Always set, a released hold must replace the previous one
+	stateMap["maintenance"] = b.maintenanceHoldToMap(agentState.Maintenance)
+
 	// Messages are handled separately by langgraphgo
 
 	return stateMap
 }
 
+// StateFromMap converts the map state of the graph to the state of a task
+func StateFromMap(stateMap map[string]any) *state.State {
+	agentState := (&OpsGraphBuilder{}).mapToAgentState(stateMap)
+	return &state.State{
+		Query:       agentState.Query,
+		Plan:        agentState.Plan,
+		PlanError:   agentState.PlanError,
+		Steps:       agentState.Steps,
+		CurrentStep: agentState.CurrentStep,
+		Results:     agentState.Results,
+		FinalResult: agentState.FinalResult,
+		Error:       agentState.Error,
+		TaskID:      agentState.TaskID,
+		StartedAt:   agentState.StartedAt,
+		UpdatedAt:   agentState.UpdatedAt,
+		Revisions:   agentState.Revisions,
+		ReplanCount: agentState.ReplanCount,
+		Rollback:    agentState.Rollback,
+		Mode:        agentState.Mode,
+
+		Requester:       agentState.Requester,
+		Environment:     agentState.Environment,
+		PolicyDecisions: agentState.PolicyDecisions,
+		Approval:        agentState.Approval,
+		Labels:          agentState.Labels,
+		OverrideWindow:  agentState.OverrideWindow,
+		Maintenance:     agentState.Maintenance,
+	}
+}
+
+// PlanToMap converts a plan to the map state of the graph
+func PlanToMap(plan *state.Plan) map[string]any {
+	return (&OpsGraphBuilder{}).planToMap(plan)
+}
+
+// StateToMap converts the state of a task to the map state of the graph
+func StateToMap(s *state.State) map[string]any {
+	return (&OpsGraphBuilder{}).agentStateToMap(&state.AgentState{
+		Query:       s.Query,
+		Plan:        s.Plan,
+		PlanError:   s.PlanError,
+		Steps:       s.Steps,
+		CurrentStep: s.CurrentStep,
+		Results:     s.Results,
+		FinalResult: s.FinalResult,
+		Error:       s.Error,
+		TaskID:      s.TaskID,
+		StartedAt:   s.StartedAt,
+		UpdatedAt:   s.UpdatedAt,
+		ReplanCount: s.ReplanCount,
+		Revisions:   s.Revisions,
+		Rollback:    s.Rollback,
+		Mode:        s.Mode,
+
+		Requester:       s.Requester,
+		Environment:     s.Environment,
+		PolicyDecisions: s.PolicyDecisions,
+		Approval:        s.Approval,
+		Labels:          s.Labels,
+		OverrideWindow:  s.OverrideWindow,
+		Maintenance:     s.Maintenance,
+	})
+}
+
 // Helper functions for type conversion
 func getString(m map[string]any, key string) string {
 	if val, ok := m[key]; ok {
@@ -778,7 +1119,11 @@ func getBool(m map[string]any, key string) bool {
 
 // Conversion helpers (simplified - full implementation would handle all fields)
 func (b *OpsGraphBuilder) mapToPlan(planMap map[string]any) *state.Plan {
-	plan := &state.Plan{}
+	plan := &state.Plan{
+		Runbook: getString(planMap, "runbook"),
+		Fixed:   getBool(planMap, "fixed"),
+		Replan:  getBool(planMap, "replan"),
+	}
 	if stepsVal, ok := planMap["steps"]; ok {
 		if stepsSlice, ok := stepsVal.([]any); ok {
 			plan.Steps = make([]*state.PlanStep, len(stepsSlice))
@@ -790,6 +1135,7 @@ func (b *OpsGraphBuilder) mapToPlan(planMap map[string]any) *state.Plan {
 						Action:      getString(stepMap, "action"),
 						Description: getString(stepMap, "description"),
 						Params:      getMap(stepMap, "params"),
+						Retry:       mapToRetry(stepMap["retry"]),
 					}
 				}
 			}
@@ -809,6 +1155,7 @@ func (b *OpsGraphBuilder) mapToSteps(stepsSlice []any) []*state.Step {
 				Description: getString(stepMap, "description"),
 				Params:      getMap(stepMap, "params"),
 				Status:      getString(stepMap, "status"),
+				Retry:       mapToRetry(stepMap["retry"]),
 			}
 		}
 	}
@@ -820,11 +1167,17 @@ func (b *OpsGraphBuilder) mapToStepResults(resultsSlice []any) []*state.StepResu
 	for i, resultVal := range resultsSlice {
 		if resultMap, ok := resultVal.(map[string]any); ok {
 			results[i] = &state.StepResult{
-				StepID:   getInt(resultMap, "step_id"),
-				Success:  getBool(resultMap, "success"),
-				Output:   getString(resultMap, "output"),
-				Error:    getString(resultMap, "error"),
-				Duration: getString(resultMap, "duration"),
+				StepID:     getInt(resultMap, "step_id"),
+				Success:    getBool(resultMap, "success"),
+				Output:     getString(resultMap, "output"),
+				Error:      getString(resultMap, "error"),
+				Duration:   getString(resultMap, "duration"),
+				Redactions: getInt(resultMap, "redactions"),
+				Artifacts:  mapToArtifacts(resultMap["artifacts"]),
+				Route:      getString(resultMap, "route"),
+				Attempts:   getInt(resultMap, "attempts"),
+				Simulated:  getBool(resultMap, "simulated"),
+				Skipped:    getBool(resultMap, "skipped"),
 			}
 		}
 	}
@@ -833,10 +1186,11 @@ func (b *OpsGraphBuilder) mapToStepResults(resultsSlice []any) []*state.StepResu
 
 func (b *OpsGraphBuilder) mapToFinalResult(finalMap map[string]any) *state.FinalResult {
 	return &state.FinalResult{
-		Success: getBool(finalMap, "success"),
-		Output:  getString(finalMap, "output"),
-		Error:   getString(finalMap, "error"),
-		Summary: getString(finalMap, "summary"),
+		Success:   getBool(finalMap, "success"),
+		Output:    getString(finalMap, "output"),
+		Error:     getString(finalMap, "error"),
+		Summary:   getString(finalMap, "summary"),
+		Simulated: getBool(finalMap, "simulated"),
 	}
 }
 
@@ -867,10 +1221,20 @@ func (b *OpsGraphBuilder) planToMap(plan *state.Plan) map[string]any {
 				"action":      step.Action,
 				"description": step.Description,
 				"params":      step.Params,
+				"retry":       step.Retry,
 			}
 		}
 		result["steps"] = steps
 	}
+	if plan.Runbook != "" {
+		result["runbook"] = plan.Runbook
+	}
+	if plan.Fixed {
+		result["fixed"] = true
+	}
+	if plan.Replan {
+		result["replan"] = true
+	}
 	return result
 }
 
@@ -884,6 +1248,7 @@ func (b *OpsGraphBuilder) stepsToMap(steps []*state.Step) []any {
 			"description": step.Description,
 			"params":      step.Params,
 			"status":      step.Status,
+			"retry":       step.Retry,
 		}
 	}
 	return result
@@ -893,22 +1258,83 @@ func (b *OpsGraphBuilder) stepResultsToMap(results []*state.StepResult) []any {
 	result := make([]any, len(results))
 	for i, res := range results {
 		result[i] = map[string]any{
-			"step_id":  res.StepID,
-			"success":  res.Success,
-			"output":   res.Output,
-			"error":    res.Error,
-			"duration": res.Duration,
+			"step_id":    res.StepID,
+			"success":    res.Success,
+			"output":     res.Output,
+			"error":      res.Error,
+			"duration":   res.Duration,
+			"redactions": res.Redactions,
+			"artifacts":  artifactsToMap(res.Artifacts),
+			"route":      res.Route,
+			"attempts":   res.Attempts,
+			"simulated":  res.Simulated,
+			"skipped":    res.Skipped,
+		}
+	}
+	return result
+}
+
+// mapToRetry reads a retry policy override, kept as is in memory and as a map
+// once the state went through a checkpoint
+func mapToRetry(val any) *retry.Policy {
+	switch v := val.(type) {
+	case *retry.Policy:
+		return v
+	case map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var policy retry.Policy
+		if err := json.Unmarshal(data, &policy); err != nil {
+			return nil
+		}
+		return &policy
+	}
+	return nil
+}
+
+func artifactsToMap(artifacts []*state.Artifact) []any {
+	result := make([]any, len(artifacts))
+	for i, a := range artifacts {
+		result[i] = map[string]any{
+			"name":       a.Name,
+			"step_id":    a.StepID,
+			"size":       int(a.Size),
+			"sha256":     a.SHA256,
+			"created_at": a.CreatedAt,
 		}
 	}
 	return result
 }
 
+func mapToArtifacts(val any) []*state.Artifact {
+	slice, ok := val.([]any)
+	if !ok {
+		return nil
+	}
+	var artifacts []*state.Artifact
+	for _, v := range slice {
+		if m, ok := v.(map[string]any); ok {
+			artifacts = append(artifacts, &state.Artifact{
+				Name:      getString(m, "name"),
+				StepID:    getInt(m, "step_id"),
+				Size:      int64(getInt(m, "size")),
+				SHA256:    getString(m, "sha256"),
+				CreatedAt: getString(m, "created_at"),
+			})
+		}
+	}
+	return artifacts
+}
+
 func (b *OpsGraphBuilder) finalResultToMap(final *state.FinalResult) map[string]any {
 	return map[string]any{
-		"success": final.Success,
-		"output":  final.Output,
-		"error":   final.Error,
-		"summary": final.Summary,
+		"success":   final.Success,
+		"output":    final.Output,
+		"error":     final.Error,
+		"summary":   final.Summary,
+		"simulated": final.Simulated,
 	}
 }
 
@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+func TestFixedPlansAreNotReplanned(t *testing.T) {
+	tests := []struct {
+		name   string
+		plan   *state.Plan
+		replan bool
+	}{
+		{"planned", &state.Plan{}, true},
+		{"fixed", &state.Plan{Runbook: "add-node", Fixed: true}, false},
+		{"fixed, opting in", &state.Plan{Runbook: "add-node", Fixed: true, Replan: true}, true},
+	}
+
+	b := NewOpsGraphBuilder(nil, nil)
+	validate := b.createValidationNode()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plan.Steps = []*state.PlanStep{{ID: 1, SkillName: "kubekey", Action: "add_nodes"}}
+			task := &state.AgentState{
+				TaskID: "task-1",
+				Query:  "add node4",
+				Plan:   tt.plan,
+				Steps:  []*state.Step{{ID: 1, SkillName: "kubekey", Action: "add_nodes", Status: "failed"}},
+				Results: []*state.StepResult{{
+					StepID: 1,
+					Error:  "ssh: connect to host node4: connection refused",
+				}},
+			}
+			out, err := validate(context.Background(), b.agentStateToMap(task))
+			if err != nil {
+				t.Fatal(err)
+			}
+			task = b.mapToAgentState(out)
+			if task.ReplanNeeded != tt.replan {
+				t.Errorf("replan needed = %v, want %v", task.ReplanNeeded, tt.replan)
+			}
+			if !tt.replan && (task.FinalResult == nil || task.FinalResult.Success) {
+				t.Errorf("final result = %+v, want the task failed", task.FinalResult)
+			}
+		})
+	}
+}
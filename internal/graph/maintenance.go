@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/maintenance"
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// MaintenanceRule is the rule ID of maintenance decisions, approving it overrides
+// the maintenance windows and freezes of a task
+const MaintenanceRule = "maintenance-window"
+
+// EffectSchedule is the effect of maintenance decisions holding a task until a
+// window opens
+const EffectSchedule = "schedule"
+
+// windowStatus checks the maintenance windows of the task for steps. ok is true
+// when they may run now: none of them is mutating, the task is simulated, a window
+// is open or the override of the windows was approved.
+func (b *OpsGraphBuilder) windowStatus(agentState *state.AgentState, steps []*state.Step) (maintenance.Status, bool) {
+	if b.maintenance.Entries() == 0 || state.Simulated(agentState.Mode) || !b.mutating(steps) {
+		return maintenance.Status{Open: true}, true
+	}
+	if approved(agentState.Approval, []string{MaintenanceRule}, steps) {
+		return maintenance.Status{Open: true}, true
+	}
+	status := b.maintenance.Check(windowTarget(agentState), time.Now())
+	return status, status.Open
+}
+
+// mutating reports whether one of steps may change anything, read-only actions do not
+func (b *OpsGraphBuilder) mutating(steps []*state.Step) bool {
+	for _, step := range steps {
+		if !b.skillRouter.ReadOnly(step.SkillName, step.Action) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdForWindow holds the task until the next window opens, or rejects it when
+// configured to or when no window opens within a year. It records the decision and
+// returns its reason.
+func (b *OpsGraphBuilder) holdForWindow(ctx context.Context, agentState *state.AgentState, status maintenance.Status, stage string, stepID int) string {
+	hold := &state.MaintenanceHold{
+		Status: state.MaintenanceScheduled,
+		Reason: status.Reason,
+		Window: status.Window,
+		StepID: stepID,
+		HeldAt: time.Now().Format(time.RFC3339),
+	}
+	effect := EffectSchedule
+	switch {
+	case b.maintenance.OutsideWindow() == maintenance.OutsideReject:
+		hold.Status = state.MaintenanceRejected
+	case status.NextOpen.IsZero():
+		hold.Status = state.MaintenanceRejected
+		hold.Reason += ", no maintenance window opens within a year"
+	default:
+		hold.NotBefore = status.NextOpen.Format(time.RFC3339)
+		hold.Reason += ", scheduled for " + hold.NotBefore
+		if status.Window != "" {
+			hold.Reason += fmt.Sprintf(" (window %s)", status.Window)
+		}
+	}
+	if hold.Status == state.MaintenanceRejected {
+		effect = policy.EffectDeny
+	}
+	agentState.Maintenance = hold
+
+	b.recordDecisions(ctx, agentState, stage, []policy.Decision{{
+		RuleID: MaintenanceRule,
+		Effect: effect,
+		StepID: stepID,
+		Reason: hold.Reason,
+	}})
+	return hold.Reason
+}
+
+// admitWindow checks the maintenance windows of the pending steps of an admitted
+// plan. Tasks asking to override the windows need MaintenanceRule approved, it is
+// returned with its reason; the others are held or rejected.
+func (b *OpsGraphBuilder) admitWindow(ctx context.Context, agentState *state.AgentState) (string, string) {
+	status, ok := b.windowStatus(agentState, pendingSteps(agentState.Steps))
+	if ok {
+		return "", ""
+	}
+
+	if agentState.OverrideWindow {
+		reason := status.Reason + ", overriding the maintenance windows"
+		b.recordDecisions(ctx, agentState, PolicyStagePlan, []policy.Decision{{
+			RuleID: MaintenanceRule,
+			Effect: policy.EffectRequireApproval,
+			Reason: reason,
+		}})
+		return MaintenanceRule, fmt.Sprintf("rule %s: %s", MaintenanceRule, reason)
+	}
+
+	reason := b.holdForWindow(ctx, agentState, status, PolicyStagePlan, 0)
+	if agentState.Maintenance.Status == state.MaintenanceRejected {
+		agentState.FinalResult = &state.FinalResult{
+			Success: false,
+			Error:   "rejected outside maintenance windows: " + reason,
+			Summary: "The plan was rejected outside the maintenance windows, nothing was executed",
+		}
+	}
+	return "", ""
+}
+
+// holdStep checks the maintenance windows right before a step runs, the window may
+// have closed since the plan was admitted. It returns why the step must not run: the
+// task is then held as scheduled, or rejected and ended without replanning nor
+// rollback, which would change the targets outside the windows too.
+func (b *OpsGraphBuilder) holdStep(ctx context.Context, agentState *state.AgentState, step *state.Step) string {
+	status, ok := b.windowStatus(agentState, []*state.Step{step})
+	if ok {
+		return ""
+	}
+	reason := b.holdForWindow(ctx, agentState, status, PolicyStageStep, step.ID)
+	if agentState.Maintenance.Status == state.MaintenanceRejected {
+		reason = "rejected outside maintenance windows: " + reason
+		agentState.FinalResult = &state.FinalResult{
+			Success: false,
+			Error:   fmt.Sprintf("step %d %s", step.ID, reason),
+			Summary: fmt.Sprintf("Step %d was rejected outside the maintenance windows, the task was stopped", step.ID),
+		}
+		return reason
+	}
+	return "held until a maintenance window opens: " + reason
+}
+
+// routeAfterExecution ends tasks stopped or held by a step: denied or rejected
+// outside the maintenance windows, waiting for approval or for a window. The others
+// are validated.
+func (b *OpsGraphBuilder) routeAfterExecution(ctx context.Context, stateMap map[string]any) string {
+	agentState := b.mapToAgentState(stateMap)
+	if agentState.FinalResult != nil {
+		return graph.END
+	}
+	if agentState.Approval != nil && agentState.Approval.Status == state.ApprovalPending {
+		return graph.END
+	}
+	if agentState.Maintenance != nil && agentState.Maintenance.Status == state.MaintenanceScheduled {
+		return graph.END
+	}
+	return "validation"
+}
+
+func windowTarget(agentState *state.AgentState) maintenance.Target {
+	return maintenance.Target{
+		Environment: agentState.Environment,
+		Labels:      agentState.Labels,
+	}
+}
+
+func (b *OpsGraphBuilder) maintenanceHoldToMap(hold *state.MaintenanceHold) map[string]any {
+	if hold == nil {
+		return nil
+	}
+	return map[string]any{
+		"status":     hold.Status,
+		"reason":     hold.Reason,
+		"window":     hold.Window,
+		"not_before": hold.NotBefore,
+		"step_id":    hold.StepID,
+		"held_at":    hold.HeldAt,
+	}
+}
+
+func (b *OpsGraphBuilder) mapToMaintenanceHold(val any) *state.MaintenanceHold {
+	m, ok := val.(map[string]any)
+	if !ok || m == nil {
+		return nil
+	}
+	return &state.MaintenanceHold{
+		Status:    getString(m, "status"),
+		Reason:    getString(m, "reason"),
+		Window:    getString(m, "window"),
+		NotBefore: getString(m, "not_before"),
+		StepID:    getInt(m, "step_id"),
+		HeldAt:    getString(m, "held_at"),
+	}
+}
+
+// getStringMap reads a map of strings, kept as map[string]string in memory and as
+// map[string]any once the state went through a checkpoint
+func getStringMap(m map[string]any, key string) map[string]string {
+	switch v := m[key].(type) {
+	case map[string]string:
+		return v
+	case map[string]any:
+		strs := make(map[string]string, len(v))
+		for k, item := range v {
+			if s, ok := item.(string); ok {
+				strs[k] = s
+			}
+		}
+		return strs
+	}
+	return nil
+}
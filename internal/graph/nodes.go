@@ -36,6 +36,7 @@ func PlanningNode(planner Planner) NodeFunc {
 				Action:      ps.Action,
 				Description: ps.Description,
 				Params:      ps.Params,
+				Retry:       ps.Retry,
 				Status:      "pending",
 			}
 		}
@@ -98,6 +99,10 @@ func ExecutionNode(executor Executor) NodeFunc {
 				Success: true,
 				Summary: fmt.Sprintf("Completed %d steps successfully", len(s.Steps)),
 			}
+			if s.Mode == state.ModeDryRun {
+				s.FinalResult.Summary = fmt.Sprintf("Dry run: %d steps, nothing was changed", len(s.Steps))
+				s.FinalResult.Simulated = true
+			}
 		}
 
 		return s, nil
@@ -109,6 +114,12 @@ type Planner interface {
 	Plan(ctx context.Context, query string) (*state.Plan, error)
 }
 
+// Replanner is implemented by planners that can create a new plan from
+// the outcome of a previous one
+type Replanner interface {
+	Replan(ctx context.Context, query string, replan *state.ReplanContext) (*state.Plan, error)
+}
+
 // Executor interface for execution
 type Executor interface {
 	Execute(ctx context.Context, step *state.Step) (*state.StepResult, error)
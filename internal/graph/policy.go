@@ -0,0 +1,383 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// Policy stages
+const (
+	PolicyStagePlan = "plan" // Before the plan, or a revision of it, runs
+	PolicyStageStep = "step" // Right before a step runs
+)
+
+// createPolicyNode creates the node admitting plans: the plan and its pending steps
+// are evaluated against the policy rules. A denied plan ends the task; a plan
+// requiring approval waits for ApproveTask, unless the rules it matches were
+// already approved for the task along with each of its pending steps, so a
+// revision adding or changing steps is approved again. Admitted plans with
+// mutating steps are then held or rejected outside the maintenance windows of
+// the task.
+func (b *OpsGraphBuilder) createPolicyNode() LangGraphNodeFunc {
+	return func(ctx context.Context, stateMap map[string]any) (map[string]any, error) {
+		startTime := time.Now()
+		nodeName := "policy"
+		taskID := getString(stateMap, "task_id")
+
+		if b.tracer != nil {
+			b.tracer.TraceNodeStart(ctx, nodeName, taskID)
+		}
+
+		agentState := b.mapToAgentState(stateMap)
+		agentState.Maintenance = nil
+		pending := pendingSteps(agentState.Steps)
+		var decisions []policy.Decision
+		if b.policy.Rules() > 0 {
+			decisions = b.policy.EvaluatePlan(policyInput(agentState), policySteps(agentState.Steps))
+			b.recordDecisions(ctx, agentState, PolicyStagePlan, decisions)
+		}
+
+		if policy.Verdict(decisions) == policy.EffectDeny {
+			reasons := policyReasons(decisions, policy.EffectDeny)
+			agentState.FinalResult = &state.FinalResult{
+				Success: false,
+				Error:   "denied by policy: " + reasons,
+				Summary: "The plan was denied by policy, nothing was executed",
+			}
+		} else {
+			rules := policyRules(decisions, policy.EffectRequireApproval)
+			reasons := policyReasons(decisions, policy.EffectRequireApproval)
+			// Tasks are held for a window once the rules requiring approval are approved
+			if len(rules) == 0 || approved(agentState.Approval, rules, pending) {
+				if rule, reason := b.admitWindow(ctx, agentState); rule != "" {
+					rules = append(rules, rule)
+					if reasons != "" {
+						reasons += "; "
+					}
+					reasons += reason
+				}
+			}
+			if len(rules) > 0 && !approved(agentState.Approval, rules, pending) {
+				agentState.Approval = &state.Approval{
+					Status:      state.ApprovalPending,
+					Rules:       rules,
+					Steps:       ApprovalSteps(agentState.Steps, agentState.Rollback),
+					Reason:      reasons,
+					RequestedAt: time.Now().Format(time.RFC3339),
+				}
+			}
+		}
+
+		if b.tracer != nil {
+			b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
+		}
+
+		return b.agentStateToMap(agentState), nil
+	}
+}
+
+// routeAfterPolicy executes admitted plans, denied and pending ones end the graph
+func (b *OpsGraphBuilder) routeAfterPolicy(ctx context.Context, stateMap map[string]any) string {
+	agentState := b.mapToAgentState(stateMap)
+	if agentState.FinalResult != nil {
+		return graph.END
+	}
+	if agentState.Approval != nil && agentState.Approval.Status == state.ApprovalPending {
+		return graph.END
+	}
+	if agentState.Maintenance != nil {
+		return graph.END
+	}
+	return "execution"
+}
+
+// authorizeStep evaluates the step rules right before a step runs, time windows
+// may have closed since the plan was admitted. A denied step ends the task, a step
+// requiring approval holds it until ApproveTask; neither is replanned. It returns
+// why the step must not run, or an empty string.
+func (b *OpsGraphBuilder) authorizeStep(ctx context.Context, agentState *state.AgentState, step *state.Step) string {
+	if b.policy.Rules() == 0 {
+		return ""
+	}
+	decisions := b.policy.AuthorizeStep(policyInput(agentState), policySteps(agentState.Steps), policyStep(step))
+	effect, reason := b.decideStep(ctx, agentState, decisions, step)
+	if effect == policy.EffectDeny {
+		agentState.FinalResult = &state.FinalResult{
+			Success: false,
+			Error:   fmt.Sprintf("step %d %s", step.ID, reason),
+			Summary: fmt.Sprintf("Step %d was denied by policy, the task was stopped", step.ID),
+		}
+	}
+	return reason
+}
+
+// decideStep records the decisions on a step and returns their effect with the
+// reason the step must not run, empty when it may. A step requiring rules that were
+// not approved yet, or not approved for this step, holds the task for approval.
+func (b *OpsGraphBuilder) decideStep(ctx context.Context, agentState *state.AgentState, decisions []policy.Decision, step *state.Step) (string, string) {
+	b.recordDecisions(ctx, agentState, PolicyStageStep, decisions)
+
+	switch effect := policy.Verdict(decisions); effect {
+	case policy.EffectDeny:
+		return effect, "denied by policy: " + policyReasons(decisions, policy.EffectDeny)
+	case policy.EffectRequireApproval:
+		rules := policyRules(decisions, policy.EffectRequireApproval)
+		if approved(agentState.Approval, rules, []*state.Step{step}) {
+			return policy.EffectAllow, ""
+		}
+		reason := "requires approval by policy: " + policyReasons(decisions, policy.EffectRequireApproval)
+		// Rules approved before stay approved
+		if agentState.Approval != nil && agentState.Approval.Status == state.ApprovalApproved {
+			for _, rule := range agentState.Approval.Rules {
+				if !slices.Contains(rules, rule) {
+					rules = append(rules, rule)
+				}
+			}
+		}
+		agentState.Approval = &state.Approval{
+			Status:      state.ApprovalPending,
+			Rules:       rules,
+			Steps:       ApprovalSteps(agentState.Steps, agentState.Rollback),
+			Reason:      reason,
+			RequestedAt: time.Now().Format(time.RFC3339),
+		}
+		return effect, reason
+	}
+	return policy.EffectAllow, ""
+}
+
+// recordDecisions traces decisions and keeps them in the state of the task. Steps
+// allowed by no rule are only traced.
+func (b *OpsGraphBuilder) recordDecisions(ctx context.Context, agentState *state.AgentState, stage string, decisions []policy.Decision) {
+	now := time.Now().Format(time.RFC3339)
+	for _, d := range decisions {
+		decision := &state.PolicyDecision{
+			RuleID: d.RuleID,
+			Effect: d.Effect,
+			Stage:  stage,
+			StepID: d.StepID,
+			Reason: d.Reason,
+			Time:   now,
+		}
+		if b.tracer != nil {
+			b.tracer.TracePolicyDecision(ctx, agentState.TaskID, decision)
+		}
+		if stage == PolicyStageStep && d.Effect == policy.EffectAllow {
+			continue
+		}
+		agentState.PolicyDecisions = append(agentState.PolicyDecisions, decision)
+	}
+}
+
+// approved reports whether every rule of rules was approved for the task, and the
+// approval covers every step of steps
+func approved(approval *state.Approval, rules []string, steps []*state.Step) bool {
+	if approval == nil || approval.Status != state.ApprovalApproved {
+		return false
+	}
+	for _, rule := range rules {
+		if !slices.Contains(approval.Rules, rule) {
+			return false
+		}
+	}
+	for _, step := range steps {
+		if !slices.Contains(approval.Steps, stepDigest(step)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ApprovalSteps returns the digests of the steps an approval of a task covers: the
+// steps of its plan not run yet or, once it failed, the compensations of its rollback
+// not completed yet
+func ApprovalSteps(steps []*state.Step, rollback *state.Rollback) []string {
+	var digests []string
+	if rollback != nil {
+		for _, c := range rollback.Steps {
+			if c.Status != "completed" {
+				digests = append(digests, stepDigest(compensationStep(c)))
+			}
+		}
+		return digests
+	}
+	for _, step := range steps {
+		if step.Status == "pending" || step.Status == "running" {
+			digests = append(digests, stepDigest(step))
+		}
+	}
+	return digests
+}
+
+// stepDigest identifies what a step does: its skill, action and params. IDs and
+// descriptions change across revisions of a plan without changing the step.
+func stepDigest(step *state.Step) string {
+	data, _ := json.Marshal(map[string]any{
+		"skill":  step.SkillName,
+		"action": step.Action,
+		"params": step.Params,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// pendingSteps returns the steps of a plan not run yet
+func pendingSteps(steps []*state.Step) []*state.Step {
+	var pending []*state.Step
+	for _, step := range steps {
+		if step.Status == "pending" {
+			pending = append(pending, step)
+		}
+	}
+	return pending
+}
+
+func policyInput(agentState *state.AgentState) policy.Input {
+	return policy.Input{
+		Requester:   agentState.Requester,
+		Environment: agentState.Environment,
+		Labels:      agentState.Labels,
+		Mode:        agentState.Mode,
+		Query:       agentState.Query,
+		Time:        time.Now(),
+	}
+}
+
+func policySteps(steps []*state.Step) []policy.Step {
+	policySteps := make([]policy.Step, len(steps))
+	for i, step := range steps {
+		policySteps[i] = policyStep(step)
+	}
+	return policySteps
+}
+
+func policyStep(step *state.Step) policy.Step {
+	return policy.Step{
+		ID:          step.ID,
+		Skill:       step.SkillName,
+		Action:      step.Action,
+		Description: step.Description,
+		Params:      step.Params,
+		Pending:     step.Status == "pending",
+	}
+}
+
+// policyRules returns the IDs of the rules of decisions with effect, in order
+func policyRules(decisions []policy.Decision, effect string) []string {
+	var rules []string
+	for _, d := range decisions {
+		if d.Effect == effect && !slices.Contains(rules, d.RuleID) {
+			rules = append(rules, d.RuleID)
+		}
+	}
+	return rules
+}
+
+// policyReasons describes the decisions with effect, e.g. "rule prod-deletes (step 2): ..."
+func policyReasons(decisions []policy.Decision, effect string) string {
+	var reasons []string
+	for _, d := range decisions {
+		if d.Effect != effect {
+			continue
+		}
+		if d.StepID > 0 {
+			reasons = append(reasons, fmt.Sprintf("rule %s (step %d): %s", d.RuleID, d.StepID, d.Reason))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("rule %s: %s", d.RuleID, d.Reason))
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (b *OpsGraphBuilder) policyDecisionsToMap(decisions []*state.PolicyDecision) []any {
+	items := make([]any, len(decisions))
+	for i, d := range decisions {
+		items[i] = map[string]any{
+			"rule_id": d.RuleID,
+			"effect":  d.Effect,
+			"stage":   d.Stage,
+			"step_id": d.StepID,
+			"reason":  d.Reason,
+			"time":    d.Time,
+		}
+	}
+	return items
+}
+
+func (b *OpsGraphBuilder) mapToPolicyDecisions(val any) []*state.PolicyDecision {
+	items, _ := val.([]any)
+	var decisions []*state.PolicyDecision
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		decisions = append(decisions, &state.PolicyDecision{
+			RuleID: getString(m, "rule_id"),
+			Effect: getString(m, "effect"),
+			Stage:  getString(m, "stage"),
+			StepID: getInt(m, "step_id"),
+			Reason: getString(m, "reason"),
+			Time:   getString(m, "time"),
+		})
+	}
+	return decisions
+}
+
+func (b *OpsGraphBuilder) approvalToMap(approval *state.Approval) map[string]any {
+	return map[string]any{
+		"status":       approval.Status,
+		"rules":        approval.Rules,
+		"steps":        approval.Steps,
+		"reason":       approval.Reason,
+		"requested_at": approval.RequestedAt,
+		"approved_by":  approval.ApprovedBy,
+		"approved_at":  approval.ApprovedAt,
+		"comment":      approval.Comment,
+	}
+}
+
+func (b *OpsGraphBuilder) mapToApproval(val any) *state.Approval {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return nil
+	}
+	return &state.Approval{
+		Status:      getString(m, "status"),
+		Rules:       getStrings(m, "rules"),
+		Steps:       getStrings(m, "steps"),
+		Reason:      getString(m, "reason"),
+		RequestedAt: getString(m, "requested_at"),
+		ApprovedBy:  getString(m, "approved_by"),
+		ApprovedAt:  getString(m, "approved_at"),
+		Comment:     getString(m, "comment"),
+	}
+}
+
+// getStrings reads a list of strings, kept as []string in memory and as []any once
+// the state went through a checkpoint
+func getStrings(m map[string]any, key string) []string {
+	switch v := m[key].(type) {
+	case []string:
+		return v
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+func addNodes(id int, status string, nodes ...any) *state.Step {
+	return &state.Step{ID: id, SkillName: "kubekey", Action: "add_nodes", Params: map[string]any{"nodes": nodes}, Status: status}
+}
+
+func TestApprovalCoversSteps(t *testing.T) {
+	engine, err := policy.New([]policy.Rule{{
+		ID:     "approve-prod-changes",
+		Effect: policy.EffectRequireApproval,
+		When:   `environment == "prod" && step.action in ["add_nodes", "delete_node"]`,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewOpsGraphBuilder(nil, nil)
+	b.SetPolicy(engine)
+	admit := b.createPolicyNode()
+
+	tests := []struct {
+		name   string
+		replan []*state.Step // Steps of the revision after the approval
+		status string        // Status of the approval once the revision is admitted
+	}{
+		{
+			name:   "same steps",
+			replan: []*state.Step{addNodes(1, "pending", "node4")},
+			status: state.ApprovalApproved,
+		},
+		{
+			name:   "same steps renumbered",
+			replan: []*state.Step{{ID: 1, SkillName: "shell", Action: "check", Status: "completed"}, addNodes(2, "pending", "node4")},
+			status: state.ApprovalApproved,
+		},
+		{
+			name:   "other step",
+			replan: []*state.Step{addNodes(1, "pending", "node5")},
+			status: state.ApprovalPending,
+		},
+		{
+			name:   "wider params",
+			replan: []*state.Step{addNodes(1, "pending", "node4", "node5", "node6")},
+			status: state.ApprovalPending,
+		},
+		{
+			name:   "added step",
+			replan: []*state.Step{addNodes(1, "pending", "node4"), {ID: 2, SkillName: "kubekey", Action: "delete_node", Params: map[string]any{"nodes": []any{"node1"}}, Status: "pending"}},
+			status: state.ApprovalPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &state.AgentState{
+				TaskID:      "task-1",
+				Environment: "prod",
+				Steps:       []*state.Step{addNodes(1, "pending", "node4")},
+			}
+			out, err := admit(context.Background(), b.agentStateToMap(task))
+			if err != nil {
+				t.Fatal(err)
+			}
+			task = b.mapToAgentState(out)
+			if task.Approval == nil || task.Approval.Status != state.ApprovalPending {
+				t.Fatalf("approval = %+v, want the plan held for approval", task.Approval)
+			}
+
+			// The plan is approved, then replanned
+			task.Approval.Status = state.ApprovalApproved
+			task.Approval.ApprovedBy = "alice"
+			task.Steps = tt.replan
+			out, err = admit(context.Background(), b.agentStateToMap(task))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.mapToAgentState(out).Approval.Status; got != tt.status {
+				t.Errorf("approval = %s, want %s", got, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/skill"
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+// routeAfterPlanning executes the plan once admitted by the policy, or only checks
+// it in plan-only mode
+func (b *OpsGraphBuilder) routeAfterPlanning(ctx context.Context, stateMap map[string]any) string {
+	if getString(stateMap, "mode") == state.ModePlanOnly {
+		return "plan_check"
+	}
+	if b.policy.Rules() > 0 || b.maintenance.Entries() > 0 {
+		return "policy"
+	}
+	return "execution"
+}
+
+// createPlanCheckNode creates the node ending plan-only tasks: it checks that every
+// step names a registered skill and action with its required parameters, and runs nothing
+func (b *OpsGraphBuilder) createPlanCheckNode() LangGraphNodeFunc {
+	return func(ctx context.Context, stateMap map[string]any) (map[string]any, error) {
+		startTime := time.Now()
+		nodeName := "plan_check"
+		taskID := getString(stateMap, "task_id")
+
+		if b.tracer != nil {
+			b.tracer.TraceNodeStart(ctx, nodeName, taskID)
+		}
+
+		agentState := b.mapToAgentState(stateMap)
+		var issues []string
+		for _, step := range agentState.Steps {
+			params := make(skill.ExecutionParams, len(step.Params)+1)
+			for k, v := range step.Params {
+				params[k] = v
+			}
+			if step.Action != "" {
+				params["action"] = step.Action
+			}
+			if err := b.skillRouter.CheckStep(step.SkillName, params); err != nil {
+				issues = append(issues, fmt.Sprintf("step %d: %v", step.ID, err))
+			}
+		}
+
+		// The policy is evaluated as it would be before execution, nothing is held
+		summary := fmt.Sprintf("Plan only: %d steps checked, nothing was executed", len(agentState.Steps))
+		if b.policy.Rules() > 0 {
+			decisions := b.policy.EvaluatePlan(policyInput(agentState), policySteps(agentState.Steps))
+			b.recordDecisions(ctx, agentState, PolicyStagePlan, decisions)
+			switch policy.Verdict(decisions) {
+			case policy.EffectDeny:
+				issues = append(issues, "denied by policy: "+policyReasons(decisions, policy.EffectDeny))
+			case policy.EffectRequireApproval:
+				summary += "; executing it requires approval by policy: " + policyReasons(decisions, policy.EffectRequireApproval)
+			}
+		}
+		if b.mutating(agentState.Steps) {
+			if status := b.maintenance.Check(windowTarget(agentState), time.Now()); !status.Open {
+				summary += "; executing it now would be held: " + status.Reason
+			}
+		}
+
+		agentState.FinalResult = &state.FinalResult{
+			Success:   len(issues) == 0,
+			Summary:   summary,
+			Error:     strings.Join(issues, "; "),
+			Simulated: true,
+		}
+
+		if b.tracer != nil {
+			b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
+		}
+
+		return b.agentStateToMap(agentState), nil
+	}
+}
+
+// dryRunResult is the final result of a dry-run task. Simulated outputs are not
+// validated and do not lead to replanning: the plan is reported as it ran.
+func dryRunResult(agentState *state.AgentState) *state.FinalResult {
+	simulated, skipped := 0, 0
+	for _, result := range agentState.Results {
+		if result.Skipped {
+			skipped++
+		} else {
+			simulated++
+		}
+	}
+	final := &state.FinalResult{
+		Success:   true,
+		Summary:   fmt.Sprintf("Dry run: %d steps simulated, %d skipped, nothing was changed", simulated, skipped),
+		Simulated: true,
+	}
+	for _, step := range agentState.Steps {
+		if step.Status != "completed" {
+			final.Success = false
+			final.Error = fmt.Sprintf("step %d failed in the dry run", step.ID)
+			if agentState.Error != "" {
+				final.Error = agentState.Error
+			}
+			break
+		}
+	}
+	return final
+}
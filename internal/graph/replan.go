@@ -0,0 +1,252 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+// ReplanPolicy limits replanning after a failed or invalid execution
+type ReplanPolicy struct {
+	MaxReplans    int  // Plan revisions after the first plan, 0 disables replanning
+	MaxSteps      int  // Steps of a plan revision, kept ones included, 0 for no limit
+	KeepCompleted bool // Keep the completed steps and plan the remaining work only
+}
+
+// DefaultReplanPolicy is the replan policy of new graph builders
+var DefaultReplanPolicy = ReplanPolicy{
+	MaxReplans:    3,
+	MaxSteps:      50,
+	KeepCompleted: true,
+}
+
+// replanContext describes the current revision of the plan for the planner: what
+// it did, which step failed and which completed steps the next revision keeps
+func (b *OpsGraphBuilder) replanContext(ctx context.Context, agentState *state.AgentState, reason string) *state.ReplanContext {
+	replan := &state.ReplanContext{
+		Reason:         reason,
+		PlanSummary:    b.summarizePlan(agentState.Plan),
+		ResultsSummary: b.summarizeResults(ctx, agentState),
+		Failure:        b.describeFailure(ctx, agentState),
+		Skill:          promptSkill(agentState),
+		NextStepID:     nextStepID(agentState),
+	}
+	if b.replan.KeepCompleted {
+		for _, step := range agentState.Steps {
+			if step.Status == "completed" {
+				replan.KeptSteps = append(replan.KeptSteps, step.ID)
+			}
+		}
+	}
+	return replan
+}
+
+// describeFailure formats the failed step with its parameters and digested error
+func (b *OpsGraphBuilder) describeFailure(ctx context.Context, agentState *state.AgentState) string {
+	for _, step := range agentState.Steps {
+		if step.Status != "failed" {
+			continue
+		}
+		failure := fmt.Sprintf("Step %d: %s %s - %s\n", step.ID, step.SkillName, step.Action, step.Description)
+		if len(step.Params) > 0 {
+			params, _ := json.Marshal(step.Params)
+			failure += fmt.Sprintf("  Params: %s\n", params)
+		}
+		for _, result := range agentState.Results {
+			if result.StepID == step.ID && result.Error != "" {
+				failure += fmt.Sprintf("  Error:\n%s\n", indent(b.digester.Digest(ctx, step.SkillName, result.Error, b.digester.StepBudget(1))))
+			}
+		}
+		return failure
+	}
+	return ""
+}
+
+// promptSkill returns the skill whose prompt overrides apply to a task: the skill of
+// its failed step, or the skill of every step of its plan, empty when they differ
+func promptSkill(agentState *state.AgentState) string {
+	for _, step := range agentState.Steps {
+		if step.Status == "failed" {
+			return step.SkillName
+		}
+	}
+	skillName := ""
+	for _, step := range agentState.Steps {
+		if skillName != "" && step.SkillName != skillName {
+			return ""
+		}
+		skillName = step.SkillName
+	}
+	return skillName
+}
+
+// nextStepID returns an ID no step of any revision of the plan used
+func nextStepID(agentState *state.AgentState) int {
+	next := 1
+	for _, step := range agentState.Steps {
+		next = max(next, step.ID+1)
+	}
+	for _, revision := range agentState.Revisions {
+		if revision.Plan == nil {
+			continue
+		}
+		for _, step := range revision.Plan.Steps {
+			next = max(next, step.ID+1)
+		}
+	}
+	return next
+}
+
+// revisePlan makes plan the next revision of the plan of a task. When replanning,
+// the completed steps listed by the replan context are kept with their results and
+// the steps of plan, numbered after them, continue the task.
+func (b *OpsGraphBuilder) revisePlan(agentState *state.AgentState, plan *state.Plan) error {
+	revision := &state.PlanRevision{
+		Revision:  len(agentState.Revisions),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	// Not nil, so the graph state drops the steps and results of the previous revision
+	var keptPlan []*state.PlanStep
+	keptSteps := make([]*state.Step, 0, len(plan.Steps))
+	keptResults := make([]*state.StepResult, 0)
+
+	replan := agentState.ReplanContext
+	if agentState.ReplanNeeded && replan != nil {
+		revision.Reason = replan.Reason
+		keep := make(map[int]bool, len(replan.KeptSteps))
+		for _, id := range replan.KeptSteps {
+			keep[id] = true
+		}
+		for _, step := range agentState.Steps {
+			if !keep[step.ID] || step.Status != "completed" {
+				continue
+			}
+			keptSteps = append(keptSteps, step)
+			revision.KeptSteps = append(revision.KeptSteps, step.ID)
+			keptPlan = append(keptPlan, &state.PlanStep{
+				ID:          step.ID,
+				SkillName:   step.SkillName,
+				Action:      step.Action,
+				Description: step.Description,
+				Params:      step.Params,
+				Retry:       step.Retry,
+			})
+		}
+		for _, result := range agentState.Results {
+			if keep[result.StepID] {
+				keptResults = append(keptResults, result)
+			} else {
+				revision.Superseded = append(revision.Superseded, result)
+			}
+		}
+
+		// Continuation steps are numbered after every step planned so far
+		next := max(replan.NextStepID, nextStepID(agentState))
+		for i, step := range plan.Steps {
+			step.ID = next + i
+		}
+	}
+
+	total := len(keptPlan) + len(plan.Steps)
+	if b.replan.MaxSteps > 0 && total > b.replan.MaxSteps {
+		return fmt.Errorf("plan revision %d has %d steps, more than the limit of %d", revision.Revision, total, b.replan.MaxSteps)
+	}
+
+	revised := &state.Plan{Steps: append(keptPlan, plan.Steps...), Runbook: plan.Runbook, Fixed: plan.Fixed, Replan: plan.Replan}
+	// A fixed plan opting in to replanning stays tagged with its runbook
+	if previous := agentState.Plan; replan != nil && agentState.ReplanNeeded && previous != nil && previous.Fixed {
+		revised.Runbook = cmp.Or(revised.Runbook, previous.Runbook)
+		revised.Fixed, revised.Replan = true, previous.Replan
+	}
+	agentState.Plan = revised
+	agentState.Steps = keptSteps
+	for _, ps := range plan.Steps {
+		agentState.Steps = append(agentState.Steps, &state.Step{
+			ID:          ps.ID,
+			SkillName:   ps.SkillName,
+			Action:      ps.Action,
+			Description: ps.Description,
+			Params:      ps.Params,
+			Retry:       ps.Retry,
+			Status:      "pending",
+		})
+	}
+	agentState.Results = keptResults
+	agentState.CurrentStep = len(keptSteps)
+	agentState.ReplanNeeded = false
+
+	revision.Plan = agentState.Plan
+	agentState.Revisions = append(agentState.Revisions, revision)
+	return nil
+}
+
+func (b *OpsGraphBuilder) revisionsToMap(revisions []*state.PlanRevision) []any {
+	result := make([]any, len(revisions))
+	for i, r := range revisions {
+		m := map[string]any{
+			"revision":   r.Revision,
+			"reason":     r.Reason,
+			"kept_steps": r.KeptSteps,
+			"created_at": r.CreatedAt,
+			"superseded": b.stepResultsToMap(r.Superseded),
+		}
+		if r.Plan != nil {
+			m["plan"] = b.planToMap(r.Plan)
+		}
+		result[i] = m
+	}
+	return result
+}
+
+func (b *OpsGraphBuilder) mapToRevisions(val any) []*state.PlanRevision {
+	slice, ok := val.([]any)
+	if !ok {
+		return nil
+	}
+	var revisions []*state.PlanRevision
+	for _, v := range slice {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		revision := &state.PlanRevision{
+			Revision:  getInt(m, "revision"),
+			Reason:    getString(m, "reason"),
+			KeptSteps: getInts(m, "kept_steps"),
+			CreatedAt: getString(m, "created_at"),
+		}
+		if planMap, ok := m["plan"].(map[string]any); ok {
+			revision.Plan = b.mapToPlan(planMap)
+		}
+		if superseded, ok := m["superseded"].([]any); ok && len(superseded) > 0 {
+			revision.Superseded = b.mapToStepResults(superseded)
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// getInts reads a list of ints, kept as []int in memory and as []any once the
+// state went through a checkpoint
+func getInts(m map[string]any, key string) []int {
+	switch v := m[key].(type) {
+	case []int:
+		return v
+	case []any:
+		ints := make([]int, 0, len(v))
+		for _, item := range v {
+			switch n := item.(type) {
+			case int:
+				ints = append(ints, n)
+			case float64:
+				ints = append(ints, int(n))
+			}
+		}
+		return ints
+	}
+	return nil
+}
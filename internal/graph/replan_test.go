@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+func TestRevisionsOfRunbookPlansKeepTheRunbook(t *testing.T) {
+	b := NewOpsGraphBuilder(nil, nil)
+	task := &state.AgentState{TaskID: "task-1"}
+	runbookPlan := &state.Plan{
+		Runbook: "add-node",
+		Fixed:   true,
+		Replan:  true,
+		Steps: []*state.PlanStep{
+			{ID: 1, SkillName: "kubekey", Action: "check_kubekey"},
+			{ID: 2, SkillName: "kubekey", Action: "add_nodes"},
+		},
+	}
+	if err := b.revisePlan(task, runbookPlan); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second step fails and the planner continues after the first one
+	task.Steps[0].Status = "completed"
+	task.Steps[1].Status = "failed"
+	task.ReplanNeeded = true
+	task.ReplanContext = &state.ReplanContext{Reason: "step 2 failed", KeptSteps: []int{1}, NextStepID: 3}
+	continuation := &state.Plan{Steps: []*state.PlanStep{{SkillName: "kubekey", Action: "add_nodes", Params: map[string]any{"retry_ssh": true}}}}
+	if err := b.revisePlan(task, continuation); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := task.Plan
+	if plan.Runbook != "add-node" || !plan.Fixed || !plan.Replan {
+		t.Errorf("plan = runbook %q, fixed %v, replan %v, want the runbook plan opting in to replanning", plan.Runbook, plan.Fixed, plan.Replan)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[1].ID != 3 {
+		t.Errorf("steps = %+v, want the kept step followed by step 3", plan.Steps)
+	}
+}
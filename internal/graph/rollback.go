@@ -0,0 +1,345 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/policy"
+	"github.com/hb-chen/opskills/internal/skill"
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// Rollback modes
+const (
+	RollbackOff      = "off"      // Failed tasks keep the changes of their completed steps
+	RollbackAuto     = "auto"     // Compensations run as soon as the task fails
+	RollbackApproval = "approval" // Compensations wait for RollbackTask
+)
+
+// RollbackPolicy decides whether the completed steps of a failed task are compensated
+type RollbackPolicy struct {
+	Mode string // off, auto or approval
+}
+
+// DefaultRollbackPolicy is the rollback policy of new graph builders
+var DefaultRollbackPolicy = RollbackPolicy{Mode: RollbackApproval}
+
+// ValidRollbackMode reports whether mode is a known rollback mode
+func ValidRollbackMode(mode string) bool {
+	switch mode {
+	case RollbackOff, RollbackAuto, RollbackApproval:
+		return true
+	}
+	return false
+}
+
+// routeAfterValidation ends the graph, unless the task failed for good after
+// completing steps that declare compensations
+func (b *OpsGraphBuilder) routeAfterValidation(ctx context.Context, stateMap map[string]any) string {
+	if b.rollback.Mode != RollbackAuto && b.rollback.Mode != RollbackApproval {
+		return graph.END
+	}
+	agentState := b.mapToAgentState(stateMap)
+	if state.Simulated(agentState.Mode) {
+		return graph.END
+	}
+	if agentState.ReplanNeeded || agentState.FinalResult == nil || agentState.FinalResult.Success {
+		return graph.END
+	}
+	if len(b.compensations(agentState)) == 0 {
+		return graph.END
+	}
+	return "rollback"
+}
+
+// createRollbackNode creates the node compensating the completed steps of a failed
+// task, latest first. In approval mode it only records the compensations, the
+// pipeline runs the node again once the rollback is approved.
+func (b *OpsGraphBuilder) createRollbackNode() LangGraphNodeFunc {
+	return func(ctx context.Context, stateMap map[string]any) (map[string]any, error) {
+		startTime := time.Now()
+		nodeName := "rollback"
+		taskID := getString(stateMap, "task_id")
+
+		if b.tracer != nil {
+			b.tracer.TraceNodeStart(ctx, nodeName, taskID)
+		}
+
+		agentState := b.mapToAgentState(stateMap)
+		if agentState.Rollback == nil {
+			reason := ""
+			if agentState.FinalResult != nil {
+				reason = agentState.FinalResult.Error
+			}
+			agentState.Rollback = &state.Rollback{
+				Reason:    reason,
+				Steps:     b.compensations(agentState),
+				CreatedAt: time.Now().Format(time.RFC3339),
+			}
+		}
+
+		rollback := agentState.Rollback
+		if b.rollback.Mode == RollbackApproval && !agentState.RollbackApproved {
+			rollback.Status = state.RollbackPendingApproval
+		} else {
+			b.runRollback(ctx, agentState)
+		}
+		rollback.UpdatedAt = time.Now().Format(time.RFC3339)
+
+		if b.tracer != nil {
+			b.tracer.TraceNodeEnd(ctx, nodeName, taskID, time.Since(startTime))
+		}
+
+		return b.agentStateToMap(agentState), nil
+	}
+}
+
+// compensations lists the compensations of the completed steps, latest first.
+// A compensation whose parameters cannot be resolved is failed upfront.
+func (b *OpsGraphBuilder) compensations(agentState *state.AgentState) []*state.Compensation {
+	var compensations []*state.Compensation
+	for i := len(agentState.Steps) - 1; i >= 0; i-- {
+		step := agentState.Steps[i]
+		if step.Status != "completed" {
+			continue
+		}
+		params := make(skill.ExecutionParams, len(step.Params)+1)
+		for k, v := range step.Params {
+			params[k] = v
+		}
+		params["action"] = step.Action
+
+		resolved, err := b.skillRouter.Compensation(step.SkillName, params)
+		if err != nil {
+			compensations = append(compensations, &state.Compensation{
+				StepID:    step.ID,
+				SkillName: step.SkillName,
+				Status:    "failed",
+				Result: &state.StepResult{
+					StepID: step.ID,
+					Error:  fmt.Sprintf("cannot compensate step %d: %v", step.ID, err),
+				},
+			})
+			continue
+		}
+		if resolved == nil {
+			continue
+		}
+
+		c := &state.Compensation{
+			StepID:    step.ID,
+			SkillName: step.SkillName,
+			Status:    "pending",
+			Params:    make(map[string]interface{}, len(resolved)),
+		}
+		for k, v := range resolved {
+			if k == "action" {
+				c.Action, _ = v.(string)
+				continue
+			}
+			c.Params[k] = v
+		}
+		compensations = append(compensations, c)
+	}
+	return compensations
+}
+
+// runRollback runs the pending compensations in order and stops at the first failure,
+// later ones may depend on it. Failed compensations run again when the rollback is
+// approved again. A compensation held for approval or a maintenance window holds
+// the rollback, ApproveTask or the window resumes it.
+func (b *OpsGraphBuilder) runRollback(ctx context.Context, agentState *state.AgentState) {
+	rollback := agentState.Rollback
+	rollback.Status = state.RollbackRunning
+	agentState.Maintenance = nil
+	for _, c := range rollback.Steps {
+		if c.Status == "completed" {
+			continue
+		}
+		if c.Action == "" {
+			// Not resolvable, the compensated step must be undone by hand
+			continue
+		}
+		step := compensationStep(c)
+		if reason, held := b.admitCompensation(ctx, agentState, step); reason != "" {
+			if b.tracer != nil {
+				b.tracer.TraceError(ctx, agentState.TaskID, "rollback", fmt.Errorf("compensation of step %d %s", c.StepID, reason))
+			}
+			if held {
+				rollback.Status = state.RollbackHeld
+				return
+			}
+			c.Status = "failed"
+			c.Result = &state.StepResult{
+				StepID: c.StepID,
+				Error:  reason,
+			}
+			break
+		}
+		if !b.compensate(ctx, agentState.TaskID, c, step) {
+			break
+		}
+	}
+
+	rollback.Status = state.RollbackCompleted
+	for _, c := range rollback.Steps {
+		if c.Status != "completed" {
+			rollback.Status = state.RollbackFailed
+		}
+	}
+}
+
+// admitCompensation checks a compensation like the execution node checks a step:
+// policy rules, with the compensations counted towards the limits, then the
+// maintenance windows. It returns why the compensation must not run, and whether
+// the rollback is held for approval or a window rather than failed.
+func (b *OpsGraphBuilder) admitCompensation(ctx context.Context, agentState *state.AgentState, step *state.Step) (string, bool) {
+	if b.policy.Rules() > 0 {
+		steps := policySteps(agentState.Steps)
+		for _, c := range agentState.Rollback.Steps {
+			if c.Status == "completed" {
+				steps = append(steps, policyStep(compensationStep(c)))
+			}
+		}
+		decisions := b.policy.AuthorizeCompensation(policyInput(agentState), steps, policyStep(step))
+		switch effect, reason := b.decideStep(ctx, agentState, decisions, step); effect {
+		case policy.EffectDeny:
+			return reason, false
+		case policy.EffectRequireApproval:
+			return reason, true
+		}
+	}
+
+	status, ok := b.windowStatus(agentState, []*state.Step{step})
+	if ok {
+		return "", false
+	}
+	reason := b.holdForWindow(ctx, agentState, status, PolicyStageStep, step.ID)
+	if agentState.Maintenance.Status == state.MaintenanceRejected {
+		return "rejected outside maintenance windows: " + reason, false
+	}
+	return "held until a maintenance window opens: " + reason, true
+}
+
+// compensationStep returns a compensation as the step it runs
+func compensationStep(c *state.Compensation) *state.Step {
+	return &state.Step{
+		ID:          c.StepID,
+		SkillName:   c.SkillName,
+		Action:      c.Action,
+		Description: fmt.Sprintf("Compensate step %d", c.StepID),
+		Params:      c.Params,
+		Status:      c.Status,
+	}
+}
+
+// compensate runs a compensation like a plan step and reports whether it succeeded
+func (b *OpsGraphBuilder) compensate(ctx context.Context, taskID string, c *state.Compensation, step *state.Step) bool {
+	startTime := time.Now()
+	step.Status = "running"
+	if b.tracer != nil {
+		b.tracer.TraceStepStart(ctx, taskID, step)
+	}
+
+	execParams := make(skill.ExecutionParams, len(c.Params)+1)
+	for k, v := range c.Params {
+		execParams[k] = v
+	}
+	execParams["action"] = c.Action
+
+	result, err := b.skillRouter.ExecuteWithRetry(b.withStepOutput(ctx, taskID, step), c.SkillName, execParams, nil)
+	duration := time.Since(startTime)
+	stepResult := &state.StepResult{
+		StepID:    c.StepID,
+		Duration:  duration.String(),
+		Artifacts: b.collectArtifacts(taskID, step, result),
+	}
+	if result != nil {
+		stepResult.Success = result.Success
+		stepResult.Output = result.Output
+		stepResult.Error = result.Error
+		stepResult.Redactions = result.Redactions
+		stepResult.Route = result.Route
+		stepResult.Attempts = result.Attempts
+	}
+	if err != nil {
+		stepResult.Success = false
+		stepResult.Error = err.Error()
+	}
+
+	c.Result = stepResult
+	c.Status = "completed"
+	step.Status = "completed"
+	if !stepResult.Success {
+		c.Status = "failed"
+		step.Status = "failed"
+	}
+
+	if b.tracer != nil {
+		b.tracer.TraceStepEnd(ctx, taskID, step, stepResult, duration)
+		if err != nil {
+			b.tracer.TraceError(ctx, taskID, "rollback", err)
+		}
+	}
+	return stepResult.Success
+}
+
+func (b *OpsGraphBuilder) rollbackToMap(rollback *state.Rollback) map[string]any {
+	steps := make([]any, len(rollback.Steps))
+	for i, c := range rollback.Steps {
+		m := map[string]any{
+			"step_id":    c.StepID,
+			"skill_name": c.SkillName,
+			"action":     c.Action,
+			"params":     c.Params,
+			"status":     c.Status,
+		}
+		if c.Result != nil {
+			m["result"] = b.stepResultsToMap([]*state.StepResult{c.Result})[0]
+		}
+		steps[i] = m
+	}
+	return map[string]any{
+		"status":     rollback.Status,
+		"reason":     rollback.Reason,
+		"steps":      steps,
+		"created_at": rollback.CreatedAt,
+		"updated_at": rollback.UpdatedAt,
+	}
+}
+
+func (b *OpsGraphBuilder) mapToRollback(val any) *state.Rollback {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return nil
+	}
+	rollback := &state.Rollback{
+		Status:    getString(m, "status"),
+		Reason:    getString(m, "reason"),
+		CreatedAt: getString(m, "created_at"),
+		UpdatedAt: getString(m, "updated_at"),
+	}
+	steps, _ := m["steps"].([]any)
+	for _, v := range steps {
+		sm, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		c := &state.Compensation{
+			StepID:    getInt(sm, "step_id"),
+			SkillName: getString(sm, "skill_name"),
+			Action:    getString(sm, "action"),
+			Status:    getString(sm, "status"),
+		}
+		c.Params = getMap(sm, "params")
+		if result, ok := sm["result"]; ok {
+			if results := b.mapToStepResults([]any{result}); len(results) > 0 {
+				c.Result = results[0]
+			}
+		}
+		rollback.Steps = append(rollback.Steps, c)
+	}
+	return rollback
+}
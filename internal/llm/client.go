@@ -92,6 +92,11 @@ func (c *Client) GenerateWithTools(ctx context.Context, prompt string, tools []l
 	return textResponse, toolCalls, nil
 }
 
+// Model returns the configured model name
+func (c *Client) Model() string {
+	return c.model
+}
+
 // GetModel returns the underlying LLM model
 func (c *Client) GetModel() llms.Model {
 	return c.llm
@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hb-chen/opskills/pkg/logger"
+)
+
+// Default digest settings
+const (
+	DefaultDigestHeadLines     = 20
+	DefaultDigestTailLines     = 40
+	DefaultDigestMaxErrorLines = 20
+	DefaultDigestBudget        = 2000 // Tokens for all step outputs in one prompt
+	minStepDigestBudget        = 200  // Lower bound of the per-step budget
+)
+
+// charsPerToken is the rough number of characters per token used for estimates.
+// It errs on the high side for code and logs, which tokenize worse than prose.
+const charsPerToken = 4
+
+// errorLinePattern matches log lines that usually explain a failure
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|fatal|failed|failure|panic|exception|denied|refused|timed? ?out|unreachable|no such|not found|cannot|unable to)\b`)
+
+// DigestConfig configures the output digester
+type DigestConfig struct {
+	HeadLines            int            // Lines kept from the start of the output
+	TailLines            int            // Lines kept from the end of the output
+	MaxErrorLines        int            // Error lines kept from the omitted middle
+	SummarizeAboveTokens int            // Outputs above this size are summarized by the LLM, 0 disables
+	DefaultBudget        int            // Token budget when the model has no entry in Budgets
+	Budgets              map[string]int // Token budget per model name
+}
+
+// Digester condenses step output so it fits into a prompt: it keeps the head and
+// tail, extracts error lines from the middle and optionally summarizes huge logs
+// with the LLM
+type Digester struct {
+	config  DigestConfig
+	client  *Client
+	prompts *PromptRegistry
+}
+
+// NewDigester creates an output digester.
+// client may be nil, in which case LLM summarization is disabled.
+func NewDigester(config DigestConfig, client *Client) *Digester {
+	if config.HeadLines <= 0 {
+		config.HeadLines = DefaultDigestHeadLines
+	}
+	if config.TailLines <= 0 {
+		config.TailLines = DefaultDigestTailLines
+	}
+	if config.MaxErrorLines <= 0 {
+		config.MaxErrorLines = DefaultDigestMaxErrorLines
+	}
+	if config.DefaultBudget <= 0 {
+		config.DefaultBudget = DefaultDigestBudget
+	}
+	// Model names are matched case-insensitively (viper lowercases map keys)
+	budgets := make(map[string]int, len(config.Budgets))
+	for model, budget := range config.Budgets {
+		budgets[strings.ToLower(model)] = budget
+	}
+	config.Budgets = budgets
+	return &Digester{
+		config:  config,
+		client:  client,
+		prompts: DefaultPrompts(),
+	}
+}
+
+// SetPrompts sets the prompt registry used to render the summarize prompt
+func (d *Digester) SetPrompts(p *PromptRegistry) {
+	d.prompts = p
+}
+
+// Budget returns the token budget for all step outputs in one prompt
+func (d *Digester) Budget() int {
+	if d.client != nil {
+		if budget, ok := d.config.Budgets[strings.ToLower(d.client.Model())]; ok && budget > 0 {
+			return budget
+		}
+	}
+	return d.config.DefaultBudget
+}
+
+// StepBudget splits the budget between n step outputs
+func (d *Digester) StepBudget(n int) int {
+	if n <= 0 {
+		n = 1
+	}
+	budget := d.Budget() / n
+	if budget < minStepDigestBudget {
+		budget = minStepDigestBudget
+	}
+	return budget
+}
+
+// Digest condenses output of a step of skillName to at most budget tokens (estimated)
+func (d *Digester) Digest(ctx context.Context, skillName, output string, budget int) string {
+	if EstimateTokens(output) <= budget {
+		return output
+	}
+
+	if d.client != nil && d.config.SummarizeAboveTokens > 0 && EstimateTokens(output) > d.config.SummarizeAboveTokens {
+		summary, err := d.summarize(ctx, skillName, output)
+		if err == nil {
+			return TruncateTokens(summary, budget)
+		}
+		logger.Warnf("[Digest] LLM summarization failed, falling back to excerpt: %v", err)
+	}
+
+	return d.excerpt(output, budget)
+}
+
+// summarize asks the LLM for a summary of a large output.
+// The LLM sees an excerpt bounded by SummarizeAboveTokens, never the raw output.
+func (d *Digester) summarize(ctx context.Context, skillName, output string) (string, error) {
+	prompt, err := d.prompts.Render(PromptSummarize, skillName, SummarizePromptData{
+		Output: d.excerpt(output, d.config.SummarizeAboveTokens),
+	})
+	if err != nil {
+		return "", err
+	}
+	summary, err := d.client.Generate(ctx, prompt.Text)
+	if err != nil {
+		return "", err
+	}
+	return "[summarized] " + strings.TrimSpace(summary), nil
+}
+
+// excerpt keeps the head, the error lines of the middle and the tail of output
+func (d *Digester) excerpt(output string, budget int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+
+	head := lines
+	var middle, tail []string
+	middleStart := 0
+	if len(lines) > d.config.HeadLines+d.config.TailLines {
+		head = lines[:d.config.HeadLines]
+		middleStart = d.config.HeadLines
+		middle = lines[d.config.HeadLines : len(lines)-d.config.TailLines]
+		tail = lines[len(lines)-d.config.TailLines:]
+	}
+
+	// Keep the last error lines, the cause of a failure is usually close to the end
+	var errorLines []string
+	for i := len(middle) - 1; i >= 0 && len(errorLines) < d.config.MaxErrorLines; i-- {
+		if errorLinePattern.MatchString(middle[i]) {
+			errorLines = append([]string{fmt.Sprintf("L%d: %s", middleStart+i+1, middle[i])}, errorLines...)
+		}
+	}
+
+	// Share the budget: the tail explains the outcome, errors explain the failure,
+	// the head gives context
+	headText := strings.Join(head, "\n")
+	tailText := strings.Join(tail, "\n")
+	errorText := strings.Join(errorLines, "\n")
+	if tail == nil {
+		// Few but very long lines: keep both ends of the text
+		return TruncateTokens(headText, budget/3) + "\n" + TruncateTokensFromStart(headText, budget-budget/3)
+	}
+
+	tailBudget := budget / 2
+	errorBudget := budget * 3 / 10
+	headBudget := budget - tailBudget - errorBudget
+	if errorText == "" {
+		tailBudget += errorBudget * 2 / 3
+		headBudget += errorBudget - errorBudget*2/3
+	}
+
+	var b strings.Builder
+	b.WriteString(TruncateTokens(headText, headBudget))
+	b.WriteString(fmt.Sprintf("\n... [%d lines omitted] ...\n", len(middle)))
+	if errorText != "" {
+		b.WriteString("[error lines]\n")
+		b.WriteString(TruncateTokensFromStart(errorText, errorBudget))
+		b.WriteString("\n[end of error lines]\n")
+	}
+	b.WriteString(TruncateTokensFromStart(tailText, tailBudget))
+
+	return b.String()
+}
+
+// EstimateTokens estimates the number of tokens in s
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + charsPerToken - 1) / charsPerToken
+}
+
+// TruncateTokens keeps the start of s within budget tokens, never splitting a character
+func TruncateTokens(s string, budget int) string {
+	maxRunes := budget * charsPerToken
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	if maxRunes <= 0 {
+		return "..."
+	}
+	runes := []rune(s)
+	return string(runes[:maxRunes]) + "..."
+}
+
+// TruncateTokensFromStart keeps the end of s within budget tokens, never splitting a character
+func TruncateTokensFromStart(s string, budget int) string {
+	maxRunes := budget * charsPerToken
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	if maxRunes <= 0 {
+		return "..."
+	}
+	runes := []rune(s)
+	return "..." + string(runes[len(runes)-maxRunes:])
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// NewEmbedder creates an embedding model client for the provider
+func NewEmbedder(provider, apiKey, url, modelName string) (embeddings.Embedder, error) {
+	switch provider {
+	case "openai":
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		opts := []openai.Option{
+			openai.WithToken(apiKey),
+		}
+		if url != "" {
+			opts = append(opts, openai.WithBaseURL(url))
+		}
+		if modelName != "" {
+			opts = append(opts, openai.WithEmbeddingModel(modelName))
+		}
+		client, err := openai.New(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenAI embedding client: %w", err)
+		}
+		return embeddings.NewEmbedder(client)
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", provider)
+	}
+}
@@ -1,112 +1,97 @@
 package llm
 
 import (
+	"bytes"
+	"embed"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
 )
 
+// Prompt names
 const (
-	// PlanningPromptTemplate is the template for planning prompts
-	PlanningPromptTemplate = `You are an intelligent operations agent. Your task is to analyze the user's request and create an execution plan using available skills.
-
-Available Skills:
-{{range .Skills}}
-- {{.Name}}: {{.Description}}
-{{end}}
-
-User Request: {{.Query}}
-
-Please create a step-by-step execution plan. For each step, specify:
-1. The skill name to use
-2. The action to perform
-3. A description of what will be done
-4. Any required parameters
-
-Format your response as a JSON object with the following structure:
-{
-  "steps": [
-    {
-      "id": 1,
-      "skill_name": "skill_name",
-      "action": "action_name",
-      "description": "description",
-      "params": {
-        "param1": "value1"
-      }
-    }
-  ]
-}
-
-Response:`
-
-	// ExecutionPromptTemplate is the template for execution prompts
-	ExecutionPromptTemplate = `You are executing a step in an operations plan.
-
-Step: {{.StepDescription}}
-Skill: {{.SkillName}}
-Action: {{.Action}}
-Parameters: {{.Params}}
-
-Execute this step and provide a summary of the results.`
-
-	// ErrorHandlingPromptTemplate is the template for error handling
-	ErrorHandlingPromptTemplate = `An error occurred during execution:
-
-Step: {{.StepDescription}}
-Error: {{.Error}}
-
-Please analyze the error and suggest:
-1. What went wrong
-2. How to fix it
-3. Whether to retry or skip this step
+	PromptPlanning      = "planning"
+	PromptExecution     = "execution"
+	PromptErrorHandling = "error_handling"
+	PromptValidation    = "validation"
+	PromptReplan        = "replan"
+	PromptSummarize     = "summarize"
+)
 
-Response:`
+// promptExt is the file extension of prompt template files
+const promptExt = ".tmpl"
 
-	// ValidationPromptTemplate is the template for result validation
-	ValidationPromptTemplate = `You are validating the execution results of an Ops task.
+// UnversionedPrompt is the version reported for templates without a version header
+const UnversionedPrompt = "unversioned"
 
-Original Query: {{.Query}}
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
 
-Execution Plan:
-{{.PlanSummary}}
+// versionPattern matches the version header of a prompt template,
+// e.g. {{- /* version: 1.0.0 */ -}}
+var versionPattern = regexp.MustCompile(`^\{\{-?\s*/\*\s*version:\s*(\S+)\s*\*/\s*-?\}\}`)
 
-Execution Results:
-{{.ResultsSummary}}
+// PlanningPromptData holds data for planning prompt
+type PlanningPromptData struct {
+	Skills   []SkillInfo
+	Query    string
+	Runbooks []RunbookInfo // Runbooks the planner may pick instead of planning steps
+}
 
-Please evaluate:
-1. Do the results match the original query requirements?
-2. Are there any issues or errors that need attention?
-3. Should we replan and retry with a different approach?
+// RunbookInfo holds a runbook for prompts
+type RunbookInfo struct {
+	Name        string
+	Description string
+	Inputs      []ActionParamInfo // Typed inputs, set from the request
+	Steps       []string          // e.g. "kubekey add_nodes: Add node ${node}"
+}
 
-Respond in JSON format:
-{
-  "success": true/false,
-  "reason": "detailed explanation",
-  "should_replan": true/false,
-  "replan_reason": "why replanning is needed (if should_replan is true)"
+// SkillInfo holds skill information for prompts
+type SkillInfo struct {
+	Name         string
+	Description  string
+	Actions      []ActionInfo
+	Scripts      []string        // File names in scripts/
+	References   []ReferenceInfo // Docs in references/
+	Instructions string          // SKILL.md body, trimmed to the skill budget
 }
 
-Response:`
-)
+// ReferenceInfo holds a reference doc of a skill for prompts
+type ReferenceInfo struct {
+	Path    string // Relative to the skill directory, e.g. references/commands.md
+	Content string // Trimmed to the skill budget, empty when only listed
+}
 
-// PlanningPromptData holds data for planning prompt
-type PlanningPromptData struct {
-	Skills []SkillInfo
-	Query  string
+// ActionInfo holds the schema of a skill action for prompts
+type ActionInfo struct {
+	Name        string
+	Description string
+	Usage       string
+	Params      []ActionParamInfo
+	Retry       string // Retry behavior, e.g. "up to 3 attempts" or "not idempotent, never retried"
+	Rollback    string // Compensation on failure, e.g. "delete_node, uses params node"
 }
 
-// SkillInfo holds skill information for prompts
-type SkillInfo struct {
+// ActionParamInfo holds an action parameter for prompts
+type ActionParamInfo struct {
 	Name        string
+	Type        string
 	Description string
+	Required    bool
 }
 
 // ExecutionPromptData holds data for execution prompt
 type ExecutionPromptData struct {
 	StepDescription string
-	SkillName        string
+	SkillName       string
 	Action          string
 	Params          string
+	Skill           SkillInfo // Instructions, scripts and references of the skill
 }
 
 // ErrorHandlingPromptData holds data for error handling prompt
@@ -117,65 +102,307 @@ type ErrorHandlingPromptData struct {
 
 // ValidationPromptData holds data for validation prompt
 type ValidationPromptData struct {
-	Query         string
-	PlanSummary   string
+	Query          string
+	PlanSummary    string
 	ResultsSummary string
 }
 
-// FormatPlanningPrompt formats the planning prompt with data
-func FormatPlanningPrompt(data PlanningPromptData) string {
-	// Simple template replacement (in production, use a proper template engine)
-	prompt := PlanningPromptTemplate
-	prompt = replaceAll(prompt, "{{.Query}}", data.Query)
-	
-	// Build skills list
-	skillsList := ""
-	for _, skill := range data.Skills {
-		skillsList += fmt.Sprintf("- %s: %s\n", skill.Name, skill.Description)
-	}
-	prompt = replaceAll(prompt, "{{range .Skills}}\n- {{.Name}}: {{.Description}}\n{{end}}", skillsList)
-	
-	return prompt
-}
-
-// FormatExecutionPrompt formats the execution prompt with data
-func FormatExecutionPrompt(data ExecutionPromptData) string {
-	prompt := ExecutionPromptTemplate
-	prompt = replaceAll(prompt, "{{.StepDescription}}", data.StepDescription)
-	prompt = replaceAll(prompt, "{{.SkillName}}", data.SkillName)
-	prompt = replaceAll(prompt, "{{.Action}}", data.Action)
-	prompt = replaceAll(prompt, "{{.Params}}", data.Params)
-	return prompt
-}
-
-// FormatErrorHandlingPrompt formats the error handling prompt with data
-func FormatErrorHandlingPrompt(data ErrorHandlingPromptData) string {
-	prompt := ErrorHandlingPromptTemplate
-	prompt = replaceAll(prompt, "{{.StepDescription}}", data.StepDescription)
-	prompt = replaceAll(prompt, "{{.Error}}", data.Error)
-	return prompt
-}
-
-// FormatValidationPrompt formats the validation prompt with data
-func FormatValidationPrompt(data ValidationPromptData) string {
-	prompt := ValidationPromptTemplate
-	prompt = replaceAll(prompt, "{{.Query}}", data.Query)
-	prompt = replaceAll(prompt, "{{.PlanSummary}}", data.PlanSummary)
-	prompt = replaceAll(prompt, "{{.ResultsSummary}}", data.ResultsSummary)
-	return prompt
-}
-
-// replaceAll replaces all occurrences of old with new in s
-func replaceAll(s, old, new string) string {
-	// Simple implementation - in production use strings.ReplaceAll
-	result := s
-	for {
-		newResult := strings.Replace(result, old, new, -1)
-		if newResult == result {
-			break
+// ReplanPromptData holds data for replan prompt
+type ReplanPromptData struct {
+	Skills         []SkillInfo
+	Query          string
+	ReplanReason   string
+	PlanSummary    string
+	ResultsSummary string
+	Failure        string // The failed step, its params and error
+	KeptSteps      []int  // Completed steps the new plan continues from
+	NextStepID     int
+}
+
+// SummarizePromptData holds data for summarize prompt
+type SummarizePromptData struct {
+	Output string
+}
+
+// PromptTemplate is a parsed prompt template
+type PromptTemplate struct {
+	Name    string
+	Version string
+	Source  string // "embedded" or the file path the template was loaded from
+	Skill   string // Non-empty for per-skill overrides
+
+	tmpl *template.Template
+}
+
+// RenderedPrompt is the output of rendering a prompt template
+type RenderedPrompt struct {
+	Name    string
+	Version string
+	Source  string
+	Skill   string
+	Text    string
+}
+
+// PromptRegistry resolves and renders prompt templates.
+//
+// Templates are resolved in the following order:
+//  1. <dir>/skills/<skill>/<name>.tmpl (per-skill override)
+//  2. <dir>/<name>.tmpl
+//  3. The embedded default template
+//
+// The skill of a prompt is the skill of its step (execution, error handling,
+// summarize), of the failed step (replan), or the single skill of the task
+// (planning, validation, replan); prompts involving several skills have none.
+type PromptRegistry struct {
+	dir       string
+	defaults  map[string]*PromptTemplate
+	overrides map[string]*PromptTemplate
+	skills    map[string]map[string]*PromptTemplate
+	mu        sync.RWMutex
+}
+
+var (
+	defaultPrompts     *PromptRegistry
+	defaultPromptsOnce sync.Once
+)
+
+// DefaultPrompts returns a registry containing only the embedded templates
+func DefaultPrompts() *PromptRegistry {
+	defaultPromptsOnce.Do(func() {
+		registry, err := NewPromptRegistry("")
+		if err != nil {
+			// Embedded templates are part of the binary, failing here is a build error
+			panic(fmt.Sprintf("failed to load embedded prompt templates: %v", err))
 		}
-		result = newResult
+		defaultPrompts = registry
+	})
+	return defaultPrompts
+}
+
+// NewPromptRegistry creates a prompt registry, loading overrides from dir if set
+func NewPromptRegistry(dir string) (*PromptRegistry, error) {
+	r := &PromptRegistry{
+		dir:       dir,
+		defaults:  make(map[string]*PromptTemplate),
+		overrides: make(map[string]*PromptTemplate),
+		skills:    make(map[string]map[string]*PromptTemplate),
 	}
-	return result
+
+	entries, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded templates: %w", err)
+	}
+	for _, entry := range entries {
+		data, err := defaultTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded template %s: %w", entry.Name(), err)
+		}
+		name := strings.TrimSuffix(entry.Name(), promptExt)
+		pt, err := parsePromptTemplate(name, "embedded", string(data))
+		if err != nil {
+			return nil, err
+		}
+		r.defaults[name] = pt
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
+// Reload re-reads the override templates from the prompts directory
+func (r *PromptRegistry) Reload() error {
+	overrides := make(map[string]*PromptTemplate)
+	skills := make(map[string]map[string]*PromptTemplate)
+
+	if r.dir != "" {
+		if _, err := os.Stat(r.dir); err != nil {
+			return fmt.Errorf("prompts directory not accessible: %w", err)
+		}
+
+		loaded, err := loadPromptDir(r.dir, "")
+		if err != nil {
+			return err
+		}
+		overrides = loaded
+
+		skillsDir := filepath.Join(r.dir, "skills")
+		entries, err := os.ReadDir(skillsDir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read skill prompts directory: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			loaded, err := loadPromptDir(filepath.Join(skillsDir, entry.Name()), entry.Name())
+			if err != nil {
+				return err
+			}
+			skills[entry.Name()] = loaded
+		}
+	}
+
+	r.mu.Lock()
+	r.overrides = overrides
+	r.skills = skills
+	r.mu.Unlock()
+
+	return nil
+}
+
+// loadPromptDir parses all templates directly inside dir
+func loadPromptDir(dir, skillName string) (map[string]*PromptTemplate, error) {
+	templates := make(map[string]*PromptTemplate)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != promptExt {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template %s: %w", path, err)
+		}
+		pt, err := parsePromptTemplate(strings.TrimSuffix(entry.Name(), promptExt), path, string(data))
+		if err != nil {
+			return nil, err
+		}
+		pt.Skill = skillName
+		templates[pt.Name] = pt
+	}
+
+	return templates, nil
+}
+
+// parsePromptTemplate parses a template and extracts its version header
+func parsePromptTemplate(name, source, text string) (*PromptTemplate, error) {
+	version := UnversionedPrompt
+	if m := versionPattern.FindStringSubmatch(text); m != nil {
+		version = m[1]
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s (%s): %w", name, source, err)
+	}
+
+	return &PromptTemplate{
+		Name:    name,
+		Version: version,
+		Source:  source,
+		tmpl:    tmpl,
+	}, nil
+}
+
+// Lookup resolves the template for a prompt name, preferring skill overrides
+func (r *PromptRegistry) Lookup(name, skillName string) (*PromptTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if skillName != "" {
+		if pt, ok := r.skills[skillName][name]; ok {
+			return pt, nil
+		}
+	}
+	if pt, ok := r.overrides[name]; ok {
+		return pt, nil
+	}
+	if pt, ok := r.defaults[name]; ok {
+		return pt, nil
+	}
+
+	return nil, fmt.Errorf("prompt template not found: %s", name)
+}
+
+// Render renders a prompt template with data
+func (r *PromptRegistry) Render(name, skillName string, data interface{}) (*RenderedPrompt, error) {
+	pt, err := r.Lookup(name, skillName)
+	if err != nil {
+		return nil, err
+	}
+
+	return pt.Render(data)
+}
+
+// Render renders the template with data
+func (pt *PromptTemplate) Render(data interface{}) (*RenderedPrompt, error) {
+	var buf bytes.Buffer
+	if err := pt.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render prompt %s (%s): %w", pt.Name, pt.Source, err)
+	}
+
+	return &RenderedPrompt{
+		Name:    pt.Name,
+		Version: pt.Version,
+		Source:  pt.Source,
+		Skill:   pt.Skill,
+		Text:    buf.String(),
+	}, nil
+}
+
+// Templates returns every loaded template (defaults, overrides and per-skill overrides)
+func (r *PromptRegistry) Templates() []*PromptTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var templates []*PromptTemplate
+	for _, pt := range r.defaults {
+		templates = append(templates, pt)
+	}
+	for _, pt := range r.overrides {
+		templates = append(templates, pt)
+	}
+	for _, skillTemplates := range r.skills {
+		for _, pt := range skillTemplates {
+			templates = append(templates, pt)
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		if templates[i].Skill != templates[j].Skill {
+			return templates[i].Skill < templates[j].Skill
+		}
+		return templates[i].Source < templates[j].Source
+	})
+
+	return templates
+}
+
+// FormatPlanningPrompt renders the planning prompt, honoring the overrides of
+// skillName, the skill selected for the query
+func (r *PromptRegistry) FormatPlanningPrompt(skillName string, data PlanningPromptData) (*RenderedPrompt, error) {
+	return r.Render(PromptPlanning, skillName, data)
+}
+
+// FormatExecutionPrompt renders the execution prompt, honoring skill overrides
+func (r *PromptRegistry) FormatExecutionPrompt(data ExecutionPromptData) (*RenderedPrompt, error) {
+	return r.Render(PromptExecution, data.SkillName, data)
+}
+
+// FormatErrorHandlingPrompt renders the error handling prompt
+func (r *PromptRegistry) FormatErrorHandlingPrompt(skillName string, data ErrorHandlingPromptData) (*RenderedPrompt, error) {
+	return r.Render(PromptErrorHandling, skillName, data)
+}
+
+// FormatValidationPrompt renders the validation prompt, honoring the overrides of
+// skillName, the skill of the plan
+func (r *PromptRegistry) FormatValidationPrompt(skillName string, data ValidationPromptData) (*RenderedPrompt, error) {
+	return r.Render(PromptValidation, skillName, data)
+}
+
+// FormatReplanPrompt renders the replan prompt, honoring the overrides of
+// skillName, the skill of the failed step or of the plan
+func (r *PromptRegistry) FormatReplanPrompt(skillName string, data ReplanPromptData) (*RenderedPrompt, error) {
+	return r.Render(PromptReplan, skillName, data)
+}
@@ -0,0 +1,137 @@
+package llm
+
+import "fmt"
+
+// PromptLintResult holds the outcome of rendering one template with sample data
+type PromptLintResult struct {
+	Name    string
+	Version string
+	Source  string
+	Skill   string
+	Error   string
+	Warning string
+}
+
+// OK reports whether the template rendered without errors
+func (r PromptLintResult) OK() bool {
+	return r.Error == ""
+}
+
+// sampleSkills is the skill list used in the planning and replan samples
+var sampleSkills = []SkillInfo{
+	{
+		Name:        "kubekey",
+		Description: "Manage Kubernetes clusters with KubeKey",
+		Actions: []ActionInfo{
+			{
+				Name:        "add_nodes",
+				Description: "Add nodes to an existing cluster",
+				Usage:       "add_nodes.sh <config-file>",
+				Retry:       "up to 3 attempts",
+				Rollback:    "delete_node, uses params node",
+				Params: []ActionParamInfo{
+					{Name: "config", Type: "file", Description: "Cluster configuration file", Required: true},
+					{Name: "node", Type: "string", Description: "Name of the added node, required to remove it on rollback"},
+				},
+			},
+		},
+		Scripts:      []string{"add_nodes.sh"},
+		Instructions: "# KubeKey\n\nRun scripts/add_nodes.sh with a config file listing every node.",
+		References: []ReferenceInfo{
+			{Path: "references/commands.md", Content: "kk add nodes -f config.yaml"},
+			{Path: "references/config-options.md"},
+		},
+	},
+}
+
+// SamplePromptData returns representative data used to lint a prompt template
+func SamplePromptData(name string) (interface{}, bool) {
+	switch name {
+	case PromptPlanning:
+		return PlanningPromptData{
+			Skills: sampleSkills,
+			Query:  "Add worker node 192.168.0.5 to the prod cluster",
+			Runbooks: []RunbookInfo{{
+				Name:        "add-node",
+				Description: "Add a worker node to a cluster",
+				Inputs: []ActionParamInfo{
+					{Name: "config", Type: "string", Description: "Cluster configuration file", Required: true},
+					{Name: "node", Type: "string", Description: "Name of the node", Required: true},
+				},
+				Steps: []string{"kubekey check_kubekey", "kubekey add_nodes: Add node ${node}"},
+			}},
+		}, true
+	case PromptExecution:
+		return ExecutionPromptData{
+			StepDescription: "Add worker nodes to the cluster",
+			SkillName:       "kubekey",
+			Action:          "add_nodes",
+			Params:          `{"config": "cluster-config.yaml"}`,
+			Skill:           sampleSkills[0],
+		}, true
+	case PromptErrorHandling:
+		return ErrorHandlingPromptData{
+			StepDescription: "Add worker nodes to the cluster",
+			Error:           "ssh: handshake failed: connection reset by peer",
+		}, true
+	case PromptValidation:
+		return ValidationPromptData{
+			Query:          "Add worker node 192.168.0.5 to the prod cluster",
+			PlanSummary:    "Step 1: kubekey - Add worker nodes to the cluster\n",
+			ResultsSummary: "Step 1: ✅ Success\n  Output: Nodes added successfully\n",
+		}, true
+	case PromptReplan:
+		return ReplanPromptData{
+			Skills:         sampleSkills,
+			Query:          "Add worker node 192.168.0.5 to the prod cluster",
+			ReplanReason:   "Some steps failed during execution",
+			PlanSummary:    "Step 1: kubekey - Add worker nodes to the cluster\n",
+			ResultsSummary: "Step 1: ✅ Success\n  Output: kk version v3.1.1\nStep 2: ❌ Failed\n  Error: ssh: handshake failed\n",
+			Failure:        "Step 2: kubekey add_nodes - Add worker nodes to the cluster\n  Params: {\"config\":\"config.yaml\"}\n  Error:\n    ssh: handshake failed\n",
+			KeptSteps:      []int{1},
+			NextStepID:     3,
+		}, true
+	case PromptSummarize:
+		return SummarizePromptData{
+			Output: "[init] Downloading kubekey\n...\nerror: failed to connect to 192.168.0.5:22\n",
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// Lint renders every loaded template with sample data
+func (r *PromptRegistry) Lint() []PromptLintResult {
+	templates := r.Templates()
+	results := make([]PromptLintResult, 0, len(templates))
+
+	for _, pt := range templates {
+		result := PromptLintResult{
+			Name:    pt.Name,
+			Version: pt.Version,
+			Source:  pt.Source,
+			Skill:   pt.Skill,
+		}
+
+		data, known := SamplePromptData(pt.Name)
+		if !known {
+			result.Warning = fmt.Sprintf("unknown prompt name %q, template is never used", pt.Name)
+			results = append(results, result)
+			continue
+		}
+
+		rendered, err := pt.Render(data)
+		if err != nil {
+			result.Error = err.Error()
+		} else if rendered.Text == "" {
+			result.Error = "template rendered to an empty prompt"
+		}
+		if result.Error == "" && pt.Version == UnversionedPrompt {
+			result.Warning = "missing version header"
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
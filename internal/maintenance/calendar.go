@@ -0,0 +1,268 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// horizon bounds the search of the next opening
+const horizon = 366 * 24 * time.Hour
+
+// Calendar checks times against compiled windows and freezes
+type Calendar struct {
+	outside string
+	loc     *time.Location
+	windows []*window
+	freezes []*freeze
+}
+
+type window struct {
+	Window
+	days       map[time.Weekday]bool // nil for every day
+	start, end int                   // Minutes since midnight
+}
+
+type freeze struct {
+	Freeze
+	from, to time.Time
+}
+
+// Status is the outcome of a check
+type Status struct {
+	// Open is true when mutating steps may run
+	Open bool
+	// Reason explains why they may not
+	Reason string
+	// Freeze is the name of the active freeze
+	Freeze string
+	// NextOpen is when they may run, zero when no window opens within a year
+	NextOpen time.Time
+	// Window is the window open now or opening at NextOpen, empty when the task is
+	// selected by no window
+	Window string
+}
+
+// New compiles the windows and freezes of a maintenance file. Every invalid entry
+// is reported.
+func New(file *File) (*Calendar, error) {
+	c := &Calendar{outside: file.OutsideWindow, loc: time.Local}
+	if c.outside == "" {
+		c.outside = OutsideSchedule
+	}
+
+	var errs []error
+	if c.outside != OutsideSchedule && c.outside != OutsideReject {
+		errs = append(errs, fmt.Errorf("unknown outside_window %q (schedule or reject)", file.OutsideWindow))
+	}
+	if file.Timezone != "" {
+		loc, err := time.LoadLocation(file.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		c.loc = loc
+	}
+
+	seen := make(map[string]bool)
+	for i, w := range file.Windows {
+		compiled, err := c.compileWindow(w)
+		if err == nil && seen[w.Name] {
+			err = fmt.Errorf("duplicate name")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("window %d (%s): %w", i, w.Name, err))
+			continue
+		}
+		seen[w.Name] = true
+		c.windows = append(c.windows, compiled)
+	}
+	for i, f := range file.Freezes {
+		compiled, err := c.compileFreeze(f)
+		if err == nil && seen[f.Name] {
+			err = fmt.Errorf("duplicate name")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("freeze %d (%s): %w", i, f.Name, err))
+			continue
+		}
+		seen[f.Name] = true
+		c.freezes = append(c.freezes, compiled)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+func (c *Calendar) compileWindow(w Window) (*window, error) {
+	if w.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := w.Selector.validate(); err != nil {
+		return nil, err
+	}
+	compiled := &window{Window: w}
+	var err error
+	if compiled.start, err = parseClock(w.Start); err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+	if compiled.end, err = parseClock(w.End); err != nil {
+		return nil, fmt.Errorf("end: %w", err)
+	}
+	if len(w.Days) > 0 {
+		compiled.days = make(map[time.Weekday]bool)
+		for _, day := range w.Days {
+			weekday, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return nil, fmt.Errorf("unknown day %q (mon, tue, wed, thu, fri, sat or sun)", day)
+			}
+			compiled.days[weekday] = true
+		}
+	}
+	return compiled, nil
+}
+
+func (c *Calendar) compileFreeze(f Freeze) (*freeze, error) {
+	if f.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := f.Selector.validate(); err != nil {
+		return nil, err
+	}
+	compiled := &freeze{Freeze: f}
+	var err error
+	if compiled.from, err = parseTime(f.From, c.loc); err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	if compiled.to, err = parseTime(f.To, c.loc); err != nil {
+		return nil, fmt.Errorf("to: %w", err)
+	}
+	if !compiled.to.After(compiled.from) {
+		return nil, fmt.Errorf("to must be after from")
+	}
+	return compiled, nil
+}
+
+// Entries returns the number of windows and freezes
+func (c *Calendar) Entries() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.windows) + len(c.freezes)
+}
+
+// OutsideWindow returns what happens to tasks with mutating steps outside a window:
+// OutsideSchedule or OutsideReject
+func (c *Calendar) OutsideWindow() string {
+	if c == nil {
+		return OutsideSchedule
+	}
+	return c.outside
+}
+
+// Check checks whether mutating steps of target may run at t, and otherwise when
+// they may
+func (c *Calendar) Check(target Target, t time.Time) Status {
+	if c == nil {
+		return Status{Open: true}
+	}
+	var windows []*window
+	for _, w := range c.windows {
+		if w.matches(target) {
+			windows = append(windows, w)
+		}
+	}
+	var freezes []*freeze
+	for _, f := range c.freezes {
+		if f.matches(target) {
+			freezes = append(freezes, f)
+		}
+	}
+
+	status := c.status(windows, freezes, t)
+	if status.Open {
+		return status
+	}
+
+	// Jump from boundary to boundary, window openings and freeze ends, until open
+	for next := t; next.Sub(t) < horizon; {
+		next = c.nextBoundary(windows, freezes, next)
+		if next.IsZero() {
+			break
+		}
+		if s := c.status(windows, freezes, next); s.Open {
+			status.NextOpen = next
+			status.Window = s.Window
+			break
+		}
+	}
+	return status
+}
+
+// status checks t without looking for the next opening
+func (c *Calendar) status(windows []*window, freezes []*freeze, t time.Time) Status {
+	for _, f := range freezes {
+		if !t.Before(f.from) && t.Before(f.to) {
+			reason := fmt.Sprintf("change freeze %s until %s", f.Name, f.to.In(c.loc).Format(time.RFC3339))
+			if f.Reason != "" {
+				reason += ": " + f.Reason
+			}
+			return Status{Reason: reason, Freeze: f.Name}
+		}
+	}
+	if len(windows) == 0 {
+		return Status{Open: true}
+	}
+	names := make([]string, len(windows))
+	for i, w := range windows {
+		if w.contains(t.In(c.loc)) {
+			return Status{Open: true, Window: w.Name}
+		}
+		names[i] = w.Name
+	}
+	return Status{Reason: "outside maintenance windows " + strings.Join(names, ", ")}
+}
+
+// nextBoundary returns the first window opening or freeze end after t, zero when none
+func (c *Calendar) nextBoundary(windows []*window, freezes []*freeze, t time.Time) time.Time {
+	var next time.Time
+	earliest := func(candidate time.Time) {
+		if candidate.After(t) && (next.IsZero() || candidate.Before(next)) {
+			next = candidate
+		}
+	}
+	for _, f := range freezes {
+		earliest(f.to)
+	}
+	local := t.In(c.loc)
+	for _, w := range windows {
+		for d := 0; d <= 7; d++ {
+			day := time.Date(local.Year(), local.Month(), local.Day()+d, 0, 0, 0, 0, c.loc)
+			if w.days == nil || w.days[day.Weekday()] {
+				earliest(day.Add(time.Duration(w.start) * time.Minute))
+			}
+		}
+	}
+	return next
+}
+
+// contains reports whether the window is open at t, in the timezone of the calendar.
+// Windows closing the next day are open on that day from its opening on the day before.
+func (w *window) contains(t time.Time) bool {
+	for _, offset := range []int{0, -1} {
+		day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+		if w.days != nil && !w.days[day.Weekday()] {
+			continue
+		}
+		start := day.Add(time.Duration(w.start) * time.Minute)
+		end := day.Add(time.Duration(w.end) * time.Minute)
+		if w.end <= w.start {
+			end = end.Add(24 * time.Hour)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
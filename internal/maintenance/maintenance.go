@@ -0,0 +1,154 @@
+// Package maintenance decides when tasks may change infrastructure: mutating steps
+// run only inside the maintenance windows of their environment and cluster labels,
+// and never during a change freeze.
+//
+// Windows recur weekly (days and a start and end time), freezes are fixed periods.
+// Both select tasks by environment and by task labels, e.g. cluster: prod-*.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// What happens to tasks with mutating steps outside a window
+const (
+	OutsideSchedule = "schedule" // Held as scheduled until a window opens
+	OutsideReject   = "reject"   // Rejected
+)
+
+// File is the maintenance file
+type File struct {
+	// Timezone of the windows and freezes, local time when empty
+	Timezone string `yaml:"timezone,omitempty"`
+	// OutsideWindow is schedule (default) or reject
+	OutsideWindow string   `yaml:"outside_window,omitempty"`
+	Windows       []Window `yaml:"windows"`
+	Freezes       []Freeze `yaml:"freezes"`
+}
+
+// Selector selects the tasks a window or freeze applies to
+type Selector struct {
+	// Environments of the tasks, empty for every environment
+	Environments []string `yaml:"environments,omitempty"`
+	// Labels are glob patterns the labels of the tasks must match, e.g. cluster: prod-*
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// Window is a weekly maintenance window. Tasks selected by at least one window
+// change nothing outside of their windows.
+type Window struct {
+	Name     string `yaml:"name"`
+	Selector `yaml:",inline"`
+	// Days of the week (mon, tue, ...) the window opens, every day when empty
+	Days []string `yaml:"days,omitempty"`
+	// Start and End are HH:MM; an End before Start closes the window the next day
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// Freeze is a change freeze, overriding windows
+type Freeze struct {
+	Name     string `yaml:"name"`
+	Selector `yaml:",inline"`
+	// From and To are RFC 3339 times, "2006-01-02 15:04" or "2006-01-02", To excluded
+	From   string `yaml:"from"`
+	To     string `yaml:"to"`
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// Target is the task a check is made for
+type Target struct {
+	Environment string
+	Labels      map[string]string
+}
+
+// LoadFile reads a maintenance file. A missing file has no windows and no freezes.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance file: %w", err)
+	}
+	return &file, nil
+}
+
+// matches reports whether the selector selects target
+func (s *Selector) matches(target Target) bool {
+	if len(s.Environments) > 0 {
+		found := false
+		for _, env := range s.Environments {
+			if env == target.Environment {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for key, pattern := range s.Labels {
+		value, ok := target.Labels[key]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(pattern, value); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Selector) validate() error {
+	for key, pattern := range s.Labels {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("label %s: invalid pattern %q", key, pattern)
+		}
+	}
+	return nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseTime parses the bounds of a freeze in loc
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, want RFC 3339, \"2006-01-02 15:04\" or \"2006-01-02\"", strings.TrimSpace(s))
+}
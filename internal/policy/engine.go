@@ -0,0 +1,320 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+// Engine evaluates compiled rules
+type Engine struct {
+	rules []*compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	when  cel.Program // nil matches everything
+	count cel.Program // nil counts 1
+}
+
+// newEnv declares the variables rules are evaluated with:
+//
+//	step        map: id, skill, action, description, params
+//	plan        map: steps, the list of step maps
+//	requester   identity of whoever submitted the task, empty when unknown
+//	environment target environment of the task
+//	labels      labels of the task, e.g. labels.cluster
+//	mode        execute, plan_only or dry_run
+//	query       the task query
+//	now         evaluation time, e.g. now.getHours("Asia/Shanghai")
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("step", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("plan", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("requester", cel.StringType),
+		cel.Variable("environment", cel.StringType),
+		cel.Variable("labels", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("mode", cel.StringType),
+		cel.Variable("query", cel.StringType),
+		cel.Variable("now", cel.TimestampType),
+		ext.Strings(),
+	)
+}
+
+// New compiles rules. Every invalid rule is reported.
+func New(rules []Rule) (*Engine, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	engine := &Engine{}
+	seen := make(map[string]bool)
+	var errs []error
+	for i := range rules {
+		rule := rules[i]
+		if err := rule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d (%s): %w", i, rule.ID, err))
+			continue
+		}
+		if seen[rule.ID] {
+			errs = append(errs, fmt.Errorf("rule %d: duplicate id %s", i, rule.ID))
+			continue
+		}
+		seen[rule.ID] = true
+
+		compiled := &compiledRule{Rule: rule}
+		if rule.When != "" {
+			compiled.when, err = compile(env, rule.When, cel.BoolType)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rule %s: when: %w", rule.ID, err))
+				continue
+			}
+		}
+		if rule.Count != "" {
+			compiled.count, err = compile(env, rule.Count, cel.IntType)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rule %s: count: %w", rule.ID, err))
+				continue
+			}
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return engine, nil
+}
+
+// compile compiles an expression that must evaluate to want, or to a dynamic value
+func compile(env *cel.Env, expr string, want *cel.Type) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if out := ast.OutputType(); !out.IsExactType(want) && !out.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("evaluates to %s, want %s", out, want)
+	}
+	return env.Program(ast)
+}
+
+// Rules returns the number of rules
+func (e *Engine) Rules() int {
+	if e == nil {
+		return 0
+	}
+	return len(e.rules)
+}
+
+// EvaluatePlan evaluates every rule before a plan runs: step rules against its pending
+// steps, plan rules once, and limits against all of its steps, completed ones
+// included, so that they hold for the whole task. It returns the decisions of the
+// matching rules, or a single allow decision.
+func (e *Engine) EvaluatePlan(input Input, steps []Step) []Decision {
+	vars := e.vars(input, steps)
+	var decisions []Decision
+	for _, rule := range e.rulesOrNone() {
+		switch {
+		case rule.Max != nil:
+			if d, ok := rule.evaluateLimit(vars, steps); ok {
+				decisions = append(decisions, d)
+			}
+		case rule.Scope == ScopePlan:
+			if d, ok := rule.evaluate(vars, 0); ok {
+				decisions = append(decisions, d)
+			}
+		default:
+			for _, step := range steps {
+				if !step.Pending {
+					continue
+				}
+				vars["step"] = stepVar(step)
+				if d, ok := rule.evaluate(vars, step.ID); ok {
+					decisions = append(decisions, d)
+				}
+			}
+		}
+	}
+	if len(decisions) == 0 {
+		decisions = append(decisions, Decision{Effect: EffectAllow, Reason: "no rule matched"})
+	}
+	return decisions
+}
+
+// AuthorizeStep evaluates the step rules right before a step runs. It returns the
+// decisions of the matching rules, or a single allow decision.
+func (e *Engine) AuthorizeStep(input Input, steps []Step, step Step) []Decision {
+	vars := e.vars(input, steps)
+	vars["step"] = stepVar(step)
+	var decisions []Decision
+	for _, rule := range e.rulesOrNone() {
+		if rule.Max != nil || rule.Scope == ScopePlan {
+			continue
+		}
+		if d, ok := rule.evaluate(vars, step.ID); ok {
+			decisions = append(decisions, d)
+		}
+	}
+	if len(decisions) == 0 {
+		decisions = append(decisions, Decision{Effect: EffectAllow, StepID: step.ID, Reason: "no rule matched"})
+	}
+	return decisions
+}
+
+// AuthorizeCompensation evaluates a compensation right before it runs: the step
+// rules against it, and the limits against steps with it added, compensations
+// change targets too. steps are the steps of the task and its completed
+// compensations. It returns the decisions of the matching rules, or a single allow
+// decision.
+func (e *Engine) AuthorizeCompensation(input Input, steps []Step, compensation Step) []Decision {
+	steps = append(slices.Clip(steps), compensation)
+	vars := e.vars(input, steps)
+	var decisions []Decision
+	for _, rule := range e.rulesOrNone() {
+		switch {
+		case rule.Max != nil:
+			if d, ok := rule.evaluateLimit(vars, steps); ok {
+				d.StepID = compensation.ID
+				decisions = append(decisions, d)
+			}
+		case rule.Scope == ScopePlan:
+			// Compensations are not a plan, plan rules were checked on admission
+		default:
+			vars["step"] = stepVar(compensation)
+			if d, ok := rule.evaluate(vars, compensation.ID); ok {
+				decisions = append(decisions, d)
+			}
+		}
+	}
+	if len(decisions) == 0 {
+		decisions = append(decisions, Decision{Effect: EffectAllow, StepID: compensation.ID, Reason: "no rule matched"})
+	}
+	return decisions
+}
+
+func (e *Engine) rulesOrNone() []*compiledRule {
+	if e == nil {
+		return nil
+	}
+	return e.rules
+}
+
+// vars returns the variables of an evaluation, step is set per step
+func (e *Engine) vars(input Input, steps []Step) map[string]any {
+	now := input.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	labels := input.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	planSteps := make([]any, len(steps))
+	for i, step := range steps {
+		planSteps[i] = stepVar(step)
+	}
+	return map[string]any{
+		"step":        map[string]any{},
+		"plan":        map[string]any{"steps": planSteps},
+		"requester":   input.Requester,
+		"environment": input.Environment,
+		"labels":      labels,
+		"mode":        input.Mode,
+		"query":       input.Query,
+		"now":         now,
+	}
+}
+
+func stepVar(step Step) map[string]any {
+	params := step.Params
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	return map[string]any{
+		"id":          step.ID,
+		"skill":       step.Skill,
+		"action":      step.Action,
+		"description": step.Description,
+		"params":      params,
+	}
+}
+
+// evaluate evaluates When. Evaluation errors deny: a rule that cannot be checked
+// must not let the step through.
+func (r *compiledRule) evaluate(vars map[string]any, stepID int) (Decision, bool) {
+	matched, err := r.matches(vars)
+	if err != nil {
+		return Decision{RuleID: r.ID, Effect: EffectDeny, StepID: stepID, Reason: fmt.Sprintf("rule could not be evaluated: %v", err)}, true
+	}
+	if !matched {
+		return Decision{}, false
+	}
+	return Decision{RuleID: r.ID, Effect: r.Effect, StepID: stepID, Reason: r.reason()}, true
+}
+
+// evaluateLimit adds up the targets of the steps selected by When
+func (r *compiledRule) evaluateLimit(vars map[string]any, steps []Step) (Decision, bool) {
+	total := 0
+	for _, step := range steps {
+		vars["step"] = stepVar(step)
+		matched, err := r.matches(vars)
+		if err == nil && matched {
+			var n int
+			n, err = r.targets(vars)
+			total += n
+		}
+		if err != nil {
+			return Decision{RuleID: r.ID, Effect: EffectDeny, StepID: step.ID, Reason: fmt.Sprintf("rule could not be evaluated: %v", err)}, true
+		}
+	}
+	if total <= *r.Max {
+		return Decision{}, false
+	}
+	return Decision{
+		RuleID: r.ID,
+		Effect: r.Effect,
+		Reason: fmt.Sprintf("%s: %d targets, at most %d per task", r.reason(), total, *r.Max),
+	}, true
+}
+
+func (r *compiledRule) matches(vars map[string]any) (bool, error) {
+	if r.when == nil {
+		return true, nil
+	}
+	out, _, err := r.when.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("when evaluated to %v, not a bool", out.Value())
+	}
+	return matched, nil
+}
+
+func (r *compiledRule) targets(vars map[string]any) (int, error) {
+	if r.count == nil {
+		return 1, nil
+	}
+	out, _, err := r.count.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := out.(types.Int)
+	if !ok {
+		return 0, fmt.Errorf("count evaluated to %v, not an int", out.Value())
+	}
+	return int(n), nil
+}
+
+func (r *compiledRule) reason() string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return r.ID
+}
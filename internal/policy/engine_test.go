@@ -0,0 +1,267 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+var testRules = []Rule{
+	{
+		ID:     "no-prod-delete-cluster",
+		Effect: EffectDeny,
+		When:   `environment == "prod" && step.skill == "kubekey" && step.action == "delete_cluster"`,
+	},
+	{
+		ID:     "approve-prod-changes",
+		Effect: EffectRequireApproval,
+		When:   `environment == "prod" && step.action in ["add_nodes", "delete_node"]`,
+	},
+	{
+		ID:     "approve-long-plans",
+		Effect: EffectRequireApproval,
+		Scope:  ScopePlan,
+		When:   `size(plan.steps) > 3`,
+	},
+	{
+		ID:     "max-deleted-nodes",
+		Effect: EffectDeny,
+		When:   `step.action == "delete_node"`,
+		Count:  `"nodes" in step.params ? size(step.params.nodes) : 1`,
+		Max:    intPtr(3),
+	},
+}
+
+func deleteNodes(id int, pending bool, nodes ...string) Step {
+	list := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		list[i] = n
+	}
+	return Step{ID: id, Skill: "kubekey", Action: "delete_node", Params: map[string]interface{}{"nodes": list}, Pending: pending}
+}
+
+func ruleIDs(decisions []Decision) []string {
+	var ids []string
+	for _, d := range decisions {
+		ids = append(ids, d.RuleID)
+	}
+	return ids
+}
+
+func TestEvaluatePlan(t *testing.T) {
+	engine, err := New(testRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   Input
+		steps   []Step
+		verdict string
+		rules   []string
+	}{
+		{
+			name:    "no rule matches",
+			input:   Input{Environment: "staging"},
+			steps:   []Step{{ID: 1, Skill: "kubekey", Action: "delete_cluster", Pending: true}},
+			verdict: EffectAllow,
+			rules:   []string{""},
+		},
+		{
+			name:    "deny",
+			input:   Input{Environment: "prod"},
+			steps:   []Step{{ID: 1, Skill: "kubekey", Action: "delete_cluster", Pending: true}},
+			verdict: EffectDeny,
+			rules:   []string{"no-prod-delete-cluster"},
+		},
+		{
+			name:    "require approval",
+			input:   Input{Environment: "prod"},
+			steps:   []Step{{ID: 1, Skill: "kubekey", Action: "add_nodes", Pending: true}},
+			verdict: EffectRequireApproval,
+			rules:   []string{"approve-prod-changes"},
+		},
+		{
+			name:    "completed steps are not checked by step rules",
+			input:   Input{Environment: "prod"},
+			steps:   []Step{{ID: 1, Skill: "kubekey", Action: "add_nodes"}},
+			verdict: EffectAllow,
+			rules:   []string{""},
+		},
+		{
+			name:  "plan rule",
+			input: Input{Environment: "staging"},
+			steps: []Step{
+				{ID: 1, Skill: "shell", Action: "check", Pending: true},
+				{ID: 2, Skill: "shell", Action: "check", Pending: true},
+				{ID: 3, Skill: "shell", Action: "check", Pending: true},
+				{ID: 4, Skill: "shell", Action: "check", Pending: true},
+			},
+			verdict: EffectRequireApproval,
+			rules:   []string{"approve-long-plans"},
+		},
+		{
+			name:    "cap within limit",
+			input:   Input{Environment: "staging"},
+			steps:   []Step{deleteNodes(1, true, "node1", "node2"), deleteNodes(2, true, "node3")},
+			verdict: EffectAllow,
+			rules:   []string{""},
+		},
+		{
+			name:    "cap exceeded, completed steps included",
+			input:   Input{Environment: "staging"},
+			steps:   []Step{deleteNodes(1, false, "node1", "node2"), deleteNodes(2, true, "node3", "node4")},
+			verdict: EffectDeny,
+			rules:   []string{"max-deleted-nodes"},
+		},
+		{
+			name:    "deny wins over require approval",
+			input:   Input{Environment: "prod"},
+			steps:   []Step{{ID: 1, Skill: "kubekey", Action: "add_nodes", Pending: true}, {ID: 2, Skill: "kubekey", Action: "delete_cluster", Pending: true}},
+			verdict: EffectDeny,
+			rules:   []string{"no-prod-delete-cluster", "approve-prod-changes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := engine.EvaluatePlan(tt.input, tt.steps)
+			if verdict := Verdict(decisions); verdict != tt.verdict {
+				t.Errorf("verdict = %s, want %s (%+v)", verdict, tt.verdict, decisions)
+			}
+			if ids := ruleIDs(decisions); !slices.Equal(ids, tt.rules) {
+				t.Errorf("rules = %q, want %q", ids, tt.rules)
+			}
+		})
+	}
+}
+
+func TestAuthorizeStep(t *testing.T) {
+	engine, err := New(testRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   Input
+		step    Step
+		verdict string
+	}{
+		{"allow", Input{Environment: "staging"}, Step{ID: 1, Skill: "kubekey", Action: "add_nodes"}, EffectAllow},
+		{"deny", Input{Environment: "prod"}, Step{ID: 1, Skill: "kubekey", Action: "delete_cluster"}, EffectDeny},
+		{"require approval", Input{Environment: "prod"}, Step{ID: 1, Skill: "kubekey", Action: "delete_node"}, EffectRequireApproval},
+		// Limits and plan rules are checked on admission only
+		{"limits are skipped", Input{Environment: "staging"}, deleteNodes(1, true, "a", "b", "c", "d"), EffectAllow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := engine.AuthorizeStep(tt.input, []Step{tt.step}, tt.step)
+			if verdict := Verdict(decisions); verdict != tt.verdict {
+				t.Errorf("verdict = %s, want %s (%+v)", verdict, tt.verdict, decisions)
+			}
+			for _, d := range decisions {
+				if d.StepID != tt.step.ID {
+					t.Errorf("decision %s is for step %d, want %d", d.RuleID, d.StepID, tt.step.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizeCompensation(t *testing.T) {
+	engine, err := New(testRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		input        Input
+		steps        []Step
+		compensation Step
+		verdict      string
+		rules        []string
+	}{
+		{
+			name:         "allow",
+			input:        Input{Environment: "staging"},
+			steps:        []Step{{ID: 1, Skill: "kubekey", Action: "add_nodes"}},
+			compensation: deleteNodes(101, true, "node1"),
+			verdict:      EffectAllow,
+			rules:        []string{""},
+		},
+		{
+			name:         "step rules apply",
+			input:        Input{Environment: "prod"},
+			steps:        []Step{{ID: 1, Skill: "kubekey", Action: "add_nodes"}},
+			compensation: deleteNodes(101, true, "node1"),
+			verdict:      EffectRequireApproval,
+			rules:        []string{"approve-prod-changes"},
+		},
+		{
+			name:         "compensations count towards limits",
+			input:        Input{Environment: "staging"},
+			steps:        []Step{deleteNodes(1, false, "node1", "node2")},
+			compensation: deleteNodes(101, true, "node3", "node4"),
+			verdict:      EffectDeny,
+			rules:        []string{"max-deleted-nodes"},
+		},
+		{
+			name:  "plan rules are skipped",
+			input: Input{Environment: "staging"},
+			steps: []Step{
+				{ID: 1, Skill: "shell", Action: "check"},
+				{ID: 2, Skill: "shell", Action: "check"},
+				{ID: 3, Skill: "shell", Action: "check"},
+				{ID: 4, Skill: "shell", Action: "check"},
+			},
+			compensation: Step{ID: 101, Skill: "shell", Action: "check", Pending: true},
+			verdict:      EffectAllow,
+			rules:        []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := engine.AuthorizeCompensation(tt.input, tt.steps, tt.compensation)
+			if verdict := Verdict(decisions); verdict != tt.verdict {
+				t.Errorf("verdict = %s, want %s (%+v)", verdict, tt.verdict, decisions)
+			}
+			if ids := ruleIDs(decisions); !slices.Equal(ids, tt.rules) {
+				t.Errorf("rules = %q, want %q", ids, tt.rules)
+			}
+			for _, d := range decisions {
+				if d.StepID != tt.compensation.ID {
+					t.Errorf("decision %s is for step %d, want %d", d.RuleID, d.StepID, tt.compensation.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"missing id", Rule{Effect: EffectDeny}},
+		{"unknown effect", Rule{ID: "r", Effect: "warn"}},
+		{"invalid expression", Rule{ID: "r", Effect: EffectDeny, When: `step.skill ==`}},
+		{"when is not a bool", Rule{ID: "r", Effect: EffectDeny, When: `requester`}},
+		{"count is not an int", Rule{ID: "r", Effect: EffectDeny, Count: `"one"`, Max: intPtr(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]Rule{tt.rule}); err == nil {
+				t.Error("New succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Package policy evaluates plans and plan steps against the rules of the policy
+// file before they are executed.
+//
+// Rules are CEL expressions over the step (skill, action, params), the plan, the
+// requester, the target environment and labels, the task mode and the current time. A matching
+// rule denies the plan or step, or holds it until it is approved; limit rules cap
+// the blast radius of a task, e.g. no more than 3 nodes deleted.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Effects of a rule
+const (
+	EffectAllow           = "allow"            // No rule matched
+	EffectDeny            = "deny"             // The plan or step must not run
+	EffectRequireApproval = "require_approval" // The plan or step runs once approved
+)
+
+// Scopes of a rule
+const (
+	ScopeStep = "step" // When is evaluated for each step
+	ScopePlan = "plan" // When is evaluated once for the plan
+)
+
+// Rule is a rule of the policy file
+type Rule struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description,omitempty"`
+	// Effect when the rule matches: deny or require_approval
+	Effect string `yaml:"effect"`
+	// Scope is step (default) or plan
+	Scope string `yaml:"scope,omitempty"`
+	// When is a CEL expression, the rule matches when it is true; empty matches everything
+	When string `yaml:"when,omitempty"`
+
+	// Max makes the rule a limit: it matches when the steps selected by When add up
+	// to more than Max targets in a task
+	Max *int `yaml:"max,omitempty"`
+	// Count is a CEL expression counting the targets of a selected step, 1 when empty
+	Count string `yaml:"count,omitempty"`
+}
+
+// File is the policy file
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Input is what a plan or step is evaluated with, besides the steps themselves
+type Input struct {
+	Requester   string
+	Environment string
+	Labels      map[string]string
+	Mode        string
+	Query       string
+	Time        time.Time
+}
+
+// Step is a plan step as seen by the rules
+type Step struct {
+	ID          int
+	Skill       string
+	Action      string
+	Description string
+	Params      map[string]interface{}
+	// Pending steps are checked by step rules; completed ones only count towards limits
+	Pending bool
+}
+
+// Decision is the outcome of a matching rule, or an allow decision when none matched
+type Decision struct {
+	RuleID string
+	Effect string
+	StepID int // 0 for decisions on the whole plan
+	Reason string
+}
+
+// Verdict returns the strongest effect of decisions: deny, then require_approval, then allow
+func Verdict(decisions []Decision) string {
+	verdict := EffectAllow
+	for _, d := range decisions {
+		switch d.Effect {
+		case EffectDeny:
+			return EffectDeny
+		case EffectRequireApproval:
+			verdict = EffectRequireApproval
+		}
+	}
+	return verdict
+}
+
+// LoadFile reads a policy file. A missing file has no rules.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return &file, nil
+}
+
+// validate checks the fields of a rule that do not need CEL
+func (r *Rule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	switch r.Effect {
+	case EffectDeny, EffectRequireApproval:
+	default:
+		return fmt.Errorf("unknown effect %q (deny or require_approval)", r.Effect)
+	}
+	switch r.Scope {
+	case "", ScopeStep:
+	case ScopePlan:
+		if r.Max != nil {
+			return fmt.Errorf("max needs the step scope, it counts the steps selected by when")
+		}
+	default:
+		return fmt.Errorf("unknown scope %q (step or plan)", r.Scope)
+	}
+	if r.Max != nil && *r.Max < 0 {
+		return fmt.Errorf("max must not be negative")
+	}
+	if r.Count != "" && r.Max == nil {
+		return fmt.Errorf("count needs max")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// secretGroup is the name of the capture group holding the secret part of a match.
+// Patterns without it redact the whole match.
+const secretGroup = "secret"
+
+// Default entropy detector settings
+const (
+	DefaultEntropyMinLength = 32
+	DefaultEntropyThreshold = 4.5
+)
+
+// RegexDetector finds secrets with a regular expression
+type RegexDetector struct {
+	name  string
+	re    *regexp.Regexp
+	group int // Index of the "secret" capture group, 0 for the whole match
+}
+
+// NewRegexDetector compiles a regex detector.
+// If the pattern has a capture group named "secret", only that group is redacted.
+func NewRegexDetector(name, pattern string) (*RegexDetector, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction pattern %s: %w", name, err)
+	}
+	group := re.SubexpIndex(secretGroup)
+	if group < 0 {
+		group = 0
+	}
+	return &RegexDetector{name: name, re: re, group: group}, nil
+}
+
+// mustRegexDetector is NewRegexDetector for built-in patterns
+func mustRegexDetector(name, pattern string) *RegexDetector {
+	d, err := NewRegexDetector(name, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d *RegexDetector) Name() string {
+	return d.name
+}
+
+func (d *RegexDetector) Find(s string) []Span {
+	var spans []Span
+	for _, m := range d.re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[2*d.group], m[2*d.group+1]
+		if start < 0 || start == end {
+			continue
+		}
+		spans = append(spans, Span{Start: start, End: end})
+	}
+	return spans
+}
+
+// defaultPatterns covers the secrets that typically show up in kubekey and kubectl output
+var defaultPatterns = []*RegexDetector{
+	mustRegexDetector("private_key",
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	mustRegexDetector("kubeconfig_data",
+		`(?m)\b(?:client-key-data|client-certificate-data|certificate-authority-data)\s*:\s*(?P<secret>[A-Za-z0-9+/=]+)`),
+	mustRegexDetector("bearer_token",
+		`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`),
+	mustRegexDetector("credential_field",
+		`(?im)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)\b["']?\s*[:=]\s*["']?(?P<secret>[^\s"',}]+)`),
+	mustRegexDetector("url_credentials",
+		`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:(?P<secret>[^/\s@]+)@`),
+	mustRegexDetector("aws_access_key",
+		`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	mustRegexDetector("openai_api_key",
+		`\bsk-[A-Za-z0-9_\-]{20,}`),
+	mustRegexDetector("jwt",
+		`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`),
+}
+
+// DefaultPatterns returns the built-in regex detectors
+func DefaultPatterns() []*RegexDetector {
+	return defaultPatterns
+}
+
+// tokenPattern matches candidate tokens for the entropy detector
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// EntropyDetector finds long, random-looking tokens (API keys, generated passwords)
+// by their Shannon entropy
+type EntropyDetector struct {
+	minLength int
+	threshold float64
+}
+
+// NewEntropyDetector creates an entropy detector.
+// Tokens of at least minLength characters with an entropy of at least threshold bits
+// per character are reported.
+func NewEntropyDetector(minLength int, threshold float64) *EntropyDetector {
+	if minLength <= 0 {
+		minLength = DefaultEntropyMinLength
+	}
+	if threshold <= 0 {
+		threshold = DefaultEntropyThreshold
+	}
+	return &EntropyDetector{minLength: minLength, threshold: threshold}
+}
+
+func (d *EntropyDetector) Name() string {
+	return "entropy"
+}
+
+func (d *EntropyDetector) Find(s string) []Span {
+	var spans []Span
+	for _, loc := range tokenPattern.FindAllStringIndex(s, -1) {
+		if loc[1]-loc[0] < d.minLength {
+			continue
+		}
+		if shannonEntropy(s[loc[0]:loc[1]]) >= d.threshold {
+			spans = append(spans, Span{Start: loc[0], End: loc[1]})
+		}
+	}
+	return spans
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,55 @@
+package redact
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Delimiters of PEM private key blocks, which span lines
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY-----`)
+)
+
+// LineRedactor redacts output streamed line by line, where detectors never see the
+// secrets spanning lines: private keys are redacted from their BEGIN line until
+// their END line. A nil *LineRedactor redacts nothing.
+type LineRedactor struct {
+	r     *Redactor
+	mu    sync.Mutex
+	inKey map[string]bool // Streams inside a private key block
+}
+
+// Lines returns a redactor of the lines of a streamed output
+func (r *Redactor) Lines() *LineRedactor {
+	if r == nil {
+		return nil
+	}
+	return &LineRedactor{r: r, inKey: make(map[string]bool)}
+}
+
+// Line redacts a line of stream (e.g. stdout or stderr), lines of each stream in order
+func (l *LineRedactor) Line(stream, text string) string {
+	if l == nil {
+		return text
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inKey[stream] {
+		end := privateKeyEnd.FindStringIndex(text)
+		if end == nil {
+			return l.r.replacement
+		}
+		delete(l.inKey, stream)
+		return l.r.replacement + l.r.String(text[end[1]:])
+	}
+
+	// A block ended on the same line is redacted by the detectors
+	begin := privateKeyBegin.FindStringIndex(text)
+	if begin == nil || privateKeyEnd.MatchString(text[begin[1]:]) {
+		return l.r.String(text)
+	}
+	l.inKey[stream] = true
+	return l.r.String(text[:begin[0]]) + l.r.replacement
+}
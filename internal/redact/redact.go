@@ -0,0 +1,242 @@
+package redact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultReplacement is the text substituted for every redacted secret
+const DefaultReplacement = "[REDACTED]"
+
+// minKnownValueLength is the shortest known secret value that is redacted.
+// Shorter values (e.g. "1", "yes") would mangle unrelated output.
+const minKnownValueLength = 4
+
+// Span is a byte range [Start, End) of a detected secret
+type Span struct {
+	Start int
+	End   int
+}
+
+// Detector finds secrets in a text
+type Detector interface {
+	// Name returns the detector name, used in configuration and logs
+	Name() string
+	// Find returns the byte ranges of all secrets found in s
+	Find(s string) []Span
+}
+
+// Redactor replaces secrets found by its detectors and known secret values.
+// A nil *Redactor is valid and redacts nothing.
+type Redactor struct {
+	detectors   []Detector
+	replacement string
+	known       *Known // Optional, see WithKnown
+}
+
+// Known is a set of literal secret values (e.g. resolved from a secret store), which
+// are always redacted regardless of the detectors. Sets are scoped, e.g. to a task,
+// so that values are forgotten with it. A nil *Known is valid and empty.
+type Known struct {
+	values map[string]struct{}
+	mu     sync.RWMutex
+}
+
+// NewKnown creates an empty set of known values
+func NewKnown() *Known {
+	return &Known{values: make(map[string]struct{})}
+}
+
+// Add registers values, those shorter than 4 bytes are ignored
+func (k *Known) Add(values ...string) {
+	if k == nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, v := range values {
+		if len(v) >= minKnownValueLength {
+			k.values[v] = struct{}{}
+		}
+	}
+}
+
+// find returns the byte ranges of the known values in s
+func (k *Known) find(s string) []Span {
+	if k == nil {
+		return nil
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var spans []Span
+	for v := range k.values {
+		for offset := 0; ; {
+			idx := strings.Index(s[offset:], v)
+			if idx < 0 {
+				break
+			}
+			spans = append(spans, Span{Start: offset + idx, End: offset + idx + len(v)})
+			offset += idx + len(v)
+		}
+	}
+	return spans
+}
+
+// New creates a redactor with the given detectors
+func New(replacement string, detectors ...Detector) *Redactor {
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+	return &Redactor{
+		detectors:   detectors,
+		replacement: replacement,
+	}
+}
+
+// Default creates a redactor with the built-in regex and entropy detectors
+func Default() *Redactor {
+	r, _ := NewFromOptions(Options{Entropy: true})
+	return r
+}
+
+// Pattern is a user-defined regex detector
+type Pattern struct {
+	Name  string
+	Regex string
+}
+
+// Options configures a redactor
+type Options struct {
+	Replacement     string
+	Patterns        []Pattern // Added to the built-in patterns
+	DisableDefaults bool      // Drop the built-in patterns
+	Entropy         bool
+	EntropyMinLen   int
+	EntropyMinBits  float64
+}
+
+// NewFromOptions creates a redactor from options, failing on invalid patterns
+func NewFromOptions(opts Options) (*Redactor, error) {
+	var detectors []Detector
+	if !opts.DisableDefaults {
+		for _, p := range DefaultPatterns() {
+			detectors = append(detectors, p)
+		}
+	}
+	for i, p := range opts.Patterns {
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("pattern_%d", i)
+		}
+		d, err := NewRegexDetector(name, p.Regex)
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, d)
+	}
+	if opts.Entropy {
+		detectors = append(detectors, NewEntropyDetector(opts.EntropyMinLen, opts.EntropyMinBits))
+	}
+	return New(opts.Replacement, detectors...), nil
+}
+
+// WithKnown returns a redactor with the detectors of r that also redacts the
+// values of known, nil when r is nil
+func (r *Redactor) WithKnown(known *Known) *Redactor {
+	if r == nil {
+		return nil
+	}
+	return &Redactor{
+		detectors:   r.detectors,
+		replacement: r.replacement,
+		known:       known,
+	}
+}
+
+// Redact replaces every secret in s and returns the redacted text and
+// the number of secrets replaced
+func (r *Redactor) Redact(s string) (string, int) {
+	if r == nil || s == "" {
+		return s, 0
+	}
+
+	spans := r.known.find(s)
+	for _, d := range r.detectors {
+		spans = append(spans, d.Find(s)...)
+	}
+
+	if len(spans) == 0 {
+		return s, 0
+	}
+
+	spans = mergeSpans(spans)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	last := 0
+	for _, span := range spans {
+		b.WriteString(s[last:span.Start])
+		b.WriteString(r.replacement)
+		last = span.End
+	}
+	b.WriteString(s[last:])
+
+	return b.String(), len(spans)
+}
+
+// String redacts s and discards the count
+func (r *Redactor) String(s string) string {
+	redacted, _ := r.Redact(s)
+	return redacted
+}
+
+// Error redacts the message of err while keeping it unwrappable
+func (r *Redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	msg, n := r.Redact(err.Error())
+	if n == 0 {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError hides the original error message but keeps errors.Is/As working
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// mergeSpans sorts spans and merges overlapping or adjacent ranges
+func mergeSpans(spans []Span) []Span {
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+		return spans[i].End > spans[j].End
+	})
+
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.Start <= last.End {
+			if span.End > last.End {
+				last.End = span.End
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
@@ -0,0 +1,229 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BM25 parameters
+const (
+	DefaultK1 = 1.2
+	DefaultB  = 0.75
+)
+
+// Document is a unit of retrieval made of named text fields
+type Document struct {
+	ID     string
+	Fields map[string]string
+}
+
+// Match is the contribution of one query term in one field to a score
+type Match struct {
+	Term  string  `json:"term"`
+	Field string  `json:"field"`
+	Score float64 `json:"score"`
+}
+
+// Result is a scored document
+type Result struct {
+	ID         string  `json:"id"`
+	Score      float64 `json:"score"`                // Final score used for ranking
+	BM25       float64 `json:"bm25"`                 // Raw BM25 score
+	Similarity float64 `json:"similarity,omitempty"` // Cosine similarity with the query, when embeddings are used
+	Matches    []Match `json:"matches,omitempty"`    // Sorted by score, highest first
+}
+
+// Embedder turns text into vectors, see github.com/tmc/langchaingo/embeddings
+type Embedder interface {
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// indexedDoc holds the term frequencies of a document per field
+type indexedDoc struct {
+	id      string
+	text    string                    // All fields, embedded when vectors are built
+	tf      map[string]map[string]int // field -> term -> count
+	lengths map[string]int            // field -> number of terms
+	vector  []float32
+}
+
+// Index is an in-memory BM25 index with per-field weights (BM25 is computed per
+// field and the weighted scores are summed). Vectors from an Embedder can be
+// added for hybrid ranking. An Index is not safe for concurrent writes; build it
+// once and replace it to update.
+type Index struct {
+	weights map[string]float64
+	k1, b   float64
+
+	docs      []*indexedDoc
+	df        map[string]int     // term -> number of documents containing it
+	fieldLens map[string]float64 // field -> total length, for averages
+}
+
+// NewIndex creates an index. Fields missing from weights have weight 1.
+func NewIndex(weights map[string]float64) *Index {
+	return &Index{
+		weights:   weights,
+		k1:        DefaultK1,
+		b:         DefaultB,
+		df:        make(map[string]int),
+		fieldLens: make(map[string]float64),
+	}
+}
+
+// Len returns the number of documents
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Add indexes a document
+func (ix *Index) Add(doc Document) {
+	d := &indexedDoc{
+		id:      doc.ID,
+		tf:      make(map[string]map[string]int),
+		lengths: make(map[string]int),
+	}
+
+	seen := make(map[string]bool)
+	for field, text := range doc.Fields {
+		d.text += text + "\n"
+		terms := Tokenize(text)
+		counts := make(map[string]int, len(terms))
+		for _, term := range terms {
+			counts[term]++
+			if !seen[term] {
+				seen[term] = true
+				ix.df[term]++
+			}
+		}
+		d.tf[field] = counts
+		d.lengths[field] = len(terms)
+		ix.fieldLens[field] += float64(len(terms))
+	}
+
+	ix.docs = append(ix.docs, d)
+}
+
+// Embed computes the vectors of every document
+func (ix *Index) Embed(ctx context.Context, embedder Embedder) error {
+	texts := make([]string, len(ix.docs))
+	for i, d := range ix.docs {
+		texts[i] = d.text
+	}
+	vectors, err := embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(vectors) != len(ix.docs) {
+		return fmt.Errorf("embedder returned %d vectors for %d documents", len(vectors), len(ix.docs))
+	}
+	for i, d := range ix.docs {
+		d.vector = vectors[i]
+	}
+	return nil
+}
+
+// Search ranks every document against the query, highest score first.
+// Documents without a matching term are included with a zero score unless the
+// query vector gives them a similarity. If queryVector is set, the score is
+// (1-weight) * BM25 normalized to the best BM25 score + weight * similarity.
+func (ix *Index) Search(query string, queryVector []float32, weight float64) []Result {
+	terms := uniqueTerms(Tokenize(query))
+	n := float64(len(ix.docs))
+
+	results := make([]Result, len(ix.docs))
+	maxBM25 := 0.0
+	for i, d := range ix.docs {
+		result := Result{ID: d.id}
+		for _, term := range terms {
+			df := float64(ix.df[term])
+			if df == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			for field, counts := range d.tf {
+				tf := float64(counts[term])
+				if tf == 0 {
+					continue
+				}
+				avgLen := ix.fieldLens[field] / n
+				norm := 1.0
+				if avgLen > 0 {
+					norm = 1 - ix.b + ix.b*float64(d.lengths[field])/avgLen
+				}
+				score := ix.weight(field) * idf * tf * (ix.k1 + 1) / (tf + ix.k1*norm)
+				result.BM25 += score
+				result.Matches = append(result.Matches, Match{Term: term, Field: field, Score: score})
+			}
+		}
+		sort.Slice(result.Matches, func(a, b int) bool { return result.Matches[a].Score > result.Matches[b].Score })
+		if queryVector != nil && d.vector != nil {
+			result.Similarity = cosine(queryVector, d.vector)
+		}
+		if result.BM25 > maxBM25 {
+			maxBM25 = result.BM25
+		}
+		results[i] = result
+	}
+
+	for i := range results {
+		if queryVector == nil {
+			results[i].Score = results[i].BM25
+			continue
+		}
+		normalized := 0.0
+		if maxBM25 > 0 {
+			normalized = results[i].BM25 / maxBM25
+		}
+		results[i].Score = (1-weight)*normalized + weight*results[i].Similarity
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// weight returns the weight of a field
+func (ix *Index) weight(field string) float64 {
+	if w, ok := ix.weights[field]; ok {
+		return w
+	}
+	return 1
+}
+
+// uniqueTerms removes repeated terms, keeping the first occurrence
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// cosine returns the cosine similarity of two vectors, 0 if their sizes differ
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,65 @@
+package retrieval
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are frequent English words that carry no meaning for retrieval
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "can": true, "do": true, "for": true, "from": true, "has": true, "have": true,
+	"how": true, "i": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "me": true, "my": true, "of": true, "on": true, "or": true, "our": true,
+	"please": true, "should": true, "so": true, "that": true, "the": true, "then": true,
+	"this": true, "to": true, "use": true, "used": true, "using": true, "want": true,
+	"we": true, "what": true, "when": true, "which": true, "will": true, "with": true,
+	"you": true, "your": true,
+}
+
+// Tokenize splits text into lowercase terms without stopwords.
+// Identifiers are split on separators ("add_nodes" gives "add" and "nodes") and
+// the whole identifier is kept as well, so exact action names still match.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		parts := strings.FieldsFunc(word, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+		if len(parts) > 1 {
+			if term := normalize(strings.Trim(word, "_-.")); term != "" {
+				terms = append(terms, term)
+			}
+		}
+		for _, part := range parts {
+			if term := normalize(part); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// isSeparator reports whether r splits words; '_', '-' and '.' are kept inside identifiers
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.'
+}
+
+// normalize drops stopwords and single characters and strips plural endings
+func normalize(word string) string {
+	if len(word) < 2 || stopwords[word] {
+		return ""
+	}
+	return stem(word)
+}
+
+// stem removes common plural suffixes ("nodes" and "node" match)
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 4 && strings.HasSuffix(word, "sses"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return word[:len(word)-1]
+	}
+	return word
+}
@@ -0,0 +1,172 @@
+// Package retry defines the retry policies of skill actions and plan steps.
+//
+// An action declares its policy in actions.yaml; a plan step can override its
+// fields within its limits: fewer attempts, delays within its own, and some of its
+// retryable failures only. Policies are plain data so they travel in plans, state
+// and prompts.
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Default policy settings
+const (
+	DefaultBackoff    = time.Second
+	DefaultMaxBackoff = time.Minute
+	DefaultMultiplier = 2.0
+)
+
+// Policy describes when and how a failed execution is retried
+type Policy struct {
+	// MaxAttempts is the number of executions including the first one, 0 or 1 disables retries
+	MaxAttempts int `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
+	// Backoff is the delay before the second attempt, e.g. "5s"; later delays grow by Multiplier
+	Backoff    string  `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	MaxBackoff string  `yaml:"max_backoff,omitempty" json:"max_backoff,omitempty"`
+	Multiplier float64 `yaml:"multiplier,omitempty" json:"multiplier,omitempty"`
+
+	// ExitCodes and Patterns (regular expressions matched against the output and error)
+	// select the retryable failures; without either, every failure is retryable
+	ExitCodes []int    `yaml:"exit_codes,omitempty" json:"exit_codes,omitempty"`
+	Patterns  []string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+}
+
+// Merge returns base with the fields set in override replacing its own, within the
+// limits of base: the attempts and the maximum delay of override never exceed those
+// of base, its delays never go below the backoff of base, and its exit codes and
+// patterns must be some of those of base. Fields out of these limits are ignored.
+// It returns nil without base, only declared policies can be overridden.
+func Merge(base, override *Policy) *Policy {
+	if base == nil {
+		return nil
+	}
+	merged := &Policy{}
+	*merged = *base
+	if override == nil {
+		return merged
+	}
+	if override.MaxAttempts != 0 {
+		merged.MaxAttempts = min(override.MaxAttempts, base.Attempts())
+	}
+	minDelay := parseDuration(base.Backoff, DefaultBackoff)
+	maxDelay := parseDuration(base.MaxBackoff, DefaultMaxBackoff)
+	if override.Backoff != "" && withinDelays(override.Backoff, minDelay, maxDelay) {
+		merged.Backoff = override.Backoff
+	}
+	if override.MaxBackoff != "" && withinDelays(override.MaxBackoff, minDelay, maxDelay) {
+		merged.MaxBackoff = override.MaxBackoff
+	}
+	if override.Multiplier >= 1 {
+		merged.Multiplier = override.Multiplier
+	}
+
+	// Overrides narrow the retryable failures, they never add any
+	conditional := len(base.ExitCodes) > 0 || len(base.Patterns) > 0
+	if override.ExitCodes != nil && (!conditional || subset(override.ExitCodes, base.ExitCodes)) {
+		merged.ExitCodes = override.ExitCodes
+	}
+	if override.Patterns != nil && (!conditional || subset(override.Patterns, base.Patterns)) {
+		merged.Patterns = override.Patterns
+	}
+	// Without conditions every failure is retryable
+	if conditional && len(merged.ExitCodes) == 0 && len(merged.Patterns) == 0 {
+		merged.ExitCodes, merged.Patterns = base.ExitCodes, base.Patterns
+	}
+	return merged
+}
+
+// withinDelays reports whether s is a valid duration between minDelay and maxDelay
+func withinDelays(s string, minDelay, maxDelay time.Duration) bool {
+	d, err := time.ParseDuration(s)
+	return err == nil && d >= minDelay && d <= maxDelay
+}
+
+// subset reports whether every value of values is one of declared
+func subset[T comparable](values, declared []T) bool {
+	for _, v := range values {
+		if !slices.Contains(declared, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks the attempts, durations and patterns of a policy
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	var errs []error
+	if p.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("max_attempts cannot be negative"))
+	}
+	if p.Multiplier < 0 {
+		errs = append(errs, fmt.Errorf("multiplier cannot be negative"))
+	}
+	for _, field := range [][2]string{{"backoff", p.Backoff}, {"max_backoff", p.MaxBackoff}} {
+		if field[1] == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(field[1]); err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %q", field[0], field[1]))
+		}
+	}
+	for _, pattern := range p.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pattern %q: %w", pattern, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Attempts returns the maximum number of executions, at least 1
+func (p *Policy) Attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Delay returns the delay after the given failed attempt (1 for the first one)
+func (p *Policy) Delay(attempt int) time.Duration {
+	delay := parseDuration(p.Backoff, DefaultBackoff)
+	maxDelay := parseDuration(p.MaxBackoff, DefaultMaxBackoff)
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = DefaultMultiplier
+	}
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay = time.Duration(float64(delay) * multiplier)
+	}
+	return min(delay, maxDelay)
+}
+
+// Retryable reports whether a failure with the given exit code and output matches the policy
+func (p *Policy) Retryable(exitCode int, output string) bool {
+	if len(p.ExitCodes) == 0 && len(p.Patterns) == 0 {
+		return true
+	}
+	for _, code := range p.ExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+	for _, pattern := range p.Patterns {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(output) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDuration(s string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d
+	}
+	return fallback
+}
@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := &Policy{
+		MaxAttempts: 3,
+		Backoff:     "5s",
+		MaxBackoff:  "1m",
+		ExitCodes:   []int{255},
+		Patterns:    []string{"Connection reset", "timed out"},
+	}
+
+	tests := []struct {
+		name     string
+		base     *Policy
+		override *Policy
+		want     *Policy
+	}{
+		{
+			name:     "no override",
+			base:     base,
+			override: nil,
+			want:     base,
+		},
+		{
+			name:     "narrower override",
+			base:     base,
+			override: &Policy{MaxAttempts: 2, Backoff: "10s", MaxBackoff: "30s", ExitCodes: []int{}, Patterns: []string{"timed out"}},
+			want:     &Policy{MaxAttempts: 2, Backoff: "10s", MaxBackoff: "30s", ExitCodes: []int{}, Patterns: []string{"timed out"}},
+		},
+		{
+			name:     "attempts and delays are capped",
+			base:     base,
+			override: &Policy{MaxAttempts: 10, Backoff: "1s", MaxBackoff: "1h", Multiplier: 0.5},
+			want:     base,
+		},
+		{
+			name:     "wider conditions are ignored",
+			base:     base,
+			override: &Policy{ExitCodes: []int{1, 255}, Patterns: []string{".*"}},
+			want:     base,
+		},
+		{
+			name:     "removing every condition is ignored",
+			base:     base,
+			override: &Policy{ExitCodes: []int{}, Patterns: []string{}},
+			want:     base,
+		},
+		{
+			name:     "conditions narrow an unconditional policy",
+			base:     &Policy{MaxAttempts: 3},
+			override: &Policy{ExitCodes: []int{255}},
+			want:     &Policy{MaxAttempts: 3, ExitCodes: []int{255}},
+		},
+		{
+			name:     "no declared policy",
+			base:     nil,
+			override: &Policy{MaxAttempts: 3},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.base, tt.override)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergedPolicyRetries(t *testing.T) {
+	base := &Policy{MaxAttempts: 3, ExitCodes: []int{255}}
+	merged := Merge(base, &Policy{ExitCodes: []int{1}, Patterns: []string{"error"}})
+
+	if merged.Retryable(1, "error: invalid config") {
+		t.Error("a failure the action does not retry is retryable after the override")
+	}
+	if !merged.Retryable(255, "ssh: connection closed") {
+		t.Error("a failure the action retries is not retryable after the override")
+	}
+}
@@ -0,0 +1,176 @@
+package runbook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotFound is returned for unknown runbooks
+	ErrNotFound = errors.New("runbook not found")
+	// ErrExists is returned when saving a runbook under the name of another one
+	ErrExists = errors.New("runbook already exists")
+)
+
+// Catalog keeps the runbooks of a directory, one YAML file per runbook named
+// after it: <dir>/<name>.yaml
+type Catalog struct {
+	dir      string
+	mu       sync.RWMutex
+	runbooks map[string]*Runbook
+}
+
+// Load loads the runbooks of dir. A missing directory has no runbooks, it is
+// created when a runbook is saved. Every invalid runbook is reported.
+func Load(dir string) (*Catalog, error) {
+	c := &Catalog{dir: dir, runbooks: make(map[string]*Runbook)}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runbooks: %w", err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		r, err := loadFile(filepath.Join(dir, entry.Name()))
+		if err == nil && r.Name != strings.TrimSuffix(entry.Name(), ext) {
+			err = fmt.Errorf("name %q does not match the file name", r.Name)
+		}
+		if err == nil && c.runbooks[r.Name] != nil {
+			err = fmt.Errorf("duplicate runbook %s", r.Name)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		c.runbooks[r.Name] = r
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+func loadFile(path string) (*Runbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a runbook definition, YAML or JSON
+func Parse(data []byte) (*Runbook, error) {
+	var r Runbook
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse runbook: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Len returns the number of runbooks
+func (c *Catalog) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.runbooks)
+}
+
+// List returns the runbooks sorted by name
+func (c *Catalog) List() []*Runbook {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	runbooks := make([]*Runbook, 0, len(c.runbooks))
+	for _, r := range c.runbooks {
+		runbooks = append(runbooks, r)
+	}
+	sort.Slice(runbooks, func(i, j int) bool { return runbooks[i].Name < runbooks[j].Name })
+	return runbooks
+}
+
+// Get returns a runbook
+func (c *Catalog) Get(name string) (*Runbook, error) {
+	if c == nil {
+		return nil, ErrNotFound
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.runbooks[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return r, nil
+}
+
+// Save validates a new runbook and writes its file
+func (c *Catalog) Save(r *Runbook) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.runbooks[r.Name]; ok {
+		return ErrExists
+	}
+
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal runbook: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create runbook directory: %w", err)
+	}
+	if err := os.WriteFile(c.path(r.Name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write runbook: %w", err)
+	}
+	c.runbooks[r.Name] = r
+	return nil
+}
+
+// Delete removes a runbook and its file
+func (c *Catalog) Delete(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.runbooks[name]; !ok {
+		return ErrNotFound
+	}
+	if err := os.Remove(c.path(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove runbook: %w", err)
+	}
+	delete(c.runbooks, name)
+	return nil
+}
+
+// path returns the file of a runbook; files loaded as .yml keep their extension
+func (c *Catalog) path(name string) string {
+	if yml := filepath.Join(c.dir, name+".yml"); fileExists(yml) {
+		return yml
+	}
+	return filepath.Join(c.dir, name+".yaml")
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,167 @@
+package runbook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+// FromPlan promotes the plan of a task to a runbook. inputs maps the name of each
+// input to its value in the plan: params equal to the value become references to
+// the input, typed after the param, and the value is replaced by a reference in
+// longer strings and in the description. Every value must occur in the plan.
+func FromPlan(name, description string, plan *state.Plan, inputs map[string]string) (*Runbook, error) {
+	if plan == nil || len(plan.Steps) == 0 {
+		return nil, fmt.Errorf("the task has no plan")
+	}
+
+	names := make([]string, 0, len(inputs))
+	for input := range inputs {
+		names = append(names, input)
+	}
+	// Longer values first, so a value containing another is replaced whole
+	sort.Slice(names, func(i, j int) bool {
+		if len(inputs[names[i]]) != len(inputs[names[j]]) {
+			return len(inputs[names[i]]) > len(inputs[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	p := &promotion{values: inputs, names: names, types: make(map[string]string), used: make(map[string]bool)}
+	r := &Runbook{Name: name}
+	for _, step := range plan.Steps {
+		params, _ := p.parameterize(step.Params).(map[string]any)
+		description, _ := p.parameterize(step.Description).(string)
+		r.Steps = append(r.Steps, Step{
+			SkillName:   step.SkillName,
+			Action:      step.Action,
+			Description: description,
+			Params:      params,
+			Retry:       step.Retry,
+		})
+	}
+
+	// Values only found in the description are no inputs of the steps
+	used := p.used
+	p.used = make(map[string]bool)
+	r.Description, _ = p.parameterize(description).(string)
+	p.used = used
+
+	sort.Strings(names)
+	var unused []string
+	for _, input := range names {
+		if !p.used[input] {
+			unused = append(unused, fmt.Sprintf("%s (%q)", input, inputs[input]))
+			continue
+		}
+		inputType := p.types[input]
+		if inputType == "" {
+			inputType = TypeString
+		}
+		r.Inputs = append(r.Inputs, Input{
+			Name:        input,
+			Type:        inputType,
+			Description: fmt.Sprintf("%s, e.g. %s", input, inputs[input]),
+			Required:    true,
+		})
+	}
+	if len(unused) > 0 {
+		return nil, fmt.Errorf("inputs not found in the plan: %s", strings.Join(unused, ", "))
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// promotion replaces the input values of a plan by references
+type promotion struct {
+	values map[string]string
+	names  []string          // Inputs, longest value first
+	types  map[string]string // Type of the inputs replacing whole typed params
+	used   map[string]bool
+}
+
+func (p *promotion) parameterize(v any) any {
+	switch v := v.(type) {
+	case string:
+		for _, name := range p.names {
+			if value := p.values[name]; value != "" && v == value {
+				p.used[name] = true
+				return "${" + name + "}"
+			}
+		}
+		var pairs []string
+		for _, name := range p.names {
+			if value := p.values[name]; value != "" && strings.Contains(v, value) {
+				p.used[name] = true
+				pairs = append(pairs, value, "${"+name+"}")
+			}
+		}
+		if len(pairs) == 0 {
+			return v
+		}
+		// A single pass never replaces values inside the references it inserted
+		return strings.NewReplacer(pairs...).Replace(v)
+	case bool, int, int64, float64:
+		for _, name := range p.names {
+			if matches(v, p.values[name]) {
+				p.used[name] = true
+				p.types[name] = typeOf(v)
+				return "${" + name + "}"
+			}
+		}
+		return v
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = p.parameterize(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = p.parameterize(item)
+		}
+		return result
+	}
+	return v
+}
+
+// matches reports whether the text of an input value parses, as inputs are resolved,
+// to the typed param v: "3.0" matches 3
+func matches(v any, text string) bool {
+	input := Input{Type: TypeNumber}
+	if _, ok := v.(bool); ok {
+		input.Type = TypeBool
+	}
+	parsed, err := input.parse(text)
+	if err != nil {
+		return false
+	}
+	switch v := v.(type) {
+	case int:
+		return parsed == float64(v)
+	case int64:
+		return parsed == float64(v)
+	}
+	return parsed == v
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return TypeBool
+	case int, int64:
+		return TypeInt
+	case float64:
+		if v == float64(int64(v)) {
+			return TypeInt
+		}
+		return TypeNumber
+	}
+	return TypeString
+}
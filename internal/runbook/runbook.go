@@ -0,0 +1,261 @@
+// Package runbook keeps runbooks: fixed step sequences with typed inputs, run
+// without planning. Repeated requests such as "add node X to cluster Y" then always
+// run the same steps.
+//
+// Params and descriptions of the steps reference inputs as ${name}. A param that is
+// only a reference gets the typed value of the input, references in descriptions and
+// longer strings are replaced by its text.
+package runbook
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hb-chen/opskills/internal/retry"
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+// Input types
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+)
+
+// inputRef matches the references to inputs
+var inputRef = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// namePattern restricts runbook names to file names
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Runbook is a fixed sequence of steps with typed inputs
+type Runbook struct {
+	Name        string  `yaml:"name" json:"name"`
+	Description string  `yaml:"description" json:"description"`
+	Inputs      []Input `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Steps       []Step  `yaml:"steps" json:"steps"`
+	// Replan lets the planner revise the remaining steps when one fails, instead of
+	// failing the task
+	Replan bool `yaml:"replan,omitempty" json:"replan,omitempty"`
+}
+
+// Input is a typed input of a runbook
+type Input struct {
+	Name        string `yaml:"name" json:"name"`
+	Type        string `yaml:"type,omitempty" json:"type,omitempty"` // string (default), int, number or bool
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	// Default is used when the input is not given
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// Step is a step of a runbook
+type Step struct {
+	SkillName   string         `yaml:"skill_name" json:"skill_name"`
+	Action      string         `yaml:"action" json:"action"`
+	Description string         `yaml:"description,omitempty" json:"description,omitempty"`
+	Params      map[string]any `yaml:"params,omitempty" json:"params,omitempty"`
+	Retry       *retry.Policy  `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// Validate checks the runbook: its name, inputs and steps, and that steps only
+// reference declared inputs
+func (r *Runbook) Validate() error {
+	if !namePattern.MatchString(r.Name) {
+		return fmt.Errorf("invalid name %q: lowercase letters, digits, - and _", r.Name)
+	}
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("runbook has no steps")
+	}
+
+	declared := make(map[string]bool, len(r.Inputs))
+	for i, input := range r.Inputs {
+		if input.Name == "" {
+			return fmt.Errorf("input %d: name is required", i+1)
+		}
+		if declared[input.Name] {
+			return fmt.Errorf("input %s: duplicate name", input.Name)
+		}
+		declared[input.Name] = true
+		switch input.Type {
+		case "", TypeString, TypeInt, TypeNumber, TypeBool:
+		default:
+			return fmt.Errorf("input %s: unknown type %q (string, int, number or bool)", input.Name, input.Type)
+		}
+		if input.Default != "" {
+			if _, err := input.parse(input.Default); err != nil {
+				return fmt.Errorf("input %s: default: %w", input.Name, err)
+			}
+		}
+	}
+
+	for i, step := range r.Steps {
+		if step.SkillName == "" || step.Action == "" {
+			return fmt.Errorf("step %d: skill_name and action are required", i+1)
+		}
+		for _, name := range step.references() {
+			if !declared[name] {
+				return fmt.Errorf("step %d references ${%s}, not an input of the runbook", i+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve checks the given input values against the inputs of the runbook, and
+// returns the typed values of the inputs set, with defaults
+func (r *Runbook) Resolve(values map[string]string) (map[string]any, error) {
+	resolved := make(map[string]any, len(r.Inputs))
+	known := make(map[string]bool, len(r.Inputs))
+	var missing []string
+	for _, input := range r.Inputs {
+		known[input.Name] = true
+		value, ok := values[input.Name]
+		if !ok || value == "" {
+			value = input.Default
+		}
+		if value == "" {
+			if input.Required {
+				missing = append(missing, input.Name)
+			}
+			continue
+		}
+		typed, err := input.parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", input.Name, err)
+		}
+		resolved[input.Name] = typed
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("required inputs not set: %s", strings.Join(missing, ", "))
+	}
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown inputs: %s", strings.Join(unknown, ", "))
+	}
+	return resolved, nil
+}
+
+// Render returns the plan of the runbook for the given input values
+func (r *Runbook) Render(values map[string]string) (*state.Plan, error) {
+	inputs, err := r.Resolve(values)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &state.Plan{Runbook: r.Name, Fixed: true, Replan: r.Replan}
+	for i, step := range r.Steps {
+		params, _ := substitute(step.Params, inputs).(map[string]any)
+		description := substituteText(step.Description, inputs)
+		plan.Steps = append(plan.Steps, &state.PlanStep{
+			ID:          i + 1,
+			SkillName:   step.SkillName,
+			Action:      step.Action,
+			Description: description,
+			Params:      params,
+			Retry:       step.Retry,
+		})
+	}
+	return plan, nil
+}
+
+// parse converts the text of an input value to its type
+func (input *Input) parse(value string) (any, error) {
+	switch input.Type {
+	case TypeInt:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %q", value)
+		}
+		return v, nil
+	case TypeNumber:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value)
+		}
+		return v, nil
+	case TypeBool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool %q", value)
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// references returns the names of the inputs referenced by the step
+func (s *Step) references() []string {
+	var names []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			for _, m := range inputRef.FindAllStringSubmatch(v, -1) {
+				names = append(names, m[1])
+			}
+		case map[string]any:
+			for _, item := range v {
+				walk(item)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(s.Description)
+	walk(s.Params)
+	return names
+}
+
+// substitute replaces the references to inputs in v, a param value. Params and list
+// items only referencing an unset optional input are dropped, references to one in
+// longer strings are replaced by nothing.
+func substitute(v any, inputs map[string]any) any {
+	switch v := v.(type) {
+	case string:
+		if m := inputRef.FindStringSubmatch(v); m != nil && m[0] == v {
+			return inputs[m[1]]
+		}
+		return substituteText(v, inputs)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			if value := substitute(item, inputs); value != nil {
+				result[key] = value
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			if value := substitute(item, inputs); value != nil {
+				result = append(result, value)
+			}
+		}
+		return result
+	}
+	return v
+}
+
+// substituteText replaces the references to inputs in text by their text, references
+// to an unset optional input by nothing
+func substituteText(text string, inputs map[string]any) string {
+	return inputRef.ReplaceAllStringFunc(text, func(ref string) string {
+		if typed, ok := inputs[inputRef.FindStringSubmatch(ref)[1]]; ok {
+			return fmt.Sprint(typed)
+		}
+		return ""
+	})
+}
@@ -0,0 +1,148 @@
+package runbook
+
+import (
+	"reflect"
+	"testing"
+)
+
+var addNode = &Runbook{
+	Name: "add-node",
+	Inputs: []Input{
+		{Name: "cluster", Required: true},
+		{Name: "node", Required: true},
+		{Name: "count", Type: TypeInt, Default: "1"},
+		{Name: "drain", Type: TypeBool},
+		{Name: "ratio", Type: TypeNumber},
+		{Name: "label"},
+	},
+	Steps: []Step{
+		{
+			SkillName:   "kubekey",
+			Action:      "add_nodes",
+			Description: "Add ${node} to ${cluster}",
+			Params: map[string]any{
+				"cluster": "${cluster}",
+				"count":   "${count}",
+				"drain":   "${drain}",
+				"ratio":   "${ratio}",
+				"config":  "config-${cluster}.yaml",
+				"nodes":   []any{"${node}", "${label}"},
+				"label":   "${label}",
+				"note":    "label ${label}.",
+			},
+		},
+		{SkillName: "kubekey", Action: "scale", Description: "${count}"},
+		{SkillName: "kubekey", Action: "drain", Description: "${drain}"},
+		{SkillName: "kubekey", Action: "label", Description: "${label}"},
+	},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       map[string]string
+		params       map[string]any
+		descriptions []string
+	}{
+		{
+			name:   "typed inputs",
+			values: map[string]string{"cluster": "prod-a", "node": "node4", "count": "3", "drain": "true", "ratio": "0.5", "label": "gpu"},
+			params: map[string]any{
+				"cluster": "prod-a",
+				"count":   3,
+				"drain":   true,
+				"ratio":   0.5,
+				"config":  "config-prod-a.yaml",
+				"nodes":   []any{"node4", "gpu"},
+				"label":   "gpu",
+				"note":    "label gpu.",
+			},
+			descriptions: []string{"Add node4 to prod-a", "3", "true", "gpu"},
+		},
+		{
+			name:   "unset optional inputs and defaults",
+			values: map[string]string{"cluster": "prod-a", "node": "node4"},
+			params: map[string]any{
+				"cluster": "prod-a",
+				"count":   1,
+				"config":  "config-prod-a.yaml",
+				"nodes":   []any{"node4"},
+				"note":    "label .",
+			},
+			descriptions: []string{"Add node4 to prod-a", "1", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := addNode.Render(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Runbook != addNode.Name {
+				t.Errorf("runbook = %q, want %q", plan.Runbook, addNode.Name)
+			}
+			if !plan.Fixed || plan.Replan {
+				t.Errorf("fixed = %v, replan = %v, want a fixed plan not replanned", plan.Fixed, plan.Replan)
+			}
+			if got := plan.Steps[0].Params; !reflect.DeepEqual(got, tt.params) {
+				t.Errorf("params = %#v, want %#v", got, tt.params)
+			}
+			for i, want := range tt.descriptions {
+				if got := plan.Steps[i].Description; got != want {
+					t.Errorf("step %d description = %q, want %q", i+1, got, want)
+				}
+				if plan.Steps[i].ID != i+1 {
+					t.Errorf("step %d has id %d", i+1, plan.Steps[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderRejectsInputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+	}{
+		{"required input missing", map[string]string{"cluster": "prod-a"}},
+		{"invalid int", map[string]string{"cluster": "prod-a", "node": "node4", "count": "three"}},
+		{"invalid bool", map[string]string{"cluster": "prod-a", "node": "node4", "drain": "maybe"}},
+		{"invalid number", map[string]string{"cluster": "prod-a", "node": "node4", "ratio": "half"}},
+		{"unknown input", map[string]string{"cluster": "prod-a", "node": "node4", "zone": "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := addNode.Render(tt.values); err == nil {
+				t.Error("Render succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	step := Step{SkillName: "kubekey", Action: "add_nodes"}
+	tests := []struct {
+		name    string
+		runbook Runbook
+		valid   bool
+	}{
+		{"valid", *addNode, true},
+		{"invalid name", Runbook{Name: "Add Node", Steps: []Step{step}}, false},
+		{"no steps", Runbook{Name: "add-node"}, false},
+		{"unknown type", Runbook{Name: "add-node", Inputs: []Input{{Name: "n", Type: "list"}}, Steps: []Step{step}}, false},
+		{"invalid default", Runbook{Name: "add-node", Inputs: []Input{{Name: "n", Type: TypeInt, Default: "x"}}, Steps: []Step{step}}, false},
+		{"duplicate input", Runbook{Name: "add-node", Inputs: []Input{{Name: "n"}, {Name: "n"}}, Steps: []Step{step}}, false},
+		{"undeclared reference", Runbook{Name: "add-node", Steps: []Step{{SkillName: "kubekey", Action: "add_nodes", Params: map[string]any{"nodes": []any{"${node}"}}}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.runbook.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Package schedule runs tasks on cron schedules, e.g. nightly config drift checks of
+// clusters or weekly certificate expiry checks. A schedule either plans its query at
+// each run or runs a fixed plan as is.
+//
+// Schedules and the history of their runs are kept in a JSON file, so they survive
+// restarts.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/state"
+	"github.com/robfig/cron/v3"
+)
+
+// What a run does when the previous run of its schedule is still running
+const (
+	ConcurrencySkip    = "skip"    // The run is skipped
+	ConcurrencyQueue   = "queue"   // The run starts once the previous runs finished
+	ConcurrencyReplace = "replace" // The previous run is cancelled
+)
+
+// Statuses of runs besides the status of their task once it ended
+const (
+	RunQueued      = "queued"      // Waiting for the previous run to finish
+	RunRunning     = "running"     // Its task is running
+	RunSkipped     = "skipped"     // Skipped, the previous run was still running
+	RunReplaced    = "replaced"    // Cancelled by the next run
+	RunInterrupted = "interrupted" // Running or queued when the server stopped
+)
+
+// Schedule runs a task on a cron expression
+type Schedule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Cron is a standard 5 field expression or a descriptor such as @daily
+	Cron string `json:"cron"`
+	// Timezone of Cron, an IANA name; local time when empty
+	Timezone string `json:"timezone,omitempty"`
+	// Query is planned at each run; with a fixed plan it only describes the task
+	Query string `json:"query,omitempty"`
+	// Plan is run as is instead of planning the query
+	Plan        *state.Plan       `json:"plan,omitempty"`
+	Mode        string            `json:"mode,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Concurrency string            `json:"concurrency"`
+	Requester   string            `json:"requester,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	// Runs are the latest runs, oldest first
+	Runs []*Run `json:"runs,omitempty"`
+
+	// NextRun is when the schedule runs next, set by List
+	NextRun time.Time `json:"-"`
+}
+
+// Run is one run of a schedule
+type Run struct {
+	// TaskID is the task of the run, empty for skipped runs
+	TaskID      string `json:"task_id,omitempty"`
+	ScheduledAt string `json:"scheduled_at"`
+	StartedAt   string `json:"started_at,omitempty"`
+	FinishedAt  string `json:"finished_at,omitempty"`
+	// Status is one of the Run statuses, or the status of the task once it ended:
+	// completed, failed, pending_approval or scheduled
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// validate checks a new schedule, sets its defaults and returns its parsed cron
+// expression
+func (s *Schedule) validate() (cron.Schedule, error) {
+	if strings.TrimSpace(s.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if s.Query == "" && s.Plan == nil {
+		return nil, fmt.Errorf("query or plan is required")
+	}
+	if s.Plan != nil {
+		if len(s.Plan.Steps) == 0 {
+			return nil, fmt.Errorf("plan has no steps")
+		}
+		for i, step := range s.Plan.Steps {
+			if step == nil || step.SkillName == "" || step.Action == "" {
+				return nil, fmt.Errorf("plan step %d: skill_name and action are required", i+1)
+			}
+			if step.ID == 0 {
+				step.ID = i + 1
+			}
+		}
+	}
+	if !state.ValidMode(s.Mode) {
+		return nil, fmt.Errorf("unknown mode %q: use execute, plan_only or dry_run", s.Mode)
+	}
+	if s.Mode == "" {
+		s.Mode = state.ModeExecute
+	}
+	switch s.Concurrency {
+	case "":
+		s.Concurrency = ConcurrencySkip
+	case ConcurrencySkip, ConcurrencyQueue, ConcurrencyReplace:
+	default:
+		return nil, fmt.Errorf("unknown concurrency %q: use skip, queue or replace", s.Concurrency)
+	}
+	return parse(s.Cron, s.Timezone)
+}
+
+// parse parses a cron expression in timezone
+func parse(expr, timezone string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("cron is required")
+	}
+	if timezone != "" {
+		if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+			return nil, fmt.Errorf("set the timezone either in cron or in timezone")
+		}
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		expr = "CRON_TZ=" + timezone + " " + expr
+	}
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron: %w", err)
+	}
+	return sched, nil
+}
+
+// clone copies the schedule and its runs
+func (s *Schedule) clone() *Schedule {
+	c := *s
+	c.Runs = make([]*Run, len(s.Runs))
+	for i, run := range s.Runs {
+		r := *run
+		c.Runs[i] = &r
+	}
+	return &c
+}
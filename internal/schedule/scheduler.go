@@ -0,0 +1,314 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hb-chen/opskills/pkg/logger"
+	"github.com/robfig/cron/v3"
+)
+
+// maxQueued bounds the runs queued behind a running one, later runs are skipped
+const maxQueued = 10
+
+// ErrNotFound is returned for unknown schedules
+var ErrNotFound = errors.New("schedule not found")
+
+// Runner runs the task of a run until it ends, held for approval or a maintenance
+// window included, and returns the status of the task. ctx is cancelled when the
+// next run replaces it.
+type Runner func(ctx context.Context, taskID string, s *Schedule) (string, error)
+
+// Scheduler fires the runs of schedules and keeps their history
+type Scheduler struct {
+	file    string
+	history int // Runs kept per schedule
+	runner  Runner
+	cron    *cron.Cron
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	schedule *Schedule
+	cronID   cron.EntryID
+	parsed   cron.Schedule
+	active   *activeRun
+	queue    []*Run
+}
+
+type activeRun struct {
+	run    *Run
+	cancel context.CancelFunc
+}
+
+// New creates a scheduler keeping its schedules in file, with the latest history
+// runs of each. Schedules already in the file are loaded, runs left running or
+// queued by the previous server are marked interrupted.
+func New(file string, history int, runner Runner) (*Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		file:    file,
+		history: history,
+		runner:  runner,
+		cron:    cron.New(),
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: make(map[string]*entry),
+	}
+
+	schedules, err := s.load()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, sched := range schedules {
+		parsed, err := parse(sched.Cron, sched.Timezone)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("schedule %s (%s): %w", sched.ID, sched.Name, err)
+		}
+		for _, run := range sched.Runs {
+			if run.Status == RunRunning || run.Status == RunQueued {
+				run.Status = RunInterrupted
+				run.Error = "the server stopped before the run ended"
+			}
+		}
+		s.add(sched, parsed)
+	}
+	return s, nil
+}
+
+// Start starts firing runs
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops firing runs and cancels the running ones
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+	s.cancel()
+}
+
+// Create validates a schedule, sets its defaults and ID, and starts firing its runs
+func (s *Scheduler) Create(sched *Schedule) (*Schedule, error) {
+	parsed, err := sched.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.schedule.Name == sched.Name {
+			return nil, fmt.Errorf("a schedule named %q already exists", sched.Name)
+		}
+	}
+	sched.ID = uuid.New().String()
+	sched.CreatedAt = time.Now().Format(time.RFC3339)
+	sched.Runs = nil
+	s.add(sched, parsed)
+	s.save()
+
+	created := sched.clone()
+	created.NextRun = parsed.Next(time.Now())
+	return created, nil
+}
+
+// List returns copies of the schedules, oldest first
+func (s *Scheduler) List() []*Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	schedules := make([]*Schedule, 0, len(s.entries))
+	for _, e := range s.entries {
+		sched := e.schedule.clone()
+		sched.NextRun = e.parsed.Next(now)
+		schedules = append(schedules, sched)
+	}
+	sortSchedules(schedules)
+	return schedules
+}
+
+// Delete stops firing the runs of a schedule and drops its queued runs. Its running
+// task, if any, is left to end.
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.cron.Remove(e.cronID)
+	e.queue = nil
+	delete(s.entries, id)
+	s.save()
+	return nil
+}
+
+// add registers a schedule with cron, the lock must be held once started
+func (s *Scheduler) add(sched *Schedule, parsed cron.Schedule) {
+	e := &entry{schedule: sched, parsed: parsed}
+	e.cronID = s.cron.Schedule(parsed, cron.FuncJob(func() { s.fire(e) }))
+	s.entries[sched.ID] = e
+}
+
+// fire starts a run of e, or applies its concurrency policy when the previous run
+// is still running
+func (s *Scheduler) fire(e *entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[e.schedule.ID]; !ok {
+		return
+	}
+	run := &Run{ScheduledAt: time.Now().Format(time.RFC3339)}
+
+	active := e.active
+	if active != nil {
+		switch e.schedule.Concurrency {
+		case ConcurrencyQueue:
+			if len(e.queue) < maxQueued {
+				run.Status = RunQueued
+				e.queue = append(e.queue, run)
+				s.record(e, run)
+				return
+			}
+			run.Status = RunSkipped
+			run.Error = fmt.Sprintf("%d runs are already queued", len(e.queue))
+			s.record(e, run)
+			return
+		case ConcurrencyReplace:
+			logger.Infof("Schedule %s: cancelling task %s, replaced by the next run", e.schedule.Name, active.run.TaskID)
+			active.cancel()
+			e.active = nil
+		default:
+			run.Status = RunSkipped
+			run.Error = fmt.Sprintf("task %s of the previous run is still running", active.run.TaskID)
+			s.record(e, run)
+			return
+		}
+	}
+
+	s.start(e, run)
+	if active != nil {
+		active.run.Status = RunReplaced
+		active.run.Error = "cancelled, replaced by task " + run.TaskID
+	}
+	s.record(e, run)
+}
+
+// start runs the task of run, the lock must be held
+func (s *Scheduler) start(e *entry, run *Run) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	active := &activeRun{run: run, cancel: cancel}
+	e.active = active
+	run.TaskID = uuid.New().String()
+	run.StartedAt = time.Now().Format(time.RFC3339)
+	run.Status = RunRunning
+	sched := e.schedule.clone()
+
+	logger.Infof("Schedule %s: running task %s", sched.Name, run.TaskID)
+	go func() {
+		status, err := s.runner(ctx, run.TaskID, sched)
+		s.finish(e, active, status, err)
+	}()
+}
+
+// finish records the end of a run and starts the next queued one
+func (s *Scheduler) finish(e *entry, active *activeRun, status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active.cancel()
+
+	run := active.run
+	run.FinishedAt = time.Now().Format(time.RFC3339)
+	if run.Status != RunReplaced {
+		run.Status = status
+		if err != nil {
+			run.Error = err.Error()
+		}
+		if run.Status == "" {
+			run.Status = "failed"
+		}
+	}
+
+	if e.active == active {
+		e.active = nil
+		if len(e.queue) > 0 {
+			next := e.queue[0]
+			e.queue = e.queue[1:]
+			s.start(e, next)
+		}
+	}
+	s.save()
+}
+
+// record adds run to the history of e and saves the schedules, the lock must be held
+func (s *Scheduler) record(e *entry, run *Run) {
+	e.schedule.Runs = append(e.schedule.Runs, run)
+	if s.history > 0 && len(e.schedule.Runs) > s.history {
+		e.schedule.Runs = e.schedule.Runs[len(e.schedule.Runs)-s.history:]
+	}
+	s.save()
+}
+
+// load reads the schedules file, a missing file has no schedules
+func (s *Scheduler) load() ([]*Schedule, error) {
+	data, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var schedules []*Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// save writes the schedules file, the lock must be held. Failures are logged, the
+// schedules keep running from memory.
+func (s *Scheduler) save() {
+	schedules := make([]*Schedule, 0, len(s.entries))
+	for _, e := range s.entries {
+		schedules = append(schedules, e.schedule)
+	}
+	sortSchedules(schedules)
+
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.file), 0755)
+	}
+	if err == nil {
+		tmp := s.file + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, s.file)
+		}
+	}
+	if err != nil {
+		logger.Errorf("Failed to save schedules to %s: %v", s.file, err)
+	}
+}
+
+func sortSchedules(schedules []*Schedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].CreatedAt != schedules[j].CreatedAt {
+			return schedules[i].CreatedAt < schedules[j].CreatedAt
+		}
+		return schedules[i].Name < schedules[j].Name
+	})
+}
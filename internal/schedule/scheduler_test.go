@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency string
+		releases    int // Runs to let end
+		want        []string
+	}{
+		{"skip", ConcurrencySkip, 1, []string{"completed", RunSkipped}},
+		{"queue", ConcurrencyQueue, 2, []string{"completed", "completed"}},
+		{"replace", ConcurrencyReplace, 1, []string{RunReplaced, "completed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Runs end when released, or fail when cancelled. Each run has its own
+			// release, so a cancelled run never takes the release of the next one.
+			type testRun struct {
+				ctx     context.Context
+				release chan struct{}
+			}
+			runs := make(chan testRun, maxQueued+2)
+			runner := func(ctx context.Context, taskID string, s *Schedule) (string, error) {
+				release := make(chan struct{})
+				runs <- testRun{ctx: ctx, release: release}
+				select {
+				case <-release:
+					return "completed", nil
+				case <-ctx.Done():
+					return "failed", ctx.Err()
+				}
+			}
+			// releaseNext ends the next run started and not cancelled
+			releaseNext := func() {
+				for run := range runs {
+					if run.ctx.Err() == nil {
+						close(run.release)
+						return
+					}
+				}
+			}
+			s, err := New(filepath.Join(t.TempDir(), "schedules.json"), 10, runner)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Stop()
+
+			sched, err := s.Create(&Schedule{Name: "drift", Cron: "@yearly", Query: "check drift", Concurrency: tt.concurrency})
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := s.entries[sched.ID]
+
+			// The second run fires while the first one is running
+			s.fire(e)
+			s.fire(e)
+			for range tt.releases {
+				releaseNext()
+			}
+
+			got := waitForRuns(t, s, sched.ID)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("runs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueueIsBounded(t *testing.T) {
+	release := make(chan struct{})
+	runner := func(ctx context.Context, taskID string, s *Schedule) (string, error) {
+		select {
+		case <-release:
+			return "completed", nil
+		case <-ctx.Done():
+			return "failed", ctx.Err()
+		}
+	}
+	s, err := New(filepath.Join(t.TempDir(), "schedules.json"), 100, runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sched, err := s.Create(&Schedule{Name: "drift", Cron: "@yearly", Query: "check drift", Concurrency: ConcurrencyQueue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := s.entries[sched.ID]
+	for range maxQueued + 2 {
+		s.fire(e)
+	}
+
+	got := runStatuses(s, sched.ID)
+	want := []string{RunRunning}
+	for range maxQueued {
+		want = append(want, RunQueued)
+	}
+	want = append(want, RunSkipped)
+	if !slices.Equal(got, want) {
+		t.Errorf("runs = %q, want %q", got, want)
+	}
+
+	// Stopping cancels the running run, which starts the queued ones
+	s.Stop()
+	waitForRuns(t, s, sched.ID)
+}
+
+func TestLoadInterruptsRuns(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "schedules.json")
+	block := func(ctx context.Context, taskID string, s *Schedule) (string, error) {
+		<-ctx.Done()
+		return "failed", ctx.Err()
+	}
+	s, err := New(file, 10, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched, err := s.Create(&Schedule{Name: "drift", Cron: "@yearly", Query: "check drift", Concurrency: ConcurrencyQueue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := s.entries[sched.ID]
+	s.fire(e)
+	s.fire(e)
+
+	// A new server finds the runs left running or queued by the previous one
+	restarted, err := New(file, 10, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := runStatuses(restarted, sched.ID)
+	want := []string{RunInterrupted, RunInterrupted}
+	if !slices.Equal(got, want) {
+		t.Errorf("runs = %q, want %q", got, want)
+	}
+	s.Stop()
+	waitForRuns(t, s, sched.ID)
+}
+
+// runStatuses returns the statuses of the runs of a schedule, oldest first
+func runStatuses(s *Scheduler, id string) []string {
+	for _, sched := range s.List() {
+		if sched.ID != id {
+			continue
+		}
+		statuses := make([]string, len(sched.Runs))
+		for i, run := range sched.Runs {
+			statuses[i] = run.Status
+		}
+		return statuses
+	}
+	return nil
+}
+
+// waitForRuns waits until no run of a schedule is running or queued
+func waitForRuns(t *testing.T, s *Scheduler, id string) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		statuses := runStatuses(s, id)
+		if !slices.Contains(statuses, RunRunning) && !slices.Contains(statuses, RunQueued) {
+			return statuses
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("runs still running: %q", statuses)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
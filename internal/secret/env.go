@@ -0,0 +1,65 @@
+package secret
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// DefaultEnvPrefix prefixes the environment variables read by EnvProvider
+const DefaultEnvPrefix = "OPSKILLS_SECRET_"
+
+// EnvProvider reads secrets from environment variables of the agent. The secret
+// ssh/prod-root is read from OPSKILLS_SECRET_SSH_PROD_ROOT, its field password
+// from OPSKILLS_SECRET_SSH_PROD_ROOT__PASSWORD.
+//
+// Scripts do not inherit these variables (see the sandbox env allowlist).
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider creates an env provider, an empty prefix uses DefaultEnvPrefix
+func NewEnvProvider(prefix string) *EnvProvider {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	return &EnvProvider{prefix: prefix}
+}
+
+// Name returns the provider name
+func (p *EnvProvider) Name() string {
+	return "env"
+}
+
+// Get returns the fields of a secret
+func (p *EnvProvider) Get(ctx context.Context, path string) (map[string]string, error) {
+	name := p.prefix + envName(path)
+	fields := make(map[string]string)
+	if v, ok := os.LookupEnv(name); ok {
+		fields[DefaultField] = v
+	}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if field, ok := strings.CutPrefix(k, name+"__"); ok && field != "" {
+			fields[strings.ToLower(field)] = v
+		}
+	}
+	if len(fields) == 0 {
+		return nil, ErrNotFound
+	}
+	return fields, nil
+}
+
+// envName converts a secret path to an environment variable name
+func envName(path string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, path)
+}
@@ -0,0 +1,252 @@
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeySize is the size in bytes of the key of encrypted secret files (AES-256)
+const KeySize = 32
+
+// encrypted matches the values of encrypted secret files
+var encrypted = regexp.MustCompile(`^ENC\[AES256_GCM,data:([A-Za-z0-9+/=]*),iv:([A-Za-z0-9+/=]+),tag:([A-Za-z0-9+/=]+)\]$`)
+
+// FileProvider reads secrets from a YAML file whose values are encrypted one by
+// one, sops style, so the file can be committed and diffed:
+//
+//	ssh/prod-root: ENC[AES256_GCM,data:...,iv:...,tag:...]
+//	registry/harbor:
+//	  username: ENC[AES256_GCM,data:...,iv:...,tag:...]
+//	  password: ENC[AES256_GCM,data:...,iv:...,tag:...]
+//
+// Values are encrypted with AES-256-GCM; the path and field are authenticated, so
+// values cannot be moved between secrets. The file is read on every lookup.
+type FileProvider struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex // Serializes Set
+}
+
+// NewFileProvider creates a provider for the secret file at path, encrypted with key
+func NewFileProvider(path string, key []byte) (*FileProvider, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secret file key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileProvider{path: path, aead: aead}, nil
+}
+
+// Name returns the provider name
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// Get returns the fields of a secret
+func (p *FileProvider) Get(ctx context.Context, path string) (map[string]string, error) {
+	secrets, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := secrets[path]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	fields := make(map[string]string)
+	switch raw := raw.(type) {
+	case string:
+		value, err := p.decrypt(raw, path, DefaultField)
+		if err != nil {
+			return nil, err
+		}
+		fields[DefaultField] = value
+	case map[string]interface{}:
+		for field, v := range raw {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("field %s of secret %s is not encrypted", field, path)
+			}
+			value, err := p.decrypt(s, path, field)
+			if err != nil {
+				return nil, err
+			}
+			fields[field] = value
+		}
+	default:
+		return nil, fmt.Errorf("secret %s is neither a value nor a map of fields", path)
+	}
+	return fields, nil
+}
+
+// Set encrypts and stores a field of a secret, creating the file if needed.
+// An empty field sets the secret to a single value.
+func (p *FileProvider) Set(path, field, value string) error {
+	ref := Ref{Path: path, Field: field}
+	if err := ref.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	secrets, err := p.read()
+	if err != nil {
+		return err
+	}
+	if field == "" {
+		secrets[path] = p.encrypt(value, path, DefaultField)
+	} else {
+		fields, ok := secrets[path].(map[string]interface{})
+		if !ok {
+			fields = make(map[string]interface{})
+			// Keep a single value as the default field
+			if s, ok := secrets[path].(string); ok {
+				fields[DefaultField] = s
+			}
+		}
+		fields[field] = p.encrypt(value, path, field)
+		secrets[path] = fields
+	}
+
+	data, err := yaml.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+	return nil
+}
+
+// List returns the references of the secrets in the file, without decrypting them
+func (p *FileProvider) List() ([]Ref, error) {
+	secrets, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for path, raw := range secrets {
+		if fields, ok := raw.(map[string]interface{}); ok {
+			for field := range fields {
+				refs = append(refs, Ref{Path: path, Field: field})
+			}
+		} else {
+			refs = append(refs, Ref{Path: path})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+	return refs, nil
+}
+
+// read parses the secret file, a missing file has no secrets
+func (p *FileProvider) read() (map[string]interface{}, error) {
+	secrets := make(map[string]interface{})
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid secret file %s: %w", p.path, err)
+	}
+	if secrets == nil {
+		secrets = make(map[string]interface{})
+	}
+	return secrets, nil
+}
+
+// encrypt returns the encrypted form of a value
+func (p *FileProvider) encrypt(value, path, field string) string {
+	iv := make([]byte, p.aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	sealed := p.aead.Seal(nil, iv, []byte(value), additionalData(path, field))
+	data, tag := sealed[:len(sealed)-p.aead.Overhead()], sealed[len(sealed)-p.aead.Overhead():]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s]",
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag))
+}
+
+// decrypt returns the value of an encrypted field
+func (p *FileProvider) decrypt(s, path, field string) (string, error) {
+	m := encrypted.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", fmt.Errorf("field %s of secret %s is not encrypted", field, path)
+	}
+	data, err1 := base64.StdEncoding.DecodeString(m[1])
+	iv, err2 := base64.StdEncoding.DecodeString(m[2])
+	tag, err3 := base64.StdEncoding.DecodeString(m[3])
+	if err1 != nil || err2 != nil || err3 != nil || len(iv) != p.aead.NonceSize() {
+		return "", fmt.Errorf("field %s of secret %s is malformed", field, path)
+	}
+	value, err := p.aead.Open(nil, iv, append(data, tag...), additionalData(path, field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field %s of secret %s: wrong key or tampered value", field, path)
+	}
+	return string(value), nil
+}
+
+// additionalData binds an encrypted value to its secret and field
+func additionalData(path, field string) []byte {
+	return []byte(path + "#" + field)
+}
+
+// GenerateKey returns a new random key, base64 encoded
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// LoadKey reads a base64 key from the environment variable keyEnv, or else from keyFile
+func LoadKey(keyFile, keyEnv string) ([]byte, error) {
+	encoded := ""
+	if keyEnv != "" {
+		encoded = os.Getenv(keyEnv)
+	}
+	if encoded == "" && keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret key: %w", err)
+		}
+		encoded = string(data)
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("no secret key: set %s or create the key file (opskills-agent secret keygen)", keyEnv)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,151 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// flipFirst flips the first character of the base64 part named part of an encrypted value
+func flipFirst(value, part string) string {
+	i := strings.Index(value, part+":") + len(part) + 1
+	flipped := byte('A')
+	if value[i] == 'A' {
+		flipped = 'B'
+	}
+	return value[:i] + string(flipped) + value[i+1:]
+}
+
+func TestFileProviderRejectsTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, KeySize)
+	otherKey := bytes.Repeat([]byte{2}, KeySize)
+
+	tests := []struct {
+		name    string
+		key     []byte
+		edit    func(secrets map[string]interface{})
+		wantErr string
+	}{
+		{
+			name: "untouched",
+			key:  key,
+		},
+		{
+			name:    "wrong key",
+			key:     otherKey,
+			wantErr: "wrong key or tampered value",
+		},
+		{
+			name: "tampered data",
+			key:  key,
+			edit: func(secrets map[string]interface{}) {
+				secrets["ssh/prod-root"] = flipFirst(secrets["ssh/prod-root"].(string), "data")
+			},
+			wantErr: "wrong key or tampered value",
+		},
+		{
+			name: "tampered tag",
+			key:  key,
+			edit: func(secrets map[string]interface{}) {
+				secrets["ssh/prod-root"] = flipFirst(secrets["ssh/prod-root"].(string), "tag")
+			},
+			wantErr: "wrong key or tampered value",
+		},
+		{
+			name: "value moved from another secret",
+			key:  key,
+			edit: func(secrets map[string]interface{}) {
+				secrets["ssh/prod-root"] = secrets["ssh/staging-root"]
+			},
+			wantErr: "wrong key or tampered value",
+		},
+		{
+			name: "value moved from another field",
+			key:  key,
+			edit: func(secrets map[string]interface{}) {
+				secrets["ssh/prod-root"] = secrets["registry/harbor"].(map[string]interface{})["password"]
+			},
+			wantErr: "wrong key or tampered value",
+		},
+		{
+			name: "truncated iv",
+			key:  key,
+			edit: func(secrets map[string]interface{}) {
+				value := secrets["ssh/prod-root"].(string)
+				i := strings.Index(value, "iv:") + 3
+				j := strings.Index(value, ",tag:")
+				secrets["ssh/prod-root"] = value[:i] + base64.StdEncoding.EncodeToString([]byte("short")) + value[j:]
+			},
+			wantErr: "malformed",
+		},
+		{
+			name: "plain text",
+			key:  key,
+			edit: func(secrets map[string]interface{}) {
+				secrets["ssh/prod-root"] = "hunter2"
+			},
+			wantErr: "not encrypted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "secrets.yaml")
+			writer, err := NewFileProvider(path, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range []struct{ path, field, value string }{
+				{"ssh/prod-root", "", "prod-password"},
+				{"ssh/staging-root", "", "staging-password"},
+				{"registry/harbor", "password", "harbor-password"},
+			} {
+				if err := writer.Set(s.path, s.field, s.value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.edit != nil {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				secrets := make(map[string]interface{})
+				if err := yaml.Unmarshal(data, &secrets); err != nil {
+					t.Fatal(err)
+				}
+				tt.edit(secrets)
+				if data, err = yaml.Marshal(secrets); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, data, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reader, err := NewFileProvider(path, tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields, err := reader.Get(context.Background(), "ssh/prod-root")
+			if tt.wantErr == "" {
+				if err != nil || fields[DefaultField] != "prod-password" {
+					t.Fatalf("Get() = %v, %v, want the value", fields, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Get() = %v, %v, want an error with %q", fields, err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), "prod-password") || strings.Contains(err.Error(), "hunter2") {
+				t.Errorf("error %q holds the value", err)
+			}
+		})
+	}
+}
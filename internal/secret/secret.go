@@ -0,0 +1,218 @@
+// Package secret resolves secret references in skill parameters.
+//
+// A parameter value "secret://ssh/prod-root" references the secret at path
+// ssh/prod-root, "secret://registry/harbor#password" one field of it. References
+// stay in plans, state and prompts; they are replaced by their values only when
+// a step is executed.
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scheme prefixes secret references
+const Scheme = "secret://"
+
+// DefaultField is the field of a secret selected by references without a #field
+const DefaultField = "value"
+
+// ErrNotFound is returned by providers that do not have a secret
+var ErrNotFound = errors.New("secret not found")
+
+// SecretProvider reads secrets from a secret store
+type SecretProvider interface {
+	// Name returns the provider name, used in logs and errors
+	Name() string
+	// Get returns the fields of the secret at path, ErrNotFound if the provider does not have it
+	Get(ctx context.Context, path string) (map[string]string, error)
+}
+
+// validPath matches secret paths: segments of letters, digits, '.', '_' and '-' separated by '/'
+var validPath = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// Ref is a parsed secret reference
+type Ref struct {
+	Path  string
+	Field string // Empty selects DefaultField, or the only field of the secret
+}
+
+// ParseRef parses a secret reference, false if s is not one
+func ParseRef(s string) (Ref, bool) {
+	rest, ok := strings.CutPrefix(s, Scheme)
+	if !ok {
+		return Ref{}, false
+	}
+	path, field, _ := strings.Cut(rest, "#")
+	return Ref{Path: path, Field: field}, true
+}
+
+// String returns the reference in secret://path#field form
+func (r Ref) String() string {
+	if r.Field == "" {
+		return Scheme + r.Path
+	}
+	return Scheme + r.Path + "#" + r.Field
+}
+
+// Validate checks the path of a reference
+func (r Ref) Validate() error {
+	if !validPath.MatchString(r.Path) || strings.Contains("/"+r.Path+"/", "/../") || strings.Contains("/"+r.Path+"/", "/./") {
+		return fmt.Errorf("invalid secret reference %s", r)
+	}
+	return nil
+}
+
+// Resolver looks secrets up in a chain of providers, the first one having a secret wins.
+// A nil *Resolver is valid and fails on every reference.
+type Resolver struct {
+	providers []SecretProvider
+}
+
+// NewResolver creates a resolver querying providers in order
+func NewResolver(providers ...SecretProvider) *Resolver {
+	return &Resolver{providers: providers}
+}
+
+// Providers returns the names of the providers, in lookup order
+func (r *Resolver) Providers() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, len(r.providers))
+	for i, p := range r.providers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// Lookup returns the value of a reference
+func (r *Resolver) Lookup(ctx context.Context, ref Ref) (string, error) {
+	if err := ref.Validate(); err != nil {
+		return "", err
+	}
+	if r == nil || len(r.providers) == 0 {
+		return "", fmt.Errorf("cannot resolve %s: no secret provider is configured", ref)
+	}
+
+	for _, p := range r.providers {
+		fields, err := p.Get(ctx, ref.Path)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s from %s: %w", ref, p.Name(), err)
+		}
+		return selectField(ref, fields, p.Name())
+	}
+	return "", fmt.Errorf("secret %s not found", ref)
+}
+
+// selectField returns the field of a secret selected by ref
+func selectField(ref Ref, fields map[string]string, provider string) (string, error) {
+	field := ref.Field
+	if field == "" {
+		if len(fields) == 1 {
+			for _, v := range fields {
+				return v, nil
+			}
+		}
+		field = DefaultField
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s of %s has no field %s", ref.Path, provider, field)
+	}
+	return v, nil
+}
+
+// Resolve returns a copy of params in which every secret reference, including those
+// nested in maps and lists, is replaced by its value, and the values resolved.
+// params is returned unchanged when it has no references.
+func (r *Resolver) Resolve(ctx context.Context, params map[string]interface{}) (map[string]interface{}, []string, error) {
+	if !hasRefs(params) {
+		return params, nil, nil
+	}
+	var values []string
+	resolved, err := r.resolveValue(ctx, params, &values)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolved.(map[string]interface{}), values, nil
+}
+
+func (r *Resolver) resolveValue(ctx context.Context, v interface{}, values *[]string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		ref, ok := ParseRef(v)
+		if !ok {
+			return v, nil
+		}
+		value, err := r.Lookup(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		*values = append(*values, value)
+		return value, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			resolved, err := r.resolveValue(ctx, item, values)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := r.resolveValue(ctx, item, values)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// RefParams returns the names of the params whose values contain secret
+// references, sorted
+func RefParams(params map[string]interface{}) []string {
+	var names []string
+	for name, v := range params {
+		if hasRefs(v) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hasRefs reports whether a value contains secret references
+func hasRefs(v interface{}) bool {
+	switch v := v.(type) {
+	case string:
+		return strings.HasPrefix(v, Scheme)
+	case map[string]interface{}:
+		for _, item := range v {
+			if hasRefs(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasRefs(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
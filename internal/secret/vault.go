@@ -0,0 +1,128 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultOptions configures a VaultProvider
+type VaultOptions struct {
+	Addr      string // e.g. https://vault.example.com:8200
+	Token     string
+	Mount     string // KV engine mount, defaults to "secret"
+	KVVersion int    // 1 or 2, defaults to 2
+	Namespace string // Enterprise namespace, optional
+	Timeout   time.Duration
+}
+
+// VaultProvider reads secrets from the KV engine of a HashiCorp Vault compatible
+// HTTP API (Vault, OpenBao or a local stand-in). Every key of a KV secret is a field.
+type VaultProvider struct {
+	opts   VaultOptions
+	client *http.Client
+}
+
+// NewVaultProvider creates a Vault provider
+func NewVaultProvider(opts VaultOptions) (*VaultProvider, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if _, err := url.Parse(opts.Addr); err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.KVVersion == 0 {
+		opts.KVVersion = 2
+	}
+	if opts.KVVersion != 1 && opts.KVVersion != 2 {
+		return nil, fmt.Errorf("unsupported vault KV version %d", opts.KVVersion)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	opts.Addr = strings.TrimSuffix(opts.Addr, "/")
+	opts.Mount = strings.Trim(opts.Mount, "/")
+	return &VaultProvider{opts: opts, client: &http.Client{Timeout: opts.Timeout}}, nil
+}
+
+// Name returns the provider name
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+// Get returns the fields of a secret
+func (p *VaultProvider) Get(ctx context.Context, path string) (map[string]string, error) {
+	endpoint := fmt.Sprintf("%s/v1/%s/%s", p.opts.Addr, p.opts.Mount, path)
+	if p.opts.KVVersion == 2 {
+		endpoint = fmt.Sprintf("%s/v1/%s/data/%s", p.opts.Addr, p.opts.Mount, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.Token != "" {
+		req.Header.Set("X-Vault-Token", p.opts.Token)
+	}
+	if p.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.opts.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && len(errResp.Errors) > 0 {
+			return nil, fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(errResp.Errors, "; "))
+		}
+		return nil, fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+
+	var kv struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &kv); err != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := kv.Data
+	if p.opts.KVVersion == 2 {
+		// KV v2 nests the secret in data.data; a deleted version has data: null
+		nested, _ := data["data"].(map[string]interface{})
+		if nested == nil {
+			return nil, ErrNotFound
+		}
+		data = nested
+	}
+
+	fields := make(map[string]string, len(data))
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			fields[k] = s
+		} else {
+			encoded, _ := json.Marshal(v)
+			fields[k] = string(encoded)
+		}
+	}
+	return fields, nil
+}
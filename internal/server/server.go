@@ -26,9 +26,8 @@ import (
 var WebFS embed.FS
 
 // Serve starts both HTTP and gRPC servers
-func Serve(ctx context.Context, cfg *config.Config, pipeline *agent.Pipeline) error {
-	// Create gRPC service
-	grpcService := api.NewService(pipeline)
+func Serve(ctx context.Context, cfg *config.Config, grpcService *api.Service) error {
+	pipeline := grpcService.Pipeline()
 
 	wg := &sync.WaitGroup{}
 
@@ -95,6 +94,7 @@ func runHTTP(ctx context.Context, httpAddr string, pipeline *agent.Pipeline, grp
 	// Create gateway with error handler
 	gw := gateway.New(
 		runtime.WithErrorHandler(httpErrorHandler),
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
 	)
 
 	// Register gRPC service handlers via gateway (in-process, no gRPC connection needed)
@@ -216,6 +216,15 @@ func httpErrorHandler(_ context.Context, _ *runtime.ServeMux, marshaler runtime.
 	runtime.DefaultHTTPErrorHandler(context.Background(), nil, marshaler, w, r, err)
 }
 
+// headerMatcher forwards the requester header, set by the authenticating proxy,
+// to the service as gRPC metadata
+func headerMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, api.RequesterHeader) {
+		return api.RequesterHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 // loggerWrapper wraps the logger package functions to implement the Logger interface
 type loggerWrapper struct{}
 
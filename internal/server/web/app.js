@@ -1,6 +1,7 @@
 document.addEventListener('DOMContentLoaded', () => {
     const queryInput = document.getElementById('queryInput');
     const sendBtn = document.getElementById('sendBtn');
+    const modeSelect = document.getElementById('modeSelect');
     const messagesContainer = document.getElementById('messages');
     const resultTab = document.getElementById('resultTab');
     const resultContent = document.getElementById('resultContent');
@@ -66,7 +67,7 @@ document.addEventListener('DOMContentLoaded', () => {
 
         try {
             // Start SSE connection
-            const eventSource = new EventSource(`/api/run?query=${encodeURIComponent(query)}`);
+            const eventSource = new EventSource(`/api/run?query=${encodeURIComponent(query)}&mode=${encodeURIComponent(modeSelect.value)}`);
 
             eventSource.onmessage = async (event) => {
                 const data = JSON.parse(event.data);
@@ -83,6 +84,17 @@ document.addEventListener('DOMContentLoaded', () => {
                     logEntry.textContent = data.message;
                     logsContainer.appendChild(logEntry);
                     logsContainer.scrollTop = logsContainer.scrollHeight;
+                } else if (data.type === 'output') {
+                    // Output line of a running step
+                    const time = new Date(data.time).toLocaleTimeString();
+                    const logEntry = document.createElement('div');
+                    logEntry.className = 'log-entry';
+                    if (data.stream === 'stderr') {
+                        logEntry.style.color = '#c0392b';
+                    }
+                    logEntry.textContent = `${time} [步骤 ${data.step_id}] ${data.text}`;
+                    logsContainer.appendChild(logEntry);
+                    logsContainer.scrollTop = logsContainer.scrollHeight;
                 } else if (data.type === 'result') {
                     // Final result
                     displayResult(data);
@@ -131,6 +143,37 @@ document.addEventListener('DOMContentLoaded', () => {
                 html += `<p><strong>任务 ID:</strong> ${state.task_id}</p>`;
             }
 
+            // Simulated tasks changed nothing
+            if (state.mode === 'plan_only') {
+                html += '<p class="simulated-banner">🧪 仅计划：未执行任何步骤，没有产生任何变更</p>';
+            } else if (state.mode === 'dry_run') {
+                html += '<p class="simulated-banner">🧪 模拟执行：结果均为模拟，没有产生任何变更</p>';
+            }
+
+            // Plans held by a policy rule wait for ApproveTask
+            if (state.approval && state.approval.status === 'pending') {
+                html += `<p class="approval-banner">✋ 需要审批：${escapeHtml(state.approval.reason || '')}<br>
+                    <code>POST /api/v1/tasks/${escapeHtml(state.task_id || '')}/approve</code></p>`;
+            }
+
+            // Mutating steps wait for a maintenance window
+            if (state.maintenance && state.maintenance.status === 'scheduled') {
+                html += `<p class="approval-banner">🕒 已排期：${escapeHtml(state.maintenance.reason || '')}</p>`;
+            } else if (state.maintenance && state.maintenance.status === 'rejected') {
+                html += `<p class="approval-banner">⛔ 维护窗口外被拒绝：${escapeHtml(state.maintenance.reason || '')}</p>`;
+            }
+
+            // Policy decisions
+            const decisions = (state.policy_decisions || []).filter(d => d.effect !== 'allow');
+            if (decisions.length > 0) {
+                html += '<h2>策略决策</h2><ul>';
+                decisions.forEach(d => {
+                    const target = d.step_id ? `步骤 ${d.step_id}` : '计划';
+                    html += `<li><strong>${escapeHtml(d.rule_id || '')}</strong> (${d.effect}, ${target}): ${escapeHtml(d.reason || '')}</li>`;
+                });
+                html += '</ul>';
+            }
+
             // Plan
             if (state.plan && state.plan.steps) {
                 html += '<h2>执行计划</h2><ul>';
@@ -145,8 +188,14 @@ document.addEventListener('DOMContentLoaded', () => {
                 html += '<h2>执行结果</h2>';
                 state.results.forEach((result, idx) => {
                     const status = result.success ? '✅' : '❌';
+                    let badge = '';
+                    if (result.skipped) {
+                        badge = ' <span class="badge badge-skipped">已跳过</span>';
+                    } else if (result.simulated) {
+                        badge = ' <span class="badge badge-simulated">模拟</span>';
+                    }
                     html += `<div style="margin: 20px 0; padding: 15px; border: 1px solid #ddd; border-radius: 8px;">
-                        <h3>${status} 步骤 ${result.step_id || idx + 1}</h3>`;
+                        <h3>${status} 步骤 ${result.step_id || idx + 1}${badge}</h3>`;
                     if (result.output) {
                         html += `<pre style="background: #f5f5f5; padding: 10px; border-radius: 4px; overflow-x: auto;">${escapeHtml(result.output)}</pre>`;
                     }
@@ -166,6 +215,9 @@ document.addEventListener('DOMContentLoaded', () => {
                 if (state.final_result.output) {
                     html += `<pre style="background: #f5f5f5; padding: 10px; border-radius: 4px; overflow-x: auto;">${escapeHtml(state.final_result.output)}</pre>`;
                 }
+                if (state.final_result.error) {
+                    html += `<p style="color: red;">错误: ${escapeHtml(state.final_result.error)}</p>`;
+                }
             }
 
             html += '</div>';
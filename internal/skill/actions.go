@@ -0,0 +1,208 @@
+package skill
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hb-chen/opskills/internal/retry"
+	"gopkg.in/yaml.v3"
+)
+
+// ActionsFile optionally declares the actions of a skill, next to SKILL.md
+const ActionsFile = "actions.yaml"
+
+// Action is an operation a skill can perform, usually backed by a script
+type Action struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description,omitempty"`
+	Usage       string        `yaml:"usage,omitempty"`
+	Script      string        `yaml:"script,omitempty"` // File in scripts/, defaults to <name>.sh
+	Params      []ActionParam `yaml:"params,omitempty"`
+
+	// Artifacts are glob patterns, relative to the task workspace, of the files the
+	// action produces (e.g. "*.kubeconfig"); scripts can also list files in $SKILL_ARTIFACTS
+	Artifacts []string `yaml:"artifacts,omitempty"`
+
+	// Retry is the policy applied to failed executions, plan steps can override it
+	Retry *retry.Policy `yaml:"retry,omitempty"`
+	// Idempotent set to false forbids automatic retries, whatever the policy
+	Idempotent *bool `yaml:"idempotent,omitempty"`
+
+	// Compensate is the action undoing a completed execution when the task fails
+	Compensate *Compensation `yaml:"compensate,omitempty"`
+
+	// DryRun declares that the action honours SKILL_DRY_RUN=1 (scripts), the dry_run
+	// argument (MCP) or DryRunFrom (native skills) and then changes nothing
+	DryRun bool `yaml:"dry_run,omitempty"`
+
+	// ReadOnly declares that the action changes nothing, it may run outside
+	// maintenance windows and during change freezes
+	ReadOnly bool `yaml:"read_only,omitempty"`
+}
+
+// Retryable reports whether the action may be retried automatically
+func (a *Action) Retryable() bool {
+	return a.Idempotent == nil || *a.Idempotent
+}
+
+// ActionParam describes a parameter of an action
+type ActionParam struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"` // string, int, bool or file
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// actionsFile is the layout of actions.yaml
+type actionsFile struct {
+	Actions []Action `yaml:"actions"`
+}
+
+// Action returns the action with the given name
+func (s *Skill) Action(name string) (*Action, bool) {
+	for i := range s.Actions {
+		if s.Actions[i].Name == name {
+			return &s.Actions[i], true
+		}
+	}
+	return nil, false
+}
+
+// LoadActions discovers the actions of a skill from its scripts and merges the
+// declarations of actions.yaml, which take precedence
+func LoadActions(basePath, scriptsPath string) ([]Action, error) {
+	byName := make(map[string]*Action)
+
+	entries, err := os.ReadDir(scriptsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read scripts directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if !IsScript(filepath.Join(scriptsPath, entry.Name()), nil) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		description, usage := scriptHeader(filepath.Join(scriptsPath, entry.Name()))
+		byName[name] = &Action{
+			Name:        name,
+			Description: description,
+			Usage:       usage,
+			Script:      entry.Name(),
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(basePath, ActionsFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", ActionsFile, err)
+	}
+	if err == nil {
+		var declared actionsFile
+		if err := yaml.Unmarshal(data, &declared); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ActionsFile, err)
+		}
+		for _, action := range declared.Actions {
+			if action.Name == "" {
+				return nil, fmt.Errorf("invalid %s: action name cannot be empty", ActionsFile)
+			}
+			existing, ok := byName[action.Name]
+			if !ok {
+				a := action
+				byName[action.Name] = &a
+				continue
+			}
+			mergeAction(existing, action)
+		}
+	}
+
+	actions := make([]Action, 0, len(byName))
+	for _, action := range byName {
+		actions = append(actions, *action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Name < actions[j].Name })
+
+	return actions, nil
+}
+
+// mergeAction overrides the discovered fields of an action with the declared ones
+func mergeAction(discovered *Action, declared Action) {
+	if declared.Description != "" {
+		discovered.Description = declared.Description
+	}
+	if declared.Usage != "" {
+		discovered.Usage = declared.Usage
+	}
+	if declared.Script != "" {
+		discovered.Script = declared.Script
+	}
+	if declared.Params != nil {
+		discovered.Params = declared.Params
+	}
+	if declared.Artifacts != nil {
+		discovered.Artifacts = declared.Artifacts
+	}
+	if declared.Retry != nil {
+		discovered.Retry = declared.Retry
+	}
+	if declared.Idempotent != nil {
+		discovered.Idempotent = declared.Idempotent
+	}
+	if declared.Compensate != nil {
+		discovered.Compensate = declared.Compensate
+	}
+	if declared.DryRun {
+		discovered.DryRun = true
+	}
+	if declared.ReadOnly {
+		discovered.ReadOnly = true
+	}
+}
+
+// scriptHeader reads the description (first comment line) and the "Usage:" line
+// from the comment block at the top of a script ("#" comments, or "//" for Go and JavaScript)
+func scriptHeader(path string) (string, string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	var description, usage string
+	scanner := bufio.NewScanner(f)
+	for lineNo := 0; scanner.Scan() && lineNo < 20; lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineNo == 0 && strings.HasPrefix(line, "#!") {
+			continue
+		}
+		if line == "" {
+			if description != "" {
+				break
+			}
+			continue
+		}
+		var text string
+		switch {
+		case strings.HasPrefix(line, "#"):
+			text = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		case strings.HasPrefix(line, "//"):
+			text = strings.TrimSpace(strings.TrimPrefix(line, "//"))
+		default:
+			return description, usage
+		}
+
+		switch {
+		case strings.HasPrefix(text, "Usage:"):
+			usage = strings.TrimSpace(strings.TrimPrefix(text, "Usage:"))
+		case description == "" && text != "":
+			description = text
+		}
+	}
+
+	return description, usage
+}
@@ -0,0 +1,66 @@
+package skill
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// paramRef matches the references to the parameters of the compensated step
+var paramRef = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// Compensation is the action of the same skill undoing a completed execution of an
+// action, e.g. delete_node for add_nodes. The rollback of a failed task runs the
+// compensations of its completed steps in reverse order.
+type Compensation struct {
+	Action string `yaml:"action"`
+	// Params of the compensating action, ${name} is replaced by the value of the
+	// parameter name of the compensated step
+	Params map[string]string `yaml:"params,omitempty"`
+}
+
+// References returns the names of the parameters of the compensated step used by the compensation
+func (c *Compensation) References() []string {
+	var names []string
+	for _, value := range c.Params {
+		for _, m := range paramRef.FindAllStringSubmatch(value, -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Resolve returns the params executing the compensation of a step executed with params
+func (c *Compensation) Resolve(params ExecutionParams) (ExecutionParams, error) {
+	resolved := ExecutionParams{"action": c.Action}
+	var missing []string
+	for key, value := range c.Params {
+		resolved[key] = paramRef.ReplaceAllStringFunc(value, func(ref string) string {
+			name := paramRef.FindStringSubmatch(ref)[1]
+			v, ok := params[name]
+			if !ok {
+				missing = append(missing, name)
+				return ""
+			}
+			return fmt.Sprintf("%v", v)
+		})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("parameters %v of the compensated step are not set", missing)
+	}
+	return resolved, nil
+}
+
+// Compensation returns the params executing the compensation of a skill execution,
+// nil when its action declares no compensation
+func (r *Router) Compensation(skillName string, params ExecutionParams) (ExecutionParams, error) {
+	s, err := r.registry.Get(skillName)
+	if err != nil {
+		return nil, err
+	}
+	actionName, _ := params["action"].(string)
+	action, ok := s.Action(actionName)
+	if !ok || action.Compensate == nil {
+		return nil, nil
+	}
+	return action.Compensate.Resolve(params)
+}
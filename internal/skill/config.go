@@ -1,8 +1,13 @@
 package skill
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,6 +19,7 @@ const (
 	ExecutionModeDirect ExecutionMode = "direct"
 	ExecutionModeMCP    ExecutionMode = "mcp"
 	ExecutionModeAuto   ExecutionMode = "auto"
+	ExecutionModeNative ExecutionMode = "native" // In-process Go skill, see NativeSkill
 )
 
 // SkillConfig represents configuration for a skill
@@ -21,6 +27,10 @@ type SkillConfig struct {
 	Name          string        `yaml:"name"`
 	ExecutionMode ExecutionMode `yaml:"execution_mode"`
 	MCPServer     string        `yaml:"mcp_server,omitempty"` // MCP server name if using MCP
+	Version       string        `yaml:"version,omitempty"`    // Pinned version, empty for the highest installed version
+
+	// Interpreters by script extension, e.g. ".py": "python3.11", ".go": "go run"
+	Interpreters map[string]string `yaml:"interpreters,omitempty"`
 }
 
 // Config represents the skills configuration
@@ -38,27 +48,210 @@ type MCPServerConfig struct {
 	Env     map[string]string `yaml:"env,omitempty"`
 }
 
-// LoadConfig loads skill configuration from a file
+// LoadConfig loads skill configuration from a file.
+// ${VAR} and ${VAR:-default} in values are replaced by environment variables.
 func LoadConfig(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := interpolate(&doc); err != nil {
+		return nil, fmt.Errorf("failed to expand config file: %w", err)
+	}
+
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := doc.Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	return &config, nil
 }
 
+// LoadConfigFiles loads and merges the config files matching patterns (file paths or
+// globs), in order, and returns the merged config and the files read.
+// Patterns matching no file are skipped.
+func LoadConfigFiles(patterns ...string) (*Config, []string, error) {
+	config := GetDefaultConfig()
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid config pattern %s: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		for _, path := range matches {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			file, err := LoadConfig(path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", path, err)
+			}
+			config.Merge(file)
+			files = append(files, path)
+		}
+	}
+	return config, files, nil
+}
+
+// Merge merges other into c. Skills are merged field by field, the fields set in
+// other win; MCP servers of other replace those of the same name.
+func (c *Config) Merge(other *Config) {
+	if c.Skills == nil {
+		c.Skills = make(map[string]SkillConfig)
+	}
+	if c.MCPServers == nil {
+		c.MCPServers = make(map[string]MCPServerConfig)
+	}
+	for name, sc := range other.Skills {
+		merged := c.Skills[name]
+		if sc.Name != "" {
+			merged.Name = sc.Name
+		}
+		if sc.ExecutionMode != "" {
+			merged.ExecutionMode = sc.ExecutionMode
+		}
+		if sc.MCPServer != "" {
+			merged.MCPServer = sc.MCPServer
+		}
+		if sc.Version != "" {
+			merged.Version = sc.Version
+		}
+		if len(sc.Interpreters) > 0 {
+			if merged.Interpreters == nil {
+				merged.Interpreters = make(map[string]string)
+			}
+			for ext, interpreter := range sc.Interpreters {
+				merged.Interpreters[ext] = interpreter
+			}
+		}
+		c.Skills[name] = merged
+	}
+	for name, server := range other.MCPServers {
+		c.MCPServers[name] = server
+	}
+}
+
+// Validate checks the execution modes of the skills and the MCP servers they use
+func (c *Config) Validate() error {
+	var errs []error
+	for _, name := range sortedKeys(c.Skills) {
+		sc := c.Skills[name]
+		switch sc.ExecutionMode {
+		case "", ExecutionModeDirect:
+			if sc.MCPServer != "" {
+				if _, ok := c.MCPServers[sc.MCPServer]; !ok {
+					errs = append(errs, fmt.Errorf("skill %s: unknown MCP server %s", name, sc.MCPServer))
+				}
+			}
+		case ExecutionModeMCP, ExecutionModeAuto:
+			if sc.MCPServer == "" {
+				errs = append(errs, fmt.Errorf("skill %s: execution mode %s needs an mcp_server", name, sc.ExecutionMode))
+			} else if _, ok := c.MCPServers[sc.MCPServer]; !ok {
+				errs = append(errs, fmt.Errorf("skill %s: unknown MCP server %s", name, sc.MCPServer))
+			}
+		case ExecutionModeNative:
+			errs = append(errs, fmt.Errorf("skill %s: the native execution mode is reserved for built-in Go skills", name))
+		default:
+			errs = append(errs, fmt.Errorf("skill %s: unknown execution mode %q (direct, mcp or auto)", name, sc.ExecutionMode))
+		}
+	}
+	for _, name := range sortedKeys(c.MCPServers) {
+		server := c.MCPServers[name]
+		switch server.Type {
+		case "stdio":
+			if server.Command == "" {
+				errs = append(errs, fmt.Errorf("MCP server %s: command is required", name))
+			}
+		case "http", "sse":
+			errs = append(errs, fmt.Errorf("MCP server %s: type %s is not supported yet, only stdio", name, server.Type))
+		default:
+			errs = append(errs, fmt.Errorf("MCP server %s: unknown type %q", name, server.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// envVar matches ${VAR} and ${VAR:-default}
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// interpolate replaces environment variable references in the scalar values of a YAML document
+func interpolate(node *yaml.Node) error {
+	var missing []string
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode {
+			n.Value = envVar.ReplaceAllStringFunc(n.Value, func(ref string) string {
+				m := envVar.FindStringSubmatch(ref)
+				v, ok := os.LookupEnv(m[1])
+				if !strings.Contains(ref, ":-") {
+					if !ok {
+						missing = append(missing, m[1])
+					}
+					return v
+				}
+				if v == "" {
+					return m[2] // Default when unset or empty
+				}
+				return v
+			})
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(node)
+	if len(missing) > 0 {
+		return fmt.Errorf("environment variables not set: %v (use ${VAR:-default} for optional ones)", missing)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // GetSkillConfig gets configuration for a specific skill
 func (c *Config) GetSkillConfig(skillName string) (SkillConfig, bool) {
 	config, exists := c.Skills[skillName]
 	return config, exists
 }
 
+// Pins returns the pinned version of every skill that has one
+func (c *Config) Pins() map[string]string {
+	pins := make(map[string]string)
+	for name, skillConfig := range c.Skills {
+		if skillConfig.Version != "" {
+			pins[name] = skillConfig.Version
+		}
+	}
+	return pins
+}
+
+// Interpreters returns the interpreter overrides of every skill that has some
+func (c *Config) Interpreters() map[string]map[string]string {
+	interpreters := make(map[string]map[string]string)
+	for name, skillConfig := range c.Skills {
+		if len(skillConfig.Interpreters) > 0 {
+			interpreters[name] = skillConfig.Interpreters
+		}
+	}
+	return interpreters
+}
+
 // GetDefaultConfig returns a default configuration
 func GetDefaultConfig() *Config {
 	return &Config{
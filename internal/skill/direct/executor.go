@@ -1,17 +1,26 @@
 package direct
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/hb-chen/opskills/internal/skill"
 )
 
+// manifestPrefix names the artifact lists in the workspace, which are never artifacts
+const manifestPrefix = ".skill-artifacts-"
+
 // DirectExecutor executes skills directly by running their scripts
 type DirectExecutor struct {
-	runner *ScriptRunner
+	runner       *ScriptRunner
+	env          map[string]string            // Extra environment for every script
+	interpreters map[string]map[string]string // Skill name -> extension -> interpreter command
 }
 
 // NewDirectExecutor creates a new direct executor
@@ -21,14 +30,60 @@ func NewDirectExecutor(timeout time.Duration) *DirectExecutor {
 	}
 }
 
+// SetEnv sets extra environment variables for every script (e.g. PATH)
+func (e *DirectExecutor) SetEnv(env map[string]string) {
+	e.env = env
+}
+
+// SetInterpreters sets per-skill interpreters by script extension (e.g. ".py": "python3.11"),
+// overriding shebangs and skill.DefaultInterpreters
+func (e *DirectExecutor) SetInterpreters(interpreters map[string]map[string]string) {
+	e.interpreters = interpreters
+}
+
+// SetWorkDir sets the working directory of scripts, by default their own directory
+func (e *DirectExecutor) SetWorkDir(dir string) {
+	e.runner.SetWorkDir(dir)
+}
+
+// SetStdin sets the standard input passed to scripts
+func (e *DirectExecutor) SetStdin(stdin string) {
+	e.runner.SetStdin(stdin)
+}
+
+// SetSandbox sets the restrictions of scripts: environment allowlist, resource limits,
+// user, namespaces and output size
+func (e *DirectExecutor) SetSandbox(sandbox Sandbox) error {
+	return e.runner.SetSandbox(sandbox)
+}
+
 // Execute executes a skill with the given parameters
 func (e *DirectExecutor) Execute(s *skill.Skill, params skill.ExecutionParams) (*skill.ExecutionResult, error) {
+	return e.ExecuteContext(context.Background(), s, params)
+}
+
+// ExecuteContext executes a skill, killing its script when ctx is done.
+// Output lines are streamed to the handler of ctx, see skill.WithOutputHandler.
+func (e *DirectExecutor) ExecuteContext(ctx context.Context, s *skill.Skill, params skill.ExecutionParams) (*skill.ExecutionResult, error) {
 	startTime := time.Now()
 
 	// Determine which script to run
 	// For now, we'll look for a script matching the skill name or use a default
 	scriptPath, err := e.findScript(s, params)
 	if err != nil {
+		err = skill.Unavailable(err)
+		return &skill.ExecutionResult{
+			Success:   false,
+			Error:     err.Error(),
+			ExitCode:  -1,
+			Duration:  time.Since(startTime),
+			Timestamp: time.Now(),
+		}, err
+	}
+
+	command, err := skill.ScriptCommand(scriptPath, e.interpreters[s.Name])
+	if err != nil {
+		err = skill.Unavailable(err)
 		return &skill.ExecutionResult{
 			Success:   false,
 			Error:     err.Error(),
@@ -39,10 +94,36 @@ func (e *DirectExecutor) Execute(s *skill.Skill, params skill.ExecutionParams) (
 	}
 
 	// Convert params to script arguments and environment variables
-	args, env := e.prepareExecution(s, params)
+	args, env := e.prepareExecution(s, params, skill.SecretParamsFrom(ctx))
+
+	// Scripts of a task run in its workspace and list the files they produce in $SKILL_ARTIFACTS
+	workspace := skill.WorkspaceFrom(ctx)
+	var manifest string
+	if workspace != "" {
+		// The list is kept in the workspace, which sandboxed scripts can reach, and
+		// both belong to the user scripts run as
+		f, err := os.CreateTemp(workspace, manifestPrefix+"*")
+		if err != nil {
+			return nil, skill.Unavailable(fmt.Errorf("failed to create artifact list: %w", err))
+		}
+		f.Close()
+		manifest = f.Name()
+		defer os.Remove(manifest)
+		for _, path := range []string{workspace, manifest} {
+			if err := e.runner.sandbox.own(path); err != nil {
+				return nil, skill.Unavailable(err)
+			}
+		}
+		env["SKILL_WORKSPACE"] = workspace
+		env["SKILL_ARTIFACTS"] = manifest
+	}
+	// Scripts declaring dry_run support change nothing when it is set
+	if skill.DryRunFrom(ctx) {
+		env[skill.DryRunEnv] = "1"
+	}
 
 	// Run the script
-	stdout, stderr, exitCode, err := e.runner.Run(scriptPath, args, env)
+	stdout, stderr, exitCode, err := e.runner.RunContext(ctx, command, args, env, skill.OutputHandlerFrom(ctx))
 
 	duration := time.Since(startTime)
 	result := &skill.ExecutionResult{
@@ -53,6 +134,10 @@ func (e *DirectExecutor) Execute(s *skill.Skill, params skill.ExecutionParams) (
 		Duration:  duration,
 		Timestamp: time.Now(),
 	}
+	if workspace != "" {
+		// Failed steps keep their artifacts too, e.g. logs to investigate
+		result.Artifacts = e.findArtifacts(s, params, workspace, manifest)
+	}
 
 	if err != nil {
 		result.Error = fmt.Sprintf("%s: %s", err.Error(), stderr)
@@ -67,6 +152,75 @@ func (e *DirectExecutor) Execute(s *skill.Skill, params skill.ExecutionParams) (
 	return result, nil
 }
 
+// CanExecute checks, without running anything, that a script and its interpreter exist
+// for the action of params
+func (e *DirectExecutor) CanExecute(s *skill.Skill, params skill.ExecutionParams) error {
+	scriptPath, err := e.findScript(s, params)
+	if err != nil {
+		return err
+	}
+	// findScript falls back to the main or first script, which does not implement another action
+	if action, _ := params["action"].(string); action != "" && params["script"] == nil {
+		name := filepath.Base(scriptPath)
+		declared, ok := s.Action(action)
+		if strings.TrimSuffix(name, filepath.Ext(name)) != action && (!ok || declared.Script != name) {
+			return fmt.Errorf("no script for action %s", action)
+		}
+	}
+	command, err := skill.ScriptCommand(scriptPath, e.interpreters[s.Name])
+	if err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return fmt.Errorf("interpreter of %s not found: %w", filepath.Base(scriptPath), err)
+	}
+	return nil
+}
+
+// findArtifacts returns the files of the workspace matching the artifact patterns of the
+// action and those listed by the script, one per line, relative to the workspace
+func (e *DirectExecutor) findArtifacts(s *skill.Skill, params skill.ExecutionParams, workspace, manifest string) []string {
+	var artifacts []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if filepath.IsAbs(path) {
+			rel, err := filepath.Rel(workspace, path)
+			if err != nil {
+				return
+			}
+			path = rel
+		}
+		path = filepath.ToSlash(filepath.Clean(path))
+		if strings.HasPrefix(path, manifestPrefix) {
+			return
+		}
+		if !seen[path] {
+			seen[path] = true
+			artifacts = append(artifacts, path)
+		}
+	}
+
+	if name, ok := params["action"].(string); ok {
+		if action, ok := s.Action(name); ok {
+			for _, pattern := range action.Artifacts {
+				matches, _ := filepath.Glob(filepath.Join(workspace, pattern))
+				for _, match := range matches {
+					add(match)
+				}
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(manifest); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				add(line)
+			}
+		}
+	}
+	return artifacts
+}
+
 // findScript finds the appropriate script to execute
 func (e *DirectExecutor) findScript(s *skill.Skill, params skill.ExecutionParams) (string, error) {
 	// Check if a specific script is requested
@@ -78,44 +232,69 @@ func (e *DirectExecutor) findScript(s *skill.Skill, params skill.ExecutionParams
 		return "", fmt.Errorf("script not found: %s", scriptPath)
 	}
 
+	scripts, err := e.listScripts(s)
+	if err != nil {
+		return "", err
+	}
+
 	// Check if action is specified (e.g., "create_cluster", "add_nodes")
 	if action, ok := params["action"].(string); ok {
-		scriptPath := filepath.Join(s.ScriptsPath, fmt.Sprintf("%s.sh", action))
-		if _, err := os.Stat(scriptPath); err == nil {
-			return scriptPath, nil
+		// Actions may name their script in actions.yaml
+		if declared, ok := s.Action(action); ok && declared.Script != "" {
+			scriptPath := filepath.Join(s.ScriptsPath, declared.Script)
+			if _, err := os.Stat(scriptPath); err == nil {
+				return scriptPath, nil
+			}
+		}
+		// Else a script of any type named after the action
+		for _, name := range scripts {
+			if strings.TrimSuffix(name, filepath.Ext(name)) == action {
+				return filepath.Join(s.ScriptsPath, name), nil
+			}
 		}
 	}
 
 	// Default: look for a main script or the first available script
-	scriptsDir := s.ScriptsPath
-	entries, err := os.ReadDir(scriptsDir)
-	if err != nil {
-		return "", fmt.Errorf("failed to read scripts directory: %w", err)
+	for _, name := range scripts {
+		if strings.TrimSuffix(name, filepath.Ext(name)) == "main" {
+			return filepath.Join(s.ScriptsPath, name), nil
+		}
+	}
+	if len(scripts) > 0 {
+		return filepath.Join(s.ScriptsPath, scripts[0]), nil
 	}
 
-	// Look for a main.sh or the first .sh file
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sh" {
-			if entry.Name() == "main.sh" {
-				return filepath.Join(scriptsDir, entry.Name()), nil
-			}
-		}
+	return "", fmt.Errorf("no script found in %s", s.ScriptsPath)
+}
+
+// listScripts returns the runnable files in the scripts directory of a skill, sorted by name
+func (e *DirectExecutor) listScripts(s *skill.Skill) ([]string, error) {
+	entries, err := os.ReadDir(s.ScriptsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scripts directory: %w", err)
 	}
 
-	// Return the first .sh file found
+	var scripts []string
 	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sh" {
-			return filepath.Join(scriptsDir, entry.Name()), nil
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if skill.IsScript(filepath.Join(s.ScriptsPath, entry.Name()), e.interpreters[s.Name]) {
+			scripts = append(scripts, entry.Name())
 		}
 	}
-
-	return "", fmt.Errorf("no script found in %s", scriptsDir)
+	return scripts, nil
 }
 
-// prepareExecution prepares arguments and environment variables for script execution
-func (e *DirectExecutor) prepareExecution(s *skill.Skill, params skill.ExecutionParams) ([]string, map[string]string) {
+// prepareExecution prepares arguments and environment variables for script execution.
+// Params holding secrets are only passed in the environment, any local user can
+// read the command line of a process.
+func (e *DirectExecutor) prepareExecution(s *skill.Skill, params skill.ExecutionParams, secretParams []string) ([]string, map[string]string) {
 	var args []string
-	env := make(map[string]string)
+	env := make(map[string]string, len(e.env))
+	for k, v := range e.env {
+		env[k] = v
+	}
 
 	// Set skill-specific environment variables
 	env["SKILL_NAME"] = s.Name
@@ -136,7 +315,7 @@ func (e *DirectExecutor) prepareExecution(s *skill.Skill, params skill.Execution
 		env[fmt.Sprintf("SKILL_PARAM_%s", key)] = valueStr
 
 		// Also add as argument if it's a simple value
-		if len(valueStr) > 0 && valueStr[0] != '-' {
+		if len(valueStr) > 0 && valueStr[0] != '-' && !slices.Contains(secretParams, key) {
 			args = append(args, fmt.Sprintf("--%s", key), valueStr)
 		}
 	}
@@ -0,0 +1,72 @@
+package direct
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+func TestArtifactManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox Sandbox
+		root    bool // Needs to run as root
+	}{
+		{"agent user", DefaultSandbox(), false},
+		{"run as another user", Sandbox{EnvAllowlist: DefaultEnvAllowlist, RunAs: "nobody"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.root && os.Getuid() != 0 {
+				t.Skip("switching users needs root")
+			}
+			dir := t.TempDir()
+			// The user scripts run as must reach the script and the workspace
+			for _, d := range []string{filepath.Dir(dir), dir} {
+				if err := os.Chmod(d, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			scripts := filepath.Join(dir, "skill", "scripts")
+			workspace := filepath.Join(dir, "workspace")
+			for _, d := range []string{scripts, workspace} {
+				if err := os.MkdirAll(d, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			script := "#!/bin/sh\necho ok > report.txt && echo report.txt >> \"$SKILL_ARTIFACTS\"\n"
+			if err := os.WriteFile(filepath.Join(scripts, "report.sh"), []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			e := NewDirectExecutor(time.Minute)
+			if err := e.SetSandbox(tt.sandbox); err != nil {
+				t.Fatal(err)
+			}
+			e.SetWorkDir(workspace)
+			s := &skill.Skill{Name: "reporter", ScriptsPath: scripts}
+			ctx := skill.WithWorkspace(context.Background(), workspace)
+			result, err := e.ExecuteContext(ctx, s, skill.ExecutionParams{"action": "report"})
+			if err != nil {
+				t.Fatalf("execute: %v (%s)", err, result.Error)
+			}
+			if want := []string{"report.txt"}; !slices.Equal(result.Artifacts, want) {
+				t.Errorf("artifacts = %q, want %q", result.Artifacts, want)
+			}
+
+			// The list is removed with the step
+			entries, _ := os.ReadDir(workspace)
+			for _, entry := range entries {
+				if entry.Name() != "report.txt" {
+					t.Errorf("workspace keeps %s", entry.Name())
+				}
+			}
+		})
+	}
+}
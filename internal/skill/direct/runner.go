@@ -2,60 +2,132 @@ package direct
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
 )
 
-// ScriptRunner runs bash scripts
+// ScriptRunner runs skill scripts with their interpreter.
+// Scripts run in their own process group with the restrictions of the sandbox,
+// and skill files are never modified.
 type ScriptRunner struct {
 	timeout time.Duration
+	workDir string // Working directory, defaults to the task workspace or the script's directory
+	stdin   string // Standard input, empty for none
+	sandbox Sandbox
 }
 
-// NewScriptRunner creates a new script runner
+// NewScriptRunner creates a new script runner with the default sandbox
 func NewScriptRunner(timeout time.Duration) *ScriptRunner {
 	return &ScriptRunner{
 		timeout: timeout,
+		sandbox: DefaultSandbox(),
 	}
 }
 
-// Run executes a bash script with the given arguments
-func (r *ScriptRunner) Run(scriptPath string, args []string, env map[string]string) (string, string, int, error) {
+// SetWorkDir sets the working directory of scripts
+func (r *ScriptRunner) SetWorkDir(dir string) {
+	r.workDir = dir
+}
+
+// SetStdin sets the standard input passed to scripts
+func (r *ScriptRunner) SetStdin(stdin string) {
+	r.stdin = stdin
+}
+
+// SetSandbox sets the restrictions of scripts
+func (r *ScriptRunner) SetSandbox(sandbox Sandbox) error {
+	if err := sandbox.Validate(); err != nil {
+		return err
+	}
+	r.sandbox = sandbox
+	return nil
+}
+
+// Run executes a script with the given arguments.
+// command is the interpreter command line ending with the script path, see skill.ScriptCommand.
+func (r *ScriptRunner) Run(command []string, args []string, env map[string]string) (string, string, int, error) {
+	return r.RunContext(context.Background(), command, args, env, nil)
+}
+
+// RunContext executes a script like Run, killing it when ctx is done.
+// Output lines are passed to onLine (if not nil) as they are written.
+// Errors before the script could start are skill.ErrUnavailable.
+func (r *ScriptRunner) RunContext(ctx context.Context, command []string, args []string, env map[string]string, onLine skill.OutputHandler) (string, string, int, error) {
+	if len(command) == 0 {
+		return "", "", -1, fmt.Errorf("empty script command")
+	}
+	scriptPath := command[len(command)-1]
+
 	// Check if script exists
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-		return "", "", -1, fmt.Errorf("script not found: %s", scriptPath)
+		return "", "", -1, skill.Unavailable(fmt.Errorf("script not found: %s", scriptPath))
 	}
 
-	// Make script executable
-	if err := os.Chmod(scriptPath, 0755); err != nil {
-		return "", "", -1, fmt.Errorf("failed to make script executable: %w", err)
+	// Scripts may run in another working directory
+	if abs, err := filepath.Abs(scriptPath); err == nil {
+		scriptPath = abs
 	}
 
-	// Create command
-	cmd := exec.Command("bash", append([]string{scriptPath}, args...)...)
+	// Create command, through the launcher when limits or mounts must be set up before the script runs
+	argv := append(append(append([]string{}, command[:len(command)-1]...), scriptPath), args...)
+	launcher := r.sandbox.needsLauncher()
+	if launcher {
+		argv = r.sandbox.launcher(argv)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	if err := r.sandbox.prepare(cmd); err != nil {
+		return "", "", -1, skill.Unavailable(err)
+	}
+	// Processes keeping the output open after the script exits do not block the runner
+	cmd.WaitDelay = 5 * time.Second
 
-	// Set working directory to script's directory
+	// Set working directory to the configured one, else the task workspace, else the script's directory
 	cmd.Dir = filepath.Dir(scriptPath)
+	if workspace := skill.WorkspaceFrom(ctx); workspace != "" {
+		cmd.Dir = workspace
+	}
+	if r.workDir != "" {
+		cmd.Dir = r.workDir
+	}
+	if r.stdin != "" {
+		cmd.Stdin = strings.NewReader(r.stdin)
+	}
 
-	// Set environment variables
-	if env != nil {
-		cmd.Env = os.Environ()
-		for k, v := range env {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	// Only allowlisted variables of the agent environment are passed
+	cmd.Env = r.sandbox.environ(env)
+
+	// Capture stdout and stderr, streaming lines as they come
+	capture := &outputCapture{limit: r.sandbox.MaxOutput, onLimit: func() { killGroup(cmd) }, lines: newLineStream(onLine)}
+	defer capture.Close()
+	stdoutLines := &lineWriter{buf: &capture.stdout, stream: skill.StreamStdout, capture: capture}
+	stderrLines := &lineWriter{buf: &capture.stderr, stream: skill.StreamStderr, capture: capture}
+	cmd.Stdout = stdoutLines
+	cmd.Stderr = stderrLines
+
+	var gate *os.File
+	if launcher {
+		gateReader, gateWriter, err := os.Pipe()
+		if err != nil {
+			return "", "", -1, skill.Unavailable(fmt.Errorf("failed to create launcher pipe: %w", err))
 		}
+		defer gateReader.Close()
+		defer gateWriter.Close()
+		cmd.ExtraFiles = []*os.File{gateReader}
+		gate = gateWriter
 	}
 
-	// Capture stdout and stderr
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
 	// Start command
 	startTime := time.Now()
 	if err := cmd.Start(); err != nil {
-		return "", "", -1, fmt.Errorf("failed to start script: %w", err)
+		return "", "", -1, skill.Unavailable(fmt.Errorf("failed to start script: %w", err))
 	}
 
 	// Wait for completion with timeout
@@ -64,22 +136,203 @@ func (r *ScriptRunner) Run(scriptPath string, args []string, env map[string]stri
 		done <- cmd.Wait()
 	}()
 
+	// The launcher execs the script once the limits are set
+	if gate != nil {
+		if err := r.sandbox.applyLimits(cmd.Process.Pid); err != nil {
+			killGroup(cmd)
+			<-done
+			return "", "", -1, skill.Unavailable(err)
+		}
+		gate.Write([]byte("\n"))
+		gate.Close()
+	}
+
 	select {
 	case err := <-done:
 		_ = time.Since(startTime) // duration captured but not used in this simplified version
+		stdoutLines.Flush()
+		stderrLines.Flush()
+		stdout, stderr := capture.String()
+		if capture.Exceeded() {
+			return stdout, stderr, -1, fmt.Errorf("script output exceeded %d bytes and was killed", capture.limit)
+		}
 		exitCode := 0
 		if err != nil {
 			if exitError, ok := err.(*exec.ExitError); ok {
 				exitCode = exitError.ExitCode()
 			} else {
-				return stdout.String(), stderr.String(), -1, fmt.Errorf("script execution error: %w", err)
+				return stdout, stderr, -1, fmt.Errorf("script execution error: %w", err)
 			}
 		}
 
-		return stdout.String(), stderr.String(), exitCode, nil
+		return stdout, stderr, exitCode, nil
 	case <-time.After(r.timeout):
-		// Kill the process on timeout
-		cmd.Process.Kill()
-		return "", "", -1, fmt.Errorf("script execution timeout after %v", r.timeout)
+		// Kill the script and its children on timeout
+		killGroup(cmd)
+		<-done
+		// The last lines before the kill are streamed too
+		stdoutLines.Flush()
+		stderrLines.Flush()
+		stdout, stderr := capture.String()
+		return stdout, stderr, -1, fmt.Errorf("script execution timeout after %v", r.timeout)
+	case <-ctx.Done():
+		killGroup(cmd)
+		<-done
+		stdoutLines.Flush()
+		stderrLines.Flush()
+		stdout, stderr := capture.String()
+		return stdout, stderr, -1, fmt.Errorf("script execution cancelled: %w", ctx.Err())
+	}
+}
+
+// outputCapture buffers the output of a script and enforces its size limit.
+// Its lock is shared by the stream writers, so lines of stdout and stderr keep their order.
+type outputCapture struct {
+	mu       sync.Mutex
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	limit    int64 // 0 for no limit
+	total    int64
+	exceeded bool
+	onLimit  func()
+	lines    *lineStream // nil without output handler
+}
+
+// String returns the captured stdout and stderr
+func (c *outputCapture) String() (string, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stdout.String(), c.stderr.String()
+}
+
+// Exceeded reports whether the script wrote more than the limit
+func (c *outputCapture) Exceeded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.exceeded
+}
+
+// Close stops streaming lines and waits until the handler got the queued ones
+func (c *outputCapture) Close() {
+	if c.lines == nil {
+		return
 	}
+	c.mu.Lock()
+	if !c.lines.closed {
+		c.lines.closed = true
+		close(c.lines.queue)
+	}
+	c.mu.Unlock()
+	<-c.lines.done
+}
+
+// lineStreamBuffer is the number of lines queued for a slow output handler
+const lineStreamBuffer = 1024
+
+// lineStream passes output lines to a handler in order, from its own goroutine: a
+// slow handler (e.g. an SSE client) never blocks the pipes of the script. Lines
+// beyond the buffer are dropped from the stream, the captured output keeps them.
+// Its fields are guarded by the lock of the output capture.
+type lineStream struct {
+	queue   chan skill.OutputLine
+	done    chan struct{}
+	dropped int // Lines dropped since the last queued one
+	closed  bool
+}
+
+// newLineStream starts streaming lines to onLine, nil if onLine is
+func newLineStream(onLine skill.OutputHandler) *lineStream {
+	if onLine == nil {
+		return nil
+	}
+	s := &lineStream{
+		queue: make(chan skill.OutputLine, lineStreamBuffer),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for line := range s.queue {
+			onLine(line)
+		}
+	}()
+	return s
+}
+
+// send queues a line without blocking, the lock of the output capture must be held
+func (s *lineStream) send(line skill.OutputLine) {
+	if s.closed {
+		return
+	}
+	if s.dropped > 0 && len(s.queue) < cap(s.queue)-1 {
+		s.queue <- skill.OutputLine{
+			Time:   line.Time,
+			Stream: line.Stream,
+			Text:   fmt.Sprintf("[%d lines not streamed, the output handler is behind]", s.dropped),
+		}
+		s.dropped = 0
+	}
+	select {
+	case s.queue <- line:
+	default:
+		s.dropped++
+	}
+}
+
+// lineWriter captures the output of a stream and streams each complete line
+type lineWriter struct {
+	buf     *bytes.Buffer
+	stream  string
+	capture *outputCapture
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	c := w.capture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.exceeded {
+		// Drain the pipe until the killed script exits
+		return len(p), nil
+	}
+	n := int64(len(p))
+	if c.limit > 0 && c.total+n > c.limit {
+		c.exceeded = true
+		c.onLimit()
+		return len(p), nil
+	}
+	c.total += n
+	w.buf.Write(p)
+	if c.lines == nil {
+		return len(p), nil
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush passes the last line if it has no trailing newline
+func (w *lineWriter) Flush() {
+	w.capture.mu.Lock()
+	defer w.capture.mu.Unlock()
+	if w.capture.lines != nil && len(w.partial) > 0 {
+		w.emit(w.partial)
+		w.partial = nil
+	}
+}
+
+// emit queues a line, the lock of the output capture must be held
+func (w *lineWriter) emit(line []byte) {
+	w.capture.lines.send(skill.OutputLine{
+		Time:   time.Now(),
+		Stream: w.stream,
+		Text:   strings.TrimSuffix(string(line), "\r"),
+	})
 }
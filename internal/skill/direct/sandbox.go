@@ -0,0 +1,161 @@
+package direct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hb-chen/opskills/internal/config"
+)
+
+// DefaultMaxOutput is the default limit of stdout and stderr of a script together, in bytes
+const DefaultMaxOutput = 16 << 20
+
+// DefaultEnvAllowlist is the agent environment passed to scripts by default.
+// Everything else (e.g. the LLM API key) is dropped.
+var DefaultEnvAllowlist = []string{"PATH", "HOME", "USER", "LANG", "LC_*", "TZ", "TMPDIR", "TERM"}
+
+// Sandbox restricts what scripts can see and use
+type Sandbox struct {
+	// EnvAllowlist names the agent environment variables passed to scripts, "PREFIX*" matches a prefix.
+	// Variables set by the executor (SKILL_*, SetEnv) are always passed.
+	EnvAllowlist []string
+
+	// Limits are the resource limits of each script process
+	Limits Limits
+
+	// RunAs is the user (name or uid) scripts run as, empty for the agent's user.
+	// The agent must run as root to switch users.
+	RunAs string
+
+	// Namespaces runs scripts in new user and mount namespaces, as root inside and the
+	// agent's user outside, with ReadOnlyPaths mounted read-only
+	Namespaces    bool
+	ReadOnlyPaths []string
+
+	// MaxOutput is the limit of stdout and stderr together in bytes, 0 for none.
+	// Scripts writing more are killed.
+	MaxOutput int64
+}
+
+// Limits are resource limits (rlimits) applied to script processes, 0 for no limit
+type Limits struct {
+	CPUSeconds uint64 // CPU time of each process
+	MemoryMB   uint64 // Address space of each process
+	OpenFiles  uint64 // Open file descriptors of each process
+	Processes  uint64 // Processes of the user running the script
+}
+
+// IsSet reports whether any limit is set
+func (l Limits) IsSet() bool {
+	return l.CPUSeconds > 0 || l.MemoryMB > 0 || l.OpenFiles > 0 || l.Processes > 0
+}
+
+// DefaultSandbox returns the sandbox of new runners: the default env allowlist and output limit
+func DefaultSandbox() Sandbox {
+	return Sandbox{
+		EnvAllowlist: DefaultEnvAllowlist,
+		MaxOutput:    DefaultMaxOutput,
+	}
+}
+
+// NewSandbox returns the sandbox configured by cfg for the scripts of the skills in
+// skillsDir, which is read-only in namespaces
+func NewSandbox(cfg config.Sandbox, skillsDir string) (Sandbox, error) {
+	sandbox := Sandbox{
+		EnvAllowlist: cfg.EnvAllowlist,
+		Limits: Limits{
+			CPUSeconds: cfg.Limits.CPUSeconds,
+			MemoryMB:   cfg.Limits.MemoryMB,
+			OpenFiles:  cfg.Limits.OpenFiles,
+			Processes:  cfg.Limits.Processes,
+		},
+		RunAs:      cfg.RunAs,
+		Namespaces: cfg.Namespaces,
+		MaxOutput:  int64(cfg.MaxOutputMB) << 20,
+	}
+	if cfg.Namespaces {
+		dir, err := filepath.Abs(skillsDir)
+		if err != nil {
+			return sandbox, err
+		}
+		sandbox.ReadOnlyPaths = []string{dir}
+	}
+	return sandbox, nil
+}
+
+// Validate checks that the options of a sandbox can be combined
+func (s Sandbox) Validate() error {
+	if s.RunAs != "" && s.Namespaces {
+		return fmt.Errorf("run_as cannot be combined with namespaces")
+	}
+	for _, path := range s.ReadOnlyPaths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("read-only path %s must be absolute", path)
+		}
+	}
+	if s.MaxOutput < 0 {
+		return fmt.Errorf("max output must not be negative")
+	}
+	return nil
+}
+
+// environ returns the environment of a script: the allowlisted agent environment and env
+func (s Sandbox) environ(env map[string]string) []string {
+	var environ []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, override := env[name]; override {
+			continue
+		}
+		if allowed(name, s.EnvAllowlist) {
+			environ = append(environ, kv)
+		}
+	}
+	for k, v := range env {
+		environ = append(environ, fmt.Sprintf("%s=%s", k, v))
+	}
+	return environ
+}
+
+// allowed reports whether an environment variable matches the allowlist
+func allowed(name string, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// needsLauncher reports whether scripts are started through the launcher, which waits
+// on fd 3 until the runner has applied the limits, then sets up mounts and execs the script
+func (s Sandbox) needsLauncher() bool {
+	return s.Limits.IsSet() || (s.Namespaces && len(s.ReadOnlyPaths) > 0)
+}
+
+// launcher returns the shell command line running argv once fd 3 is readable
+func (s Sandbox) launcher(argv []string) []string {
+	var script strings.Builder
+	script.WriteString("read _ <&3; exec 3<&-; ")
+	if s.Namespaces {
+		for _, path := range s.ReadOnlyPaths {
+			p := shellQuote(path)
+			fmt.Fprintf(&script, "mount --bind %s %s && mount -o remount,ro,bind %s %s || exit 126; ", p, p, p, p)
+		}
+		// Re-enter the working directory to see it through the new mounts
+		script.WriteString(`cd "$PWD" || exit 126; `)
+	}
+	script.WriteString(`exec "$@"`)
+	return append([]string{"/bin/sh", "-c", script.String(), "sh"}, argv...)
+}
+
+// shellQuote quotes s for the shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
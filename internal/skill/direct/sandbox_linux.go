@@ -0,0 +1,106 @@
+package direct
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// prepare sets the process attributes of a script: its own process group, and the
+// user or namespaces of the sandbox
+func (s Sandbox) prepare(cmd *exec.Cmd) error {
+	attr := &syscall.SysProcAttr{Setpgid: true}
+
+	if s.RunAs != "" {
+		uid, gid, err := lookupUser(s.RunAs)
+		if err != nil {
+			return err
+		}
+		attr.Credential = &syscall.Credential{Uid: uid, Gid: gid}
+	}
+
+	if s.Namespaces {
+		attr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	}
+
+	cmd.SysProcAttr = attr
+	return nil
+}
+
+// own gives a file of the agent to the user scripts run as, so they can write to it
+func (s Sandbox) own(path string) error {
+	if s.RunAs == "" {
+		return nil
+	}
+	uid, gid, err := lookupUser(s.RunAs)
+	if err != nil {
+		return err
+	}
+	if err := os.Lchown(path, int(uid), int(gid)); err != nil {
+		return fmt.Errorf("failed to give %s to run_as user %s: %w", path, s.RunAs, err)
+	}
+	return nil
+}
+
+// applyLimits sets the resource limits of a started process
+func (s Sandbox) applyLimits(pid int) error {
+	limits := []struct {
+		resource int
+		value    uint64
+		name     string
+	}{
+		{unix.RLIMIT_CPU, s.Limits.CPUSeconds, "cpu"},
+		{unix.RLIMIT_AS, s.Limits.MemoryMB << 20, "memory"},
+		{unix.RLIMIT_NOFILE, s.Limits.OpenFiles, "open files"},
+		{unix.RLIMIT_NPROC, s.Limits.Processes, "processes"},
+	}
+	for _, l := range limits {
+		if l.value == 0 {
+			continue
+		}
+		rlimit := &unix.Rlimit{Cur: l.value, Max: l.value}
+		if err := unix.Prlimit(pid, l.resource, rlimit, nil); err != nil {
+			return fmt.Errorf("failed to set %s limit: %w", l.name, err)
+		}
+	}
+	return nil
+}
+
+// killGroup kills a script and every process it started
+func killGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		cmd.Process.Kill()
+	}
+}
+
+// lookupUser resolves a user name or uid
+func lookupUser(name string) (uint32, uint32, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		if _, numErr := strconv.Atoi(name); numErr != nil {
+			return 0, 0, fmt.Errorf("unknown run_as user %s: %w", name, err)
+		}
+		if u, err = user.LookupId(name); err != nil {
+			return 0, 0, fmt.Errorf("unknown run_as user %s: %w", name, err)
+		}
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid uid of %s: %w", name, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid gid of %s: %w", name, err)
+	}
+	return uint32(uid), uint32(gid), nil
+}
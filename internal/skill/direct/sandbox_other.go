@@ -0,0 +1,33 @@
+//go:build !linux
+
+package direct
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// prepare rejects the sandbox options that need Linux
+func (s Sandbox) prepare(cmd *exec.Cmd) error {
+	if s.RunAs != "" || s.Namespaces {
+		return fmt.Errorf("run_as and namespaces are only supported on Linux")
+	}
+	return nil
+}
+
+// own does nothing, scripts run as the agent's user outside Linux
+func (s Sandbox) own(path string) error {
+	return nil
+}
+
+// applyLimits rejects resource limits, which need Linux
+func (s Sandbox) applyLimits(pid int) error {
+	return fmt.Errorf("resource limits are only supported on Linux")
+}
+
+// killGroup kills a script; processes it started are not tracked outside Linux
+func killGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
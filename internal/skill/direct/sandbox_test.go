@@ -0,0 +1,105 @@
+package direct
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+func TestSandboxEnviron(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin:/bin")
+	t.Setenv("LC_ALL", "C.UTF-8")
+	t.Setenv("LCX", "not a locale")
+	t.Setenv("OPENAI_API_KEY", "sk-agent")
+	t.Setenv("OPSKILLS_SECRET_KEY", "agent-key")
+	t.Setenv("SKILL_PARAM_host", "from the agent")
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		env       map[string]string
+		want      []string // Variables of the script
+		dropped   []string // Variables of the agent the script must not see
+	}{
+		{
+			name:      "default allowlist",
+			allowlist: DefaultEnvAllowlist,
+			want:      []string{"PATH=/usr/bin:/bin", "LC_ALL=C.UTF-8"},
+			dropped:   []string{"LCX", "OPENAI_API_KEY", "OPSKILLS_SECRET_KEY", "SKILL_PARAM_host"},
+		},
+		{
+			name:      "prefix",
+			allowlist: []string{"OPSKILLS_*"},
+			want:      []string{"OPSKILLS_SECRET_KEY=agent-key"},
+			dropped:   []string{"PATH", "LC_ALL", "OPENAI_API_KEY"},
+		},
+		{
+			name:      "empty allowlist",
+			allowlist: nil,
+			dropped:   []string{"PATH", "LC_ALL", "LCX", "OPENAI_API_KEY", "OPSKILLS_SECRET_KEY", "SKILL_PARAM_host"},
+		},
+		{
+			name:      "executor variables are always set",
+			allowlist: nil,
+			env:       map[string]string{"SKILL_PARAM_host": "node1", "SKILL_WORKSPACE": "/tmp/ws"},
+			want:      []string{"SKILL_PARAM_host=node1", "SKILL_WORKSPACE=/tmp/ws"},
+			dropped:   []string{"PATH", "OPENAI_API_KEY"},
+		},
+		{
+			name:      "executor variables override the agent",
+			allowlist: []string{"SKILL_*", "PATH"},
+			env:       map[string]string{"SKILL_PARAM_host": "node1", "PATH": "/opt/bin"},
+			want:      []string{"SKILL_PARAM_host=node1", "PATH=/opt/bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environ := Sandbox{EnvAllowlist: tt.allowlist}.environ(tt.env)
+			for _, kv := range tt.want {
+				if !slices.Contains(environ, kv) {
+					t.Errorf("environ() = %v, want %s", environ, kv)
+				}
+			}
+			for _, kv := range environ {
+				name, _, _ := strings.Cut(kv, "=")
+				if slices.Contains(tt.dropped, name) {
+					t.Errorf("environ() passes %s", kv)
+				}
+				if n := slices.IndexFunc(environ, func(other string) bool { return strings.HasPrefix(other, name+"=") }); environ[n] != kv {
+					t.Errorf("environ() sets %s twice", name)
+				}
+			}
+		})
+	}
+}
+
+func TestScriptsOnlySeeAllowlistedEnv(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-agent")
+	dir := t.TempDir()
+	scripts := filepath.Join(dir, "skill", "scripts")
+	if err := os.MkdirAll(scripts, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scripts, "env.sh"), []byte("#!/bin/sh\nenv\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewDirectExecutor(time.Minute)
+	result, err := executor.ExecuteContext(context.Background(), &skill.Skill{Name: "skill", ScriptsPath: scripts}, skill.ExecutionParams{"action": "env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(result.Output, "OPENAI_API_KEY") {
+		t.Errorf("script sees the agent environment: %s", result.Output)
+	}
+	if !strings.Contains(result.Output, "PATH=") {
+		t.Errorf("script does not see PATH: %s", result.Output)
+	}
+}
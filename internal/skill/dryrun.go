@@ -0,0 +1,77 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DryRunEnv is set to 1 for scripts executed in a dry run
+const DryRunEnv = "SKILL_DRY_RUN"
+
+// DryRunArgument is set to true in the arguments of MCP tool calls made in a dry run
+const DryRunArgument = "dry_run"
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context executing skills in a dry run: actions declaring
+// dry_run support run without changing anything, the others are skipped
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// DryRunFrom reports whether a context executes skills in a dry run
+func DryRunFrom(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// supportsDryRun reports whether the action of params can run in a dry run
+func supportsDryRun(s *Skill, params ExecutionParams) bool {
+	actionName, _ := params["action"].(string)
+	action, ok := s.Action(actionName)
+	return ok && action.DryRun
+}
+
+// skippedResult is the result of an action skipped by a dry run
+func skippedResult(s *Skill, params ExecutionParams) *ExecutionResult {
+	actionName, _ := params["action"].(string)
+	if actionName == "" {
+		actionName = "(none)"
+	}
+	return &ExecutionResult{
+		Success:   true,
+		Output:    fmt.Sprintf("[dry run] skipped: action %s of skill %s does not support dry run", actionName, s.Name),
+		Timestamp: time.Now(),
+		DryRun:    true,
+		Skipped:   true,
+	}
+}
+
+// CheckStep checks that a plan step can be executed: its skill is registered, its
+// action is one of the skill's actions when it lists them, and the required
+// parameters of the action are set
+func (r *Router) CheckStep(skillName string, params ExecutionParams) error {
+	s, err := r.registry.Get(skillName)
+	if err != nil {
+		return fmt.Errorf("skill not found: %s", skillName)
+	}
+	actionName, _ := params["action"].(string)
+	if len(s.Actions) == 0 {
+		return nil
+	}
+	action, ok := s.Action(actionName)
+	if !ok {
+		return fmt.Errorf("skill %s has no action %q", skillName, actionName)
+	}
+	var missing []string
+	for _, param := range action.Params {
+		if v, ok := params[param.Name]; param.Required && (!ok || v == nil || v == "") {
+			missing = append(missing, param.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("action %s of skill %s requires parameters %v", actionName, skillName, missing)
+	}
+	return nil
+}
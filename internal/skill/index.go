@@ -0,0 +1,251 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hb-chen/opskills/internal/retrieval"
+	"github.com/hb-chen/opskills/pkg/logger"
+)
+
+// Default retrieval settings
+const (
+	DefaultSelectTopK      = 5
+	DefaultEmbeddingWeight = 0.5
+)
+
+// Indexed fields of a skill and their BM25 weights
+const (
+	FieldName         = "name"
+	FieldDescription  = "description"
+	FieldActions      = "actions"
+	FieldInstructions = "instructions"
+	FieldReferences   = "references"
+)
+
+var fieldWeights = map[string]float64{
+	FieldName:         3,
+	FieldDescription:  2,
+	FieldActions:      2,
+	FieldInstructions: 1,
+	FieldReferences:   1,
+}
+
+// maxReferenceBytes bounds the text read from each file in references/
+const maxReferenceBytes = 64 * 1024
+
+// Selection is a skill scored against a query
+type Selection struct {
+	retrieval.Result
+	Skill    *Skill
+	Selected bool   // Within the top K skills passed to the planner
+	Reason   string // Why the skill was or was not selected
+}
+
+// SkillIndex selects the skills relevant to a query from a BM25 index over
+// SKILL.md, references and actions, optionally combined with embeddings.
+// The index is rebuilt on first use after the registry changes.
+type SkillIndex struct {
+	registry        *Registry
+	topK            int
+	embedder        retrieval.Embedder
+	embeddingWeight float64
+
+	index  *retrieval.Index
+	skills map[string]*Skill
+	gen    uint64 // Bumped on every registry change
+	built  uint64 // gen the index was built for
+	mu     sync.Mutex
+}
+
+// NewSkillIndex creates a skill index over the registry
+func NewSkillIndex(registry *Registry, topK int) *SkillIndex {
+	if topK <= 0 {
+		topK = DefaultSelectTopK
+	}
+	x := &SkillIndex{
+		registry:        registry,
+		topK:            topK,
+		embeddingWeight: DefaultEmbeddingWeight,
+		gen:             1,
+	}
+	registry.Subscribe(func(RegistryEvent) {
+		x.mu.Lock()
+		x.gen++
+		x.mu.Unlock()
+	})
+	return x
+}
+
+// SetEmbedder enables hybrid ranking; weight is the share of the embedding similarity in the score
+func (x *SkillIndex) SetEmbedder(embedder retrieval.Embedder, weight float64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.embedder = embedder
+	if weight > 0 && weight <= 1 {
+		x.embeddingWeight = weight
+	}
+	x.built = 0
+}
+
+// TopK returns the number of skills selected per query
+func (x *SkillIndex) TopK() int {
+	return x.topK
+}
+
+// Select returns the skills to plan with for a query, most relevant first
+func (x *SkillIndex) Select(ctx context.Context, query string) ([]*Skill, error) {
+	selections, err := x.Explain(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var skills []*Skill
+	for _, s := range selections {
+		if s.Selected {
+			skills = append(skills, s.Skill)
+		}
+	}
+	return skills, nil
+}
+
+// Explain scores every skill against a query, most relevant first, and marks
+// the ones Select returns
+func (x *SkillIndex) Explain(ctx context.Context, query string) ([]Selection, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.buildLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	var queryVector []float32
+	if x.embedder != nil {
+		vector, err := x.embedder.EmbedQuery(ctx, query)
+		if err != nil {
+			logger.Warnf("Failed to embed query, ranking skills with BM25 only: %v", err)
+		} else {
+			queryVector = vector
+		}
+	}
+
+	results := x.index.Search(query, queryVector, x.embeddingWeight)
+	selections := make([]Selection, len(results))
+	matched := 0
+	for i, result := range results {
+		selections[i] = Selection{Result: result, Skill: x.skills[result.ID]}
+		if result.Score > 0 {
+			matched++
+		}
+	}
+
+	// Small catalogs are passed whole; without any match, the first skills keep the planner working
+	switch {
+	case len(selections) <= x.topK:
+		for i := range selections {
+			selections[i].Selected = true
+			selections[i].Reason = fmt.Sprintf("catalog has no more than %d skills", x.topK)
+		}
+	case matched == 0:
+		for i := range selections {
+			selections[i].Selected = i < x.topK
+			selections[i].Reason = "no skill matches the query"
+		}
+	default:
+		for i := range selections {
+			switch {
+			case selections[i].Score <= 0:
+				selections[i].Reason = "no match"
+			case i < x.topK:
+				selections[i].Selected = true
+				selections[i].Reason = fmt.Sprintf("rank %d of %d", i+1, matched)
+			default:
+				selections[i].Reason = fmt.Sprintf("rank %d is below the top %d", i+1, x.topK)
+			}
+		}
+	}
+
+	return selections, nil
+}
+
+// buildLocked rebuilds the index if the registry changed since it was built
+func (x *SkillIndex) buildLocked(ctx context.Context) error {
+	if x.index != nil && x.built == x.gen {
+		return nil
+	}
+
+	skills := x.registry.List()
+	if len(skills) == 0 {
+		return fmt.Errorf("no skills available")
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+
+	index := retrieval.NewIndex(fieldWeights)
+	byName := make(map[string]*Skill, len(skills))
+	for _, s := range skills {
+		index.Add(retrieval.Document{ID: s.Name, Fields: skillFields(s)})
+		byName[s.Name] = s
+	}
+
+	if x.embedder != nil {
+		if err := index.Embed(ctx, x.embedder); err != nil {
+			logger.Warnf("Failed to embed skills, ranking skills with BM25 only: %v", err)
+		}
+	}
+
+	x.index = index
+	x.skills = byName
+	x.built = x.gen
+	logger.Debugf("Built skill index with %d skills", index.Len())
+	return nil
+}
+
+// skillFields returns the indexed text of a skill
+func skillFields(s *Skill) map[string]string {
+	var actions strings.Builder
+	for _, action := range s.Actions {
+		fmt.Fprintf(&actions, "%s %s %s\n", action.Name, action.Description, action.Usage)
+		for _, param := range action.Params {
+			fmt.Fprintf(&actions, "%s %s\n", param.Name, param.Description)
+		}
+	}
+
+	return map[string]string{
+		FieldName:         s.Name,
+		FieldDescription:  s.Description,
+		FieldActions:      actions.String(),
+		FieldInstructions: s.Instructions,
+		FieldReferences:   readReferences(s.BasePath),
+	}
+}
+
+// readReferences returns the text of the markdown files in references/
+func readReferences(basePath string) string {
+	if basePath == "" {
+		return ""
+	}
+	files, err := filepath.Glob(filepath.Join(basePath, "references", "*.md"))
+	if err != nil {
+		return ""
+	}
+	sort.Strings(files)
+
+	var b strings.Builder
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if len(data) > maxReferenceBytes {
+			data = data[:maxReferenceBytes]
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
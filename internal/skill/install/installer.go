@@ -0,0 +1,419 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// Options configures an install or upgrade
+type Options struct {
+	Path   string // Skill directory inside the source, when the source holds several skills
+	SHA256 string // Expected sha256 of a tarball source
+	Pin    bool   // Pin the installed version in the skills config
+	Force  bool   // Reinstall an installed version, or remove a pinned one
+}
+
+// Installed describes a skill version found in the skills directory
+type Installed struct {
+	skill.LockEntry
+	Managed bool  // Installed by `skill install` (false for skills copied by hand)
+	Pinned  bool  // Version pinned in the skills config
+	Active  bool  // Version loaded by the agent
+	Err     error // Integrity check failure
+}
+
+// Installer installs skill versions side by side into <skills dir>/<name>@<version>,
+// records their checksums in a lockfile and maintains version pins in the skills config
+type Installer struct {
+	skillsDir  string
+	lockPath   string
+	configPath string
+}
+
+// NewInstaller creates a skill installer
+func NewInstaller(skillsDir, lockPath, configPath string) *Installer {
+	return &Installer{
+		skillsDir:  skillsDir,
+		lockPath:   lockPath,
+		configPath: configPath,
+	}
+}
+
+// Install installs a skill from a tarball, a directory or a local git repository
+func (i *Installer) Install(source string, opts Options) (*skill.LockEntry, error) {
+	src, err := ParseSource(source)
+	if err != nil {
+		return nil, err
+	}
+	entry, _, err := i.install(src, opts, "", nil)
+	return entry, err
+}
+
+// Upgrade installs the newest version of an installed skill and moves its pin.
+// source defaults to the source of the highest installed version; for git sources
+// the default branch is fetched again instead of the originally installed ref.
+// It returns the previously highest and the new version, which are the same if
+// the skill is already up to date.
+func (i *Installer) Upgrade(name, source string, opts Options) (*skill.LockEntry, *skill.LockEntry, error) {
+	lock, err := skill.LoadLockfile(i.lockPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var current *skill.LockEntry
+	if versions := lock.Versions(name); len(versions) > 0 {
+		current = &versions[len(versions)-1]
+	}
+
+	if source == "" {
+		if current == nil {
+			return nil, nil, fmt.Errorf("skill %s is not installed, install it first or give a source", name)
+		}
+		source = current.Source
+	}
+	src, err := ParseSource(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current != nil && source == current.Source && src.Type == SourceGit {
+		src.Ref = ""
+	}
+
+	pins, err := ReadPins(i.configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, pinned := pins[name]; pinned {
+		opts.Pin = true
+	}
+
+	entry, upToDate, err := i.install(src, opts, name, current)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upToDate {
+		return current, current, nil
+	}
+	return current, entry, nil
+}
+
+// install fetches a source and installs the skill it contains.
+// If name is set the source must contain that skill, and a version that is not
+// newer than current is reported as up to date instead of being installed.
+func (i *Installer) install(src *Source, opts Options, name string, current *skill.LockEntry) (*skill.LockEntry, bool, error) {
+	if opts.SHA256 != "" {
+		if src.Type != SourceTarball {
+			return nil, false, fmt.Errorf("--sha256 is only supported for tarball sources")
+		}
+		sum, err := fileSHA256(src.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		if !strings.EqualFold(sum, opts.SHA256) {
+			return nil, false, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", src.Path, opts.SHA256, sum)
+		}
+	}
+
+	if err := os.MkdirAll(i.skillsDir, 0755); err != nil {
+		return nil, false, fmt.Errorf("failed to create skills directory: %w", err)
+	}
+
+	// Stage inside the skills directory so the final rename stays on one filesystem.
+	// The loader and watcher ignore hidden directories.
+	staging, err := os.MkdirTemp(i.skillsDir, ".install-")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	fetched := filepath.Join(staging, "source")
+	commit, err := src.fetch(fetched)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", src, err)
+	}
+
+	root, err := skillRoot(fetched, opts.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	s, err := skill.ParseSKILL(filepath.Join(root, "SKILL.md"))
+	if err != nil {
+		return nil, false, err
+	}
+	if err := validateNameVersion(s); err != nil {
+		return nil, false, err
+	}
+
+	if name != "" {
+		if s.Name != name {
+			return nil, false, fmt.Errorf("source contains skill %s, not %s", s.Name, name)
+		}
+		if current != nil {
+			switch c := skill.CompareVersions(s.Version, current.Version); {
+			case c == 0:
+				return nil, true, nil
+			case c < 0:
+				return nil, false, fmt.Errorf("source has version %s of skill %s, older than the installed %s", s.Version, name, current.Version)
+			}
+		}
+	}
+
+	lock, err := skill.LoadLockfile(i.lockPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	dirName := skill.VersionedDirName(s.Name, s.Version)
+	target := filepath.Join(i.skillsDir, dirName)
+	previous := ""
+	if _, err := os.Stat(target); err == nil {
+		if !opts.Force {
+			return nil, false, fmt.Errorf("skill %s version %s is already installed, use --force to reinstall", s.Name, s.Version)
+		}
+		// Move the old copy out of the way, it is removed with the staging directory
+		previous = filepath.Join(staging, "previous")
+		if err := os.Rename(target, previous); err != nil {
+			return nil, false, fmt.Errorf("failed to replace %s: %w", dirName, err)
+		}
+	}
+
+	digest, err := skill.DirDigest(root)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to checksum skill: %w", err)
+	}
+
+	entry := skill.LockEntry{
+		Name:        s.Name,
+		Version:     s.Version,
+		Dir:         dirName,
+		Source:      src.String(),
+		SourceType:  src.Type,
+		Commit:      commit,
+		Digest:      digest,
+		InstalledAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	// Record the checksum before the skill appears, so a watching agent can verify it
+	var old *skill.LockEntry
+	if existing, ok := lock.Find(entry.Name, entry.Version); ok {
+		saved := *existing
+		old = &saved
+	}
+	lock.Put(entry)
+	if err := lock.Save(i.lockPath); err != nil {
+		return nil, false, err
+	}
+	if err := os.Rename(root, target); err != nil {
+		if previous != "" {
+			_ = os.Rename(previous, target)
+		}
+		if old != nil {
+			lock.Put(*old)
+		} else {
+			lock.Remove(entry.Name, entry.Version)
+		}
+		_ = lock.Save(i.lockPath)
+		return nil, false, fmt.Errorf("failed to install %s: %w", dirName, err)
+	}
+
+	if opts.Pin {
+		if err := SetPin(i.configPath, entry.Name, entry.Version); err != nil {
+			return nil, false, fmt.Errorf("installed %s but failed to pin it: %w", dirName, err)
+		}
+	}
+
+	return &entry, false, nil
+}
+
+// Remove removes an installed skill version, or every version if version is empty.
+// A pinned version is only removed with force, which also removes the pin.
+func (i *Installer) Remove(name, version string, force bool) ([]skill.LockEntry, error) {
+	lock, err := skill.LoadLockfile(i.lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []skill.LockEntry
+	if version == "" {
+		entries = lock.Versions(name)
+	} else if entry, ok := lock.Find(name, version); ok {
+		entries = []skill.LockEntry{*entry}
+	}
+	if len(entries) == 0 {
+		if version == "" {
+			return nil, fmt.Errorf("skill %s is not installed", name)
+		}
+		return nil, fmt.Errorf("skill %s version %s is not installed", name, version)
+	}
+
+	pins, err := ReadPins(i.configPath)
+	if err != nil {
+		return nil, err
+	}
+	pin, pinned := pins[name]
+	for _, entry := range entries {
+		if pinned && skill.CompareVersions(pin, entry.Version) == 0 && !force {
+			return nil, fmt.Errorf("skill %s version %s is pinned in %s, use --force to remove it and its pin", name, entry.Version, i.configPath)
+		}
+	}
+
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(i.skillsDir, entry.Dir)); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", entry.Dir, err)
+		}
+		lock.Remove(entry.Name, entry.Version)
+		if pinned && skill.CompareVersions(pin, entry.Version) == 0 {
+			if err := SetPin(i.configPath, name, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := lock.Save(i.lockPath); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// List returns every installed skill version and every skill copied by hand,
+// with its pin, whether the agent loads it and the result of its integrity check
+func (i *Installer) List() ([]Installed, error) {
+	lock, err := skill.LoadLockfile(i.lockPath)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := ReadPins(i.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := skill.NewLoader(i.skillsDir)
+	loader.SetPins(pins)
+	loader.SetLockfile(i.lockPath)
+	active := make(map[string]*skill.Skill)
+	if _, err := os.Stat(i.skillsDir); err == nil {
+		skills, err := loader.LoadAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range skills {
+			active[filepath.Base(s.BasePath)] = s
+		}
+	}
+
+	var installed []Installed
+	for _, entry := range lock.Skills {
+		item := Installed{
+			LockEntry: entry,
+			Managed:   true,
+			Active:    active[entry.Dir] != nil,
+			Err:       entry.Verify(i.skillsDir),
+		}
+		if pin, ok := pins[entry.Name]; ok && skill.CompareVersions(pin, entry.Version) == 0 {
+			item.Pinned = true
+		}
+		installed = append(installed, item)
+	}
+
+	// Skills copied by hand are listed too, they compete with installed versions
+	dirs, err := os.ReadDir(i.skillsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read skills directory: %w", err)
+	}
+	for _, d := range dirs {
+		if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+		if _, managed := lock.FindDir(d.Name()); managed {
+			continue
+		}
+		s, err := skill.ParseSKILL(filepath.Join(i.skillsDir, d.Name(), "SKILL.md"))
+		if err != nil {
+			continue
+		}
+		item := Installed{
+			LockEntry: skill.LockEntry{Name: s.Name, Version: s.Version, Dir: d.Name()},
+			Active:    active[d.Name()] != nil,
+		}
+		if pin, ok := pins[s.Name]; ok && skill.CompareVersions(pin, s.Version) == 0 {
+			item.Pinned = true
+		}
+		installed = append(installed, item)
+	}
+
+	sort.Slice(installed, func(a, b int) bool {
+		if installed[a].Name != installed[b].Name {
+			return installed[a].Name < installed[b].Name
+		}
+		return skill.CompareVersions(installed[a].Version, installed[b].Version) < 0
+	})
+
+	return installed, nil
+}
+
+// skillRoot finds the skill directory in a fetched source: path if given, else the
+// source root or the only directory below it that contains a SKILL.md
+func skillRoot(dir, path string) (string, error) {
+	if path != "" {
+		root := filepath.Join(dir, filepath.Clean("/"+path))
+		if _, err := os.Stat(filepath.Join(root, "SKILL.md")); err != nil {
+			return "", fmt.Errorf("no SKILL.md in %s of the source", path)
+		}
+		return root, nil
+	}
+
+	var roots []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && info.Name() == "SKILL.md" {
+			roots = append(roots, filepath.Dir(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, root := range roots {
+		if root == dir {
+			return root, nil
+		}
+	}
+	switch len(roots) {
+	case 0:
+		return "", fmt.Errorf("no SKILL.md found in the source")
+	case 1:
+		return roots[0], nil
+	}
+
+	var rels []string
+	for _, root := range roots {
+		rel, _ := filepath.Rel(dir, root)
+		rels = append(rels, rel)
+	}
+	return "", fmt.Errorf("source contains several skills (%s), select one with --path", strings.Join(rels, ", "))
+}
+
+// validateNameVersion checks that a skill can be installed into <name>@<version>
+func validateNameVersion(s *skill.Skill) error {
+	if s.Name == "" {
+		return fmt.Errorf("skill name cannot be empty")
+	}
+	if s.Version == "" {
+		return fmt.Errorf("skill %s has no version, set version in the SKILL.md frontmatter or %s", s.Name, skill.MarketplaceFile)
+	}
+	for _, value := range []string{s.Name, s.Version} {
+		if strings.ContainsAny(value, `/\@`) || strings.HasPrefix(value, ".") {
+			return fmt.Errorf("invalid skill name or version %q", value)
+		}
+	}
+	return nil
+}
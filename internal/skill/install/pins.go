@@ -0,0 +1,138 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// ReadPins returns the pinned skill versions from a skills config.
+// A missing config has no pins.
+func ReadPins(configPath string) (map[string]string, error) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	config, err := skill.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return config.Pins(), nil
+}
+
+// SetPin pins a skill version in the skills config. The file is edited line by line
+// so that its comments and layout are kept. An empty version removes the pin.
+func SetPin(configPath, name, version string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	var root *yaml.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return fmt.Errorf("invalid config file %s: expected a mapping", configPath)
+		}
+	}
+
+	skillsKey, skills := lookupKey(root, "skills")
+	skillKey, skillNode := lookupKey(skills, name)
+	_, versionNode := lookupKey(skillNode, "version")
+
+	switch {
+	case version == "":
+		if versionNode == nil {
+			return nil
+		}
+		lines = append(lines[:versionNode.Line-1], lines[versionNode.Line:]...)
+	case versionNode != nil:
+		line := lines[versionNode.Line-1]
+		lines[versionNode.Line-1] = line[:versionNode.Column-1] + version
+	case isBlockMapping(skillNode):
+		indent := strings.Repeat(" ", skillNode.Content[0].Column-1)
+		lines = insertLines(lines, lastLine(skillNode), indent+"version: "+version)
+	case skillKey != nil:
+		if isFlow(skillNode) {
+			return fmt.Errorf("cannot pin %s: flow-style mappings are not supported in %s", name, configPath)
+		}
+		indent := strings.Repeat(" ", skillKey.Column-1)
+		lines[skillKey.Line-1] = indent + name + ":"
+		lines = insertLines(lines, skillKey.Line, indent+"  version: "+version)
+	case isBlockMapping(skills):
+		indent := strings.Repeat(" ", skills.Content[0].Column-1)
+		lines = insertLines(lines, lastLine(skills), indent+name+":", indent+"  version: "+version)
+	case skillsKey != nil:
+		if isFlow(skills) {
+			return fmt.Errorf("cannot pin %s: flow-style mappings are not supported in %s", name, configPath)
+		}
+		lines[skillsKey.Line-1] = "skills:"
+		lines = insertLines(lines, skillsKey.Line, "  "+name+":", "    version: "+version)
+	default:
+		lines = append(lines, "skills:", "  "+name+":", "    version: "+version)
+	}
+
+	if err := os.WriteFile(configPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// lookupKey returns the key and value nodes of key in a mapping node
+func lookupKey(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// isBlockMapping reports whether node is a non-empty block-style mapping
+func isBlockMapping(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.MappingNode && !isFlow(node) && len(node.Content) > 0
+}
+
+// isFlow reports whether node is written in flow style ({...} or [...])
+func isFlow(node *yaml.Node) bool {
+	return node != nil && (node.Style&yaml.FlowStyle != 0 || (node.Kind == yaml.MappingNode && len(node.Content) == 0))
+}
+
+// lastLine returns the last line (1-based) occupied by a node and its children
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		if l := lastLine(child); l > last {
+			last = l
+		}
+	}
+	// Literal and folded block scalars span several lines
+	if node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		last += strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+	}
+	return last
+}
+
+// insertLines inserts text after the given line (1-based, 0 for the start)
+func insertLines(lines []string, after int, text ...string) []string {
+	result := make([]string, 0, len(lines)+len(text))
+	result = append(result, lines[:after]...)
+	result = append(result, text...)
+	return append(result, lines[after:]...)
+}
@@ -0,0 +1,271 @@
+package install
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Source types recorded in the lockfile
+const (
+	SourceTarball = "tarball"
+	SourceDir     = "dir"
+	SourceGit     = "git"
+)
+
+// gitPrefix forces a source to be treated as a git repository
+const gitPrefix = "git+"
+
+// Source is a parsed skill source
+type Source struct {
+	Type string
+	Path string // Local path of the tarball, directory or repository
+	Ref  string // Git ref (branch, tag or commit), empty for HEAD
+}
+
+// ParseSource detects the type of a skill source.
+// Git repositories are given as git+<path>, as a path ending in .git, or as
+// <path>#<ref>; directories containing .git are also cloned so that only
+// committed files are installed.
+func ParseSource(source string) (*Source, error) {
+	if source == "" {
+		return nil, fmt.Errorf("source cannot be empty")
+	}
+
+	path, ref, _ := strings.Cut(source, "#")
+	forceGit := strings.HasPrefix(path, gitPrefix)
+	path = strings.TrimPrefix(strings.TrimPrefix(path, gitPrefix), "file://")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", path, err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case forceGit || ref != "" || strings.HasSuffix(path, ".git") || isGitRepo(path):
+		if !info.IsDir() {
+			return nil, fmt.Errorf("git source %s is not a directory", path)
+		}
+		return &Source{Type: SourceGit, Path: abs, Ref: ref}, nil
+	case info.IsDir():
+		return &Source{Type: SourceDir, Path: abs}, nil
+	case isTarball(path):
+		return &Source{Type: SourceTarball, Path: abs}, nil
+	default:
+		return nil, fmt.Errorf("unsupported source %s: expected a directory, a git repository or a .tar, .tar.gz or .tgz archive", path)
+	}
+}
+
+// String returns the source in the form accepted by ParseSource
+func (s *Source) String() string {
+	if s.Type == SourceGit {
+		source := gitPrefix + s.Path
+		if s.Ref != "" {
+			source += "#" + s.Ref
+		}
+		return source
+	}
+	return s.Path
+}
+
+// isGitRepo reports whether dir is the root of a git work tree
+func isGitRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// isTarball reports whether path has a supported archive extension
+func isTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz") || strings.HasSuffix(path, ".tar")
+}
+
+// fetch copies the source into dest, which must not exist.
+// It returns the resolved commit for git sources.
+func (s *Source) fetch(dest string) (string, error) {
+	switch s.Type {
+	case SourceDir:
+		return "", copyDir(s.Path, dest)
+	case SourceTarball:
+		return "", extractTarball(s.Path, dest)
+	case SourceGit:
+		return cloneGit(s.Path, s.Ref, dest)
+	default:
+		return "", fmt.Errorf("unsupported source type %q", s.Type)
+	}
+}
+
+// copyDir copies a directory tree, keeping file modes and skipping .git
+func copyDir(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dest, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("unsupported file type in skill source: %s", rel)
+		}
+	})
+}
+
+// copyFile copies a regular file with the given mode
+func copyFile(src, dest string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dest, in, mode)
+}
+
+// writeFile writes r to a new file with the given mode
+func writeFile(dest string, r io.Reader, mode os.FileMode) error {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile applies the umask, the executable bits of scripts must survive it
+	return os.Chmod(dest, mode)
+}
+
+// extractTarball extracts a (gzipped) tar archive.
+// Only regular files and directories are accepted, and no entry may escape dest.
+func extractTarball(archive, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(archive, ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if name == "." {
+			continue
+		}
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q escapes the skill directory", header.Name)
+		}
+		target := filepath.Join(dest, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode).Perm()|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, os.FileMode(header.Mode).Perm()); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			// pax header written by git archive
+		default:
+			return fmt.Errorf("unsupported archive entry %q (links and special files are not allowed)", header.Name)
+		}
+	}
+}
+
+// cloneGit clones a local repository at ref and removes its .git directory.
+// It returns the resolved commit.
+func cloneGit(repo, ref, dest string) (string, error) {
+	if _, err := runGit("", "clone", "--quiet", "--no-hardlinks", repo, dest); err != nil {
+		return "", err
+	}
+	if ref != "" {
+		if _, err := runGit(dest, "checkout", "--quiet", ref); err != nil {
+			return "", err
+		}
+	}
+	commit, err := runGit(dest, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if err := os.RemoveAll(filepath.Join(dest, ".git")); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// runGit runs a git command and returns its trimmed stdout
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// fileSHA256 returns the hex sha256 of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,152 @@
+package install
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// entry is a file of a test archive
+type entry struct {
+	name     string
+	typeflag byte
+	linkname string
+}
+
+// writeTarball writes a gzipped archive of entries, regular files holding their name
+func writeTarball(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0o644}
+		if e.typeflag == tar.TypeDir {
+			header.Mode = 0o755
+		}
+		if e.typeflag == tar.TypeReg {
+			header.Size = int64(len(e.name))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractTarball(t *testing.T) {
+	skillFiles := []entry{
+		{name: "demo/", typeflag: tar.TypeDir},
+		{name: "demo/SKILL.md", typeflag: tar.TypeReg},
+	}
+
+	tests := []struct {
+		name    string
+		entries []entry
+		wantErr string
+	}{
+		{
+			name:    "skill files",
+			entries: append(skillFiles, entry{name: "./demo/scripts/../README.md", typeflag: tar.TypeReg}),
+		},
+		{
+			name:    "parent directory",
+			entries: append(skillFiles, entry{name: "../evil", typeflag: tar.TypeReg}),
+			wantErr: "escapes the skill directory",
+		},
+		{
+			name:    "parent directory after a subdirectory",
+			entries: append(skillFiles, entry{name: "demo/../../evil", typeflag: tar.TypeReg}),
+			wantErr: "escapes the skill directory",
+		},
+		{
+			name:    "absolute path",
+			entries: append(skillFiles, entry{name: "/tmp/evil", typeflag: tar.TypeReg}),
+			wantErr: "escapes the skill directory",
+		},
+		{
+			name:    "symlink out of the skill",
+			entries: append(skillFiles, entry{name: "demo/scripts", typeflag: tar.TypeSymlink, linkname: "../.."}, entry{name: "demo/scripts/evil", typeflag: tar.TypeReg}),
+			wantErr: "links and special files are not allowed",
+		},
+		{
+			name:    "symlink in the skill",
+			entries: append(skillFiles, entry{name: "demo/README.md", typeflag: tar.TypeSymlink, linkname: "SKILL.md"}),
+			wantErr: "links and special files are not allowed",
+		},
+		{
+			name:    "hard link",
+			entries: append(skillFiles, entry{name: "demo/passwd", typeflag: tar.TypeLink, linkname: "/etc/passwd"}),
+			wantErr: "links and special files are not allowed",
+		},
+		{
+			name:    "device",
+			entries: append(skillFiles, entry{name: "demo/null", typeflag: tar.TypeChar}),
+			wantErr: "links and special files are not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "demo.tar.gz")
+			writeTarball(t, archive, tt.entries)
+
+			dest := filepath.Join(dir, "a", "b", "dest")
+			err := extractTarball(archive, dest)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := os.Stat(filepath.Join(dest, "demo", "README.md")); err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("extractTarball() = %v, want an error with %q", err, tt.wantErr)
+			}
+			// Nothing was written next to the skill directory
+			for _, path := range []string{filepath.Join(dir, "a", "b", "evil"), filepath.Join(dir, "a", "evil"), filepath.Join(dir, "evil"), "/tmp/evil"} {
+				if _, err := os.Lstat(path); err == nil {
+					t.Errorf("%s was written", path)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyDirRejectsSymlinks(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "SKILL.md"), []byte("---\nname: demo\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(src, "passwd")); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "demo")
+	if err := copyDir(src, dest); err == nil || !strings.Contains(err.Error(), "unsupported file type") {
+		t.Fatalf("copyDir() = %v, want the symlink rejected", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "passwd")); err == nil {
+		t.Error("the symlink was copied")
+	}
+}
@@ -0,0 +1,78 @@
+package skill
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultInterpreters maps script extensions to the command running them.
+// Per-skill overrides are set with `interpreters` in the skills config.
+var DefaultInterpreters = map[string]string{
+	".sh":   "bash",
+	".bash": "bash",
+	".py":   "python3",
+	".js":   "node",
+	".mjs":  "node",
+	".rb":   "ruby",
+	".pl":   "perl",
+	".go":   "go run",
+}
+
+// ScriptCommand returns the command line running a script, the script path last.
+// The interpreter is taken from overrides (by extension), then the shebang, then
+// DefaultInterpreters; other executable files (e.g. compiled binaries) run directly.
+func ScriptCommand(path string, overrides map[string]string) ([]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if command, ok := overrides[ext]; ok && strings.TrimSpace(command) != "" {
+		return append(strings.Fields(command), path), nil
+	}
+
+	if shebang := readShebang(path); shebang != nil {
+		return append(shebang, path), nil
+	}
+	if command, ok := DefaultInterpreters[ext]; ok {
+		return append(strings.Fields(command), path), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return nil, fmt.Errorf("no interpreter for %s: unknown extension, no shebang and not executable", filepath.Base(path))
+	}
+	return []string{path}, nil
+}
+
+// IsScript reports whether a file in scripts/ can be run: it has a known
+// extension, a shebang or the executable bit
+func IsScript(path string, overrides map[string]string) bool {
+	_, err := ScriptCommand(path, overrides)
+	return err == nil
+}
+
+// readShebang returns the interpreter and arguments of the "#!" line of a file, nil if it has none
+func readShebang(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil
+	}
+	if !bytes.HasPrefix(line, []byte("#!")) {
+		return nil
+	}
+	fields := strings.Fields(string(line[2:]))
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
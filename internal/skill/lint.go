@@ -0,0 +1,591 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint severities
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// Frontmatter limits, following the Agent Skills specification
+const (
+	maxNameLength          = 64
+	maxDescriptionLength   = 1024
+	maxCompatibilityLength = 500
+)
+
+// bashSyntaxTimeout bounds a single `bash -n` check
+const bashSyntaxTimeout = 10 * time.Second
+
+var (
+	// skillNamePattern is the allowed form of a skill name: lowercase words separated by hyphens
+	skillNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+	// versionPattern is a semantic version with an optional "v" prefix
+	versionPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+	// fileRefPattern matches references to bundled files in the SKILL.md body
+	fileRefPattern = regexp.MustCompile(`(?:^|[\s("'\x60])(?:\./)?((?:scripts|references|examples)/[A-Za-z0-9_.\-/]*[A-Za-z0-9_\-])`)
+)
+
+// Known SKILL.md frontmatter fields
+var (
+	requiredFrontmatterFields = []string{"name", "description"}
+	optionalFrontmatterFields = []string{"license", "compatibility", "version", "metadata", "allowed-tools"}
+)
+
+// LintIssue is a problem found in a skill
+type LintIssue struct {
+	Skill    string `json:"skill"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", location, i.Severity, i.Message, i.Rule)
+}
+
+// LintReport is the result of linting one or more skills
+type LintReport struct {
+	Skills   []string    `json:"skills"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+	Issues   []LintIssue `json:"issues"`
+}
+
+// OK reports whether the report has no errors
+func (r *LintReport) OK() bool {
+	return r.Errors == 0
+}
+
+// WriteJSON writes the report as JSON
+func (r *LintReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes one line per issue
+func (r *LintReport) WriteText(w io.Writer) {
+	for _, issue := range r.Issues {
+		fmt.Fprintln(w, issue)
+	}
+	fmt.Fprintf(w, "%d skill(s) checked: %d error(s), %d warning(s)\n", len(r.Skills), r.Errors, r.Warnings)
+}
+
+// Linter validates skill directories: the SKILL.md frontmatter, the files referenced
+// in its body, the scripts, marketplace.json and the example YAML files
+type Linter struct {
+	report *LintReport
+}
+
+// LintSkills lints the given skill directories
+func LintSkills(dirs ...string) *LintReport {
+	l := &Linter{report: &LintReport{Issues: []LintIssue{}}}
+	for _, dir := range dirs {
+		l.lintSkill(dir)
+	}
+	sort.SliceStable(l.report.Issues, func(i, j int) bool {
+		a, b := l.report.Issues[i], l.report.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.report
+}
+
+// FindSkillDirs returns every directory below root that contains a SKILL.md,
+// skipping hidden directories
+func FindSkillDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && info.Name() == "SKILL.md" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// SkillDirOf returns the skill directory containing path: the closest directory,
+// starting at path itself, that has a SKILL.md
+func SkillDirOf(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir := abs
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err == nil {
+			// Keep paths in the report relative, as pre-commit passes them
+			if wd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(wd, dir); err == nil && !strings.HasPrefix(rel, "..") {
+					return rel, nil
+				}
+			}
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%s is not inside a skill directory", path)
+		}
+		dir = parent
+	}
+}
+
+// add records an issue
+func (l *Linter) add(skill, file string, line int, rule, severity, format string, args ...any) {
+	l.report.Issues = append(l.report.Issues, LintIssue{
+		Skill:    skill,
+		File:     file,
+		Line:     line,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	if severity == LintError {
+		l.report.Errors++
+	} else {
+		l.report.Warnings++
+	}
+}
+
+// lintSkill runs every check on one skill directory
+func (l *Linter) lintSkill(dir string) {
+	skillPath := filepath.Join(dir, "SKILL.md")
+	name := filepath.Base(dir)
+	if n, _, ok := ParseVersionedDirName(name); ok {
+		name = n
+	}
+	l.report.Skills = append(l.report.Skills, dir)
+
+	data, err := os.ReadFile(skillPath)
+	if err != nil {
+		l.add(name, skillPath, 0, "skill-md", LintError, "cannot read SKILL.md: %v", err)
+		return
+	}
+
+	metadata, bodyLine := l.lintFrontmatter(name, dir, skillPath, data)
+	version := ""
+	if metadata != nil {
+		if metadata.Name != "" {
+			name = metadata.Name
+		}
+		version = metadata.Version
+	}
+
+	referenced := l.lintReferences(name, dir, skillPath, data, bodyLine)
+	l.lintScripts(name, dir, referenced)
+	l.lintActions(name, dir)
+	l.lintMarketplace(name, dir, version)
+	l.lintExamples(name, dir)
+}
+
+// lintFrontmatter validates the frontmatter against the schema and returns the
+// parsed metadata and the first line of the body
+func (l *Linter) lintFrontmatter(name, dir, skillPath string, data []byte) (*SkillMetadata, int) {
+	if bytes.Contains(data, []byte("\r\n")) {
+		l.add(name, skillPath, 0, "line-endings", LintWarning, "SKILL.md has Windows (CRLF) line endings")
+	}
+
+	frontmatter, _, err := extractFrontmatter(string(data))
+	if err != nil {
+		l.add(name, skillPath, 1, "frontmatter", LintError, "%v", err)
+		return nil, 0
+	}
+	bodyLine := strings.Count(frontmatter, "\n") + 4 // After the opening ---, the frontmatter lines and the closing ---
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		l.add(name, skillPath, 2, "frontmatter", LintError, "invalid frontmatter YAML: %v", err)
+		return nil, bodyLine
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		l.add(name, skillPath, 2, "frontmatter", LintError, "frontmatter must be a YAML mapping")
+		return nil, bodyLine
+	}
+
+	// Check field names and types; a line is the frontmatter line + 1 for the opening ---
+	fields := doc.Content[0].Content
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, value := fields[i], fields[i+1]
+		line := key.Line + 1
+		if seen[key.Value] {
+			l.add(name, skillPath, line, "frontmatter-duplicate", LintError, "duplicate field %q", key.Value)
+		}
+		seen[key.Value] = true
+
+		if !slices.Contains(requiredFrontmatterFields, key.Value) && !slices.Contains(optionalFrontmatterFields, key.Value) {
+			l.add(name, skillPath, line, "frontmatter-unknown", LintWarning, "unknown field %q", key.Value)
+			continue
+		}
+		switch key.Value {
+		case "metadata":
+			if value.Kind != yaml.MappingNode {
+				l.add(name, skillPath, line, "frontmatter-type", LintError, "field %q must be a mapping", key.Value)
+			}
+		case "allowed-tools":
+			if value.Kind != yaml.ScalarNode && value.Kind != yaml.SequenceNode {
+				l.add(name, skillPath, line, "frontmatter-type", LintError, "field %q must be a string or a list", key.Value)
+			}
+		default:
+			if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+				l.add(name, skillPath, line, "frontmatter-type", LintError, "field %q must be a string", key.Value)
+			}
+		}
+	}
+	for _, field := range requiredFrontmatterFields {
+		if !seen[field] {
+			l.add(name, skillPath, 1, "frontmatter-required", LintError, "missing required field %q", field)
+		}
+	}
+
+	var metadata SkillMetadata
+	if err := doc.Decode(&metadata); err != nil {
+		l.add(name, skillPath, 2, "frontmatter-type", LintError, "invalid frontmatter: %v", err)
+		return nil, bodyLine
+	}
+
+	line := func(field string) int {
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i].Value == field {
+				return fields[i].Line + 1
+			}
+		}
+		return 1
+	}
+
+	if metadata.Name != "" {
+		switch {
+		case len(metadata.Name) > maxNameLength:
+			l.add(name, skillPath, line("name"), "frontmatter-name", LintError, "name is longer than %d characters", maxNameLength)
+		case !skillNamePattern.MatchString(metadata.Name):
+			l.add(name, skillPath, line("name"), "frontmatter-name", LintError, "name %q must be lowercase letters, digits and single hyphens", metadata.Name)
+		}
+		if dirName := filepath.Base(dir); dirName != metadata.Name && dirName != VersionedDirName(metadata.Name, metadata.Version) {
+			l.add(name, skillPath, line("name"), "frontmatter-name", LintError, "name %q does not match the directory name %q", metadata.Name, dirName)
+		}
+	}
+	if seen["description"] && strings.TrimSpace(metadata.Description) == "" {
+		l.add(name, skillPath, line("description"), "frontmatter-description", LintError, "description cannot be empty")
+	}
+	if len(metadata.Description) > maxDescriptionLength {
+		l.add(name, skillPath, line("description"), "frontmatter-description", LintError, "description is longer than %d characters", maxDescriptionLength)
+	}
+	if len(metadata.Compatibility) > maxCompatibilityLength {
+		l.add(name, skillPath, line("compatibility"), "frontmatter-compatibility", LintError, "compatibility is longer than %d characters", maxCompatibilityLength)
+	}
+	if metadata.Version != "" && !versionPattern.MatchString(metadata.Version) {
+		l.add(name, skillPath, line("version"), "frontmatter-version", LintError, "version %q is not a semantic version", metadata.Version)
+	}
+
+	return &metadata, bodyLine
+}
+
+// lintReferences checks that every bundled file referenced in the body exists and
+// returns the referenced scripts
+func (l *Linter) lintReferences(name, dir, skillPath string, data []byte, bodyLine int) map[string]bool {
+	scripts := make(map[string]bool)
+	if bodyLine == 0 {
+		return scripts
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	reported := make(map[string]bool)
+	for i := bodyLine - 1; i < len(lines); i++ {
+		for _, m := range fileRefPattern.FindAllStringSubmatch(lines[i], -1) {
+			ref := strings.TrimSuffix(m[1], "/")
+			if strings.HasPrefix(ref, "scripts/") {
+				scripts[ref] = true
+			}
+			if reported[ref] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(ref))); err != nil {
+				reported[ref] = true
+				l.add(name, skillPath, i+1, "missing-file", LintError, "referenced file %s does not exist", ref)
+			}
+		}
+	}
+	return scripts
+}
+
+// lintScripts checks that scripts are executable and that shell scripts pass `bash -n`
+func (l *Linter) lintScripts(name, dir string, referenced map[string]bool) {
+	scriptsDir := filepath.Join(dir, "scripts")
+	entries, err := os.ReadDir(scriptsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.add(name, scriptsDir, 0, "scripts", LintError, "cannot read scripts directory: %v", err)
+		}
+		return
+	}
+
+	bash, bashErr := exec.LookPath("bash")
+	if bashErr != nil && len(entries) > 0 {
+		l.add(name, scriptsDir, 0, "script-syntax", LintWarning, "bash not found, skipping syntax checks")
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(scriptsDir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			l.add(name, path, 0, "scripts", LintError, "cannot stat script: %v", err)
+			continue
+		}
+
+		if _, err := ScriptCommand(path, nil); err != nil {
+			l.add(name, path, 0, "script-executable", LintError, "script cannot be run: %v (add a shebang or chmod +x)", err)
+		} else if info.Mode().Perm()&0111 == 0 && readShebang(path) != nil {
+			l.add(name, path, 0, "script-executable", LintWarning, "script has a shebang but is not executable (chmod +x)")
+		}
+		if !referenced["scripts/"+entry.Name()] {
+			l.add(name, path, 0, "script-unreferenced", LintWarning, "script is not referenced in SKILL.md")
+		}
+
+		if bashErr == nil && isShellScript(path) {
+			if msg, err := bashSyntaxCheck(bash, path); err != nil {
+				l.add(name, path, 0, "script-syntax", LintError, "bash -n failed: %s", msg)
+			}
+		}
+	}
+}
+
+// isShellScript reports whether a script is a shell script, by extension or shebang
+func isShellScript(path string) bool {
+	if strings.HasSuffix(path, ".sh") || strings.HasSuffix(path, ".bash") {
+		return true
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 64)
+	n, _ := f.Read(head)
+	firstLine, _, _ := bytes.Cut(head[:n], []byte("\n"))
+	return bytes.HasPrefix(firstLine, []byte("#!")) &&
+		(bytes.HasSuffix(firstLine, []byte("/sh")) || bytes.Contains(firstLine, []byte("bash")))
+}
+
+// bashSyntaxCheck runs `bash -n` on a script
+func bashSyntaxCheck(bash, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bashSyntaxTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, bash, "-n", path).CombinedOutput()
+	if err != nil {
+		// One issue per line in the text report
+		msg := strings.Join(strings.Fields(strings.ReplaceAll(strings.TrimSpace(string(out)), "\n", " | ")), " ")
+		if msg == "" {
+			msg = err.Error()
+		}
+		return msg, err
+	}
+	return "", nil
+}
+
+// lintActions validates actions.yaml: it must parse, name existing scripts, use known parameter
+// types, relative artifact patterns, valid retry policies and compensations of the same skill
+func (l *Linter) lintActions(name, dir string) {
+	path := filepath.Join(dir, ActionsFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return
+	}
+
+	actions, err := LoadActions(dir, filepath.Join(dir, "scripts"))
+	if err != nil {
+		l.add(name, path, 0, "actions", LintError, "%v", err)
+		return
+	}
+	for _, action := range actions {
+		if action.Script == "" {
+			l.add(name, path, 0, "actions", LintError, "action %s has no script", action.Name)
+		} else if _, err := os.Stat(filepath.Join(dir, "scripts", action.Script)); err != nil {
+			l.add(name, path, 0, "actions", LintError, "script %s of action %s does not exist", action.Script, action.Name)
+		}
+		for _, param := range action.Params {
+			switch param.Type {
+			case "", "string", "int", "bool", "file":
+			default:
+				l.add(name, path, 0, "actions", LintError, "parameter %s of action %s has unknown type %q", param.Name, action.Name, param.Type)
+			}
+		}
+		for _, pattern := range action.Artifacts {
+			if _, err := filepath.Match(pattern, ""); err != nil || filepath.IsAbs(pattern) || strings.HasPrefix(filepath.Clean(pattern), "..") {
+				l.add(name, path, 0, "actions", LintError, "artifact pattern %q of action %s must be a glob relative to the workspace", pattern, action.Name)
+			}
+		}
+		if err := action.Retry.Validate(); err != nil {
+			l.add(name, path, 0, "actions", LintError, "invalid retry policy of action %s: %v", action.Name, err)
+		}
+		if action.Retry.Attempts() > 1 && !action.Retryable() {
+			l.add(name, path, 0, "actions", LintWarning, "action %s is not idempotent, its retry policy is ignored", action.Name)
+		}
+		l.lintCompensation(name, path, action, actions)
+	}
+}
+
+// lintCompensation checks that the compensation of an action is another action of the
+// skill, referencing parameters the compensated action declares
+func (l *Linter) lintCompensation(name, path string, action Action, actions []Action) {
+	c := action.Compensate
+	if c == nil {
+		return
+	}
+	if c.Action == "" || c.Action == action.Name {
+		l.add(name, path, 0, "actions", LintError, "compensation of action %s must name another action", action.Name)
+		return
+	}
+	found := false
+	for _, a := range actions {
+		found = found || a.Name == c.Action
+	}
+	if !found {
+		l.add(name, path, 0, "actions", LintError, "compensation %s of action %s is not an action of the skill", c.Action, action.Name)
+	}
+	if len(action.Params) == 0 {
+		return
+	}
+	for _, ref := range c.References() {
+		declared := false
+		for _, param := range action.Params {
+			declared = declared || param.Name == ref
+		}
+		if !declared {
+			l.add(name, path, 0, "actions", LintWarning, "compensation of action %s references ${%s}, not a parameter of the action", action.Name, ref)
+		}
+	}
+}
+
+// lintMarketplace validates marketplace.json and its consistency with SKILL.md
+func (l *Linter) lintMarketplace(name, dir, version string) {
+	path := filepath.Join(dir, MarketplaceFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		l.add(name, path, 0, "marketplace", LintError, "cannot read %s: %v", MarketplaceFile, err)
+		return
+	}
+
+	var marketplace Marketplace
+	if err := json.Unmarshal(data, &marketplace); err != nil {
+		line := 0
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line = bytes.Count(data[:syntaxErr.Offset], []byte("\n")) + 1
+		}
+		l.add(name, path, line, "marketplace", LintError, "invalid JSON: %v", err)
+		return
+	}
+
+	if marketplace.Name == "" {
+		l.add(name, path, 0, "marketplace", LintError, "missing required field \"name\"")
+	} else if marketplace.Name != name {
+		l.add(name, path, 0, "marketplace", LintError, "name %q does not match the SKILL.md name %q", marketplace.Name, name)
+	}
+	if marketplace.Description == "" {
+		l.add(name, path, 0, "marketplace", LintError, "missing required field \"description\"")
+	}
+	switch {
+	case marketplace.Version == "":
+		l.add(name, path, 0, "marketplace", LintError, "missing required field \"version\"")
+	case !versionPattern.MatchString(marketplace.Version):
+		l.add(name, path, 0, "marketplace", LintError, "version %q is not a semantic version", marketplace.Version)
+	case version != "" && CompareVersions(version, marketplace.Version) != 0:
+		l.add(name, path, 0, "marketplace", LintError, "version %s does not match the SKILL.md version %s", marketplace.Version, version)
+	}
+}
+
+// lintExamples checks that every example YAML file parses
+func (l *Linter) lintExamples(name, dir string) {
+	examplesDir := filepath.Join(dir, "examples")
+	err := filepath.Walk(examplesDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == examplesDir {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || (!strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml")) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			l.add(name, path, 0, "example-yaml", LintError, "cannot read example: %v", err)
+			return nil
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var doc yaml.Node
+			err := dec.Decode(&doc)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				l.add(name, path, yamlErrorLine(err), "example-yaml", LintError, "invalid YAML: %v", err)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		l.add(name, examplesDir, 0, "example-yaml", LintError, "cannot read examples: %v", err)
+	}
+}
+
+// yamlLinePattern extracts the line number from a yaml.v3 error
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLine returns the line reported in a YAML error, or 0
+func yamlErrorLine(err error) int {
+	m := yamlLinePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	var line int
+	fmt.Sscanf(m[1], "%d", &line)
+	return line
+}
@@ -1,16 +1,26 @@
 package skill
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/hb-chen/opskills/pkg/logger"
 )
 
-// Loader loads skills from a directory
+// Loader loads skills from a directory.
+// Skills installed by `skill install` live in <name>@<version> directories next to
+// skills copied by hand; when several versions of a skill are present the pinned
+// version, or else the highest one, is loaded.
 type Loader struct {
 	skillsDir string
+	pins      map[string]string // Pinned version per skill name
+	lockPath  string            // Lockfile used to verify installed versions, empty to skip
 }
 
 // NewLoader creates a new skill loader
@@ -20,40 +30,244 @@ func NewLoader(skillsDir string) *Loader {
 	}
 }
 
+// Dir returns the skills directory
+func (l *Loader) Dir() string {
+	return l.skillsDir
+}
+
+// SetPins sets the pinned version per skill name
+func (l *Loader) SetPins(pins map[string]string) {
+	l.pins = pins
+}
+
+// SetLockfile sets the lockfile used to verify the checksum of installed skill versions.
+// It is re-read on every load so that installs are picked up by hot reload.
+func (l *Loader) SetLockfile(path string) {
+	l.lockPath = path
+}
+
+// LoadError describes a SKILL.md that could not be loaded
+type LoadError struct {
+	Path string
+	Name string // Set when the skill name is known (e.g. a previous version is kept)
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// ReloadResult summarizes the changes made by Reload
+type ReloadResult struct {
+	Events []RegistryEvent
+	Errors []*LoadError
+}
+
 // LoadAll loads all skills from the skills directory
 func (l *Loader) LoadAll() ([]*Skill, error) {
+	skills, loadErrs, err := l.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, loadErr := range loadErrs {
+		// Log error but continue loading other skills
+		logger.Warnf("Failed to load skill from %s: %v", loadErr.Path, loadErr.Err)
+	}
+
+	return skills, nil
+}
+
+// Reload re-parses every skill and atomically replaces the registry contents.
+// A skill that fails to parse keeps its last good version in the registry.
+func (l *Loader) Reload(registry *Registry) (*ReloadResult, error) {
+	skills, loadErrs, err := l.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the last good version of skills that no longer parse
+	loaded := make(map[string]bool, len(skills))
+	for _, s := range skills {
+		loaded[s.Name] = true
+	}
+	previous := make(map[string]*Skill)
+	for _, s := range registry.List() {
+		previous[s.SKILLPath] = s
+	}
+	for _, loadErr := range loadErrs {
+		if old, ok := previous[loadErr.Path]; ok && !loaded[old.Name] {
+			loadErr.Name = old.Name
+			skills = append(skills, old)
+			loaded[old.Name] = true
+		}
+	}
+
+	events, err := registry.Replace(skills)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReloadResult{Events: events, Errors: loadErrs}, nil
+}
+
+// scan parses every SKILL.md under the skills directory and selects one version per skill
+func (l *Loader) scan() ([]*Skill, []*LoadError, error) {
 	var skills []*Skill
+	var loadErrs []*LoadError
 
 	// Check if skills directory exists
 	if _, err := os.Stat(l.skillsDir); os.IsNotExist(err) {
-		return skills, fmt.Errorf("skills directory does not exist: %s", l.skillsDir)
+		return skills, nil, fmt.Errorf("skills directory does not exist: %s", l.skillsDir)
+	}
+
+	var lock *Lockfile
+	if l.lockPath != "" {
+		var err error
+		if lock, err = LoadLockfile(l.lockPath); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	var names []string
+	candidates := make(map[string][]*Skill)
+
 	// Walk through the skills directory
 	err := filepath.Walk(l.skillsDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Skip hidden directories (.git, in-progress installs)
+		if info.IsDir() && path != l.skillsDir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+
 		// Look for SKILL.md files
 		if info.Name() == "SKILL.md" {
-			skill, err := ParseSKILL(path)
+			skill, err := l.loadSkillFile(path)
 			if err != nil {
-				// Log error but continue loading other skills
-				logger.Warnf("Failed to load skill from %s: %v", path, err)
+				loadErrs = append(loadErrs, &LoadError{Path: path, Err: err})
 				return nil
 			}
-			skills = append(skills, skill)
+			if err := l.verifyInstalled(skill, lock); err != nil {
+				loadErrs = append(loadErrs, &LoadError{Path: path, Err: err})
+				return nil
+			}
+			if _, exists := candidates[skill.Name]; !exists {
+				names = append(names, skill.Name)
+			}
+			candidates[skill.Name] = append(candidates[skill.Name], skill)
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk skills directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to walk skills directory: %w", err)
 	}
 
-	return skills, nil
+	for _, name := range names {
+		skill, errs := l.selectVersion(name, candidates[name])
+		if skill != nil {
+			skills = append(skills, skill)
+		}
+		loadErrs = append(loadErrs, errs...)
+	}
+
+	return skills, loadErrs, nil
+}
+
+// installedDir returns the directory name of a skill installed by `skill install`,
+// or false for a skill copied by hand
+func (l *Loader) installedDir(skill *Skill) (string, bool) {
+	rel, err := filepath.Rel(l.skillsDir, skill.BasePath)
+	if err != nil || strings.ContainsRune(rel, filepath.Separator) {
+		return "", false
+	}
+	if _, _, ok := ParseVersionedDirName(rel); !ok {
+		return "", false
+	}
+	return rel, true
+}
+
+// verifyInstalled checks an installed skill version against the lockfile
+func (l *Loader) verifyInstalled(skill *Skill, lock *Lockfile) error {
+	dir, ok := l.installedDir(skill)
+	if !ok || lock == nil {
+		return nil
+	}
+
+	if dir != VersionedDirName(skill.Name, skill.Version) {
+		return fmt.Errorf("directory %s does not match skill %s version %q", dir, skill.Name, skill.Version)
+	}
+	entry, ok := lock.FindDir(dir)
+	if !ok {
+		return fmt.Errorf("installed skill %s is not in the lockfile %s", dir, l.lockPath)
+	}
+	if entry.Digest != skill.Digest {
+		return fmt.Errorf("integrity check failed for %s: checksum does not match the lockfile", dir)
+	}
+
+	return nil
+}
+
+// selectVersion picks the pinned or else the highest version of a skill
+func (l *Loader) selectVersion(name string, candidates []*Skill) (*Skill, []*LoadError) {
+	var loadErrs []*LoadError
+
+	if pin := l.pins[name]; pin != "" {
+		var selected *Skill
+		for _, s := range candidates {
+			if CompareVersions(s.Version, pin) == 0 && (selected == nil || l.preferred(s, selected)) {
+				selected = s
+			}
+		}
+		if selected == nil {
+			return nil, []*LoadError{{
+				Path: candidates[0].SKILLPath,
+				Name: name,
+				Err:  fmt.Errorf("pinned version %s of skill %s is not installed", pin, name),
+			}}
+		}
+		candidates = []*Skill{selected}
+	}
+
+	selected := candidates[0]
+	for _, s := range candidates[1:] {
+		if l.preferred(s, selected) {
+			selected = s
+		}
+	}
+
+	for _, s := range candidates {
+		if s == selected {
+			continue
+		}
+		_, installed := l.installedDir(s)
+		_, selectedInstalled := l.installedDir(selected)
+		if !installed && !selectedInstalled && CompareVersions(s.Version, selected.Version) == 0 {
+			loadErrs = append(loadErrs, &LoadError{
+				Path: s.SKILLPath,
+				Err:  fmt.Errorf("duplicate skill name %q, already loaded from %s", name, selected.SKILLPath),
+			})
+			continue
+		}
+		logger.Debugf("[Skills] Skill %s: using version %q from %s, ignoring version %q", name, selected.Version, selected.BasePath, s.Version)
+	}
+
+	return selected, loadErrs
+}
+
+// preferred reports whether a should be loaded instead of b: the higher version wins,
+// and an installed copy wins over a hand-copied one of the same version
+func (l *Loader) preferred(a, b *Skill) bool {
+	if c := CompareVersions(a.Version, b.Version); c != 0 {
+		return c > 0
+	}
+	_, aInstalled := l.installedDir(a)
+	_, bInstalled := l.installedDir(b)
+	return aInstalled && !bInstalled
 }
 
 // LoadSkill loads a specific skill by name
@@ -65,5 +279,69 @@ func (l *Loader) LoadSkill(name string) (*Skill, error) {
 		return nil, fmt.Errorf("skill not found: %s", name)
 	}
 
-	return ParseSKILL(skillPath)
+	return l.loadSkillFile(skillPath)
+}
+
+// loadSkillFile parses a SKILL.md and fingerprints its skill directory
+func (l *Loader) loadSkillFile(skillPath string) (*Skill, error) {
+	skill, err := ParseSKILL(skillPath)
+	if err != nil {
+		return nil, err
+	}
+	if skill.Name == "" {
+		return nil, fmt.Errorf("skill name cannot be empty")
+	}
+
+	digest, err := DirDigest(skill.BasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fingerprint skill directory: %w", err)
+	}
+	skill.Digest = digest
+
+	return skill, nil
+}
+
+// DirDigest returns a sha256 over the relative paths, modes and contents of
+// every regular file in dir
+func DirDigest(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%o\x00", filepath.ToSlash(rel), info.Mode().Perm())
+
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
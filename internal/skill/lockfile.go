@@ -0,0 +1,155 @@
+package skill
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lockfileHeader is written at the top of every lockfile
+const lockfileHeader = "# Generated by opskills-agent skill install. Do not edit.\n"
+
+// Lockfile records the checksum of every installed skill version
+type Lockfile struct {
+	Skills []LockEntry `yaml:"skills"`
+}
+
+// LockEntry is an installed skill version
+type LockEntry struct {
+	Name        string    `yaml:"name"`
+	Version     string    `yaml:"version"`
+	Dir         string    `yaml:"dir"`              // Directory name under the skills directory
+	Source      string    `yaml:"source"`           // Path of the tarball, directory or git repository
+	SourceType  string    `yaml:"source_type"`      // tarball, dir or git
+	Commit      string    `yaml:"commit,omitempty"` // Resolved commit for git sources
+	Digest      string    `yaml:"digest"`           // DirDigest of the installed directory
+	InstalledAt time.Time `yaml:"installed_at"`
+}
+
+// LoadLockfile reads a lockfile. A missing file is an empty lockfile.
+func LoadLockfile(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Lockfile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var lock Lockfile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+
+	return &lock, nil
+}
+
+// Save writes the lockfile, sorted by name and version
+func (l *Lockfile) Save(path string) error {
+	sort.Slice(l.Skills, func(i, j int) bool {
+		if l.Skills[i].Name != l.Skills[j].Name {
+			return l.Skills[i].Name < l.Skills[j].Name
+		}
+		return CompareVersions(l.Skills[i].Version, l.Skills[j].Version) < 0
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString(lockfileHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(l); err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create lockfile directory: %w", err)
+		}
+	}
+
+	// Write atomically so a running agent never reads a partial lockfile
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+
+	return nil
+}
+
+// Find returns the entry of an installed skill version
+func (l *Lockfile) Find(name, version string) (*LockEntry, bool) {
+	for i := range l.Skills {
+		if l.Skills[i].Name == name && l.Skills[i].Version == version {
+			return &l.Skills[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindDir returns the entry of the skill installed in a directory
+func (l *Lockfile) FindDir(dir string) (*LockEntry, bool) {
+	for i := range l.Skills {
+		if l.Skills[i].Dir == dir {
+			return &l.Skills[i], true
+		}
+	}
+	return nil, false
+}
+
+// Versions returns the entries of every installed version of a skill, oldest first
+func (l *Lockfile) Versions(name string) []LockEntry {
+	var entries []LockEntry
+	for _, entry := range l.Skills {
+		if entry.Name == name {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return CompareVersions(entries[i].Version, entries[j].Version) < 0
+	})
+	return entries
+}
+
+// Put adds an entry, replacing the entry of the same skill version
+func (l *Lockfile) Put(entry LockEntry) {
+	if existing, ok := l.Find(entry.Name, entry.Version); ok {
+		*existing = entry
+		return
+	}
+	l.Skills = append(l.Skills, entry)
+}
+
+// Remove removes the entry of a skill version
+func (l *Lockfile) Remove(name, version string) {
+	entries := l.Skills[:0]
+	for _, entry := range l.Skills {
+		if entry.Name != name || entry.Version != version {
+			entries = append(entries, entry)
+		}
+	}
+	l.Skills = entries
+}
+
+// Verify checks that the installed directory still matches the recorded digest
+func (e *LockEntry) Verify(skillsDir string) error {
+	digest, err := DirDigest(filepath.Join(skillsDir, e.Dir))
+	if err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", e.Dir, err)
+	}
+	if digest != e.Digest {
+		return fmt.Errorf("checksum mismatch for %s: lockfile has %s, found %s", e.Dir, e.Digest, digest)
+	}
+	return nil
+}
@@ -3,11 +3,16 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
 )
 
+// ErrClosed is returned by calls on a connection that is closed or broken,
+// e.g. because the server exited
+var ErrClosed = errors.New("MCP connection closed")
+
 // Client represents an MCP client
 type Client struct {
 	reader   io.Reader
@@ -18,6 +23,21 @@ type Client struct {
 	mu       sync.RWMutex
 	nextID   int64
 	idMu     sync.Mutex
+
+	// progress maps progress tokens of running calls to their handlers
+	progress map[string]func(ProgressParams)
+
+	// done is closed when the message loop stops, closeErr tells why
+	done      chan struct{}
+	closeErr  error
+	closeOnce sync.Once
+}
+
+// message is any message read by the client: a response, or a notification with a method
+type message struct {
+	JSONRPCResponse
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
 }
 
 // NewClient creates a new MCP client
@@ -28,10 +48,33 @@ func NewClient(reader io.Reader, writer io.Writer) *Client {
 		decoder:  json.NewDecoder(reader),
 		encoder:  json.NewEncoder(writer),
 		requests: make(map[interface{}]chan *JSONRPCResponse),
+		progress: make(map[string]func(ProgressParams)),
 		nextID:   1,
+		done:     make(chan struct{}),
 	}
 }
 
+// Closed reports whether the message loop has stopped, after which every call fails
+func (c *Client) Closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the client, failing pending and future calls
+func (c *Client) close(err error) {
+	c.closeOnce.Do(func() {
+		if err == nil {
+			err = io.EOF
+		}
+		c.closeErr = err
+		close(c.done)
+	})
+}
+
 // Initialize initializes the MCP connection
 func (c *Client) Initialize(ctx context.Context, clientInfo ClientInfo) (*InitializeResult, error) {
 	params := InitializeParams{
@@ -66,30 +109,36 @@ func (c *Client) Call(ctx context.Context, method string, params interface{}, re
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Create response channel
+	// Create response channel, keyed by the ID as printed since decoded IDs are float64
+	key := fmt.Sprint(id)
 	respChan := make(chan *JSONRPCResponse, 1)
 	c.mu.Lock()
-	c.requests[id] = respChan
+	c.requests[key] = respChan
 	c.mu.Unlock()
 
 	// Send request
 	if err := c.encoder.Encode(req); err != nil {
 		c.mu.Lock()
-		delete(c.requests, id)
+		delete(c.requests, key)
 		c.mu.Unlock()
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("%w: failed to send request: %v", ErrClosed, err)
 	}
 
 	// Wait for response
 	select {
 	case <-ctx.Done():
 		c.mu.Lock()
-		delete(c.requests, id)
+		delete(c.requests, key)
 		c.mu.Unlock()
 		return ctx.Err()
+	case <-c.done:
+		c.mu.Lock()
+		delete(c.requests, key)
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %v", ErrClosed, c.closeErr)
 	case resp := <-respChan:
 		c.mu.Lock()
-		delete(c.requests, id)
+		delete(c.requests, key)
 		c.mu.Unlock()
 
 		if resp.Error != nil {
@@ -106,8 +155,9 @@ func (c *Client) Call(ctx context.Context, method string, params interface{}, re
 	}
 }
 
-// Start starts the client message loop
-func (c *Client) Start(ctx context.Context) error {
+// Start starts the client message loop. When it returns, pending and future calls fail with ErrClosed.
+func (c *Client) Start(ctx context.Context) (err error) {
+	defer func() { c.close(err) }()
 	for {
 		select {
 		case <-ctx.Done():
@@ -115,17 +165,22 @@ func (c *Client) Start(ctx context.Context) error {
 		default:
 		}
 
-		var resp JSONRPCResponse
-		if err := c.decoder.Decode(&resp); err != nil {
+		var msg message
+		if err := c.decoder.Decode(&msg); err != nil {
 			if err == io.EOF {
 				return nil
 			}
 			return fmt.Errorf("failed to decode response: %w", err)
 		}
+		if msg.Method != "" {
+			c.handleNotification(msg.Method, msg.Params)
+			continue
+		}
+		resp := msg.JSONRPCResponse
 
 		// Find waiting request
 		c.mu.RLock()
-		respChan, exists := c.requests[resp.ID]
+		respChan, exists := c.requests[fmt.Sprint(resp.ID)]
 		c.mu.RUnlock()
 
 		if exists {
@@ -137,6 +192,23 @@ func (c *Client) Start(ctx context.Context) error {
 	}
 }
 
+// handleNotification dispatches a notification from the server, unknown ones are ignored
+func (c *Client) handleNotification(method string, params json.RawMessage) {
+	if method != NotificationProgress {
+		return
+	}
+	var progress ProgressParams
+	if err := json.Unmarshal(params, &progress); err != nil {
+		return
+	}
+	c.mu.RLock()
+	handler, exists := c.progress[fmt.Sprint(progress.ProgressToken)]
+	c.mu.RUnlock()
+	if exists {
+		handler(progress)
+	}
+}
+
 // ListTools lists available tools
 func (c *Client) ListTools(ctx context.Context) (*ToolsListResult, error) {
 	var result ToolsListResult
@@ -148,10 +220,31 @@ func (c *Client) ListTools(ctx context.Context) (*ToolsListResult, error) {
 
 // CallTool calls a tool
 func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*ToolCallResult, error) {
+	return c.CallToolWithProgress(ctx, name, arguments, nil)
+}
+
+// CallToolWithProgress calls a tool, passing its progress notifications to onProgress (if not nil)
+func (c *Client) CallToolWithProgress(ctx context.Context, name string, arguments map[string]interface{}, onProgress func(ProgressParams)) (*ToolCallResult, error) {
 	params := ToolCallParams{
 		Name:      name,
 		Arguments: arguments,
 	}
+	if onProgress != nil {
+		c.idMu.Lock()
+		token := fmt.Sprintf("progress-%d", c.nextID)
+		c.nextID++
+		c.idMu.Unlock()
+
+		params.Meta = &RequestMeta{ProgressToken: token}
+		c.mu.Lock()
+		c.progress[token] = onProgress
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.progress, token)
+			c.mu.Unlock()
+		}()
+	}
 
 	var result ToolCallResult
 	if err := c.Call(ctx, MethodToolsCall, params, &result); err != nil {
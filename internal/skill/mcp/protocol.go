@@ -24,6 +24,13 @@ type JSONRPCResponse struct {
 	Error   *JSONRPCError   `json:"error,omitempty"`
 }
 
+// JSONRPCNotification represents a JSON-RPC notification (a request without id)
+type JSONRPCNotification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
 // JSONRPCError represents a JSON-RPC error
 type JSONRPCError struct {
 	Code    int         `json:"code"`
@@ -45,6 +52,27 @@ const (
 	MethodShutdown        = "shutdown"
 )
 
+// MCP Notifications
+const (
+	NotificationToolsListChanged     = "notifications/tools/list_changed"
+	NotificationResourcesListChanged = "notifications/resources/list_changed"
+	NotificationProgress             = "notifications/progress"
+)
+
+// RequestMeta represents the _meta field of a request
+type RequestMeta struct {
+	// ProgressToken asks the server for progress notifications carrying this token
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// ProgressParams represents notifications/progress parameters
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
 // InitializeParams represents initialize request parameters
 type InitializeParams struct {
 	ProtocolVersion string                 `json:"protocolVersion"`
@@ -78,7 +106,9 @@ type ServerCapabilities struct {
 }
 
 // ToolsCapability indicates tools support
-type ToolsCapability struct{}
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
 
 // ResourcesCapability indicates resources support
 type ResourcesCapability struct {
@@ -113,6 +143,7 @@ type ToolsListResult struct {
 type ToolCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
 }
 
 // ToolCallResult represents tools/call response
@@ -226,6 +257,24 @@ func NewJSONRPCResponse(id interface{}, result interface{}, err *JSONRPCError) (
 	return resp, nil
 }
 
+// NewJSONRPCNotification creates a new JSON-RPC notification
+func NewJSONRPCNotification(method string, params interface{}) (*JSONRPCNotification, error) {
+	var paramsJSON json.RawMessage
+	if params != nil {
+		var err error
+		paramsJSON, err = json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+	}
+
+	return &JSONRPCNotification{
+		JSONRPC: JSONRPCVersion,
+		Method:  method,
+		Params:  paramsJSON,
+	}, nil
+}
+
 // NewJSONRPCError creates a new JSON-RPC error
 func NewJSONRPCError(code int, message string, data interface{}) *JSONRPCError {
 	return &JSONRPCError{
@@ -15,6 +15,10 @@ type Server struct {
 	capabilities ServerCapabilities
 	handlers     map[string]HandlerFunc
 	mu           sync.RWMutex
+
+	// encoder is the output of the running Serve loop, shared by responses and notifications
+	encoder *json.Encoder
+	encMu   sync.Mutex
 }
 
 // HandlerFunc represents a handler function for MCP methods
@@ -100,11 +104,46 @@ func (s *Server) handleInitialize(ctx context.Context, req *JSONRPCRequest) (*JS
 	return NewJSONRPCResponse(req.ID, result, nil)
 }
 
+// Notify sends a notification to the connected client.
+// Notifications sent before Serve is running are dropped.
+func (s *Server) Notify(method string, params interface{}) error {
+	notification, err := NewJSONRPCNotification(method, params)
+	if err != nil {
+		return err
+	}
+
+	s.encMu.Lock()
+	defer s.encMu.Unlock()
+	if s.encoder == nil {
+		return nil
+	}
+	if err := s.encoder.Encode(notification); err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return nil
+}
+
+// send writes a message under the encoder lock
+func (s *Server) send(encoder *json.Encoder, msg interface{}) error {
+	s.encMu.Lock()
+	defer s.encMu.Unlock()
+	return encoder.Encode(msg)
+}
+
 // Serve serves requests from a reader and writes responses to a writer
 func (s *Server) Serve(ctx context.Context, reader io.Reader, writer io.Writer) error {
 	decoder := json.NewDecoder(reader)
 	encoder := json.NewEncoder(writer)
 
+	s.encMu.Lock()
+	s.encoder = encoder
+	s.encMu.Unlock()
+	defer func() {
+		s.encMu.Lock()
+		s.encoder = nil
+		s.encMu.Unlock()
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -123,7 +162,7 @@ func (s *Server) Serve(ctx context.Context, reader io.Reader, writer io.Writer)
 				"Parse error",
 				nil,
 			))
-			encoder.Encode(resp)
+			s.send(encoder, resp)
 			continue
 		}
 
@@ -137,8 +176,13 @@ func (s *Server) Serve(ctx context.Context, reader io.Reader, writer io.Writer)
 			))
 		}
 
+		// Notifications (no id) get no response
+		if req.ID == nil {
+			continue
+		}
+
 		// Send response
-		if err := encoder.Encode(resp); err != nil {
+		if err := s.send(encoder, resp); err != nil {
 			return fmt.Errorf("failed to encode response: %w", err)
 		}
 	}
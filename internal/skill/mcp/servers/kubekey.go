@@ -10,6 +10,7 @@ import (
 	"github.com/hb-chen/opskills/internal/skill"
 	"github.com/hb-chen/opskills/internal/skill/direct"
 	"github.com/hb-chen/opskills/internal/skill/mcp"
+	"github.com/hb-chen/opskills/pkg/logger"
 )
 
 // KubeKeyServer implements an MCP server for KubeKey skills
@@ -18,6 +19,7 @@ type KubeKeyServer struct {
 	registry *skill.Registry
 	executor *direct.DirectExecutor
 	adapter  *skill.MCPAdapter
+	reloader *skill.Reloader
 }
 
 // NewKubeKeyServer creates a new KubeKey MCP server
@@ -44,10 +46,12 @@ func NewKubeKeyServer(skillsDir string) (*KubeKeyServer, error) {
 	
 	// Set capabilities
 	server.SetCapabilities(mcp.ServerCapabilities{
-		Tools: &mcp.ToolsCapability{},
+		Tools: &mcp.ToolsCapability{
+			ListChanged: true,
+		},
 		Resources: &mcp.ResourcesCapability{
 			Subscribe:   false,
-			ListChanged: false,
+			ListChanged: true,
 		},
 	})
 
@@ -57,10 +61,15 @@ func NewKubeKeyServer(skillsDir string) (*KubeKeyServer, error) {
 		registry: registry,
 		executor: executor,
 		adapter:  adapter,
+		// Only the kubekey skill directory is reloaded
+		reloader: skill.NewReloader(skill.NewLoader(filepath.Join(skillsDir, "kubekey")), registry),
 	}
 
 	kks.registerHandlers()
 
+	// Tell the client to refetch tools and resources whenever the skill changes
+	registry.Subscribe(kks.handleRegistryEvent)
+
 	return kks, nil
 }
 
@@ -100,7 +109,7 @@ func (kks *KubeKeyServer) handleToolsCall(ctx context.Context, params json.RawMe
 	}
 
 	// Get skill
-	skill, err := kks.registry.Get(callParams.Name)
+	s, err := kks.registry.Get(callParams.Name)
 	if err != nil {
 		return nil, fmt.Errorf("skill not found: %s", callParams.Name)
 	}
@@ -111,8 +120,40 @@ func (kks *KubeKeyServer) handleToolsCall(ctx context.Context, params json.RawMe
 		return nil, fmt.Errorf("failed to convert tool call: %w", err)
 	}
 
+	// Dry run calls run the scripts with SKILL_DRY_RUN=1
+	if dryRun, _ := skillParams[skill.DryRunArgument].(bool); dryRun {
+		delete(skillParams, skill.DryRunArgument)
+		ctx = skill.WithDryRun(ctx)
+	}
+
+	// Params holding secrets are passed to the scripts in their environment only
+	if value, ok := skillParams[skill.SecretParamsArgument]; ok {
+		delete(skillParams, skill.SecretParamsArgument)
+		names, _ := value.([]interface{})
+		secretParams := make([]string, 0, len(names))
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				secretParams = append(secretParams, name)
+			}
+		}
+		ctx = skill.WithSecretParams(ctx, secretParams)
+	}
+
+	// Scripts run in the workspace of the task and list their artifacts in it
+	if workspace, ok := skillParams[skill.WorkspaceArgument]; ok {
+		delete(skillParams, skill.WorkspaceArgument)
+		if workspace, ok := workspace.(string); ok && workspace != "" {
+			ctx = skill.WithWorkspace(ctx, workspace)
+		}
+	}
+
+	// Stream output lines as progress notifications when the client asked for progress
+	if callParams.Meta != nil && callParams.Meta.ProgressToken != nil {
+		ctx = skill.WithOutputHandler(ctx, kks.progressHandler(callParams.Meta.ProgressToken))
+	}
+
 	// Execute skill
-	result, err := kks.executor.Execute(skill, skillParams)
+	result, err := kks.executor.ExecuteContext(ctx, s, skillParams)
 	if err != nil {
 		return nil, fmt.Errorf("skill execution failed: %w", err)
 	}
@@ -126,6 +167,35 @@ func (kks *KubeKeyServer) handleToolsCall(ctx context.Context, params json.RawMe
 	return toolResult, nil
 }
 
+// SetSandbox sets the restrictions of the scripts the server runs
+func (kks *KubeKeyServer) SetSandbox(sandbox direct.Sandbox) error {
+	return kks.executor.SetSandbox(sandbox)
+}
+
+// SetInterpreters sets the interpreters of the scripts the server runs, by skill and file extension
+func (kks *KubeKeyServer) SetInterpreters(interpreters map[string]map[string]string) {
+	kks.executor.SetInterpreters(interpreters)
+}
+
+// progressHandler sends each output line as a progress notification, numbered by line
+func (kks *KubeKeyServer) progressHandler(token interface{}) skill.OutputHandler {
+	var lines float64
+	return func(line skill.OutputLine) {
+		lines++
+		message := line.Text
+		if line.Stream == skill.StreamStderr {
+			message = "[stderr] " + message
+		}
+		if err := kks.server.Notify(mcp.NotificationProgress, mcp.ProgressParams{
+			ProgressToken: token,
+			Progress:      lines,
+			Message:       message,
+		}); err != nil {
+			logger.Warnf("Failed to send progress notification: %v", err)
+		}
+	}
+}
+
 // handleResourcesList handles resources/list requests
 func (kks *KubeKeyServer) handleResourcesList(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	skills := kks.registry.List()
@@ -148,12 +218,16 @@ func (kks *KubeKeyServer) handleResourcesList(ctx context.Context, params json.R
 			entries, err := os.ReadDir(s.ScriptsPath)
 			if err == nil {
 				for _, entry := range entries {
-					if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sh" {
+					if !entry.IsDir() && skill.IsScript(filepath.Join(s.ScriptsPath, entry.Name()), nil) {
+						mimeType := "text/plain"
+						if filepath.Ext(entry.Name()) == ".sh" {
+							mimeType = "text/x-shellscript"
+						}
 						resources = append(resources, mcp.Resource{
 							URI:         fmt.Sprintf("skill://%s/script/%s", s.Name, entry.Name()),
 							Name:        fmt.Sprintf("%s: %s", s.Name, entry.Name()),
 							Description: fmt.Sprintf("Script file for %s", s.Name),
-							MimeType:    "text/x-shellscript",
+							MimeType:    mimeType,
 						})
 					}
 				}
@@ -266,6 +340,20 @@ func parseSkillURI(uri string) (skillName, resourcePath string) {
 	return path[:idx], path[idx+1:]
 }
 
+// Watch reloads the kubekey skill when its files change until ctx is done
+func (kks *KubeKeyServer) Watch(ctx context.Context) error {
+	return kks.reloader.Watch(ctx)
+}
+
+// handleRegistryEvent sends list_changed notifications for skill changes
+func (kks *KubeKeyServer) handleRegistryEvent(event skill.RegistryEvent) {
+	for _, method := range []string{mcp.NotificationToolsListChanged, mcp.NotificationResourcesListChanged} {
+		if err := kks.server.Notify(method, nil); err != nil {
+			logger.Warnf("Failed to send %s notification: %v", method, err)
+		}
+	}
+}
+
 // GetServer returns the underlying MCP server
 func (kks *KubeKeyServer) GetServer() *mcp.Server {
 	return kks.server
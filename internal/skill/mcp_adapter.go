@@ -101,16 +101,23 @@ func (a *MCPAdapter) SkillResultToToolResult(result *ExecutionResult) (*mcp.Tool
 	content := []mcp.Content{}
 
 	if result.Success {
-		content = append(content, mcp.Content{
+		output := mcp.Content{
 			Type: "text",
 			Text: result.Output,
-		})
+		}
+		if len(result.Artifacts) > 0 {
+			output.Data = map[string]interface{}{"artifacts": result.Artifacts}
+		}
+		content = append(content, output)
 	} else {
 		// Include error information
 		errorData := map[string]interface{}{
 			"error":     result.Error,
 			"exit_code": result.ExitCode,
 		}
+		if len(result.Artifacts) > 0 {
+			errorData["artifacts"] = result.Artifacts
+		}
 		content = append(content, mcp.Content{
 			Type: "text",
 			Text: fmt.Sprintf("Error: %s", result.Error),
@@ -0,0 +1,72 @@
+package skill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// NativeSkill is a skill implemented in Go and run in-process by the router.
+// The action to perform is passed in params["action"], like for script skills.
+type NativeSkill interface {
+	Name() string
+	Description() string
+	Actions() []Action
+	Execute(ctx context.Context, params ExecutionParams) (*NativeResult, error)
+}
+
+// NativeResult is the structured output of a native skill
+type NativeResult struct {
+	Output string         // Human readable output, Data as JSON when empty
+	Data   map[string]any // Structured output
+}
+
+// nativeDigest marks native skills, which have no files to hash
+const nativeDigest = "native"
+
+// newNativeSkill wraps a native skill for the registry
+func newNativeSkill(native NativeSkill) *Skill {
+	return &Skill{
+		Name:        native.Name(),
+		Description: native.Description(),
+		Actions:     native.Actions(),
+		Native:      native,
+		LoadedAt:    time.Now(),
+		Digest:      nativeDigest,
+	}
+}
+
+// executeNative runs a native skill and converts its result
+func executeNative(ctx context.Context, s *Skill, params ExecutionParams) (*ExecutionResult, error) {
+	if s.Native == nil {
+		return nil, fmt.Errorf("skill %s is not a native skill", s.Name)
+	}
+	if action, ok := params["action"].(string); ok && action != "" {
+		if _, ok := s.Action(action); !ok {
+			return nil, fmt.Errorf("skill %s has no action %s", s.Name, action)
+		}
+	}
+
+	start := time.Now()
+	native, err := s.Native.Execute(ctx, params)
+	result := &ExecutionResult{
+		Success:   err == nil,
+		Duration:  time.Since(start),
+		Timestamp: time.Now(),
+	}
+	if native != nil {
+		result.Output = native.Output
+		result.Data = native.Data
+		if result.Output == "" && native.Data != nil {
+			data, _ := json.MarshalIndent(native.Data, "", "  ")
+			result.Output = string(data)
+		}
+	}
+	if err != nil {
+		result.ExitCode = 1
+		result.Error = err.Error()
+		return result, err
+	}
+	return result, nil
+}
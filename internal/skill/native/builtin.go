@@ -0,0 +1,43 @@
+// Package native provides the built-in skills implemented in Go
+package native
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// Builtins returns every built-in skill by name
+func Builtins() map[string]skill.NativeSkill {
+	builtins := make(map[string]skill.NativeSkill)
+	for _, s := range []skill.NativeSkill{HTTPCheck{}, FileTemplate{}, Wait{}} {
+		builtins[s.Name()] = s
+	}
+	return builtins
+}
+
+// BuiltinNames returns the names of the built-in skills, sorted
+func BuiltinNames() []string {
+	var names []string
+	for name := range Builtins() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register registers the named built-in skills
+func Register(registry *skill.Registry, names []string) error {
+	builtins := Builtins()
+	for _, name := range names {
+		s, ok := builtins[name]
+		if !ok {
+			return fmt.Errorf("unknown built-in skill %s (available: %v)", name, BuiltinNames())
+		}
+		if err := registry.RegisterNative(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
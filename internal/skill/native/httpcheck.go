@@ -0,0 +1,134 @@
+package native
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// maxHealthBody bounds the response body read by a health check
+const maxHealthBody = 64 * 1024
+
+// HTTPCheck checks that an HTTP endpoint answers with the expected status and content
+type HTTPCheck struct{}
+
+// Name returns the skill name
+func (HTTPCheck) Name() string {
+	return "http-check"
+}
+
+// Description returns the skill description
+func (HTTPCheck) Description() string {
+	return "Check the health of an HTTP endpoint: expected status code, response content and latency. Use to verify a service, ingress or API is up after a change."
+}
+
+// Actions returns the actions of the skill
+func (HTTPCheck) Actions() []skill.Action {
+	return []skill.Action{
+		{
+			Name:        "check",
+			Description: "Request a URL and fail unless the status (and content) match",
+			Params: []skill.ActionParam{
+				{Name: "url", Type: "string", Description: "URL to request", Required: true},
+				{Name: "method", Type: "string", Description: "HTTP method, GET by default"},
+				{Name: "expect_status", Type: "int", Description: "Expected status code, any 2xx by default"},
+				{Name: "contains", Type: "string", Description: "Text the response body must contain"},
+				{Name: "timeout", Type: "string", Description: "Timeout of each attempt, 10s by default"},
+				{Name: "retries", Type: "int", Description: "Attempts after the first failure, 0 by default"},
+				{Name: "insecure", Type: "bool", Description: "Skip TLS certificate verification"},
+			},
+			DryRun:   true,
+			ReadOnly: true,
+		},
+	}
+}
+
+// Execute runs the health check
+func (HTTPCheck) Execute(ctx context.Context, params skill.ExecutionParams) (*skill.NativeResult, error) {
+	p := Params(params)
+	url, err := p.Required("url")
+	if err != nil {
+		return nil, err
+	}
+	method := strings.ToUpper(p.String("method", http.MethodGet))
+	contains := p.String("contains", "")
+	expectStatus, err := p.Int("expect_status", 0)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := p.Duration("timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := p.Int("retries", 0)
+	if err != nil {
+		return nil, err
+	}
+	insecure, err := p.Bool("insecure", false)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	if insecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	var data map[string]any
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return &skill.NativeResult{Data: data}, ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+		data, err = checkOnce(ctx, client, method, url, expectStatus, contains)
+		data["attempts"] = attempt + 1
+		if err == nil {
+			return &skill.NativeResult{
+				Output: fmt.Sprintf("%s %s: %v in %vms", method, url, data["status"], data["latency_ms"]),
+				Data:   data,
+			}, nil
+		}
+	}
+	return &skill.NativeResult{Data: data}, err
+}
+
+// checkOnce performs one request and checks the response
+func checkOnce(ctx context.Context, client *http.Client, method, url string, expectStatus int, contains string) (map[string]any, error) {
+	data := map[string]any{"url": url, "healthy": false}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return data, fmt.Errorf("invalid request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	data["latency_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		return data, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHealthBody))
+	data["status"] = resp.StatusCode
+
+	switch {
+	case expectStatus != 0 && resp.StatusCode != expectStatus:
+		return data, fmt.Errorf("status %d, expected %d", resp.StatusCode, expectStatus)
+	case expectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299):
+		return data, fmt.Errorf("status %d, expected 2xx", resp.StatusCode)
+	case contains != "" && !strings.Contains(string(body), contains):
+		return data, fmt.Errorf("response does not contain %q", contains)
+	}
+
+	data["healthy"] = true
+	return data, nil
+}
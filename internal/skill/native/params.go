@@ -0,0 +1,128 @@
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// Params reads typed values from execution params, which come from plans as
+// JSON values or strings
+type Params skill.ExecutionParams
+
+// String returns a string param, def when it is missing
+func (p Params) String(key, def string) string {
+	v, ok := p[key]
+	if !ok || v == nil {
+		return def
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// Required returns a string param that must be set
+func (p Params) Required(key string) (string, error) {
+	s := p.String(key, "")
+	if s == "" {
+		return "", fmt.Errorf("parameter %s is required", key)
+	}
+	return s, nil
+}
+
+// Int returns an integer param
+func (p Params) Int(key string, def int) (int, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		if v == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("parameter %s must be an integer: %w", key, err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("parameter %s must be an integer, got %T", key, v)
+	}
+}
+
+// Bool returns a boolean param
+func (p Params) Bool(key string, def bool) (bool, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return v, nil
+	case string:
+		if v == "" {
+			return def, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("parameter %s must be a boolean: %w", key, err)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("parameter %s must be a boolean, got %T", key, v)
+	}
+}
+
+// Duration returns a duration param, given as a Go duration ("30s") or a number of seconds
+func (p Params) Duration(key string, def time.Duration) (time.Duration, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		if v == "" {
+			return def, nil
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("parameter %s must be a duration: %w", key, err)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("parameter %s must be a duration, got %T", key, v)
+	}
+}
+
+// Map returns an object param, given as an object or a JSON string
+func (p Params) Map(key string) (map[string]any, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(v), &m); err != nil {
+			return nil, fmt.Errorf("parameter %s must be a JSON object: %w", key, err)
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("parameter %s must be an object, got %T", key, v)
+	}
+}
@@ -0,0 +1,119 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// FileTemplate renders Go text/template files, e.g. to produce configs from plan parameters
+type FileTemplate struct{}
+
+// Name returns the skill name
+func (FileTemplate) Name() string {
+	return "file-template"
+}
+
+// Description returns the skill description
+func (FileTemplate) Description() string {
+	return "Render a file from a Go text/template and variables, e.g. a cluster or service configuration. Missing variables are errors."
+}
+
+// Actions returns the actions of the skill
+func (FileTemplate) Actions() []skill.Action {
+	return []skill.Action{
+		{
+			Name:        "render",
+			Description: "Render a template with variables ({{.name}}) to a file, or to the output when no file is given",
+			Params: []skill.ActionParam{
+				{Name: "template", Type: "string", Description: "Template text, or use template_file"},
+				{Name: "template_file", Type: "file", Description: "Path of the template"},
+				{Name: "vars", Type: "string", Description: "Variables as a JSON object"},
+				{Name: "output", Type: "file", Description: "Path of the rendered file, created or replaced"},
+			},
+			DryRun: true,
+		},
+	}
+}
+
+// Execute renders the template
+func (FileTemplate) Execute(ctx context.Context, params skill.ExecutionParams) (*skill.NativeResult, error) {
+	p := Params(params)
+
+	text := p.String("template", "")
+	name := "template"
+	if file := p.String("template_file", ""); file != "" {
+		if text != "" {
+			return nil, fmt.Errorf("set either template or template_file")
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text, name = string(data), filepath.Base(file)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("parameter template or template_file is required")
+	}
+	vars, err := p.Map("vars")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	output := p.String("output", "")
+	if output == "" {
+		return &skill.NativeResult{
+			Output: out.String(),
+			Data:   map[string]any{"bytes": out.Len()},
+		}, nil
+	}
+
+	if skill.DryRunFrom(ctx) {
+		return &skill.NativeResult{
+			Output: fmt.Sprintf("Would render %s (%d bytes):\n%s", output, out.Len(), out.String()),
+			Data:   map[string]any{"path": output, "bytes": out.Len()},
+		}, nil
+	}
+	if err := writeFileAtomic(output, out.Bytes()); err != nil {
+		return nil, err
+	}
+	return &skill.NativeResult{
+		Output: fmt.Sprintf("Rendered %s (%d bytes)", output, out.Len()),
+		Data:   map[string]any{"path": output, "bytes": out.Len()},
+	}, nil
+}
+
+// writeFileAtomic replaces path with data, keeping the mode of an existing file
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
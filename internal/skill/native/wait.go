@@ -0,0 +1,195 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+)
+
+// Default wait settings
+const (
+	DefaultWaitTimeout  = 5 * time.Minute
+	DefaultWaitInterval = 5 * time.Second
+)
+
+// Wait pauses a plan for a duration or until a condition holds
+type Wait struct{}
+
+// Name returns the skill name
+func (Wait) Name() string {
+	return "wait"
+}
+
+// Description returns the skill description
+func (Wait) Description() string {
+	return "Wait for a duration, or until a TCP port accepts connections, an HTTP endpoint is healthy or a file exists. Use between steps that need a service to come up."
+}
+
+// Actions returns the actions of the skill
+func (Wait) Actions() []skill.Action {
+	common := []skill.ActionParam{
+		{Name: "timeout", Type: "string", Description: "Maximum wait, 5m by default"},
+		{Name: "interval", Type: "string", Description: "Delay between checks, 5s by default"},
+	}
+	return []skill.Action{
+		{
+			Name:        "sleep",
+			Description: "Wait for a fixed duration",
+			Params: []skill.ActionParam{
+				{Name: "duration", Type: "string", Description: "Duration, e.g. 30s", Required: true},
+			},
+			ReadOnly: true,
+		},
+		{
+			Name:        "tcp",
+			Description: "Wait until a TCP address accepts connections",
+			Params: append([]skill.ActionParam{
+				{Name: "address", Type: "string", Description: "host:port", Required: true},
+			}, common...),
+			ReadOnly: true,
+		},
+		{
+			Name:        "http",
+			Description: "Wait until a URL answers with the expected status",
+			Params: append([]skill.ActionParam{
+				{Name: "url", Type: "string", Description: "URL to request", Required: true},
+				{Name: "expect_status", Type: "int", Description: "Expected status code, any 2xx by default"},
+			}, common...),
+			ReadOnly: true,
+		},
+		{
+			Name:        "file",
+			Description: "Wait until a file exists, or no longer exists",
+			Params: append([]skill.ActionParam{
+				{Name: "path", Type: "file", Description: "Path of the file", Required: true},
+				{Name: "absent", Type: "bool", Description: "Wait for the file to be removed instead"},
+			}, common...),
+			ReadOnly: true,
+		},
+	}
+}
+
+// Execute waits according to the action
+func (Wait) Execute(ctx context.Context, params skill.ExecutionParams) (*skill.NativeResult, error) {
+	p := Params(params)
+	action := p.String("action", "sleep")
+
+	if action == "sleep" {
+		duration, err := p.Duration("duration", 0)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("parameter duration is required")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(duration):
+		}
+		return &skill.NativeResult{
+			Output: fmt.Sprintf("Waited %s", duration),
+			Data:   map[string]any{"waited_ms": duration.Milliseconds()},
+		}, nil
+	}
+
+	timeout, err := p.Duration("timeout", DefaultWaitTimeout)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := p.Duration("interval", DefaultWaitInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	var check func(ctx context.Context) error
+	var target string
+	switch action {
+	case "tcp":
+		address, err := p.Required("address")
+		if err != nil {
+			return nil, err
+		}
+		target = address
+		check = func(ctx context.Context) error {
+			conn, err := (&net.Dialer{Timeout: interval}).DialContext(ctx, "tcp", address)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}
+	case "http":
+		url, err := p.Required("url")
+		if err != nil {
+			return nil, err
+		}
+		expectStatus, err := p.Int("expect_status", 0)
+		if err != nil {
+			return nil, err
+		}
+		target = url
+		client := &http.Client{Timeout: interval}
+		check = func(ctx context.Context) error {
+			_, err := checkOnce(ctx, client, http.MethodGet, url, expectStatus, "")
+			return err
+		}
+	case "file":
+		path, err := p.Required("path")
+		if err != nil {
+			return nil, err
+		}
+		absent, err := p.Bool("absent", false)
+		if err != nil {
+			return nil, err
+		}
+		target = path
+		check = func(context.Context) error {
+			_, err := os.Stat(path)
+			switch {
+			case absent && err == nil:
+				return fmt.Errorf("%s still exists", path)
+			case !absent && err != nil:
+				return err
+			}
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %s", action)
+	}
+
+	return poll(ctx, target, timeout, interval, check)
+}
+
+// poll runs check every interval until it succeeds or timeout expires
+func poll(ctx context.Context, target string, timeout, interval time.Duration, check func(ctx context.Context) error) (*skill.NativeResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := check(ctx)
+		data := map[string]any{
+			"target":    target,
+			"attempts":  attempt,
+			"waited_ms": time.Since(start).Milliseconds(),
+		}
+		if err == nil {
+			return &skill.NativeResult{
+				Output: fmt.Sprintf("%s ready after %s (%d checks)", target, time.Since(start).Round(time.Millisecond), attempt),
+				Data:   data,
+			}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			data["last_error"] = err.Error()
+			return &skill.NativeResult{Data: data}, fmt.Errorf("%s not ready after %s: %v", target, timeout, err)
+		case <-time.After(interval):
+		}
+	}
+}
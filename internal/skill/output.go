@@ -0,0 +1,97 @@
+package skill
+
+import (
+	"context"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/redact"
+)
+
+// Output streams
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputLine is one line written by a skill while it runs
+type OutputLine struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"` // stdout or stderr
+	Text   string    `json:"text"`   // Without the trailing newline
+
+	// StepID is the plan step producing the line, set by the executing agent (0 when unknown)
+	StepID int `json:"step_id,omitempty"`
+}
+
+// OutputHandler receives the output of a skill line by line, stdout and stderr
+// interleaved in the order they were written. Calls are serialized.
+type OutputHandler func(line OutputLine)
+
+type outputHandlerKey struct{}
+
+// WithOutputHandler returns a context streaming the output of skills executed with it to handler
+func WithOutputHandler(ctx context.Context, handler OutputHandler) context.Context {
+	return context.WithValue(ctx, outputHandlerKey{}, handler)
+}
+
+// OutputHandlerFrom returns the output handler of a context, nil if it has none
+func OutputHandlerFrom(ctx context.Context) OutputHandler {
+	handler, _ := ctx.Value(outputHandlerKey{}).(OutputHandler)
+	return handler
+}
+
+// WorkspaceArgument is the workspace of the task in the arguments of MCP tool calls.
+// It is a local path, only servers on the host of the agent can run scripts in it.
+const WorkspaceArgument = "skill_workspace"
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a context running skills in dir, the workspace of the task (SKILL_WORKSPACE)
+func WithWorkspace(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, dir)
+}
+
+// WorkspaceFrom returns the workspace of a context, empty if it has none
+func WorkspaceFrom(ctx context.Context) string {
+	dir, _ := ctx.Value(workspaceKey{}).(string)
+	return dir
+}
+
+// SecretParamsArgument lists the names of the params holding secrets in the
+// arguments of MCP tool calls, so that the server does not expose them either
+const SecretParamsArgument = "secret_params"
+
+type secretParamsKey struct{}
+
+// WithSecretParams returns a context executing skills whose params named names
+// hold resolved secrets, which executors must not expose, e.g. on the command line
+func WithSecretParams(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, secretParamsKey{}, names)
+}
+
+// SecretParamsFrom returns the names of the params holding secrets in a context
+func SecretParamsFrom(ctx context.Context) []string {
+	names, _ := ctx.Value(secretParamsKey{}).([]string)
+	return names
+}
+
+type knownSecretsKey struct{}
+
+// WithKnownSecrets returns a context whose executions add the secrets they resolve
+// to known, and redact all of them, e.g. the secrets resolved by earlier steps of a task
+func WithKnownSecrets(ctx context.Context, known *redact.Known) context.Context {
+	return context.WithValue(ctx, knownSecretsKey{}, known)
+}
+
+// KnownSecretsFrom returns the known secrets of a context, nil if it has none
+func KnownSecretsFrom(ctx context.Context) *redact.Known {
+	known, _ := ctx.Value(knownSecretsKey{}).(*redact.Known)
+	return known
+}
+
+// ContextExecutor is an Executor that can be cancelled, streams output to the
+// handler of the context (see WithOutputHandler) and runs in its workspace (see WithWorkspace)
+type ContextExecutor interface {
+	Executor
+	ExecuteContext(ctx context.Context, skill *Skill, params ExecutionParams) (*ExecutionResult, error)
+}
@@ -16,6 +16,7 @@ type SkillMetadata struct {
 	Description   string `yaml:"description"`
 	License       string `yaml:"license"`
 	Compatibility string `yaml:"compatibility"`
+	Version       string `yaml:"version,omitempty"` // Falls back to the marketplace.json version
 }
 
 // ParseSKILL parses a SKILL.md file and extracts metadata and content
@@ -44,16 +45,34 @@ func ParseSKILL(skillPath string) (*Skill, error) {
 	basePath := filepath.Dir(skillPath)
 	scriptsPath := filepath.Join(basePath, "scripts")
 
+	version := metadata.Version
+	if version == "" {
+		marketplace, err := ReadMarketplace(basePath)
+		if err != nil {
+			return nil, err
+		}
+		if marketplace != nil {
+			version = marketplace.Version
+		}
+	}
+
+	actions, err := LoadActions(basePath, scriptsPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create Skill object
 	skill := &Skill{
 		Name:          metadata.Name,
 		Description:   metadata.Description,
 		License:       metadata.License,
 		Compatibility: metadata.Compatibility,
+		Version:       version,
 		BasePath:      basePath,
 		ScriptsPath:   scriptsPath,
 		SKILLPath:     skillPath,
 		Instructions:  strings.TrimSpace(body),
+		Actions:       actions,
 		LoadedAt:      time.Now(),
 	}
 
@@ -63,6 +82,10 @@ func ParseSKILL(skillPath string) (*Skill, error) {
 // extractFrontmatter extracts YAML frontmatter from markdown content
 // Returns frontmatter, body, and error
 func extractFrontmatter(content string) (string, string, error) {
+	// Accept files saved with a BOM or Windows line endings
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
 	// Check if content starts with ---
 	if !strings.HasPrefix(content, "---") {
 		return "", content, fmt.Errorf("SKILL.md must start with YAML frontmatter (---)")
@@ -74,7 +97,7 @@ func extractFrontmatter(content string) (string, string, error) {
 	var bodyStart int
 
 	// First line should be ---
-	if len(lines) == 0 || lines[0] != "---" {
+	if len(lines) == 0 || !isFrontmatterDelimiter(lines[0]) {
 		return "", content, fmt.Errorf("invalid frontmatter format: first line must be ---")
 	}
 
@@ -83,7 +106,7 @@ func extractFrontmatter(content string) (string, string, error) {
 
 	// Find closing ---
 	for i := 1; i < len(lines); i++ {
-		if lines[i] == "---" {
+		if isFrontmatterDelimiter(lines[i]) {
 			bodyStart = i + 1
 			break
 		}
@@ -99,3 +122,8 @@ func extractFrontmatter(content string) (string, string, error) {
 
 	return frontmatter, body, nil
 }
+
+// isFrontmatterDelimiter reports whether line is a --- delimiter, ignoring trailing whitespace
+func isFrontmatterDelimiter(line string) bool {
+	return strings.TrimRight(line, " \t") == "---"
+}
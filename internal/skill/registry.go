@@ -5,10 +5,32 @@ import (
 	"sync"
 )
 
+// RegistryEventType is the kind of change made to the registry
+type RegistryEventType string
+
+const (
+	RegistryEventAdded   RegistryEventType = "added"
+	RegistryEventUpdated RegistryEventType = "updated"
+	RegistryEventRemoved RegistryEventType = "removed"
+)
+
+// RegistryEvent describes a change made to the registry
+type RegistryEvent struct {
+	Type  RegistryEventType
+	Name  string
+	Skill *Skill // The new skill, or the removed skill for RegistryEventRemoved
+}
+
+// RegistryListener is notified of registry changes
+// Listeners are called synchronously after the change is applied and must not block
+type RegistryListener func(event RegistryEvent)
+
 // Registry manages skills
 type Registry struct {
-	skills map[string]*Skill
-	mu     sync.RWMutex
+	skills    map[string]*Skill
+	mu        sync.RWMutex
+	listeners []RegistryListener
+	lmu       sync.RWMutex
 }
 
 // NewRegistry creates a new skill registry
@@ -18,6 +40,31 @@ func NewRegistry() *Registry {
 	}
 }
 
+// Subscribe registers a listener for registry changes
+func (r *Registry) Subscribe(listener RegistryListener) {
+	r.lmu.Lock()
+	defer r.lmu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// notify calls the listeners for each event
+func (r *Registry) notify(events ...RegistryEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	r.lmu.RLock()
+	listeners := make([]RegistryListener, len(r.listeners))
+	copy(listeners, r.listeners)
+	r.lmu.RUnlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener(event)
+		}
+	}
+}
+
 // Register registers a skill in the registry
 func (r *Registry) Register(skill *Skill) error {
 	if skill == nil {
@@ -29,12 +76,99 @@ func (r *Registry) Register(skill *Skill) error {
 	}
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
+	_, exists := r.skills[skill.Name]
 	r.skills[skill.Name] = skill
+	r.mu.Unlock()
+
+	eventType := RegistryEventAdded
+	if exists {
+		eventType = RegistryEventUpdated
+	}
+	r.notify(RegistryEvent{Type: eventType, Name: skill.Name, Skill: skill})
 	return nil
 }
 
+// RegisterNative registers a skill implemented in Go.
+// Native skills are kept when the skills directory is reloaded and take
+// precedence over a skill directory with the same name.
+func (r *Registry) RegisterNative(native NativeSkill) error {
+	if native == nil {
+		return fmt.Errorf("cannot register nil skill")
+	}
+	if native.Name() == "" {
+		return fmt.Errorf("skill name cannot be empty")
+	}
+
+	r.mu.RLock()
+	existing, exists := r.skills[native.Name()]
+	r.mu.RUnlock()
+	if exists && existing.Native == nil {
+		return fmt.Errorf("skill %s is already registered from %s", native.Name(), existing.BasePath)
+	}
+
+	return r.Register(newNativeSkill(native))
+}
+
+// Unregister removes a skill from the registry
+func (r *Registry) Unregister(name string) error {
+	r.mu.Lock()
+	skill, exists := r.skills[name]
+	delete(r.skills, name)
+	r.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("skill not found: %s", name)
+	}
+
+	r.notify(RegistryEvent{Type: RegistryEventRemoved, Name: name, Skill: skill})
+	return nil
+}
+
+// Replace atomically swaps the registered skills for skills and returns the
+// resulting changes. Skills whose Digest is unchanged are not reported.
+// Native skills are kept, a loaded skill with the name of a native skill is ignored.
+func (r *Registry) Replace(skills []*Skill) ([]RegistryEvent, error) {
+	next := make(map[string]*Skill, len(skills))
+	for _, skill := range skills {
+		if skill == nil || skill.Name == "" {
+			return nil, fmt.Errorf("skill name cannot be empty")
+		}
+		next[skill.Name] = skill
+	}
+
+	var events []RegistryEvent
+
+	r.mu.Lock()
+	// Native skills are not loaded from the skills directory and always stay
+	for name, skill := range r.skills {
+		if skill.Native != nil {
+			next[name] = skill
+		}
+	}
+	for name, skill := range next {
+		old, exists := r.skills[name]
+		switch {
+		case !exists:
+			events = append(events, RegistryEvent{Type: RegistryEventAdded, Name: name, Skill: skill})
+		case old.Digest == "" || old.Digest != skill.Digest:
+			events = append(events, RegistryEvent{Type: RegistryEventUpdated, Name: name, Skill: skill})
+		default:
+			// Unchanged: keep the loaded instance
+			next[name] = old
+		}
+	}
+	for name, old := range r.skills {
+		if _, exists := next[name]; !exists {
+			events = append(events, RegistryEvent{Type: RegistryEventRemoved, Name: name, Skill: old})
+		}
+	}
+	r.skills = next
+	r.mu.Unlock()
+
+	r.notify(events...)
+	return events, nil
+}
+
 // Get retrieves a skill by name
 func (r *Registry) Get(name string) (*Skill, error) {
 	r.mu.RLock()
@@ -90,6 +224,3 @@ func (r *Registry) Count() int {
 
 	return len(r.skills)
 }
-
-
-
@@ -0,0 +1,156 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/hb-chen/opskills/pkg/logger"
+)
+
+// DefaultReloadDebounce is how long the watcher waits for changes to settle
+// before reloading (editors write files in several steps)
+const DefaultReloadDebounce = 500 * time.Millisecond
+
+// Reloader reloads skills into a registry, manually or when the skills directory changes
+type Reloader struct {
+	loader   *Loader
+	registry *Registry
+	debounce time.Duration
+	mu       sync.Mutex // Serializes reloads
+}
+
+// NewReloader creates a skill reloader
+func NewReloader(loader *Loader, registry *Registry) *Reloader {
+	return &Reloader{
+		loader:   loader,
+		registry: registry,
+		debounce: DefaultReloadDebounce,
+	}
+}
+
+// Registry returns the registry the reloader updates
+func (r *Reloader) Registry() *Registry {
+	return r.registry
+}
+
+// SetDebounce sets the delay between the last filesystem change and the reload
+func (r *Reloader) SetDebounce(d time.Duration) {
+	r.debounce = d
+}
+
+// Reload re-parses all skills and applies the changes to the registry
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, err := r.loader.Reload(r.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range result.Events {
+		logger.Infof("[Skills] Skill %s: %s", event.Type, event.Name)
+	}
+	for _, loadErr := range result.Errors {
+		if loadErr.Name != "" {
+			logger.Warnf("[Skills] Rejected invalid skill %s, keeping last good version: %v", loadErr.Name, loadErr)
+		} else {
+			logger.Warnf("[Skills] Rejected invalid skill: %v", loadErr)
+		}
+	}
+
+	return result, nil
+}
+
+// Watch watches the skills directory and reloads on changes until ctx is done.
+// It returns once the watcher is set up.
+func (r *Reloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create skills watcher: %w", err)
+	}
+
+	if err := addWatchDirs(watcher, r.loader.Dir()); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	logger.Infof("[Skills] Watching %s for changes", r.loader.Dir())
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(r.debounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isIgnoredPath(event.Name) {
+					continue
+				}
+				// fsnotify is not recursive: watch directories as they are created
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := addWatchDirs(watcher, event.Name); err != nil {
+							logger.Warnf("[Skills] Failed to watch %s: %v", event.Name, err)
+						}
+					}
+				}
+				timer.Reset(r.debounce)
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warnf("[Skills] Watcher error: %v", err)
+
+			case <-timer.C:
+				if _, err := r.Reload(); err != nil {
+					logger.Errorf("[Skills] Reload failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// addWatchDirs adds root and all its subdirectories to the watcher
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && isIgnoredPath(path) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// isIgnoredPath reports whether a path is hidden (e.g. .git) or an editor temp file
+func isIgnoredPath(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") || strings.HasSuffix(base, ".swp")
+}
@@ -0,0 +1,73 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/retry"
+)
+
+// ExecuteWithRetry executes a skill like ExecuteContext and retries failed executions
+// according to the retry policy of the action, with the fields of override replacing
+// its own within its limits. Actions without a retry policy, or declared not
+// idempotent, are never retried.
+func (r *Router) ExecuteWithRetry(ctx context.Context, skillName string, params ExecutionParams, override *retry.Policy) (*ExecutionResult, error) {
+	policy := r.RetryPolicy(skillName, params, override)
+	for attempt := 1; ; attempt++ {
+		result, err := r.ExecuteContext(ctx, skillName, params)
+		if result != nil {
+			result.Attempts = attempt
+		}
+		if err == nil && (result == nil || result.Success) {
+			return result, err
+		}
+		if attempt >= policy.Attempts() || ctx.Err() != nil || !retryable(policy, result, err) {
+			return result, err
+		}
+
+		delay := policy.Delay(attempt)
+		if handler := OutputHandlerFrom(ctx); handler != nil {
+			handler(OutputLine{
+				Time:   time.Now(),
+				Stream: StreamStderr,
+				Text:   fmt.Sprintf("[retry] attempt %d/%d failed, retrying in %s", attempt, policy.Attempts(), delay),
+			})
+		}
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// RetryPolicy returns the effective retry policy of a skill execution, nil when it
+// must not be retried
+func (r *Router) RetryPolicy(skillName string, params ExecutionParams, override *retry.Policy) *retry.Policy {
+	var base *retry.Policy
+	if s, err := r.registry.Get(skillName); err == nil {
+		actionName, _ := params["action"].(string)
+		if action, ok := s.Action(actionName); ok {
+			if !action.Retryable() {
+				return nil
+			}
+			base = action.Retry
+		}
+	}
+	return retry.Merge(base, override)
+}
+
+// retryable reports whether a failed execution matches the retry conditions of policy
+func retryable(policy *retry.Policy, result *ExecutionResult, err error) bool {
+	exitCode := -1
+	output := ""
+	if result != nil {
+		exitCode = result.ExitCode
+		output = result.Output + "\n" + result.Error
+	}
+	if err != nil {
+		output += "\n" + err.Error()
+	}
+	return policy.Retryable(exitCode, output)
+}
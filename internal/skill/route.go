@@ -0,0 +1,146 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable marks errors of a backend that could not run a skill at all, e.g. the
+// script could not be spawned or the MCP server is unreachable. Only these errors make
+// auto mode try the next backend; a skill that ran and failed is never run again.
+var ErrUnavailable = errors.New("backend unavailable")
+
+// unavailableError wraps an error as ErrUnavailable
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string   { return e.err.Error() }
+func (e *unavailableError) Unwrap() []error { return []error{e.err, ErrUnavailable} }
+
+// Unavailable marks err as an infrastructure error (see ErrUnavailable), nil stays nil
+func Unavailable(err error) error {
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return &unavailableError{err: err}
+}
+
+// CapabilityChecker is implemented by executors that can tell, without running it,
+// whether they are able to execute a skill (e.g. a script exists for the action)
+type CapabilityChecker interface {
+	CanExecute(skill *Skill, params ExecutionParams) error
+}
+
+// unhealthyFor is how long an MCP server that failed is skipped by auto mode
+const unhealthyFor = 30 * time.Second
+
+// backend is a way to run a skill: an execution mode, and the MCP server for mcp
+type backend struct {
+	mode   ExecutionMode
+	server string
+}
+
+func (b backend) String() string {
+	if b.mode == ExecutionModeMCP {
+		return fmt.Sprintf("%s:%s", b.mode, b.server)
+	}
+	return string(b.mode)
+}
+
+// serverHealth tracks MCP servers that recently failed
+type serverHealth struct {
+	mu        sync.Mutex
+	failedAt  map[string]time.Time
+	lastError map[string]error
+}
+
+func newServerHealth() *serverHealth {
+	return &serverHealth{failedAt: make(map[string]time.Time), lastError: make(map[string]error)}
+}
+
+// fail marks a server unhealthy
+func (h *serverHealth) fail(server string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failedAt[server] = time.Now()
+	h.lastError[server] = err
+}
+
+// ok marks a server healthy
+func (h *serverHealth) ok(server string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failedAt, server)
+	delete(h.lastError, server)
+}
+
+// check returns why a server is unhealthy, nil if it is healthy or its failure is old
+func (h *serverHealth) check(server string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if failedAt, ok := h.failedAt[server]; ok && time.Since(failedAt) < unhealthyFor {
+		return fmt.Errorf("MCP server %s failed %s ago: %w", server, time.Since(failedAt).Round(time.Second), h.lastError[server])
+	}
+	return nil
+}
+
+// backends returns the backends able to run a skill, in order of preference.
+// Explicit modes have a single backend; auto mode keeps the capable ones of
+// direct then MCP, and fails with ErrUnavailable when there is none.
+func (r *Router) backends(skill *Skill, params ExecutionParams) ([]backend, error) {
+	// Native skills have no scripts to run another way
+	if skill.Native != nil {
+		return []backend{{mode: ExecutionModeNative}}, nil
+	}
+
+	var skillConfig SkillConfig
+	if r.config != nil {
+		skillConfig, _ = r.config.GetSkillConfig(skill.Name)
+	}
+
+	switch skillConfig.ExecutionMode {
+	case "", ExecutionModeDirect:
+		return []backend{{mode: ExecutionModeDirect}}, nil
+	case ExecutionModeMCP:
+		return []backend{{mode: ExecutionModeMCP, server: skillConfig.MCPServer}}, nil
+	case ExecutionModeAuto:
+	default:
+		return nil, fmt.Errorf("unknown execution mode: %s", skillConfig.ExecutionMode)
+	}
+
+	var candidates []backend
+	var reasons []string
+	if err := r.canExecuteDirect(skill, params); err != nil {
+		reasons = append(reasons, fmt.Sprintf("direct: %v", err))
+	} else {
+		candidates = append(candidates, backend{mode: ExecutionModeDirect})
+	}
+	switch {
+	case skillConfig.MCPServer == "":
+		reasons = append(reasons, "mcp: no MCP server configured")
+	case r.health.check(skillConfig.MCPServer) != nil:
+		reasons = append(reasons, fmt.Sprintf("mcp: %v", r.health.check(skillConfig.MCPServer)))
+	default:
+		candidates = append(candidates, backend{mode: ExecutionModeMCP, server: skillConfig.MCPServer})
+	}
+
+	if len(candidates) == 0 {
+		return nil, Unavailable(fmt.Errorf("no backend can run skill %s (%s)", skill.Name, strings.Join(reasons, "; ")))
+	}
+	return candidates, nil
+}
+
+// canExecuteDirect checks that the direct executor can run a skill
+func (r *Router) canExecuteDirect(skill *Skill, params ExecutionParams) error {
+	if r.directExecutor == nil {
+		return fmt.Errorf("direct executor not available")
+	}
+	if checker, ok := r.directExecutor.(CapabilityChecker); ok {
+		return checker.CanExecute(skill, params)
+	}
+	return nil
+}
@@ -2,98 +2,214 @@ package skill
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/hb-chen/opskills/internal/redact"
+	"github.com/hb-chen/opskills/internal/secret"
 	"github.com/hb-chen/opskills/internal/skill/mcp"
 )
 
 // Router routes skill execution to the appropriate executor
 type Router struct {
 	directExecutor Executor // Use interface instead of concrete type
-	mcpClients      map[string]*mcp.Client
+	mcpConns        map[string]*mcp.Connection
+	mcpMu           sync.Mutex
+	health          *serverHealth // MCP servers that recently failed, skipped by auto mode
 	config          *Config
 	registry        *Registry
+	redactor        *redact.Redactor
+	secrets         *secret.Resolver
 }
 
 // NewRouter creates a new skill router
 func NewRouter(directExecutor Executor, config *Config, registry *Registry) *Router {
 	return &Router{
 		directExecutor: directExecutor,
-		mcpClients:      make(map[string]*mcp.Client),
+		mcpConns:        make(map[string]*mcp.Connection),
+		health:          newServerHealth(),
 		config:          config,
 		registry:        registry,
 	}
 }
 
+// SetRedactor sets the redactor applied to every execution result.
+// The router is the single point where skill output leaves the executors,
+// so results are redacted here before reaching state, prompts, traces and reports.
+func (r *Router) SetRedactor(redactor *redact.Redactor) {
+	r.redactor = redactor
+}
+
+// SetSecrets sets the resolver of the secret references (secret://path) in params.
+// References are resolved here, right before execution, so plans, state and prompts
+// only hold the references; resolved values are redacted for the rest of the task
+// (see WithKnownSecrets).
+func (r *Router) SetSecrets(secrets *secret.Resolver) {
+	r.secrets = secrets
+}
+
 // Execute executes a skill using the appropriate method
 func (r *Router) Execute(skillName string, params ExecutionParams) (*ExecutionResult, error) {
+	return r.ExecuteContext(context.Background(), skillName, params)
+}
+
+// ExecuteContext executes a skill, cancelling it when ctx is done.
+// Output lines are streamed, redacted, to the handler of ctx (see WithOutputHandler).
+func (r *Router) ExecuteContext(ctx context.Context, skillName string, params ExecutionParams) (*ExecutionResult, error) {
+	if names := secret.RefParams(params); len(names) > 0 {
+		ctx = WithSecretParams(ctx, names)
+	}
+	params, values, err := r.secrets.Resolve(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	// Executions outside of a task only redact the secrets they resolved
+	known := KnownSecretsFrom(ctx)
+	if known == nil {
+		known = redact.NewKnown()
+	}
+	known.Add(values...)
+	redactor := r.redactor.WithKnown(known)
+
+	if handler := OutputHandlerFrom(ctx); handler != nil && redactor != nil {
+		lines := redactor.Lines()
+		ctx = WithOutputHandler(ctx, func(line OutputLine) {
+			line.Text = lines.Line(line.Stream, line.Text)
+			handler(line)
+		})
+	}
+
+	result, err := r.execute(ctx, skillName, params)
+	return redactResult(redactor, result), redactor.Error(err)
+}
+
+// redactResult removes secrets from the output and error of a result
+func redactResult(redactor *redact.Redactor, result *ExecutionResult) *ExecutionResult {
+	if result == nil || redactor == nil {
+		return result
+	}
+	var outputCount, errorCount int
+	result.Output, outputCount = redactor.Redact(result.Output)
+	result.Error, errorCount = redactor.Redact(result.Error)
+	result.Redactions += outputCount + errorCount
+	return result
+}
+
+// execute dispatches a skill to the backends of its execution mode. In auto mode the
+// next backend is tried only when one is unavailable (see ErrUnavailable), never
+// after the skill ran and failed.
+func (r *Router) execute(ctx context.Context, skillName string, params ExecutionParams) (*ExecutionResult, error) {
 	// Get skill
 	skill, err := r.registry.Get(skillName)
 	if err != nil {
 		return nil, fmt.Errorf("skill not found: %s", skillName)
 	}
 
-	// Determine execution mode
-	mode := r.determineExecutionMode(skillName)
+	// A dry run only executes the actions that support it
+	dryRun := DryRunFrom(ctx)
+	if dryRun && !supportsDryRun(skill, params) {
+		return skippedResult(skill, params), nil
+	}
 
-	switch mode {
+	backends, err := r.backends(skill, params)
+	if err != nil {
+		return failedResult(err), err
+	}
+
+	var skipped []string
+	for i, b := range backends {
+		result, err := r.executeBackend(ctx, skill, params, b)
+		if errors.Is(err, ErrUnavailable) && i < len(backends)-1 {
+			skipped = append(skipped, fmt.Sprintf("%s unavailable: %v", b, err))
+			continue
+		}
+
+		if result == nil && err != nil {
+			result = failedResult(err)
+		}
+		if result != nil {
+			result.DryRun = dryRun
+			result.Route = b.String()
+			if len(skipped) > 0 {
+				result.Route += " (after " + strings.Join(skipped, "; ") + ")"
+			}
+		}
+		return result, err
+	}
+	return nil, fmt.Errorf("no backend for skill %s", skillName)
+}
+
+// executeBackend runs a skill with one backend
+func (r *Router) executeBackend(ctx context.Context, skill *Skill, params ExecutionParams, b backend) (*ExecutionResult, error) {
+	switch b.mode {
+	case ExecutionModeNative:
+		return executeNative(ctx, skill, params)
 	case ExecutionModeDirect:
-		return r.executeDirect(skill, params)
+		return r.executeDirect(ctx, skill, params)
 	case ExecutionModeMCP:
-		return r.executeMCP(skillName, params)
-	case ExecutionModeAuto:
-		// Auto mode: try direct first, fallback to MCP if needed
-		result, err := r.executeDirect(skill, params)
-		if err == nil {
-			return result, nil
-		}
-		// Fallback to MCP
-		return r.executeMCP(skillName, params)
+		return r.executeMCP(ctx, skill.Name, b.server, params)
 	default:
-		return nil, fmt.Errorf("unknown execution mode: %s", mode)
+		return nil, fmt.Errorf("unknown execution mode: %s", b.mode)
 	}
 }
 
-// determineExecutionMode determines the execution mode for a skill
-func (r *Router) determineExecutionMode(skillName string) ExecutionMode {
-	// Check config first
-	if r.config != nil {
-		if skillConfig, exists := r.config.GetSkillConfig(skillName); exists {
-			if skillConfig.ExecutionMode != ExecutionModeAuto {
-				return skillConfig.ExecutionMode
-			}
-		}
+// failedResult returns the result of an execution that failed before running the skill
+func failedResult(err error) *ExecutionResult {
+	return &ExecutionResult{
+		Success:   false,
+		Error:     err.Error(),
+		ExitCode:  -1,
+		Timestamp: time.Now(),
 	}
+}
 
-	// Default to direct for now
-	// In the future, this could check skill metadata or other factors
-	return ExecutionModeDirect
+// Route describes how a skill is executed, e.g. "mcp (server kubekey-mcp-server)"
+func (r *Router) Route(skill *Skill) string {
+	if skill.Native != nil {
+		return string(ExecutionModeNative)
+	}
+	var skillConfig SkillConfig
+	if r.config != nil {
+		skillConfig, _ = r.config.GetSkillConfig(skill.Name)
+	}
+	switch skillConfig.ExecutionMode {
+	case ExecutionModeMCP:
+		return fmt.Sprintf("mcp (server %s)", skillConfig.MCPServer)
+	case ExecutionModeAuto:
+		return fmt.Sprintf("auto (direct, or MCP server %s when no script exists or direct is unavailable)", skillConfig.MCPServer)
+	default:
+		return string(ExecutionModeDirect)
+	}
 }
 
 // executeDirect executes a skill directly
-func (r *Router) executeDirect(skill *Skill, params ExecutionParams) (*ExecutionResult, error) {
+func (r *Router) executeDirect(ctx context.Context, skill *Skill, params ExecutionParams) (*ExecutionResult, error) {
 	if r.directExecutor == nil {
 		return nil, fmt.Errorf("direct executor not available")
 	}
+	if executor, ok := r.directExecutor.(ContextExecutor); ok {
+		return executor.ExecuteContext(ctx, skill, params)
+	}
 	return r.directExecutor.Execute(skill, params)
 }
 
-// executeMCP executes a skill via MCP
-func (r *Router) executeMCP(skillName string, params ExecutionParams) (*ExecutionResult, error) {
-	// Get MCP server config for this skill
-	if r.config == nil {
-		return nil, fmt.Errorf("MCP execution requires configuration")
-	}
-
-	skillConfig, exists := r.config.GetSkillConfig(skillName)
-	if !exists || skillConfig.MCPServer == "" {
+// executeMCP executes a skill via an MCP server. Errors reaching the server are
+// ErrUnavailable and mark it unhealthy.
+func (r *Router) executeMCP(ctx context.Context, skillName, serverName string, params ExecutionParams) (*ExecutionResult, error) {
+	if serverName == "" {
 		return nil, fmt.Errorf("MCP server not configured for skill: %s", skillName)
 	}
 
 	// Get or create MCP client
-	client, err := r.getMCPClient(skillConfig.MCPServer)
+	client, err := r.getMCPClient(serverName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get MCP client: %w", err)
+		err = fmt.Errorf("failed to get MCP client: %w", err)
+		r.health.fail(serverName, err)
+		return nil, Unavailable(err)
 	}
 
 	// Convert params to tool call arguments
@@ -101,13 +217,42 @@ func (r *Router) executeMCP(skillName string, params ExecutionParams) (*Executio
 	for k, v := range params {
 		arguments[k] = v
 	}
+	if DryRunFrom(ctx) {
+		arguments[DryRunArgument] = true
+	}
+	if names := SecretParamsFrom(ctx); len(names) > 0 {
+		arguments[SecretParamsArgument] = names
+	}
+	if workspace := WorkspaceFrom(ctx); workspace != "" {
+		arguments[WorkspaceArgument] = workspace
+	}
 
-	// Call tool via MCP
-	ctx := context.Background()
-	result, err := client.CallTool(ctx, skillName, arguments)
+	// Call tool via MCP, progress notifications carry the output lines
+	var onProgress func(mcp.ProgressParams)
+	if handler := OutputHandlerFrom(ctx); handler != nil {
+		onProgress = func(progress mcp.ProgressParams) {
+			if progress.Message == "" {
+				return
+			}
+			line := OutputLine{Time: time.Now(), Stream: StreamStdout, Text: progress.Message}
+			if text, ok := strings.CutPrefix(progress.Message, "[stderr] "); ok {
+				line.Stream, line.Text = StreamStderr, text
+			}
+			handler(line)
+		}
+	}
+	result, err := client.CallToolWithProgress(ctx, skillName, arguments, onProgress)
+	if errors.Is(err, mcp.ErrClosed) {
+		// The server is gone, start a new one next time
+		r.dropMCPClient(serverName, client)
+		err = fmt.Errorf("MCP tool call failed: %w", err)
+		r.health.fail(serverName, err)
+		return nil, Unavailable(err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("MCP tool call failed: %w", err)
 	}
+	r.health.ok(serverName)
 
 	// Convert MCP result to ExecutionResult
 	return r.convertMCPResult(result), nil
@@ -115,9 +260,16 @@ func (r *Router) executeMCP(skillName string, params ExecutionParams) (*Executio
 
 // getMCPClient gets or creates an MCP client for a server
 func (r *Router) getMCPClient(serverName string) (*mcp.Client, error) {
-	// Check if client already exists
-	if client, exists := r.mcpClients[serverName]; exists {
-		return client, nil
+	r.mcpMu.Lock()
+	defer r.mcpMu.Unlock()
+
+	// Check if client already exists, a closed one is replaced
+	if conn, exists := r.mcpConns[serverName]; exists {
+		if !conn.Client.Closed() {
+			return conn.Client, nil
+		}
+		conn.Stop()
+		delete(r.mcpConns, serverName)
 	}
 
 	// Get server config
@@ -142,6 +294,13 @@ func (r *Router) getMCPClient(serverName string) (*mcp.Client, error) {
 		return nil, fmt.Errorf("failed to create MCP connection: %w", err)
 	}
 
+	if len(serverConfig.Env) > 0 {
+		conn.Command.Env = os.Environ()
+		for k, v := range serverConfig.Env {
+			conn.Command.Env = append(conn.Command.Env, k+"="+v)
+		}
+	}
+
 	// Start connection
 	if err := conn.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start MCP connection: %w", err)
@@ -157,12 +316,22 @@ func (r *Router) getMCPClient(serverName string) (*mcp.Client, error) {
 		return nil, fmt.Errorf("failed to initialize MCP connection: %w", err)
 	}
 
-	// Store client
-	r.mcpClients[serverName] = conn.Client
+	// Store connection
+	r.mcpConns[serverName] = conn
 
 	return conn.Client, nil
 }
 
+// dropMCPClient stops the connection of a client if it is still the current one of the server
+func (r *Router) dropMCPClient(serverName string, client *mcp.Client) {
+	r.mcpMu.Lock()
+	defer r.mcpMu.Unlock()
+	if conn, exists := r.mcpConns[serverName]; exists && conn.Client == client {
+		conn.Stop()
+		delete(r.mcpConns, serverName)
+	}
+}
+
 // convertMCPResult converts an MCP tool call result to ExecutionResult
 func (r *Router) convertMCPResult(result *mcp.ToolCallResult) *ExecutionResult {
 	execResult := &ExecutionResult{
@@ -178,6 +347,15 @@ func (r *Router) convertMCPResult(result *mcp.ToolCallResult) *ExecutionResult {
 				execResult.Error += content.Text + "\n"
 			}
 		}
+		// Artifacts written to the workspace of the task
+		if data, ok := content.Data.(map[string]interface{}); ok {
+			artifacts, _ := data["artifacts"].([]interface{})
+			for _, artifact := range artifacts {
+				if path, ok := artifact.(string); ok {
+					execResult.Artifacts = append(execResult.Artifacts, path)
+				}
+			}
+		}
 	}
 
 	return execResult
@@ -188,3 +366,13 @@ func (r *Router) GetRegistry() *Registry {
 	return r.registry
 }
 
+// ReadOnly reports whether an action of a skill is declared read-only. Unknown
+// skills and actions may change anything.
+func (r *Router) ReadOnly(skillName, actionName string) bool {
+	s, err := r.registry.Get(skillName)
+	if err != nil {
+		return false
+	}
+	action, ok := s.Action(actionName)
+	return ok && action.ReadOnly
+}
@@ -0,0 +1,122 @@
+package skilltest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestsDir is the directory holding the test cases of a skill
+const TestsDir = "tests"
+
+// Suite is a file of test cases
+type Suite struct {
+	File  string `yaml:"-"`
+	Cases []Case `yaml:"cases"`
+}
+
+// Case runs one skill action against stubbed commands and checks the result
+type Case struct {
+	Name    string            `yaml:"name"`
+	Action  string            `yaml:"action,omitempty"` // Runs scripts/<action>.sh
+	Script  string            `yaml:"script,omitempty"` // Runs a script by file name instead
+	Params  map[string]any    `yaml:"params,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Stdin   string            `yaml:"stdin,omitempty"`   // Answers to interactive prompts
+	Files   map[string]string `yaml:"files,omitempty"`   // Files created in the workspace before the run
+	Stubs   map[string]Stub   `yaml:"stubs,omitempty"`   // Fake commands put first on PATH
+	Hide    []string          `yaml:"hide,omitempty"`    // Real commands removed from PATH
+	Timeout time.Duration     `yaml:"timeout,omitempty"` // Overrides the runner timeout
+	Expect  Expect            `yaml:"expect"`
+}
+
+// Stub is a fake command with scripted output.
+// The first response whose arguments match is used, else the default output.
+type Stub struct {
+	Stdout    string         `yaml:"stdout,omitempty"`
+	Stderr    string         `yaml:"stderr,omitempty"`
+	ExitCode  int            `yaml:"exit_code,omitempty"`
+	Responses []StubResponse `yaml:"responses,omitempty"`
+}
+
+// StubResponse is the output of a stub for matching arguments
+type StubResponse struct {
+	Args     []string `yaml:"args,omitempty"`      // Exact arguments
+	ArgsGlob string   `yaml:"args_glob,omitempty"` // Shell pattern matched against the space-joined arguments
+	Stdout   string   `yaml:"stdout,omitempty"`
+	Stderr   string   `yaml:"stderr,omitempty"`
+	ExitCode int      `yaml:"exit_code,omitempty"`
+}
+
+// Expect is the expected result of a case
+type Expect struct {
+	ExitCode          *int     `yaml:"exit_code,omitempty"` // Defaults to 0
+	OutputContains    []string `yaml:"output_contains,omitempty"`
+	OutputNotContains []string `yaml:"output_not_contains,omitempty"`
+	OutputMatches     []string `yaml:"output_matches,omitempty"` // Regular expressions
+	StderrContains    []string `yaml:"stderr_contains,omitempty"`
+	Calls             []string `yaml:"calls,omitempty"`      // Stub invocations ("kk version") expected in this order
+	NotCalled         []string `yaml:"not_called,omitempty"` // Prefixes of stub invocations that must not happen
+}
+
+// LoadSuites reads every tests/*.yaml file of a skill directory.
+// A skill without a tests directory has no suites.
+func LoadSuites(skillDir string) ([]*Suite, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(skillDir, TestsDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	var suites []*Suite
+	for _, file := range files {
+		suite, err := LoadSuite(file)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+// LoadSuite reads and validates a test file. Unknown fields are rejected to catch typos.
+func LoadSuite(file string) (*Suite, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file: %w", err)
+	}
+
+	suite := &Suite{File: file}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(suite); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range suite.Cases {
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("%s: case %d has no name", file, i+1)
+		case seen[c.Name]:
+			return nil, fmt.Errorf("%s: duplicate case name %q", file, c.Name)
+		case c.Action == "" && c.Script == "":
+			return nil, fmt.Errorf("%s: case %q needs an action or a script", file, c.Name)
+		case c.Action != "" && c.Script != "":
+			return nil, fmt.Errorf("%s: case %q has both an action and a script", file, c.Name)
+		}
+		seen[c.Name] = true
+	}
+
+	return suite, nil
+}
@@ -0,0 +1,318 @@
+package skilltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/skill"
+	"github.com/hb-chen/opskills/internal/skill/direct"
+)
+
+// DefaultTimeout bounds a single case unless the case sets its own timeout
+const DefaultTimeout = 60 * time.Second
+
+// CaseResult is the outcome of one case
+type CaseResult struct {
+	Skill      string   `json:"skill"`
+	File       string   `json:"file"`
+	Name       string   `json:"name"`
+	Passed     bool     `json:"passed"`
+	Failures   []string `json:"failures,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	DurationMs int64    `json:"duration_ms"`
+	Output     string   `json:"output,omitempty"` // Only kept for failed cases
+	Stderr     string   `json:"stderr,omitempty"`
+	Calls      []string `json:"calls,omitempty"`
+	Workspace  string   `json:"workspace,omitempty"` // Set when workspaces are kept
+}
+
+// Report is the outcome of a test run
+type Report struct {
+	Passed  int          `json:"passed"`
+	Failed  int          `json:"failed"`
+	Results []CaseResult `json:"results"`
+}
+
+// OK reports whether every case passed
+func (r *Report) OK() bool {
+	return r.Failed == 0
+}
+
+// add records a case result
+func (r *Report) add(result CaseResult) {
+	if result.Passed {
+		r.Passed++
+	} else {
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes one line per case, with details for failures
+func (r *Report) WriteText(w io.Writer) {
+	for _, result := range r.Results {
+		status := "ok"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%-4s %s/%s (%dms)\n", status, result.Skill, result.Name, result.DurationMs)
+		for _, failure := range result.Failures {
+			fmt.Fprintf(w, "     %s\n", failure)
+		}
+		if !result.Passed {
+			if result.Output != "" {
+				fmt.Fprintf(w, "     output:\n%s\n", indent(result.Output, "       "))
+			}
+			if result.Stderr != "" {
+				fmt.Fprintf(w, "     stderr:\n%s\n", indent(result.Stderr, "       "))
+			}
+			if len(result.Calls) > 0 {
+				fmt.Fprintf(w, "     calls:\n%s\n", indent(strings.Join(result.Calls, "\n"), "       "))
+			}
+		}
+		if result.Workspace != "" {
+			fmt.Fprintf(w, "     workspace: %s\n", result.Workspace)
+		}
+	}
+	fmt.Fprintf(w, "%d passed, %d failed\n", r.Passed, r.Failed)
+}
+
+// Runner runs skill test cases through the DirectExecutor, each in a temporary
+// workspace with its stubbed commands first on PATH
+type Runner struct {
+	timeout       time.Duration
+	keepWorkspace bool
+	filter        *regexp.Regexp
+	interpreters  map[string]map[string]string
+	report        *Report
+}
+
+// NewRunner creates a test runner
+func NewRunner(timeout time.Duration) *Runner {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Runner{
+		timeout: timeout,
+		report:  &Report{Results: []CaseResult{}},
+	}
+}
+
+// SetKeepWorkspace keeps the workspaces of cases for inspection
+func (r *Runner) SetKeepWorkspace(keep bool) {
+	r.keepWorkspace = keep
+}
+
+// SetFilter only runs cases whose name matches filter
+func (r *Runner) SetFilter(filter *regexp.Regexp) {
+	r.filter = filter
+}
+
+// SetInterpreters sets the per-skill interpreters of the executor, see direct.DirectExecutor.SetInterpreters
+func (r *Runner) SetInterpreters(interpreters map[string]map[string]string) {
+	r.interpreters = interpreters
+}
+
+// Report returns the results of every case run so far
+func (r *Runner) Report() *Report {
+	return r.report
+}
+
+// RunSkill runs every test case of a skill
+func (r *Runner) RunSkill(s *skill.Skill) error {
+	suites, err := LoadSuites(s.BasePath)
+	if err != nil {
+		return err
+	}
+
+	for _, suite := range suites {
+		for _, c := range suite.Cases {
+			if r.filter != nil && !r.filter.MatchString(c.Name) {
+				continue
+			}
+			result := r.runCase(s, c)
+			result.File = suite.File
+			r.report.add(result)
+		}
+	}
+	return nil
+}
+
+// runCase runs one case in a fresh workspace
+func (r *Runner) runCase(s *skill.Skill, c Case) CaseResult {
+	result := CaseResult{Skill: s.Name, Name: c.Name, ExitCode: -1}
+	fail := func(format string, args ...any) CaseResult {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	root, err := os.MkdirTemp("", "skill-test-")
+	if err != nil {
+		return fail("failed to create workspace: %v", err)
+	}
+	if r.keepWorkspace {
+		result.Workspace = root
+	} else {
+		defer os.RemoveAll(root)
+	}
+
+	workspace := filepath.Join(root, "workspace")
+	home := filepath.Join(root, "home")
+	binDir := filepath.Join(root, "bin")
+	callsFile := filepath.Join(root, "calls")
+	for _, dir := range []string{workspace, home} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fail("failed to create workspace: %v", err)
+		}
+	}
+	if err := writeFiles(workspace, c.Files); err != nil {
+		return fail("%v", err)
+	}
+	if err := writeStubs(binDir, filepath.Join(root, "stubs"), callsFile, c.Stubs); err != nil {
+		return fail("%v", err)
+	}
+
+	params := skill.ExecutionParams{}
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	if c.Action != "" {
+		// The executor falls back to another script for unknown actions, a test must not
+		if _, ok := s.Action(c.Action); !ok {
+			return fail("no script for action %q", c.Action)
+		}
+		params["action"] = c.Action
+	}
+	if c.Script != "" {
+		params["script"] = c.Script
+	}
+
+	env := map[string]string{
+		"PATH": binDir + string(os.PathListSeparator) + filterPath(os.Getenv("PATH"), c.Hide),
+		"HOME": home,
+	}
+	for k, v := range c.Env {
+		env[k] = v
+	}
+
+	timeout := r.timeout
+	if c.Timeout > 0 {
+		timeout = c.Timeout
+	}
+	executor := direct.NewDirectExecutor(timeout)
+	executor.SetEnv(env)
+	executor.SetInterpreters(r.interpreters)
+	executor.SetWorkDir(workspace)
+	executor.SetStdin(c.Stdin)
+
+	start := time.Now()
+	execResult, execErr := executor.Execute(s, params)
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.Calls = readCalls(callsFile)
+
+	// A non-zero exit is a result to check, anything else is a harness failure
+	if execResult == nil || execResult.ExitCode < 0 {
+		return fail("execution failed: %v", execErr)
+	}
+	result.ExitCode = execResult.ExitCode
+	result.Output = execResult.Output
+	result.Stderr = execResult.Error
+
+	result.Failures = check(c.Expect, execResult.ExitCode, execResult.Output, execResult.Error, result.Calls)
+	result.Passed = len(result.Failures) == 0
+	if result.Passed {
+		result.Output, result.Stderr, result.Calls = "", "", nil
+	}
+	return result
+}
+
+// check compares a result with the expectations and returns the failures
+func check(expect Expect, exitCode int, output, stderr string, calls []string) []string {
+	var failures []string
+
+	wantExit := 0
+	if expect.ExitCode != nil {
+		wantExit = *expect.ExitCode
+	}
+	if exitCode != wantExit {
+		failures = append(failures, fmt.Sprintf("exit code %d, expected %d", exitCode, wantExit))
+	}
+
+	for _, s := range expect.OutputContains {
+		if !strings.Contains(output, s) {
+			failures = append(failures, fmt.Sprintf("output does not contain %q", s))
+		}
+	}
+	for _, s := range expect.OutputNotContains {
+		if strings.Contains(output, s) {
+			failures = append(failures, fmt.Sprintf("output contains %q", s))
+		}
+	}
+	for _, pattern := range expect.OutputMatches {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid output_matches pattern %q: %v", pattern, err))
+			continue
+		}
+		if !re.MatchString(output) {
+			failures = append(failures, fmt.Sprintf("output does not match %q", pattern))
+		}
+	}
+	for _, s := range expect.StderrContains {
+		if !strings.Contains(stderr, s) {
+			failures = append(failures, fmt.Sprintf("stderr does not contain %q", s))
+		}
+	}
+
+	// Expected calls must appear in order, other calls may happen in between
+	next := 0
+	for _, call := range calls {
+		if next < len(expect.Calls) && call == expect.Calls[next] {
+			next++
+		}
+	}
+	if next < len(expect.Calls) {
+		failures = append(failures, fmt.Sprintf("expected call %q not made (in order)", expect.Calls[next]))
+	}
+	for _, prefix := range expect.NotCalled {
+		for _, call := range calls {
+			if call == prefix || strings.HasPrefix(call, prefix+" ") {
+				failures = append(failures, fmt.Sprintf("unexpected call %q", call))
+			}
+		}
+	}
+
+	return failures
+}
+
+// writeFiles creates the case files in the workspace
+func writeFiles(workspace string, files map[string]string) error {
+	for name, content := range files {
+		path := filepath.Join(workspace, filepath.Clean("/"+name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to create %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// indent prefixes every line of s
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+prefix)
+}
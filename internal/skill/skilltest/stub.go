@@ -0,0 +1,108 @@
+package skilltest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeStubs creates an executable fake for every stubbed command in binDir.
+// Outputs are stored as files in dataDir so they are reproduced byte for byte,
+// and every invocation is appended to callsFile.
+func writeStubs(binDir, dataDir, callsFile string, stubs map[string]Stub) error {
+	for _, dir := range []string{binDir, dataDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	for name, stub := range stubs {
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid stub command name %q", name)
+		}
+
+		var b strings.Builder
+		b.WriteString("#!/bin/bash\n")
+		fmt.Fprintf(&b, "# Stub for %s generated by skill test\n", name)
+		fmt.Fprintf(&b, "printf '%%s\\n' %s >> %s\n", shellQuote(name)+`"${*:+ $*}"`, shellQuote(callsFile))
+		b.WriteString("args=\"$*\"\n")
+
+		for i, response := range stub.Responses {
+			respond, err := writeResponse(dataDir, fmt.Sprintf("%s.%d", name, i), response.Stdout, response.Stderr, response.ExitCode)
+			if err != nil {
+				return err
+			}
+			switch {
+			case response.Args != nil:
+				fmt.Fprintf(&b, "if [ \"$args\" = %s ]; then %s; fi\n", shellQuote(strings.Join(response.Args, " ")), respond)
+			case response.ArgsGlob != "":
+				// An unquoted variable on the right of == is matched as a pattern
+				fmt.Fprintf(&b, "pattern=%s\nif [[ $args == $pattern ]]; then %s; fi\n", shellQuote(response.ArgsGlob), respond)
+			default:
+				return fmt.Errorf("stub %s: response %d needs args or args_glob", name, i+1)
+			}
+		}
+
+		respond, err := writeResponse(dataDir, name, stub.Stdout, stub.Stderr, stub.ExitCode)
+		if err != nil {
+			return err
+		}
+		b.WriteString(respond + "\n")
+
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(b.String()), 0755); err != nil {
+			return fmt.Errorf("failed to write stub %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeResponse stores the outputs of a response and returns the shell code replaying it
+func writeResponse(dataDir, id, stdout, stderr string, exitCode int) (string, error) {
+	outFile := filepath.Join(dataDir, id+".out")
+	errFile := filepath.Join(dataDir, id+".err")
+	if err := os.WriteFile(outFile, []byte(stdout), 0644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(errFile, []byte(stderr), 0644); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("cat %s; cat %s >&2; exit %d", shellQuote(outFile), shellQuote(errFile), exitCode), nil
+}
+
+// readCalls returns the recorded stub invocations
+func readCalls(callsFile string) []string {
+	data, err := os.ReadFile(callsFile)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// filterPath removes the directories that contain one of the hidden commands from PATH
+func filterPath(path string, hidden []string) string {
+	if len(hidden) == 0 {
+		return path
+	}
+
+	var dirs []string
+	for _, dir := range filepath.SplitList(path) {
+		keep := true
+		for _, name := range hidden {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, string(os.PathListSeparator))
+}
+
+// shellQuote quotes s for bash
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
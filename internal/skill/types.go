@@ -9,6 +9,7 @@ type Skill struct {
 	Description   string
 	License       string
 	Compatibility string
+	Version       string // From SKILL.md frontmatter or marketplace.json, may be empty
 
 	// Path information
 	BasePath    string // Path to skill directory (e.g., skills/kubekey)
@@ -16,10 +17,13 @@ type Skill struct {
 	SKILLPath   string // Path to SKILL.md file
 
 	// Content
-	Instructions string // Full content of SKILL.md (after frontmatter)
+	Instructions string      // Full content of SKILL.md (after frontmatter)
+	Actions      []Action    // From scripts/ and actions.yaml
+	Native       NativeSkill // Set for skills implemented in Go, which have no files
 
 	// Metadata
 	LoadedAt time.Time
+	Digest   string // sha256 over every file in the skill directory, used to detect changes
 }
 
 // ExecutionResult represents the result of executing a skill
@@ -30,6 +34,26 @@ type ExecutionResult struct {
 	ExitCode  int
 	Duration  time.Duration
 	Timestamp time.Time
+
+	// Redactions is the number of secrets removed from Output and Error
+	Redactions int
+
+	// Data is the structured output of native skills
+	Data map[string]interface{}
+
+	// Artifacts are the files the execution produced, relative to the workspace
+	Artifacts []string
+
+	// Route is the backend that ran the skill, e.g. "direct" or "mcp:kubekey-mcp-server",
+	// with the backends skipped before it in auto mode
+	Route string
+
+	// Attempts is the number of executions, more than 1 when failures were retried
+	Attempts int
+
+	// DryRun marks executions in a dry run, which changed nothing; Skipped ones did not run
+	DryRun  bool
+	Skipped bool
 }
 
 // ExecutionParams represents parameters for skill execution
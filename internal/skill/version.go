@@ -0,0 +1,127 @@
+package skill
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MarketplaceFile is the name of the optional skill manifest next to SKILL.md
+const MarketplaceFile = "marketplace.json"
+
+// versionSeparator separates the skill name from the version in the
+// directory name of an installed skill (e.g. skills/kubekey@1.2.0)
+const versionSeparator = "@"
+
+// Marketplace represents the fields of marketplace.json used by the agent
+type Marketplace struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	Author      string `json:"author"`
+	License     string `json:"license"`
+}
+
+// ReadMarketplace reads marketplace.json from a skill directory.
+// It returns nil without error if the skill has no marketplace.json.
+func ReadMarketplace(dir string) (*Marketplace, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MarketplaceFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", MarketplaceFile, err)
+	}
+
+	var marketplace Marketplace
+	if err := json.Unmarshal(data, &marketplace); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", MarketplaceFile, err)
+	}
+
+	return &marketplace, nil
+}
+
+// VersionedDirName returns the directory name of an installed skill version
+func VersionedDirName(name, version string) string {
+	return name + versionSeparator + version
+}
+
+// ParseVersionedDirName splits an installed skill directory name into name and version.
+// ok is false for directories of skills copied by hand (no version in the name).
+func ParseVersionedDirName(dirName string) (name, version string, ok bool) {
+	name, version, ok = strings.Cut(dirName, versionSeparator)
+	if !ok || name == "" || version == "" {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+// CompareVersions compares two semantic versions (an optional "v" prefix is ignored).
+// It returns -1, 0 or 1. A pre-release sorts before its release, and components
+// that are not numbers are compared as strings.
+func CompareVersions(a, b string) int {
+	a, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	// Build metadata does not affect precedence
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	if c := compareDotted(a, b); c != 0 {
+		return c
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareDotted(aPre, bPre)
+}
+
+// compareDotted compares dot-separated version components, numerically when possible
+func compareDotted(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x == y {
+			continue
+		}
+
+		xn, xErr := strconv.Atoi(orZero(x))
+		yn, yErr := strconv.Atoi(orZero(y))
+		if xErr == nil && yErr == nil {
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		return strings.Compare(x, y)
+	}
+	return 0
+}
+
+// orZero treats a missing version component as 0 (1.2 == 1.2.0)
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
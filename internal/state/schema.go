@@ -1,9 +1,31 @@
 package state
 
 import (
+	"github.com/hb-chen/opskills/internal/retry"
 	"github.com/tmc/langchaingo/llms"
 )
 
+// Task modes
+const (
+	ModeExecute  = "execute"   // Run the plan
+	ModePlanOnly = "plan_only" // Plan and check the plan, run nothing
+	ModeDryRun   = "dry_run"   // Run the actions supporting dry run, skip the others
+)
+
+// ValidMode reports whether mode is a task mode, empty meaning ModeExecute
+func ValidMode(mode string) bool {
+	switch mode {
+	case "", ModeExecute, ModePlanOnly, ModeDryRun:
+		return true
+	}
+	return false
+}
+
+// Simulated reports whether a task in mode changes nothing
+func Simulated(mode string) bool {
+	return mode == ModePlanOnly || mode == ModeDryRun
+}
+
 // AgentState represents the agent execution state using langgraphgo State Schema
 type AgentState struct {
 	// Messages for LLM interaction (using langgraphgo graph tag)
@@ -14,10 +36,10 @@ type AgentState struct {
 	PlanError string `graph:"plan_error" json:"plan_error,omitempty"`
 
 	// Execution phase
-	Steps       []*Step      `graph:"steps" json:"steps,omitempty"`
-	CurrentStep int          `graph:"current_step" json:"current_step,omitempty"`
+	Steps       []*Step       `graph:"steps" json:"steps,omitempty"`
+	CurrentStep int           `graph:"current_step" json:"current_step,omitempty"`
 	Results     []*StepResult `graph:"results" json:"results,omitempty"`
-	FinalResult *FinalResult `graph:"final_result" json:"final_result,omitempty"`
+	FinalResult *FinalResult  `graph:"final_result" json:"final_result,omitempty"`
 
 	// User query/request
 	Query string `graph:"query" json:"query"`
@@ -31,9 +53,139 @@ type AgentState struct {
 	UpdatedAt string `graph:"updated_at" json:"updated_at,omitempty"`
 
 	// Replanning support
-	ReplanNeeded bool   `graph:"replan_needed" json:"replan_needed,omitempty"`
-	ReplanReason  string `graph:"replan_reason" json:"replan_reason,omitempty"`
-	ReplanCount   int    `graph:"replan_count" json:"replan_count,omitempty"`
+	ReplanNeeded  bool           `graph:"replan_needed" json:"replan_needed,omitempty"`
+	ReplanReason  string         `graph:"replan_reason" json:"replan_reason,omitempty"`
+	ReplanCount   int            `graph:"replan_count" json:"replan_count,omitempty"`
+	ReplanContext *ReplanContext `graph:"replan_context" json:"replan_context,omitempty"`
+
+	// Revisions is the history of the plan, the first plan and every replan
+	Revisions []*PlanRevision `graph:"revisions" json:"revisions,omitempty"`
+
+	// Mode of the task, see ModeExecute
+	Mode string `graph:"mode" json:"mode,omitempty"`
+
+	// Rollback undoes the completed steps of a failed task, RollbackApproved lets it run
+	Rollback         *Rollback `graph:"rollback" json:"rollback,omitempty"`
+	RollbackApproved bool      `graph:"rollback_approved" json:"rollback_approved,omitempty"`
+
+	// Requester and target environment of the task, evaluated by the policy rules
+	Requester   string `graph:"requester" json:"requester,omitempty"`
+	Environment string `graph:"environment" json:"environment,omitempty"`
+
+	// PolicyDecisions made on the plan and its steps; Approval holds the plan when a rule requires it
+	PolicyDecisions []*PolicyDecision `graph:"policy_decisions" json:"policy_decisions,omitempty"`
+	Approval        *Approval         `graph:"approval" json:"approval,omitempty"`
+
+	// Labels select the maintenance windows and freezes of the task, e.g. cluster: prod-a
+	Labels map[string]string `graph:"labels" json:"labels,omitempty"`
+	// OverrideWindow asks to run outside maintenance windows once approved
+	OverrideWindow bool `graph:"override_window" json:"override_window,omitempty"`
+	// Maintenance holds the task when its mutating steps are due outside a window
+	Maintenance *MaintenanceHold `graph:"maintenance" json:"maintenance,omitempty"`
+}
+
+// ReplanContext carries what the previous plan did into replanning
+type ReplanContext struct {
+	Reason         string `json:"reason"`
+	PlanSummary    string `json:"plan_summary,omitempty"`
+	ResultsSummary string `json:"results_summary,omitempty"` // Digested step outputs
+
+	// Failure details the failed step: skill, action, params and digested error
+	Failure string `json:"failure,omitempty"`
+	// Skill is the skill of the failed step, or of every step of the plan, whose
+	// prompt overrides apply to replanning
+	Skill string `json:"skill,omitempty"`
+	// KeptSteps are the IDs of the completed steps kept by the new plan, which
+	// continues with steps numbered from NextStepID
+	KeptSteps  []int `json:"kept_steps,omitempty"`
+	NextStepID int   `json:"next_step_id,omitempty"`
+}
+
+// PlanRevision is a version of the plan of a task
+type PlanRevision struct {
+	Revision  int    `json:"revision"`         // 0 for the first plan
+	Reason    string `json:"reason,omitempty"` // Why the previous revision was replaced
+	Plan      *Plan  `json:"plan"`             // Kept steps followed by the new ones
+	KeptSteps []int  `json:"kept_steps,omitempty"`
+	CreatedAt string `json:"created_at"`
+
+	// Superseded are the results of the steps of the previous revision that were not kept
+	Superseded []*StepResult `json:"superseded,omitempty"`
+}
+
+// Rollback statuses
+const (
+	RollbackPendingApproval = "pending_approval"
+	RollbackHeld            = "held" // A compensation waits for ApproveTask or a maintenance window
+	RollbackRunning         = "running"
+	RollbackCompleted       = "completed"
+	RollbackFailed          = "failed"
+)
+
+// Rollback runs the compensations of the completed steps of a failed task
+type Rollback struct {
+	Status    string          `json:"status"` // pending_approval, held, running, completed, failed
+	Reason    string          `json:"reason,omitempty"`
+	Steps     []*Compensation `json:"steps"` // Latest completed step first
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at,omitempty"`
+
+	ApprovedBy string `json:"approved_by,omitempty"` // Who approved the rollback, never the requester of the task
+	ApprovedAt string `json:"approved_at,omitempty"`
+}
+
+// Compensation undoes a completed step of the plan
+type Compensation struct {
+	StepID    int                    `json:"step_id"` // Compensated step
+	SkillName string                 `json:"skill_name"`
+	Action    string                 `json:"action"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	Status    string                 `json:"status"` // pending, completed, failed
+	Result    *StepResult            `json:"result,omitempty"`
+}
+
+// PolicyDecision is the decision of a policy rule on a plan or one of its steps
+type PolicyDecision struct {
+	RuleID string `json:"rule_id,omitempty"` // Empty for allow decisions, no rule matched
+	Effect string `json:"effect"`            // allow, deny or require_approval
+	Stage  string `json:"stage"`             // plan: before the plan runs, step: before the step runs
+	StepID int    `json:"step_id,omitempty"` // 0 for decisions on the whole plan
+	Reason string `json:"reason,omitempty"`
+	Time   string `json:"time"`
+}
+
+// Approval statuses
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+)
+
+// Approval holds a plan until the rules requiring approval are approved
+type Approval struct {
+	Status      string   `json:"status"` // pending or approved
+	Rules       []string `json:"rules"`  // Rules requiring approval; once approved, later revisions matching only these run
+	Steps       []string `json:"steps"`  // Digests of the steps approved; later revisions with other steps are approved again
+	Reason      string   `json:"reason,omitempty"`
+	RequestedAt string   `json:"requested_at"`
+	ApprovedBy  string   `json:"approved_by,omitempty"`
+	ApprovedAt  string   `json:"approved_at,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+}
+
+// Maintenance statuses of a task
+const (
+	MaintenanceScheduled = "scheduled" // Held until a maintenance window opens
+	MaintenanceRejected  = "rejected"  // Rejected outside the maintenance windows
+)
+
+// MaintenanceHold records why the mutating steps of a task did not run when they were due
+type MaintenanceHold struct {
+	Status    string `json:"status"` // scheduled or rejected
+	Reason    string `json:"reason"`
+	Window    string `json:"window,omitempty"`     // Window opening at NotBefore
+	NotBefore string `json:"not_before,omitempty"` // When a scheduled task resumes
+	StepID    int    `json:"step_id,omitempty"`    // First held step, 0 when the plan was held
+	HeldAt    string `json:"held_at"`
 }
 
 // State represents the agent execution state (legacy, kept for compatibility)
@@ -42,14 +194,14 @@ type State struct {
 	Query string `json:"query"`
 
 	// Planning phase
-	Plan      *Plan      `json:"plan,omitempty"`
-	PlanError string     `json:"plan_error,omitempty"`
+	Plan      *Plan  `json:"plan,omitempty"`
+	PlanError string `json:"plan_error,omitempty"`
 
 	// Execution phase
-	Steps        []*Step        `json:"steps,omitempty"`
-	CurrentStep  int            `json:"current_step,omitempty"`
-	Results      []*StepResult  `json:"results,omitempty"`
-	FinalResult  *FinalResult   `json:"final_result,omitempty"`
+	Steps       []*Step       `json:"steps,omitempty"`
+	CurrentStep int           `json:"current_step,omitempty"`
+	Results     []*StepResult `json:"results,omitempty"`
+	FinalResult *FinalResult  `json:"final_result,omitempty"`
 
 	// Error handling
 	Error string `json:"error,omitempty"`
@@ -58,30 +210,77 @@ type State struct {
 	TaskID    string `json:"task_id"`
 	StartedAt string `json:"started_at,omitempty"`
 	UpdatedAt string `json:"updated_at,omitempty"`
+
+	// Artifacts collected from the task workspace, by step
+	Artifacts []*Artifact `json:"artifacts,omitempty"`
+
+	// Plan history and number of replans
+	Revisions   []*PlanRevision `json:"revisions,omitempty"`
+	ReplanCount int             `json:"replan_count,omitempty"`
+
+	// Compensation of the completed steps when the task failed
+	Rollback *Rollback `json:"rollback,omitempty"`
+
+	// Mode of the task, see ModeExecute
+	Mode string `json:"mode,omitempty"`
+
+	// Requester and target environment of the task
+	Requester   string `json:"requester,omitempty"`
+	Environment string `json:"environment,omitempty"`
+
+	// Policy decisions and the approval of the plan
+	PolicyDecisions []*PolicyDecision `json:"policy_decisions,omitempty"`
+	Approval        *Approval         `json:"approval,omitempty"`
+
+	// Labels, window override and maintenance hold of the task
+	Labels         map[string]string `json:"labels,omitempty"`
+	OverrideWindow bool              `json:"override_window,omitempty"`
+	Maintenance    *MaintenanceHold  `json:"maintenance,omitempty"`
 }
 
 // Plan represents an execution plan
 type Plan struct {
 	Steps []*PlanStep `json:"steps"`
+	// Runbook is the runbook the plan was rendered from, empty when it was planned
+	Runbook string `json:"runbook,omitempty"`
+	// Fixed plans, rendered from a runbook or given with the task, run as is: when
+	// they fail the task fails, without replanning, unless Replan opts in
+	Fixed  bool `json:"fixed,omitempty"`
+	Replan bool `json:"replan,omitempty"`
+	// Prompt is the template the plan was generated with, traced by the planning node
+	Prompt *PromptInfo `json:"-"`
+}
+
+// PromptInfo identifies a rendered prompt template
+type PromptInfo struct {
+	Name    string
+	Version string
+	Source  string
 }
 
 // PlanStep represents a single step in the plan
 type PlanStep struct {
-	ID          int    `json:"id"`
-	SkillName   string `json:"skill_name"`
-	Action      string `json:"action"`
-	Description string `json:"description"`
+	ID          int                    `json:"id"`
+	SkillName   string                 `json:"skill_name"`
+	Action      string                 `json:"action"`
+	Description string                 `json:"description"`
 	Params      map[string]interface{} `json:"params,omitempty"`
+
+	// Retry overrides fields of the retry policy of the action
+	Retry *retry.Policy `json:"retry,omitempty"`
 }
 
 // Step represents an execution step
 type Step struct {
-	ID          int    `json:"id"`
-	SkillName   string `json:"skill_name"`
-	Action      string `json:"action"`
-	Description string `json:"description"`
+	ID          int                    `json:"id"`
+	SkillName   string                 `json:"skill_name"`
+	Action      string                 `json:"action"`
+	Description string                 `json:"description"`
 	Params      map[string]interface{} `json:"params,omitempty"`
-	Status      string `json:"status"` // pending, running, completed, failed
+	Status      string                 `json:"status"` // pending, running, completed, failed
+
+	// Retry overrides fields of the retry policy of the action
+	Retry *retry.Policy `json:"retry,omitempty"`
 }
 
 // StepResult represents the result of executing a step
@@ -91,6 +290,31 @@ type StepResult struct {
 	Output   string `json:"output,omitempty"`
 	Error    string `json:"error,omitempty"`
 	Duration string `json:"duration,omitempty"`
+
+	// Redactions is the number of secrets removed from Output and Error
+	Redactions int `json:"redactions,omitempty"`
+
+	// Artifacts are the files collected from the task workspace after the step
+	Artifacts []*Artifact `json:"artifacts,omitempty"`
+
+	// Route is the backend that ran the step, e.g. "direct" or "mcp:kubekey-mcp-server"
+	Route string `json:"route,omitempty"`
+
+	// Attempts is the number of executions of the step, more than 1 when failures were retried
+	Attempts int `json:"attempts,omitempty"`
+
+	// Simulated results come from a dry run, which changed nothing; Skipped steps did not run
+	Simulated bool `json:"simulated,omitempty"`
+	Skipped   bool `json:"skipped,omitempty"`
+}
+
+// Artifact is a file produced by a step, copied out of the task workspace
+type Artifact struct {
+	Name      string `json:"name"` // Path relative to the task workspace
+	StepID    int    `json:"step_id"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	CreatedAt string `json:"created_at"`
 }
 
 // FinalResult represents the final execution result
@@ -99,5 +323,7 @@ type FinalResult struct {
 	Output  string `json:"output,omitempty"`
 	Error   string `json:"error,omitempty"`
 	Summary string `json:"summary,omitempty"`
-}
 
+	// Simulated marks the result of a plan-only or dry-run task, which changed nothing
+	Simulated bool `json:"simulated,omitempty"`
+}
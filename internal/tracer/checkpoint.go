@@ -49,6 +49,11 @@ func (c *CheckpointTracer) TraceLLMResponse(ctx context.Context, taskID, respons
 	return nil
 }
 
+func (c *CheckpointTracer) TracePrompt(ctx context.Context, taskID, name, version, source string) error {
+	// No-op: Prompt versions are recorded by the log tracer
+	return nil
+}
+
 func (c *CheckpointTracer) TraceStepStart(ctx context.Context, taskID string, step *state.Step) error {
 	// No-op: Step execution is captured in state
 	return nil
@@ -59,11 +64,21 @@ func (c *CheckpointTracer) TraceStepEnd(ctx context.Context, taskID string, step
 	return nil
 }
 
+func (c *CheckpointTracer) TraceStepOutput(ctx context.Context, taskID string, stepID int, stream, line string, at time.Time) error {
+	// No-op: Step output is persisted by the step log tracer
+	return nil
+}
+
 func (c *CheckpointTracer) TraceError(ctx context.Context, taskID, nodeName string, err error) error {
 	// No-op: Errors are captured in state
 	return nil
 }
 
+func (c *CheckpointTracer) TracePolicyDecision(ctx context.Context, taskID string, decision *state.PolicyDecision) error {
+	// No-op: Policy decisions are captured in state
+	return nil
+}
+
 func (c *CheckpointTracer) TraceStateChange(ctx context.Context, taskID string, state *state.State) error {
 	// No-op: State changes are automatically checkpointed by langgraphgo
 	return nil
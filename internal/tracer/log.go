@@ -61,6 +61,14 @@ func (l *LogTracer) TraceLLMResponse(ctx context.Context, taskID, response strin
 	return nil
 }
 
+func (l *LogTracer) TracePrompt(ctx context.Context, taskID, name, version, source string) error {
+	if l.level == "minimal" {
+		return nil
+	}
+	logger.Infof("[Tracer] Prompt rendered: task=%s, prompt=%s, version=%s, source=%s", taskID, name, version, source)
+	return nil
+}
+
 func (l *LogTracer) TraceStepStart(ctx context.Context, taskID string, step *state.Step) error {
 	if l.level == "minimal" {
 		return nil
@@ -78,20 +86,42 @@ func (l *LogTracer) TraceStepEnd(ctx context.Context, taskID string, step *state
 	if !result.Success {
 		status = "failed"
 	}
-	logger.Infof("[Tracer] Step completed: task=%s, step=%d, status=%s, duration=%v",
-		taskID, step.ID, status, duration)
+	logger.Infof("[Tracer] Step completed: task=%s, step=%d, status=%s, route=%s, attempts=%d, duration=%v, redactions=%d",
+		taskID, step.ID, status, result.Route, result.Attempts, duration, result.Redactions)
 	if result.Error != "" && l.level == "detailed" {
 		logger.Debugf("[Tracer] Step error: task=%s, step=%d, error=%s", taskID, step.ID, result.Error)
 	}
 	return nil
 }
 
+func (l *LogTracer) TraceStepOutput(ctx context.Context, taskID string, stepID int, stream, line string, at time.Time) error {
+	if l.level != "detailed" {
+		return nil
+	}
+	logger.Debugf("[Tracer] Step output: task=%s, step=%d, %s: %s", taskID, stepID, stream, line)
+	return nil
+}
+
 func (l *LogTracer) TraceError(ctx context.Context, taskID, nodeName string, err error) error {
 	// Always log errors regardless of level
 	logger.Errorf("[Tracer] Error occurred: task=%s, node=%s, error=%v", taskID, nodeName, err)
 	return nil
 }
 
+func (l *LogTracer) TracePolicyDecision(ctx context.Context, taskID string, decision *state.PolicyDecision) error {
+	// Denials and approvals are always logged, like errors
+	if decision.Effect != "allow" {
+		logger.Warnf("[Tracer] Policy decision: task=%s, stage=%s, step=%d, rule=%s, effect=%s, reason=%s",
+			taskID, decision.Stage, decision.StepID, decision.RuleID, decision.Effect, decision.Reason)
+		return nil
+	}
+	if l.level == "minimal" {
+		return nil
+	}
+	logger.Infof("[Tracer] Policy decision: task=%s, stage=%s, step=%d, effect=allow", taskID, decision.Stage, decision.StepID)
+	return nil
+}
+
 func (l *LogTracer) TraceStateChange(ctx context.Context, taskID string, state *state.State) error {
 	if l.level != "detailed" {
 		return nil
@@ -0,0 +1,139 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hb-chen/opskills/internal/state"
+)
+
+// stepLogTimeFormat is the timestamp of each line of a step log
+const stepLogTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// StepLogTracer implements ExecutionTracer by persisting the full output of each step
+// to <dir>/<task id>/step-<step id>.log, one timestamped line per output line.
+// Other events are ignored.
+type StepLogTracer struct {
+	dir   string
+	mu    sync.Mutex
+	files map[string]*os.File // Open logs of running steps by path
+}
+
+// NewStepLogTracer creates a tracer writing step logs under dir
+func NewStepLogTracer(dir string) (*StepLogTracer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create step log directory: %w", err)
+	}
+	return &StepLogTracer{
+		dir:   dir,
+		files: make(map[string]*os.File),
+	}, nil
+}
+
+// Path returns the log file of a step
+func (t *StepLogTracer) Path(taskID string, stepID int) string {
+	return filepath.Join(t.dir, filepath.Base(taskID), fmt.Sprintf("step-%d.log", stepID))
+}
+
+func (t *StepLogTracer) TraceNodeStart(ctx context.Context, nodeName, taskID string) error {
+	return nil
+}
+
+func (t *StepLogTracer) TraceNodeEnd(ctx context.Context, nodeName, taskID string, duration time.Duration) error {
+	return nil
+}
+
+func (t *StepLogTracer) TraceLLMRequest(ctx context.Context, taskID, prompt string) error {
+	return nil
+}
+
+func (t *StepLogTracer) TraceLLMResponse(ctx context.Context, taskID, response string, duration time.Duration) error {
+	return nil
+}
+
+func (t *StepLogTracer) TracePrompt(ctx context.Context, taskID, name, version, source string) error {
+	return nil
+}
+
+func (t *StepLogTracer) TraceStepStart(ctx context.Context, taskID string, step *state.Step) error {
+	// A replanned step with the same ID appends to the log of the previous attempt
+	return t.write(taskID, step.ID, time.Now(), fmt.Sprintf("=== step %d started: skill=%s, action=%s",
+		step.ID, step.SkillName, step.Action))
+}
+
+func (t *StepLogTracer) TraceStepEnd(ctx context.Context, taskID string, step *state.Step, result *state.StepResult, duration time.Duration) error {
+	status := "success"
+	if !result.Success {
+		status = "failed"
+	}
+	err := t.write(taskID, step.ID, time.Now(), fmt.Sprintf("=== step %d %s after %v", step.ID, status, duration))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	path := t.Path(taskID, step.ID)
+	if f, ok := t.files[path]; ok {
+		delete(t.files, path)
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (t *StepLogTracer) TraceStepOutput(ctx context.Context, taskID string, stepID int, stream, line string, at time.Time) error {
+	return t.write(taskID, stepID, at, fmt.Sprintf("%s | %s", stream, line))
+}
+
+func (t *StepLogTracer) TraceError(ctx context.Context, taskID, nodeName string, err error) error {
+	return nil
+}
+
+func (t *StepLogTracer) TracePolicyDecision(ctx context.Context, taskID string, decision *state.PolicyDecision) error {
+	return nil
+}
+
+func (t *StepLogTracer) TraceStateChange(ctx context.Context, taskID string, state *state.State) error {
+	return nil
+}
+
+func (t *StepLogTracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var lastErr error
+	for path, f := range t.files {
+		if err := f.Close(); err != nil {
+			lastErr = err
+		}
+		delete(t.files, path)
+	}
+	return lastErr
+}
+
+// write appends a timestamped line to the log of a step, opening it if needed
+func (t *StepLogTracer) write(taskID string, stepID int, at time.Time, line string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path := t.Path(taskID, stepID)
+	f, ok := t.files[path]
+	if !ok {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create step log directory: %w", err)
+		}
+		var err error
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open step log: %w", err)
+		}
+		t.files[path] = f
+	}
+
+	if _, err := fmt.Fprintf(f, "%s %s\n", at.Format(stepLogTimeFormat), line); err != nil {
+		return fmt.Errorf("failed to write step log: %w", err)
+	}
+	return nil
+}
@@ -23,15 +23,24 @@ type ExecutionTracer interface {
 	// TraceLLMResponse records an LLM response
 	TraceLLMResponse(ctx context.Context, taskID, response string, duration time.Duration) error
 
+	// TracePrompt records which prompt template (and version) was rendered
+	TracePrompt(ctx context.Context, taskID, name, version, source string) error
+
 	// TraceStepStart records when a step starts execution
 	TraceStepStart(ctx context.Context, taskID string, step *state.Step) error
 
 	// TraceStepEnd records when a step completes execution
 	TraceStepEnd(ctx context.Context, taskID string, step *state.Step, result *state.StepResult, duration time.Duration) error
 
+	// TraceStepOutput records a line written by a running step on stdout or stderr
+	TraceStepOutput(ctx context.Context, taskID string, stepID int, stream, line string, at time.Time) error
+
 	// TraceError records an error event
 	TraceError(ctx context.Context, taskID, nodeName string, err error) error
 
+	// TracePolicyDecision records the decision of a policy rule on a plan or step
+	TracePolicyDecision(ctx context.Context, taskID string, decision *state.PolicyDecision) error
+
 	// TraceStateChange records a state change (optional, for detailed tracing)
 	TraceStateChange(ctx context.Context, taskID string, state *state.State) error
 
@@ -47,9 +56,12 @@ const (
 	TraceEventNodeEnd     TraceEventType = "NodeEnd"
 	TraceEventLLMRequest  TraceEventType = "LLMRequest"
 	TraceEventLLMResponse TraceEventType = "LLMResponse"
+	TraceEventPrompt      TraceEventType = "Prompt"
 	TraceEventStepStart   TraceEventType = "StepStart"
 	TraceEventStepEnd     TraceEventType = "StepEnd"
+	TraceEventStepOutput  TraceEventType = "StepOutput"
 	TraceEventError       TraceEventType = "Error"
+	TraceEventPolicy      TraceEventType = "PolicyDecision"
 	TraceEventStateChange TraceEventType = "StateChange"
 )
 
@@ -124,6 +136,19 @@ func (m *MultiTracer) TraceLLMResponse(ctx context.Context, taskID, response str
 	return lastErr
 }
 
+func (m *MultiTracer) TracePrompt(ctx context.Context, taskID, name, version, source string) error {
+	var lastErr error
+	for _, tracer := range m.tracers {
+		if err := tracer.TracePrompt(ctx, taskID, name, version, source); err != nil {
+			logger.Warnf("[MultiTracer] Failed to trace prompt: tracer=%T, task=%s, prompt=%s, error=%v",
+				tracer, taskID, name, err)
+			lastErr = err
+			// Continue with other tracers (best effort)
+		}
+	}
+	return lastErr
+}
+
 func (m *MultiTracer) TraceStepStart(ctx context.Context, taskID string, step *state.Step) error {
 	var lastErr error
 	for _, tracer := range m.tracers {
@@ -150,6 +175,19 @@ func (m *MultiTracer) TraceStepEnd(ctx context.Context, taskID string, step *sta
 	return lastErr
 }
 
+func (m *MultiTracer) TraceStepOutput(ctx context.Context, taskID string, stepID int, stream, line string, at time.Time) error {
+	var lastErr error
+	for _, tracer := range m.tracers {
+		if err := tracer.TraceStepOutput(ctx, taskID, stepID, stream, line, at); err != nil {
+			logger.Warnf("[MultiTracer] Failed to trace step output: tracer=%T, task=%s, step=%d, error=%v",
+				tracer, taskID, stepID, err)
+			lastErr = err
+			// Continue with other tracers (best effort)
+		}
+	}
+	return lastErr
+}
+
 func (m *MultiTracer) TraceError(ctx context.Context, taskID, nodeName string, err error) error {
 	var lastErr error
 	for _, tracer := range m.tracers {
@@ -163,6 +201,19 @@ func (m *MultiTracer) TraceError(ctx context.Context, taskID, nodeName string, e
 	return lastErr
 }
 
+func (m *MultiTracer) TracePolicyDecision(ctx context.Context, taskID string, decision *state.PolicyDecision) error {
+	var lastErr error
+	for _, tracer := range m.tracers {
+		if err := tracer.TracePolicyDecision(ctx, taskID, decision); err != nil {
+			logger.Warnf("[MultiTracer] Failed to trace policy decision: tracer=%T, task=%s, rule=%s, error=%v",
+				tracer, taskID, decision.RuleID, err)
+			lastErr = err
+			// Continue with other tracers (best effort)
+		}
+	}
+	return lastErr
+}
+
 func (m *MultiTracer) TraceStateChange(ctx context.Context, taskID string, state *state.State) error {
 	var lastErr error
 	for _, tracer := range m.tracers {
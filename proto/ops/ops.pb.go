@@ -25,11 +25,15 @@ const (
 
 // SubmitTaskRequest represents a request to submit a task
 type SubmitTaskRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`                                                                             // User query/request
-	Params        map[string]string      `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Additional parameters
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Query          string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`                                                                             // User query/request
+	Params         map[string]string      `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Additional parameters
+	Mode           string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`                                                                               // execute (default), plan_only: plan and check the plan, or dry_run: run only the actions supporting it
+	Environment    string                 `protobuf:"bytes,4,opt,name=environment,proto3" json:"environment,omitempty"`                                                                 // Target environment evaluated by the policy rules, defaults to policy.default_environment
+	Labels         map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Select the maintenance windows and freezes of the task, e.g. cluster: prod-a
+	OverrideWindow bool                   `protobuf:"varint,6,opt,name=override_window,json=overrideWindow,proto3" json:"override_window,omitempty"`                                    // Run outside maintenance windows and freezes once approved, instead of being scheduled or rejected
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SubmitTaskRequest) Reset() {
@@ -76,6 +80,34 @@ func (x *SubmitTaskRequest) GetParams() map[string]string {
 	return nil
 }
 
+func (x *SubmitTaskRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetOverrideWindow() bool {
+	if x != nil {
+		return x.OverrideWindow
+	}
+	return false
+}
+
 // GetTaskStatusRequest represents a request to get task status
 type GetTaskStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -126,7 +158,7 @@ type ListTasksRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
 	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // Filter by status: pending, running, completed, failed
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // Filter by status: pending, running, pending_approval, scheduled, completed, failed
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -227,24 +259,136 @@ func (x *CancelTaskRequest) GetTaskId() string {
 	return ""
 }
 
-// Task represents a task
-type Task struct {
+// RollbackTaskRequest approves the rollback of a failed task.
+// The approver is the requester of the call, from the X-Opskills-Requester header.
+type RollbackTaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	Plan          string                 `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`
-	Results       []*StepResult          `protobuf:"bytes,5,rep,name=results,proto3" json:"results,omitempty"`
-	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *RollbackTaskRequest) Reset() {
+	*x = RollbackTaskRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackTaskRequest) ProtoMessage() {}
+
+func (x *RollbackTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackTaskRequest.ProtoReflect.Descriptor instead.
+func (*RollbackTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RollbackTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+// ApproveTaskRequest approves the plan of a task waiting for approval, or runs a
+// task scheduled for a maintenance window now, overriding the window.
+// The approver is the requester of the call, from the X-Opskills-Requester header.
+type ApproveTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Comment       string                 `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveTaskRequest) Reset() {
+	*x = ApproveTaskRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveTaskRequest) ProtoMessage() {}
+
+func (x *ApproveTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveTaskRequest.ProtoReflect.Descriptor instead.
+func (*ApproveTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ApproveTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ApproveTaskRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+// Task represents a task
+type Task struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TaskId          string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Query           string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Status          string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Plan            string                 `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`
+	Results         []*StepResult          `protobuf:"bytes,5,rep,name=results,proto3" json:"results,omitempty"`
+	Error           string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Artifacts       []*Artifact            `protobuf:"bytes,9,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	Revisions       []*PlanRevision        `protobuf:"bytes,10,rep,name=revisions,proto3" json:"revisions,omitempty"`                                    // Plan history, the first plan and every replan
+	Rollback        *Rollback              `protobuf:"bytes,11,opt,name=rollback,proto3" json:"rollback,omitempty"`                                      // Compensation of the completed steps, when the task failed
+	Mode            string                 `protobuf:"bytes,12,opt,name=mode,proto3" json:"mode,omitempty"`                                              // execute, plan_only or dry_run; plan_only and dry_run tasks change nothing
+	Requester       string                 `protobuf:"bytes,13,opt,name=requester,proto3" json:"requester,omitempty"`                                    // Who submitted the task, from the X-Opskills-Requester header
+	Environment     string                 `protobuf:"bytes,14,opt,name=environment,proto3" json:"environment,omitempty"`                                // Target environment
+	PolicyDecisions []*PolicyDecision      `protobuf:"bytes,15,rep,name=policy_decisions,json=policyDecisions,proto3" json:"policy_decisions,omitempty"` // Decisions of the policy rules on the plan and its steps
+	Approval        *Approval              `protobuf:"bytes,16,opt,name=approval,proto3" json:"approval,omitempty"`                                      // Set when policy rules require approval of the plan
+	Labels          map[string]string      `protobuf:"bytes,17,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Maintenance     *MaintenanceHold       `protobuf:"bytes,18,opt,name=maintenance,proto3" json:"maintenance,omitempty"` // Set when mutating steps were due outside a maintenance window
+	Runbook         string                 `protobuf:"bytes,19,opt,name=runbook,proto3" json:"runbook,omitempty"`         // Runbook the plan was rendered from, empty when it was planned
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
 func (x *Task) Reset() {
 	*x = Task{}
-	mi := &file_proto_ops_ops_proto_msgTypes[4]
+	mi := &file_proto_ops_ops_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -256,7 +400,7 @@ func (x *Task) String() string {
 func (*Task) ProtoMessage() {}
 
 func (x *Task) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_ops_ops_proto_msgTypes[4]
+	mi := &file_proto_ops_ops_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -269,7 +413,7 @@ func (x *Task) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Task.ProtoReflect.Descriptor instead.
 func (*Task) Descriptor() ([]byte, []int) {
-	return file_proto_ops_ops_proto_rawDescGZIP(), []int{4}
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Task) GetTaskId() string {
@@ -328,33 +472,111 @@ func (x *Task) GetUpdatedAt() string {
 	return ""
 }
 
-// StepResult represents the result of a step
-type StepResult struct {
+func (x *Task) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *Task) GetRevisions() []*PlanRevision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+func (x *Task) GetRollback() *Rollback {
+	if x != nil {
+		return x.Rollback
+	}
+	return nil
+}
+
+func (x *Task) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Task) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *Task) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *Task) GetPolicyDecisions() []*PolicyDecision {
+	if x != nil {
+		return x.PolicyDecisions
+	}
+	return nil
+}
+
+func (x *Task) GetApproval() *Approval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+func (x *Task) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Task) GetMaintenance() *MaintenanceHold {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+func (x *Task) GetRunbook() string {
+	if x != nil {
+		return x.Runbook
+	}
+	return ""
+}
+
+// MaintenanceHold records why the mutating steps of a task did not run when they were due
+type MaintenanceHold struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StepId        int32                  `protobuf:"varint,1,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"`
-	SkillName     string                 `protobuf:"bytes,2,opt,name=skill_name,json=skillName,proto3" json:"skill_name,omitempty"`
-	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
-	Output        string                 `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
-	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // scheduled or rejected
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Window        string                 `protobuf:"bytes,3,opt,name=window,proto3" json:"window,omitempty"`                        // Window opening at not_before
+	NotBefore     string                 `protobuf:"bytes,4,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"` // When a scheduled task resumes
+	StepId        int32                  `protobuf:"varint,5,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"`         // First held step, 0 when the plan was held
+	HeldAt        string                 `protobuf:"bytes,6,opt,name=held_at,json=heldAt,proto3" json:"held_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StepResult) Reset() {
-	*x = StepResult{}
-	mi := &file_proto_ops_ops_proto_msgTypes[5]
+func (x *MaintenanceHold) Reset() {
+	*x = MaintenanceHold{}
+	mi := &file_proto_ops_ops_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StepResult) String() string {
+func (x *MaintenanceHold) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StepResult) ProtoMessage() {}
+func (*MaintenanceHold) ProtoMessage() {}
 
-func (x *StepResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_ops_ops_proto_msgTypes[5]
+func (x *MaintenanceHold) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -365,84 +587,2502 @@ func (x *StepResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StepResult.ProtoReflect.Descriptor instead.
-func (*StepResult) Descriptor() ([]byte, []int) {
-	return file_proto_ops_ops_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use MaintenanceHold.ProtoReflect.Descriptor instead.
+func (*MaintenanceHold) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *StepResult) GetStepId() int32 {
+func (x *MaintenanceHold) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MaintenanceHold) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *MaintenanceHold) GetWindow() string {
+	if x != nil {
+		return x.Window
+	}
+	return ""
+}
+
+func (x *MaintenanceHold) GetNotBefore() string {
+	if x != nil {
+		return x.NotBefore
+	}
+	return ""
+}
+
+func (x *MaintenanceHold) GetStepId() int32 {
 	if x != nil {
 		return x.StepId
 	}
 	return 0
 }
 
-func (x *StepResult) GetSkillName() string {
+func (x *MaintenanceHold) GetHeldAt() string {
 	if x != nil {
-		return x.SkillName
+		return x.HeldAt
 	}
 	return ""
 }
 
-func (x *StepResult) GetSuccess() bool {
+// PolicyDecision is the decision of a policy rule on a plan or step
+type PolicyDecision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`  // Empty for allow decisions, no rule matched
+	Effect        string                 `protobuf:"bytes,2,opt,name=effect,proto3" json:"effect,omitempty"`                // allow, deny or require_approval
+	Stage         string                 `protobuf:"bytes,3,opt,name=stage,proto3" json:"stage,omitempty"`                  // plan: before the plan runs, step: before the step runs
+	StepId        int32                  `protobuf:"varint,4,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"` // 0 for decisions on the whole plan
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Time          string                 `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PolicyDecision) Reset() {
+	*x = PolicyDecision{}
+	mi := &file_proto_ops_ops_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PolicyDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyDecision) ProtoMessage() {}
+
+func (x *PolicyDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[8]
 	if x != nil {
-		return x.Success
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *StepResult) GetOutput() string {
+// Deprecated: Use PolicyDecision.ProtoReflect.Descriptor instead.
+func (*PolicyDecision) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PolicyDecision) GetRuleId() string {
 	if x != nil {
-		return x.Output
+		return x.RuleId
 	}
 	return ""
 }
 
-func (x *StepResult) GetError() string {
+func (x *PolicyDecision) GetEffect() string {
 	if x != nil {
-		return x.Error
+		return x.Effect
 	}
 	return ""
 }
 
-var File_proto_ops_ops_proto protoreflect.FileDescriptor
+func (x *PolicyDecision) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
 
-const file_proto_ops_ops_proto_rawDesc = "" +
-	"\n" +
-	"\x13proto/ops/ops.proto\x12\fopskills.ops\x1a\x1cgoogle/api/annotations.proto\x1a\x19proto/common/common.proto\"\xa9\x01\n" +
-	"\x11SubmitTaskRequest\x12\x14\n" +
-	"\x05query\x18\x01 \x01(\tR\x05query\x12C\n" +
-	"\x06params\x18\x02 \x03(\v2+.opskills.ops.SubmitTaskRequest.ParamsEntryR\x06params\x1a9\n" +
-	"\vParamsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"/\n" +
-	"\x14GetTaskStatusRequest\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\"[\n" +
-	"\x10ListTasksRequest\x12\x12\n" +
-	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\",\n" +
-	"\x11CancelTaskRequest\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\xe9\x01\n" +
-	"\x04Task\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x14\n" +
-	"\x05query\x18\x02 \x01(\tR\x05query\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\x12\x12\n" +
-	"\x04plan\x18\x04 \x01(\tR\x04plan\x122\n" +
-	"\aresults\x18\x05 \x03(\v2\x18.opskills.ops.StepResultR\aresults\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\b \x01(\tR\tupdatedAt\"\x8c\x01\n" +
-	"\n" +
+func (x *PolicyDecision) GetStepId() int32 {
+	if x != nil {
+		return x.StepId
+	}
+	return 0
+}
+
+func (x *PolicyDecision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PolicyDecision) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+// Approval holds the plan of a task until the rules requiring approval are approved
+type Approval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // pending or approved
+	Rules         []string               `protobuf:"bytes,2,rep,name=rules,proto3" json:"rules,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	RequestedAt   string                 `protobuf:"bytes,4,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	ApprovedBy    string                 `protobuf:"bytes,5,opt,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"`
+	ApprovedAt    string                 `protobuf:"bytes,6,opt,name=approved_at,json=approvedAt,proto3" json:"approved_at,omitempty"`
+	Comment       string                 `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Approval) Reset() {
+	*x = Approval{}
+	mi := &file_proto_ops_ops_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Approval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Approval) ProtoMessage() {}
+
+func (x *Approval) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Approval.ProtoReflect.Descriptor instead.
+func (*Approval) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Approval) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Approval) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Approval) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Approval) GetRequestedAt() string {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return ""
+}
+
+func (x *Approval) GetApprovedBy() string {
+	if x != nil {
+		return x.ApprovedBy
+	}
+	return ""
+}
+
+func (x *Approval) GetApprovedAt() string {
+	if x != nil {
+		return x.ApprovedAt
+	}
+	return ""
+}
+
+func (x *Approval) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+// PlanRevision represents a version of the plan of a task
+type PlanRevision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      int32                  `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"` // 0 for the first plan
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`      // Why the previous revision was replaced
+	Plan          string                 `protobuf:"bytes,3,opt,name=plan,proto3" json:"plan,omitempty"`          // JSON, kept steps followed by the new ones
+	KeptSteps     []int32                `protobuf:"varint,4,rep,packed,name=kept_steps,json=keptSteps,proto3" json:"kept_steps,omitempty"`
+	Superseded    []*StepResult          `protobuf:"bytes,5,rep,name=superseded,proto3" json:"superseded,omitempty"` // Results of the previous steps that were not kept
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanRevision) Reset() {
+	*x = PlanRevision{}
+	mi := &file_proto_ops_ops_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanRevision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanRevision) ProtoMessage() {}
+
+func (x *PlanRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanRevision.ProtoReflect.Descriptor instead.
+func (*PlanRevision) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PlanRevision) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *PlanRevision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PlanRevision) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *PlanRevision) GetKeptSteps() []int32 {
+	if x != nil {
+		return x.KeptSteps
+	}
+	return nil
+}
+
+func (x *PlanRevision) GetSuperseded() []*StepResult {
+	if x != nil {
+		return x.Superseded
+	}
+	return nil
+}
+
+func (x *PlanRevision) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// Rollback undoes the completed steps of a failed task, latest first
+type Rollback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // pending_approval, held, running, completed, failed
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Error of the task
+	Steps         []*Compensation        `protobuf:"bytes,3,rep,name=steps,proto3" json:"steps,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ApprovedBy    string                 `protobuf:"bytes,6,opt,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"` // Who approved the rollback
+	ApprovedAt    string                 `protobuf:"bytes,7,opt,name=approved_at,json=approvedAt,proto3" json:"approved_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rollback) Reset() {
+	*x = Rollback{}
+	mi := &file_proto_ops_ops_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rollback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rollback) ProtoMessage() {}
+
+func (x *Rollback) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rollback.ProtoReflect.Descriptor instead.
+func (*Rollback) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Rollback) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Rollback) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Rollback) GetSteps() []*Compensation {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *Rollback) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Rollback) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Rollback) GetApprovedBy() string {
+	if x != nil {
+		return x.ApprovedBy
+	}
+	return ""
+}
+
+func (x *Rollback) GetApprovedAt() string {
+	if x != nil {
+		return x.ApprovedAt
+	}
+	return ""
+}
+
+// Compensation undoes a completed step
+type Compensation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StepId        int32                  `protobuf:"varint,1,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"` // Compensated step
+	SkillName     string                 `protobuf:"bytes,2,opt,name=skill_name,json=skillName,proto3" json:"skill_name,omitempty"`
+	Action        string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Params        string                 `protobuf:"bytes,4,opt,name=params,proto3" json:"params,omitempty"` // JSON
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"` // pending, completed, failed
+	Result        *StepResult            `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Compensation) Reset() {
+	*x = Compensation{}
+	mi := &file_proto_ops_ops_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Compensation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Compensation) ProtoMessage() {}
+
+func (x *Compensation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Compensation.ProtoReflect.Descriptor instead.
+func (*Compensation) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Compensation) GetStepId() int32 {
+	if x != nil {
+		return x.StepId
+	}
+	return 0
+}
+
+func (x *Compensation) GetSkillName() string {
+	if x != nil {
+		return x.SkillName
+	}
+	return ""
+}
+
+func (x *Compensation) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Compensation) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+func (x *Compensation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Compensation) GetResult() *StepResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// StepResult represents the result of a step
+type StepResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StepId        int32                  `protobuf:"varint,1,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"`
+	SkillName     string                 `protobuf:"bytes,2,opt,name=skill_name,json=skillName,proto3" json:"skill_name,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Output        string                 `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Artifacts     []*Artifact            `protobuf:"bytes,6,rep,name=artifacts,proto3" json:"artifacts,omitempty"`  // Artifacts collected from the step
+	Route         string                 `protobuf:"bytes,7,opt,name=route,proto3" json:"route,omitempty"`          // Backend that ran the step, e.g. direct or mcp:<server>
+	Attempts      int32                  `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`   // Executions of the step, more than 1 when failures were retried
+	Simulated     bool                   `protobuf:"varint,9,opt,name=simulated,proto3" json:"simulated,omitempty"` // Ran in a dry run, nothing was changed
+	Skipped       bool                   `protobuf:"varint,10,opt,name=skipped,proto3" json:"skipped,omitempty"`    // Not run by a dry run, the action does not support it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepResult) Reset() {
+	*x = StepResult{}
+	mi := &file_proto_ops_ops_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepResult) ProtoMessage() {}
+
+func (x *StepResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepResult.ProtoReflect.Descriptor instead.
+func (*StepResult) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StepResult) GetStepId() int32 {
+	if x != nil {
+		return x.StepId
+	}
+	return 0
+}
+
+func (x *StepResult) GetSkillName() string {
+	if x != nil {
+		return x.SkillName
+	}
+	return ""
+}
+
+func (x *StepResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StepResult) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *StepResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StepResult) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *StepResult) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *StepResult) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *StepResult) GetSimulated() bool {
+	if x != nil {
+		return x.Simulated
+	}
+	return false
+}
+
+func (x *StepResult) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+// Artifact represents a file a step left in the task workspace
+type Artifact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Path relative to the workspace
+	StepId        int32                  `protobuf:"varint,2,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_proto_ops_ops_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Artifact) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Artifact) GetStepId() int32 {
+	if x != nil {
+		return x.StepId
+	}
+	return 0
+}
+
+func (x *Artifact) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Artifact) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Artifact) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// GetArtifactRequest represents a request to get an artifact of a task
+type GetArtifactRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	StepId        int32                  `protobuf:"varint,3,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"` // Step producing the artifact, 0 for the last one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetArtifactRequest) Reset() {
+	*x = GetArtifactRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetArtifactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetArtifactRequest) ProtoMessage() {}
+
+func (x *GetArtifactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetArtifactRequest.ProtoReflect.Descriptor instead.
+func (*GetArtifactRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetArtifactRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *GetArtifactRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GetArtifactRequest) GetStepId() int32 {
+	if x != nil {
+		return x.StepId
+	}
+	return 0
+}
+
+// ArtifactContent represents an artifact and its content
+type ArtifactContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Artifact      *Artifact              `protobuf:"bytes,1,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtifactContent) Reset() {
+	*x = ArtifactContent{}
+	mi := &file_proto_ops_ops_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtifactContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtifactContent) ProtoMessage() {}
+
+func (x *ArtifactContent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtifactContent.ProtoReflect.Descriptor instead.
+func (*ArtifactContent) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ArtifactContent) GetArtifact() *Artifact {
+	if x != nil {
+		return x.Artifact
+	}
+	return nil
+}
+
+func (x *ArtifactContent) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// ReloadSkillsRequest represents a request to reload skills
+type ReloadSkillsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadSkillsRequest) Reset() {
+	*x = ReloadSkillsRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadSkillsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadSkillsRequest) ProtoMessage() {}
+
+func (x *ReloadSkillsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadSkillsRequest.ProtoReflect.Descriptor instead.
+func (*ReloadSkillsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{17}
+}
+
+// ReloadSkillsResult represents the outcome of a skill reload
+type ReloadSkillsResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*SkillChange         `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Errors        []*SkillLoadError      `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`                            // Rejected skills, the last good version is kept when known
+	SkillCount    int32                  `protobuf:"varint,3,opt,name=skill_count,json=skillCount,proto3" json:"skill_count,omitempty"` // Number of skills registered after the reload
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadSkillsResult) Reset() {
+	*x = ReloadSkillsResult{}
+	mi := &file_proto_ops_ops_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadSkillsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadSkillsResult) ProtoMessage() {}
+
+func (x *ReloadSkillsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadSkillsResult.ProtoReflect.Descriptor instead.
+func (*ReloadSkillsResult) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReloadSkillsResult) GetChanges() []*SkillChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ReloadSkillsResult) GetErrors() []*SkillLoadError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ReloadSkillsResult) GetSkillCount() int32 {
+	if x != nil {
+		return x.SkillCount
+	}
+	return 0
+}
+
+// SkillChange represents a skill added, updated or removed by a reload
+type SkillChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // added, updated, removed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkillChange) Reset() {
+	*x = SkillChange{}
+	mi := &file_proto_ops_ops_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkillChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkillChange) ProtoMessage() {}
+
+func (x *SkillChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkillChange.ProtoReflect.Descriptor instead.
+func (*SkillChange) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SkillChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SkillChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+// SkillLoadError represents a skill that failed to load
+type SkillLoadError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkillLoadError) Reset() {
+	*x = SkillLoadError{}
+	mi := &file_proto_ops_ops_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkillLoadError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkillLoadError) ProtoMessage() {}
+
+func (x *SkillLoadError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkillLoadError.ProtoReflect.Descriptor instead.
+func (*SkillLoadError) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SkillLoadError) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SkillLoadError) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SkillLoadError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ExplainSkillSelectionRequest represents a request to explain the skill selection of a query
+type ExplainSkillSelectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainSkillSelectionRequest) Reset() {
+	*x = ExplainSkillSelectionRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainSkillSelectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainSkillSelectionRequest) ProtoMessage() {}
+
+func (x *ExplainSkillSelectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainSkillSelectionRequest.ProtoReflect.Descriptor instead.
+func (*ExplainSkillSelectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ExplainSkillSelectionRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+// SkillSelectionResult represents the ranking of the skills for a query
+type SkillSelectionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	TopK          int32                  `protobuf:"varint,2,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	Candidates    []*SkillCandidate      `protobuf:"bytes,3,rep,name=candidates,proto3" json:"candidates,omitempty"` // Every skill, most relevant first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkillSelectionResult) Reset() {
+	*x = SkillSelectionResult{}
+	mi := &file_proto_ops_ops_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkillSelectionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkillSelectionResult) ProtoMessage() {}
+
+func (x *SkillSelectionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkillSelectionResult.ProtoReflect.Descriptor instead.
+func (*SkillSelectionResult) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SkillSelectionResult) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SkillSelectionResult) GetTopK() int32 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+func (x *SkillSelectionResult) GetCandidates() []*SkillCandidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+// SkillCandidate represents a skill scored against a query
+type SkillCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"` // Final score used for ranking
+	Bm25          float64                `protobuf:"fixed64,3,opt,name=bm25,proto3" json:"bm25,omitempty"`
+	Similarity    float64                `protobuf:"fixed64,4,opt,name=similarity,proto3" json:"similarity,omitempty"` // Embedding similarity, 0 when embeddings are disabled
+	Selected      bool                   `protobuf:"varint,5,opt,name=selected,proto3" json:"selected,omitempty"`      // Passed to the planner
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	Matches       []*TermMatch           `protobuf:"bytes,7,rep,name=matches,proto3" json:"matches,omitempty"` // Contributions to the BM25 score, highest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkillCandidate) Reset() {
+	*x = SkillCandidate{}
+	mi := &file_proto_ops_ops_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkillCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkillCandidate) ProtoMessage() {}
+
+func (x *SkillCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkillCandidate.ProtoReflect.Descriptor instead.
+func (*SkillCandidate) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SkillCandidate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SkillCandidate) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SkillCandidate) GetBm25() float64 {
+	if x != nil {
+		return x.Bm25
+	}
+	return 0
+}
+
+func (x *SkillCandidate) GetSimilarity() float64 {
+	if x != nil {
+		return x.Similarity
+	}
+	return 0
+}
+
+func (x *SkillCandidate) GetSelected() bool {
+	if x != nil {
+		return x.Selected
+	}
+	return false
+}
+
+func (x *SkillCandidate) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SkillCandidate) GetMatches() []*TermMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+// TermMatch represents the contribution of a query term in a skill field
+type TermMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          string                 `protobuf:"bytes,1,opt,name=term,proto3" json:"term,omitempty"`
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"` // name, description, actions, instructions, references
+	Score         float64                `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TermMatch) Reset() {
+	*x = TermMatch{}
+	mi := &file_proto_ops_ops_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TermMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermMatch) ProtoMessage() {}
+
+func (x *TermMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TermMatch.ProtoReflect.Descriptor instead.
+func (*TermMatch) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TermMatch) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *TermMatch) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *TermMatch) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// CreateScheduleRequest represents a request to create a schedule
+type CreateScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                                               // Unique name of the schedule
+	Cron          string                 `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"`                                                                               // Standard 5 field cron expression or descriptor, e.g. "0 2 * * *" or @weekly
+	Timezone      string                 `protobuf:"bytes,3,opt,name=timezone,proto3" json:"timezone,omitempty"`                                                                       // IANA timezone of cron, e.g. Europe/Paris; server local time when empty
+	Query         string                 `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`                                                                             // Planned at each run; describes the task when plan is set
+	Plan          string                 `protobuf:"bytes,5,opt,name=plan,proto3" json:"plan,omitempty"`                                                                               // JSON plan ({"steps": [...]}) run as is at each run, without planning
+	Mode          string                 `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"`                                                                               // Mode of the tasks: execute (default), plan_only or dry_run
+	Environment   string                 `protobuf:"bytes,7,opt,name=environment,proto3" json:"environment,omitempty"`                                                                 // Target environment of the tasks
+	Labels        map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Labels of the tasks
+	Concurrency   string                 `protobuf:"bytes,9,opt,name=concurrency,proto3" json:"concurrency,omitempty"`                                                                 // When the previous run is still running: skip (default), queue or replace it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateScheduleRequest) Reset() {
+	*x = CreateScheduleRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateScheduleRequest) ProtoMessage() {}
+
+func (x *CreateScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CreateScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreateScheduleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CreateScheduleRequest) GetConcurrency() string {
+	if x != nil {
+		return x.Concurrency
+	}
+	return ""
+}
+
+// ListSchedulesRequest represents a request to list schedules
+type ListSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesRequest) ProtoMessage() {}
+
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{26}
+}
+
+// DeleteScheduleRequest represents a request to delete a schedule
+type DeleteScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteScheduleRequest) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+// Schedule represents a schedule
+type Schedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Cron          string                 `protobuf:"bytes,3,opt,name=cron,proto3" json:"cron,omitempty"`
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Query         string                 `protobuf:"bytes,5,opt,name=query,proto3" json:"query,omitempty"`
+	Plan          string                 `protobuf:"bytes,6,opt,name=plan,proto3" json:"plan,omitempty"` // JSON plan, empty when the query is planned at each run
+	Mode          string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	Environment   string                 `protobuf:"bytes,8,opt,name=environment,proto3" json:"environment,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Concurrency   string                 `protobuf:"bytes,10,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
+	Requester     string                 `protobuf:"bytes,11,opt,name=requester,proto3" json:"requester,omitempty"` // Creator of the schedule, requester of its tasks
+	CreatedAt     string                 `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	NextRun       string                 `protobuf:"bytes,13,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
+	Runs          []*ScheduleRun         `protobuf:"bytes,14,rep,name=runs,proto3" json:"runs,omitempty"` // Latest runs, oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_proto_ops_ops_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Schedule) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *Schedule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Schedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *Schedule) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *Schedule) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Schedule) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *Schedule) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Schedule) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *Schedule) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Schedule) GetConcurrency() string {
+	if x != nil {
+		return x.Concurrency
+	}
+	return ""
+}
+
+func (x *Schedule) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *Schedule) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Schedule) GetNextRun() string {
+	if x != nil {
+		return x.NextRun
+	}
+	return ""
+}
+
+func (x *Schedule) GetRuns() []*ScheduleRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+// ScheduleRun represents a run of a schedule
+type ScheduleRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"` // Task of the run, empty when skipped
+	ScheduledAt   string                 `protobuf:"bytes,2,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
+	StartedAt     string                 `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    string                 `protobuf:"bytes,4,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"` // queued, running, skipped, replaced, interrupted, or the status of the task once it ended
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleRun) Reset() {
+	*x = ScheduleRun{}
+	mi := &file_proto_ops_ops_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleRun) ProtoMessage() {}
+
+func (x *ScheduleRun) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleRun.ProtoReflect.Descriptor instead.
+func (*ScheduleRun) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ScheduleRun) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetScheduledAt() string {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetFinishedAt() string {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ScheduleList represents the schedules
+type ScheduleList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*Schedule            `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleList) Reset() {
+	*x = ScheduleList{}
+	mi := &file_proto_ops_ops_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleList) ProtoMessage() {}
+
+func (x *ScheduleList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleList.ProtoReflect.Descriptor instead.
+func (*ScheduleList) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ScheduleList) GetSchedules() []*Schedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+func (x *ScheduleList) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// CreateRunbookRequest represents a request to save a runbook
+type CreateRunbookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Definition    string                 `protobuf:"bytes,1,opt,name=definition,proto3" json:"definition,omitempty"` // YAML or JSON runbook: name, description, inputs and steps
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRunbookRequest) Reset() {
+	*x = CreateRunbookRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRunbookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRunbookRequest) ProtoMessage() {}
+
+func (x *CreateRunbookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRunbookRequest.ProtoReflect.Descriptor instead.
+func (*CreateRunbookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CreateRunbookRequest) GetDefinition() string {
+	if x != nil {
+		return x.Definition
+	}
+	return ""
+}
+
+// ListRunbooksRequest represents a request to list runbooks
+type ListRunbooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunbooksRequest) Reset() {
+	*x = ListRunbooksRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunbooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunbooksRequest) ProtoMessage() {}
+
+func (x *ListRunbooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunbooksRequest.ProtoReflect.Descriptor instead.
+func (*ListRunbooksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{32}
+}
+
+// DeleteRunbookRequest represents a request to delete a runbook
+type DeleteRunbookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRunbookRequest) Reset() {
+	*x = DeleteRunbookRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRunbookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRunbookRequest) ProtoMessage() {}
+
+func (x *DeleteRunbookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRunbookRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRunbookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DeleteRunbookRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// RunRunbookRequest represents a request to run a runbook
+type RunRunbookRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Inputs         map[string]string      `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Input values, converted to the type of each input
+	Mode           string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`                                                                               // execute (default), plan_only or dry_run
+	Environment    string                 `protobuf:"bytes,4,opt,name=environment,proto3" json:"environment,omitempty"`                                                                 // Target environment evaluated by the policy rules
+	Labels         map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Select the maintenance windows and freezes of the task
+	OverrideWindow bool                   `protobuf:"varint,6,opt,name=override_window,json=overrideWindow,proto3" json:"override_window,omitempty"`                                    // Run outside maintenance windows and freezes once approved
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RunRunbookRequest) Reset() {
+	*x = RunRunbookRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunRunbookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunRunbookRequest) ProtoMessage() {}
+
+func (x *RunRunbookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunRunbookRequest.ProtoReflect.Descriptor instead.
+func (*RunRunbookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RunRunbookRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RunRunbookRequest) GetInputs() map[string]string {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *RunRunbookRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *RunRunbookRequest) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *RunRunbookRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RunRunbookRequest) GetOverrideWindow() bool {
+	if x != nil {
+		return x.OverrideWindow
+	}
+	return false
+}
+
+// PromoteTaskRequest represents a request to save the plan of a task as a runbook
+type PromoteTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                                                               // Name of the runbook: lowercase letters, digits, - and _
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`                                                                 // Defaults to the query of the task
+	Inputs        map[string]string      `protobuf:"bytes,4,rep,name=inputs,proto3" json:"inputs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Input name to its value in the plan, e.g. node: worker-3
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromoteTaskRequest) Reset() {
+	*x = PromoteTaskRequest{}
+	mi := &file_proto_ops_ops_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromoteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromoteTaskRequest) ProtoMessage() {}
+
+func (x *PromoteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromoteTaskRequest.ProtoReflect.Descriptor instead.
+func (*PromoteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *PromoteTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *PromoteTaskRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PromoteTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PromoteTaskRequest) GetInputs() map[string]string {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+// Runbook represents a runbook
+type Runbook struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Inputs        []*RunbookInput        `protobuf:"bytes,3,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Definition    string                 `protobuf:"bytes,4,opt,name=definition,proto3" json:"definition,omitempty"` // YAML definition, with the steps
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Runbook) Reset() {
+	*x = Runbook{}
+	mi := &file_proto_ops_ops_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Runbook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Runbook) ProtoMessage() {}
+
+func (x *Runbook) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Runbook.ProtoReflect.Descriptor instead.
+func (*Runbook) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *Runbook) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Runbook) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Runbook) GetInputs() []*RunbookInput {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *Runbook) GetDefinition() string {
+	if x != nil {
+		return x.Definition
+	}
+	return ""
+}
+
+// RunbookInput represents a typed input of a runbook
+type RunbookInput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // string, int, number or bool
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Required      bool                   `protobuf:"varint,4,opt,name=required,proto3" json:"required,omitempty"`
+	Default       string                 `protobuf:"bytes,5,opt,name=default,proto3" json:"default,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunbookInput) Reset() {
+	*x = RunbookInput{}
+	mi := &file_proto_ops_ops_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunbookInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunbookInput) ProtoMessage() {}
+
+func (x *RunbookInput) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunbookInput.ProtoReflect.Descriptor instead.
+func (*RunbookInput) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RunbookInput) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RunbookInput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *RunbookInput) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RunbookInput) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *RunbookInput) GetDefault() string {
+	if x != nil {
+		return x.Default
+	}
+	return ""
+}
+
+// RunbookList represents the runbooks
+type RunbookList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runbooks      []*Runbook             `protobuf:"bytes,1,rep,name=runbooks,proto3" json:"runbooks,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunbookList) Reset() {
+	*x = RunbookList{}
+	mi := &file_proto_ops_ops_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunbookList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunbookList) ProtoMessage() {}
+
+func (x *RunbookList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ops_ops_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunbookList.ProtoReflect.Descriptor instead.
+func (*RunbookList) Descriptor() ([]byte, []int) {
+	return file_proto_ops_ops_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RunbookList) GetRunbooks() []*Runbook {
+	if x != nil {
+		return x.Runbooks
+	}
+	return nil
+}
+
+func (x *RunbookList) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+var File_proto_ops_ops_proto protoreflect.FileDescriptor
+
+const file_proto_ops_ops_proto_rawDesc = "" +
+	"\n" +
+	"\x13proto/ops/ops.proto\x12\fopskills.ops\x1a\x1cgoogle/api/annotations.proto\x1a\x19proto/common/common.proto\"\x88\x03\n" +
+	"\x11SubmitTaskRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12C\n" +
+	"\x06params\x18\x02 \x03(\v2+.opskills.ops.SubmitTaskRequest.ParamsEntryR\x06params\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12 \n" +
+	"\venvironment\x18\x04 \x01(\tR\venvironment\x12C\n" +
+	"\x06labels\x18\x05 \x03(\v2+.opskills.ops.SubmitTaskRequest.LabelsEntryR\x06labels\x12'\n" +
+	"\x0foverride_window\x18\x06 \x01(\bR\x0eoverrideWindow\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"/\n" +
+	"\x14GetTaskStatusRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"[\n" +
+	"\x10ListTasksRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\",\n" +
+	"\x11CancelTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\".\n" +
+	"\x13RollbackTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"G\n" +
+	"\x12ApproveTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x18\n" +
+	"\acomment\x18\x02 \x01(\tR\acomment\"\xac\x06\n" +
+	"\x04Task\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x12\n" +
+	"\x04plan\x18\x04 \x01(\tR\x04plan\x122\n" +
+	"\aresults\x18\x05 \x03(\v2\x18.opskills.ops.StepResultR\aresults\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\tR\tupdatedAt\x124\n" +
+	"\tartifacts\x18\t \x03(\v2\x16.opskills.ops.ArtifactR\tartifacts\x128\n" +
+	"\trevisions\x18\n" +
+	" \x03(\v2\x1a.opskills.ops.PlanRevisionR\trevisions\x122\n" +
+	"\brollback\x18\v \x01(\v2\x16.opskills.ops.RollbackR\brollback\x12\x12\n" +
+	"\x04mode\x18\f \x01(\tR\x04mode\x12\x1c\n" +
+	"\trequester\x18\r \x01(\tR\trequester\x12 \n" +
+	"\venvironment\x18\x0e \x01(\tR\venvironment\x12G\n" +
+	"\x10policy_decisions\x18\x0f \x03(\v2\x1c.opskills.ops.PolicyDecisionR\x0fpolicyDecisions\x122\n" +
+	"\bapproval\x18\x10 \x01(\v2\x16.opskills.ops.ApprovalR\bapproval\x126\n" +
+	"\x06labels\x18\x11 \x03(\v2\x1e.opskills.ops.Task.LabelsEntryR\x06labels\x12?\n" +
+	"\vmaintenance\x18\x12 \x01(\v2\x1d.opskills.ops.MaintenanceHoldR\vmaintenance\x12\x18\n" +
+	"\arunbook\x18\x13 \x01(\tR\arunbook\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xaa\x01\n" +
+	"\x0fMaintenanceHold\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06window\x18\x03 \x01(\tR\x06window\x12\x1d\n" +
+	"\n" +
+	"not_before\x18\x04 \x01(\tR\tnotBefore\x12\x17\n" +
+	"\astep_id\x18\x05 \x01(\x05R\x06stepId\x12\x17\n" +
+	"\aheld_at\x18\x06 \x01(\tR\x06heldAt\"\x9c\x01\n" +
+	"\x0ePolicyDecision\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x16\n" +
+	"\x06effect\x18\x02 \x01(\tR\x06effect\x12\x14\n" +
+	"\x05stage\x18\x03 \x01(\tR\x05stage\x12\x17\n" +
+	"\astep_id\x18\x04 \x01(\x05R\x06stepId\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04time\x18\x06 \x01(\tR\x04time\"\xcf\x01\n" +
+	"\bApproval\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
+	"\x05rules\x18\x02 \x03(\tR\x05rules\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12!\n" +
+	"\frequested_at\x18\x04 \x01(\tR\vrequestedAt\x12\x1f\n" +
+	"\vapproved_by\x18\x05 \x01(\tR\n" +
+	"approvedBy\x12\x1f\n" +
+	"\vapproved_at\x18\x06 \x01(\tR\n" +
+	"approvedAt\x12\x18\n" +
+	"\acomment\x18\a \x01(\tR\acomment\"\xce\x01\n" +
+	"\fPlanRevision\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x05R\brevision\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04plan\x18\x03 \x01(\tR\x04plan\x12\x1d\n" +
+	"\n" +
+	"kept_steps\x18\x04 \x03(\x05R\tkeptSteps\x128\n" +
+	"\n" +
+	"superseded\x18\x05 \x03(\v2\x18.opskills.ops.StepResultR\n" +
+	"superseded\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\"\xec\x01\n" +
+	"\bRollback\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x120\n" +
+	"\x05steps\x18\x03 \x03(\v2\x1a.opskills.ops.CompensationR\x05steps\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\tR\tupdatedAt\x12\x1f\n" +
+	"\vapproved_by\x18\x06 \x01(\tR\n" +
+	"approvedBy\x12\x1f\n" +
+	"\vapproved_at\x18\a \x01(\tR\n" +
+	"approvedAt\"\xc0\x01\n" +
+	"\fCompensation\x12\x17\n" +
+	"\astep_id\x18\x01 \x01(\x05R\x06stepId\x12\x1d\n" +
+	"\n" +
+	"skill_name\x18\x02 \x01(\tR\tskillName\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x16\n" +
+	"\x06params\x18\x04 \x01(\tR\x06params\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x120\n" +
+	"\x06result\x18\x06 \x01(\v2\x18.opskills.ops.StepResultR\x06result\"\xac\x02\n" +
+	"\n" +
 	"StepResult\x12\x17\n" +
 	"\astep_id\x18\x01 \x01(\x05R\x06stepId\x12\x1d\n" +
 	"\n" +
 	"skill_name\x18\x02 \x01(\tR\tskillName\x12\x18\n" +
 	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x16\n" +
 	"\x06output\x18\x04 \x01(\tR\x06output\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error2\xb5\x03\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x124\n" +
+	"\tartifacts\x18\x06 \x03(\v2\x16.opskills.ops.ArtifactR\tartifacts\x12\x14\n" +
+	"\x05route\x18\a \x01(\tR\x05route\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x1c\n" +
+	"\tsimulated\x18\t \x01(\bR\tsimulated\x12\x18\n" +
+	"\askipped\x18\n" +
+	" \x01(\bR\askipped\"\x82\x01\n" +
+	"\bArtifact\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\astep_id\x18\x02 \x01(\x05R\x06stepId\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\"Z\n" +
+	"\x12GetArtifactRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
+	"\astep_id\x18\x03 \x01(\x05R\x06stepId\"_\n" +
+	"\x0fArtifactContent\x122\n" +
+	"\bartifact\x18\x01 \x01(\v2\x16.opskills.ops.ArtifactR\bartifact\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"\x15\n" +
+	"\x13ReloadSkillsRequest\"\xa0\x01\n" +
+	"\x12ReloadSkillsResult\x123\n" +
+	"\achanges\x18\x01 \x03(\v2\x19.opskills.ops.SkillChangeR\achanges\x124\n" +
+	"\x06errors\x18\x02 \x03(\v2\x1c.opskills.ops.SkillLoadErrorR\x06errors\x12\x1f\n" +
+	"\vskill_count\x18\x03 \x01(\x05R\n" +
+	"skillCount\"5\n" +
+	"\vSkillChange\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"N\n" +
+	"\x0eSkillLoadError\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"4\n" +
+	"\x1cExplainSkillSelectionRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\"\x7f\n" +
+	"\x14SkillSelectionResult\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x13\n" +
+	"\x05top_k\x18\x02 \x01(\x05R\x04topK\x12<\n" +
+	"\n" +
+	"candidates\x18\x03 \x03(\v2\x1c.opskills.ops.SkillCandidateR\n" +
+	"candidates\"\xd5\x01\n" +
+	"\x0eSkillCandidate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x12\n" +
+	"\x04bm25\x18\x03 \x01(\x01R\x04bm25\x12\x1e\n" +
+	"\n" +
+	"similarity\x18\x04 \x01(\x01R\n" +
+	"similarity\x12\x1a\n" +
+	"\bselected\x18\x05 \x01(\bR\bselected\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x121\n" +
+	"\amatches\x18\a \x03(\v2\x17.opskills.ops.TermMatchR\amatches\"K\n" +
+	"\tTermMatch\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\tR\x04term\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\"\xe1\x02\n" +
+	"\x15CreateScheduleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cron\x18\x02 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x03 \x01(\tR\btimezone\x12\x14\n" +
+	"\x05query\x18\x04 \x01(\tR\x05query\x12\x12\n" +
+	"\x04plan\x18\x05 \x01(\tR\x04plan\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\x12 \n" +
+	"\venvironment\x18\a \x01(\tR\venvironment\x12G\n" +
+	"\x06labels\x18\b \x03(\v2/.opskills.ops.CreateScheduleRequest.LabelsEntryR\x06labels\x12 \n" +
+	"\vconcurrency\x18\t \x01(\tR\vconcurrency\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x16\n" +
+	"\x14ListSchedulesRequest\"8\n" +
+	"\x15DeleteScheduleRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\"\xef\x03\n" +
+	"\bSchedule\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cron\x18\x03 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12\x14\n" +
+	"\x05query\x18\x05 \x01(\tR\x05query\x12\x12\n" +
+	"\x04plan\x18\x06 \x01(\tR\x04plan\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\x12 \n" +
+	"\venvironment\x18\b \x01(\tR\venvironment\x12:\n" +
+	"\x06labels\x18\t \x03(\v2\".opskills.ops.Schedule.LabelsEntryR\x06labels\x12 \n" +
+	"\vconcurrency\x18\n" +
+	" \x01(\tR\vconcurrency\x12\x1c\n" +
+	"\trequester\x18\v \x01(\tR\trequester\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\tR\tcreatedAt\x12\x19\n" +
+	"\bnext_run\x18\r \x01(\tR\anextRun\x12-\n" +
+	"\x04runs\x18\x0e \x03(\v2\x19.opskills.ops.ScheduleRunR\x04runs\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb7\x01\n" +
+	"\vScheduleRun\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12!\n" +
+	"\fscheduled_at\x18\x02 \x01(\tR\vscheduledAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x03 \x01(\tR\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\x04 \x01(\tR\n" +
+	"finishedAt\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"Z\n" +
+	"\fScheduleList\x124\n" +
+	"\tschedules\x18\x01 \x03(\v2\x16.opskills.ops.ScheduleR\tschedules\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"6\n" +
+	"\x14CreateRunbookRequest\x12\x1e\n" +
+	"\n" +
+	"definition\x18\x01 \x01(\tR\n" +
+	"definition\"\x15\n" +
+	"\x13ListRunbooksRequest\"*\n" +
+	"\x14DeleteRunbookRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x86\x03\n" +
+	"\x11RunRunbookRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12C\n" +
+	"\x06inputs\x18\x02 \x03(\v2+.opskills.ops.RunRunbookRequest.InputsEntryR\x06inputs\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12 \n" +
+	"\venvironment\x18\x04 \x01(\tR\venvironment\x12C\n" +
+	"\x06labels\x18\x05 \x03(\v2+.opskills.ops.RunRunbookRequest.LabelsEntryR\x06labels\x12'\n" +
+	"\x0foverride_window\x18\x06 \x01(\bR\x0eoverrideWindow\x1a9\n" +
+	"\vInputsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe4\x01\n" +
+	"\x12PromoteTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12D\n" +
+	"\x06inputs\x18\x04 \x03(\v2,.opskills.ops.PromoteTaskRequest.InputsEntryR\x06inputs\x1a9\n" +
+	"\vInputsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x01\n" +
+	"\aRunbook\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x122\n" +
+	"\x06inputs\x18\x03 \x03(\v2\x1a.opskills.ops.RunbookInputR\x06inputs\x12\x1e\n" +
+	"\n" +
+	"definition\x18\x04 \x01(\tR\n" +
+	"definition\"\x8e\x01\n" +
+	"\fRunbookInput\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x04 \x01(\bR\brequired\x12\x18\n" +
+	"\adefault\x18\x05 \x01(\tR\adefault\"V\n" +
+	"\vRunbookList\x121\n" +
+	"\brunbooks\x18\x01 \x03(\v2\x15.opskills.ops.RunbookR\brunbooks\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total2\x9f\x0f\n" +
 	"\n" +
 	"OpsService\x12b\n" +
 	"\n" +
@@ -450,7 +3090,21 @@ const file_proto_ops_ops_proto_rawDesc = "" +
 	"\rGetTaskStatus\x12\".opskills.ops.GetTaskStatusRequest\x1a\x19.opskills.common.Response\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/tasks/{task_id}\x12]\n" +
 	"\tListTasks\x12\x1e.opskills.ops.ListTasksRequest\x1a\x19.opskills.common.Response\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/tasks\x12s\n" +
 	"\n" +
-	"CancelTask\x12\x1f.opskills.ops.CancelTaskRequest\x1a\x19.opskills.common.Response\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/v1/tasks/{task_id}/cancelB+Z)github.com/hb-chen/opskills/proto/ops;opsb\x06proto3"
+	"CancelTask\x12\x1f.opskills.ops.CancelTaskRequest\x1a\x19.opskills.common.Response\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/v1/tasks/{task_id}/cancel\x12y\n" +
+	"\fRollbackTask\x12!.opskills.ops.RollbackTaskRequest\x1a\x19.opskills.common.Response\"+\x82\xd3\xe4\x93\x02%:\x01*\" /api/v1/tasks/{task_id}/rollback\x12v\n" +
+	"\vApproveTask\x12 .opskills.ops.ApproveTaskRequest\x1a\x19.opskills.common.Response\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/tasks/{task_id}/approve\x12\x7f\n" +
+	"\vGetArtifact\x12 .opskills.ops.GetArtifactRequest\x1a\x19.opskills.common.Response\"3\x82\xd3\xe4\x93\x02-\x12+/api/v1/tasks/{task_id}/artifacts/{name=**}\x12n\n" +
+	"\fReloadSkills\x12!.opskills.ops.ReloadSkillsRequest\x1a\x19.opskills.common.Response\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/api/v1/skills:reload\x12~\n" +
+	"\x15ExplainSkillSelection\x12*.opskills.ops.ExplainSkillSelectionRequest\x1a\x19.opskills.common.Response\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/v1/skills:explain\x12n\n" +
+	"\x0eCreateSchedule\x12#.opskills.ops.CreateScheduleRequest\x1a\x19.opskills.common.Response\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/api/v1/schedules\x12i\n" +
+	"\rListSchedules\x12\".opskills.ops.ListSchedulesRequest\x1a\x19.opskills.common.Response\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/api/v1/schedules\x12y\n" +
+	"\x0eDeleteSchedule\x12#.opskills.ops.DeleteScheduleRequest\x1a\x19.opskills.common.Response\"'\x82\xd3\xe4\x93\x02!*\x1f/api/v1/schedules/{schedule_id}\x12k\n" +
+	"\rCreateRunbook\x12\".opskills.ops.CreateRunbookRequest\x1a\x19.opskills.common.Response\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v1/runbooks\x12f\n" +
+	"\fListRunbooks\x12!.opskills.ops.ListRunbooksRequest\x1a\x19.opskills.common.Response\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/api/v1/runbooks\x12o\n" +
+	"\rDeleteRunbook\x12\".opskills.ops.DeleteRunbookRequest\x1a\x19.opskills.common.Response\"\x1f\x82\xd3\xe4\x93\x02\x19*\x17/api/v1/runbooks/{name}\x12p\n" +
+	"\n" +
+	"RunRunbook\x12\x1f.opskills.ops.RunRunbookRequest\x1a\x19.opskills.common.Response\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/api/v1/runbooks/{name}/run\x12v\n" +
+	"\vPromoteTask\x12 .opskills.ops.PromoteTaskRequest\x1a\x19.opskills.common.Response\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/tasks/{task_id}/promoteB+Z)github.com/hb-chen/opskills/proto/ops;opsb\x06proto3"
 
 var (
 	file_proto_ops_ops_proto_rawDescOnce sync.Once
@@ -464,33 +3118,125 @@ func file_proto_ops_ops_proto_rawDescGZIP() []byte {
 	return file_proto_ops_ops_proto_rawDescData
 }
 
-var file_proto_ops_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_ops_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_proto_ops_ops_proto_goTypes = []any{
-	(*SubmitTaskRequest)(nil),    // 0: opskills.ops.SubmitTaskRequest
-	(*GetTaskStatusRequest)(nil), // 1: opskills.ops.GetTaskStatusRequest
-	(*ListTasksRequest)(nil),     // 2: opskills.ops.ListTasksRequest
-	(*CancelTaskRequest)(nil),    // 3: opskills.ops.CancelTaskRequest
-	(*Task)(nil),                 // 4: opskills.ops.Task
-	(*StepResult)(nil),           // 5: opskills.ops.StepResult
-	nil,                          // 6: opskills.ops.SubmitTaskRequest.ParamsEntry
-	(*common.Response)(nil),      // 7: opskills.common.Response
+	(*SubmitTaskRequest)(nil),            // 0: opskills.ops.SubmitTaskRequest
+	(*GetTaskStatusRequest)(nil),         // 1: opskills.ops.GetTaskStatusRequest
+	(*ListTasksRequest)(nil),             // 2: opskills.ops.ListTasksRequest
+	(*CancelTaskRequest)(nil),            // 3: opskills.ops.CancelTaskRequest
+	(*RollbackTaskRequest)(nil),          // 4: opskills.ops.RollbackTaskRequest
+	(*ApproveTaskRequest)(nil),           // 5: opskills.ops.ApproveTaskRequest
+	(*Task)(nil),                         // 6: opskills.ops.Task
+	(*MaintenanceHold)(nil),              // 7: opskills.ops.MaintenanceHold
+	(*PolicyDecision)(nil),               // 8: opskills.ops.PolicyDecision
+	(*Approval)(nil),                     // 9: opskills.ops.Approval
+	(*PlanRevision)(nil),                 // 10: opskills.ops.PlanRevision
+	(*Rollback)(nil),                     // 11: opskills.ops.Rollback
+	(*Compensation)(nil),                 // 12: opskills.ops.Compensation
+	(*StepResult)(nil),                   // 13: opskills.ops.StepResult
+	(*Artifact)(nil),                     // 14: opskills.ops.Artifact
+	(*GetArtifactRequest)(nil),           // 15: opskills.ops.GetArtifactRequest
+	(*ArtifactContent)(nil),              // 16: opskills.ops.ArtifactContent
+	(*ReloadSkillsRequest)(nil),          // 17: opskills.ops.ReloadSkillsRequest
+	(*ReloadSkillsResult)(nil),           // 18: opskills.ops.ReloadSkillsResult
+	(*SkillChange)(nil),                  // 19: opskills.ops.SkillChange
+	(*SkillLoadError)(nil),               // 20: opskills.ops.SkillLoadError
+	(*ExplainSkillSelectionRequest)(nil), // 21: opskills.ops.ExplainSkillSelectionRequest
+	(*SkillSelectionResult)(nil),         // 22: opskills.ops.SkillSelectionResult
+	(*SkillCandidate)(nil),               // 23: opskills.ops.SkillCandidate
+	(*TermMatch)(nil),                    // 24: opskills.ops.TermMatch
+	(*CreateScheduleRequest)(nil),        // 25: opskills.ops.CreateScheduleRequest
+	(*ListSchedulesRequest)(nil),         // 26: opskills.ops.ListSchedulesRequest
+	(*DeleteScheduleRequest)(nil),        // 27: opskills.ops.DeleteScheduleRequest
+	(*Schedule)(nil),                     // 28: opskills.ops.Schedule
+	(*ScheduleRun)(nil),                  // 29: opskills.ops.ScheduleRun
+	(*ScheduleList)(nil),                 // 30: opskills.ops.ScheduleList
+	(*CreateRunbookRequest)(nil),         // 31: opskills.ops.CreateRunbookRequest
+	(*ListRunbooksRequest)(nil),          // 32: opskills.ops.ListRunbooksRequest
+	(*DeleteRunbookRequest)(nil),         // 33: opskills.ops.DeleteRunbookRequest
+	(*RunRunbookRequest)(nil),            // 34: opskills.ops.RunRunbookRequest
+	(*PromoteTaskRequest)(nil),           // 35: opskills.ops.PromoteTaskRequest
+	(*Runbook)(nil),                      // 36: opskills.ops.Runbook
+	(*RunbookInput)(nil),                 // 37: opskills.ops.RunbookInput
+	(*RunbookList)(nil),                  // 38: opskills.ops.RunbookList
+	nil,                                  // 39: opskills.ops.SubmitTaskRequest.ParamsEntry
+	nil,                                  // 40: opskills.ops.SubmitTaskRequest.LabelsEntry
+	nil,                                  // 41: opskills.ops.Task.LabelsEntry
+	nil,                                  // 42: opskills.ops.CreateScheduleRequest.LabelsEntry
+	nil,                                  // 43: opskills.ops.Schedule.LabelsEntry
+	nil,                                  // 44: opskills.ops.RunRunbookRequest.InputsEntry
+	nil,                                  // 45: opskills.ops.RunRunbookRequest.LabelsEntry
+	nil,                                  // 46: opskills.ops.PromoteTaskRequest.InputsEntry
+	(*common.Response)(nil),              // 47: opskills.common.Response
 }
 var file_proto_ops_ops_proto_depIdxs = []int32{
-	6, // 0: opskills.ops.SubmitTaskRequest.params:type_name -> opskills.ops.SubmitTaskRequest.ParamsEntry
-	5, // 1: opskills.ops.Task.results:type_name -> opskills.ops.StepResult
-	0, // 2: opskills.ops.OpsService.SubmitTask:input_type -> opskills.ops.SubmitTaskRequest
-	1, // 3: opskills.ops.OpsService.GetTaskStatus:input_type -> opskills.ops.GetTaskStatusRequest
-	2, // 4: opskills.ops.OpsService.ListTasks:input_type -> opskills.ops.ListTasksRequest
-	3, // 5: opskills.ops.OpsService.CancelTask:input_type -> opskills.ops.CancelTaskRequest
-	7, // 6: opskills.ops.OpsService.SubmitTask:output_type -> opskills.common.Response
-	7, // 7: opskills.ops.OpsService.GetTaskStatus:output_type -> opskills.common.Response
-	7, // 8: opskills.ops.OpsService.ListTasks:output_type -> opskills.common.Response
-	7, // 9: opskills.ops.OpsService.CancelTask:output_type -> opskills.common.Response
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	39, // 0: opskills.ops.SubmitTaskRequest.params:type_name -> opskills.ops.SubmitTaskRequest.ParamsEntry
+	40, // 1: opskills.ops.SubmitTaskRequest.labels:type_name -> opskills.ops.SubmitTaskRequest.LabelsEntry
+	13, // 2: opskills.ops.Task.results:type_name -> opskills.ops.StepResult
+	14, // 3: opskills.ops.Task.artifacts:type_name -> opskills.ops.Artifact
+	10, // 4: opskills.ops.Task.revisions:type_name -> opskills.ops.PlanRevision
+	11, // 5: opskills.ops.Task.rollback:type_name -> opskills.ops.Rollback
+	8,  // 6: opskills.ops.Task.policy_decisions:type_name -> opskills.ops.PolicyDecision
+	9,  // 7: opskills.ops.Task.approval:type_name -> opskills.ops.Approval
+	41, // 8: opskills.ops.Task.labels:type_name -> opskills.ops.Task.LabelsEntry
+	7,  // 9: opskills.ops.Task.maintenance:type_name -> opskills.ops.MaintenanceHold
+	13, // 10: opskills.ops.PlanRevision.superseded:type_name -> opskills.ops.StepResult
+	12, // 11: opskills.ops.Rollback.steps:type_name -> opskills.ops.Compensation
+	13, // 12: opskills.ops.Compensation.result:type_name -> opskills.ops.StepResult
+	14, // 13: opskills.ops.StepResult.artifacts:type_name -> opskills.ops.Artifact
+	14, // 14: opskills.ops.ArtifactContent.artifact:type_name -> opskills.ops.Artifact
+	19, // 15: opskills.ops.ReloadSkillsResult.changes:type_name -> opskills.ops.SkillChange
+	20, // 16: opskills.ops.ReloadSkillsResult.errors:type_name -> opskills.ops.SkillLoadError
+	23, // 17: opskills.ops.SkillSelectionResult.candidates:type_name -> opskills.ops.SkillCandidate
+	24, // 18: opskills.ops.SkillCandidate.matches:type_name -> opskills.ops.TermMatch
+	42, // 19: opskills.ops.CreateScheduleRequest.labels:type_name -> opskills.ops.CreateScheduleRequest.LabelsEntry
+	43, // 20: opskills.ops.Schedule.labels:type_name -> opskills.ops.Schedule.LabelsEntry
+	29, // 21: opskills.ops.Schedule.runs:type_name -> opskills.ops.ScheduleRun
+	28, // 22: opskills.ops.ScheduleList.schedules:type_name -> opskills.ops.Schedule
+	44, // 23: opskills.ops.RunRunbookRequest.inputs:type_name -> opskills.ops.RunRunbookRequest.InputsEntry
+	45, // 24: opskills.ops.RunRunbookRequest.labels:type_name -> opskills.ops.RunRunbookRequest.LabelsEntry
+	46, // 25: opskills.ops.PromoteTaskRequest.inputs:type_name -> opskills.ops.PromoteTaskRequest.InputsEntry
+	37, // 26: opskills.ops.Runbook.inputs:type_name -> opskills.ops.RunbookInput
+	36, // 27: opskills.ops.RunbookList.runbooks:type_name -> opskills.ops.Runbook
+	0,  // 28: opskills.ops.OpsService.SubmitTask:input_type -> opskills.ops.SubmitTaskRequest
+	1,  // 29: opskills.ops.OpsService.GetTaskStatus:input_type -> opskills.ops.GetTaskStatusRequest
+	2,  // 30: opskills.ops.OpsService.ListTasks:input_type -> opskills.ops.ListTasksRequest
+	3,  // 31: opskills.ops.OpsService.CancelTask:input_type -> opskills.ops.CancelTaskRequest
+	4,  // 32: opskills.ops.OpsService.RollbackTask:input_type -> opskills.ops.RollbackTaskRequest
+	5,  // 33: opskills.ops.OpsService.ApproveTask:input_type -> opskills.ops.ApproveTaskRequest
+	15, // 34: opskills.ops.OpsService.GetArtifact:input_type -> opskills.ops.GetArtifactRequest
+	17, // 35: opskills.ops.OpsService.ReloadSkills:input_type -> opskills.ops.ReloadSkillsRequest
+	21, // 36: opskills.ops.OpsService.ExplainSkillSelection:input_type -> opskills.ops.ExplainSkillSelectionRequest
+	25, // 37: opskills.ops.OpsService.CreateSchedule:input_type -> opskills.ops.CreateScheduleRequest
+	26, // 38: opskills.ops.OpsService.ListSchedules:input_type -> opskills.ops.ListSchedulesRequest
+	27, // 39: opskills.ops.OpsService.DeleteSchedule:input_type -> opskills.ops.DeleteScheduleRequest
+	31, // 40: opskills.ops.OpsService.CreateRunbook:input_type -> opskills.ops.CreateRunbookRequest
+	32, // 41: opskills.ops.OpsService.ListRunbooks:input_type -> opskills.ops.ListRunbooksRequest
+	33, // 42: opskills.ops.OpsService.DeleteRunbook:input_type -> opskills.ops.DeleteRunbookRequest
+	34, // 43: opskills.ops.OpsService.RunRunbook:input_type -> opskills.ops.RunRunbookRequest
+	35, // 44: opskills.ops.OpsService.PromoteTask:input_type -> opskills.ops.PromoteTaskRequest
+	47, // 45: opskills.ops.OpsService.SubmitTask:output_type -> opskills.common.Response
+	47, // 46: opskills.ops.OpsService.GetTaskStatus:output_type -> opskills.common.Response
+	47, // 47: opskills.ops.OpsService.ListTasks:output_type -> opskills.common.Response
+	47, // 48: opskills.ops.OpsService.CancelTask:output_type -> opskills.common.Response
+	47, // 49: opskills.ops.OpsService.RollbackTask:output_type -> opskills.common.Response
+	47, // 50: opskills.ops.OpsService.ApproveTask:output_type -> opskills.common.Response
+	47, // 51: opskills.ops.OpsService.GetArtifact:output_type -> opskills.common.Response
+	47, // 52: opskills.ops.OpsService.ReloadSkills:output_type -> opskills.common.Response
+	47, // 53: opskills.ops.OpsService.ExplainSkillSelection:output_type -> opskills.common.Response
+	47, // 54: opskills.ops.OpsService.CreateSchedule:output_type -> opskills.common.Response
+	47, // 55: opskills.ops.OpsService.ListSchedules:output_type -> opskills.common.Response
+	47, // 56: opskills.ops.OpsService.DeleteSchedule:output_type -> opskills.common.Response
+	47, // 57: opskills.ops.OpsService.CreateRunbook:output_type -> opskills.common.Response
+	47, // 58: opskills.ops.OpsService.ListRunbooks:output_type -> opskills.common.Response
+	47, // 59: opskills.ops.OpsService.DeleteRunbook:output_type -> opskills.common.Response
+	47, // 60: opskills.ops.OpsService.RunRunbook:output_type -> opskills.common.Response
+	47, // 61: opskills.ops.OpsService.PromoteTask:output_type -> opskills.common.Response
+	45, // [45:62] is the sub-list for method output_type
+	28, // [28:45] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_proto_ops_ops_proto_init() }
@@ -504,7 +3250,7 @@ func file_proto_ops_ops_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_ops_ops_proto_rawDesc), len(file_proto_ops_ops_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
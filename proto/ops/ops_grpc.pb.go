@@ -20,10 +20,23 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OpsService_SubmitTask_FullMethodName    = "/opskills.ops.OpsService/SubmitTask"
-	OpsService_GetTaskStatus_FullMethodName = "/opskills.ops.OpsService/GetTaskStatus"
-	OpsService_ListTasks_FullMethodName     = "/opskills.ops.OpsService/ListTasks"
-	OpsService_CancelTask_FullMethodName    = "/opskills.ops.OpsService/CancelTask"
+	OpsService_SubmitTask_FullMethodName            = "/opskills.ops.OpsService/SubmitTask"
+	OpsService_GetTaskStatus_FullMethodName         = "/opskills.ops.OpsService/GetTaskStatus"
+	OpsService_ListTasks_FullMethodName             = "/opskills.ops.OpsService/ListTasks"
+	OpsService_CancelTask_FullMethodName            = "/opskills.ops.OpsService/CancelTask"
+	OpsService_RollbackTask_FullMethodName          = "/opskills.ops.OpsService/RollbackTask"
+	OpsService_ApproveTask_FullMethodName           = "/opskills.ops.OpsService/ApproveTask"
+	OpsService_GetArtifact_FullMethodName           = "/opskills.ops.OpsService/GetArtifact"
+	OpsService_ReloadSkills_FullMethodName          = "/opskills.ops.OpsService/ReloadSkills"
+	OpsService_ExplainSkillSelection_FullMethodName = "/opskills.ops.OpsService/ExplainSkillSelection"
+	OpsService_CreateSchedule_FullMethodName        = "/opskills.ops.OpsService/CreateSchedule"
+	OpsService_ListSchedules_FullMethodName         = "/opskills.ops.OpsService/ListSchedules"
+	OpsService_DeleteSchedule_FullMethodName        = "/opskills.ops.OpsService/DeleteSchedule"
+	OpsService_CreateRunbook_FullMethodName         = "/opskills.ops.OpsService/CreateRunbook"
+	OpsService_ListRunbooks_FullMethodName          = "/opskills.ops.OpsService/ListRunbooks"
+	OpsService_DeleteRunbook_FullMethodName         = "/opskills.ops.OpsService/DeleteRunbook"
+	OpsService_RunRunbook_FullMethodName            = "/opskills.ops.OpsService/RunRunbook"
+	OpsService_PromoteTask_FullMethodName           = "/opskills.ops.OpsService/PromoteTask"
 )
 
 // OpsServiceClient is the client API for OpsService service.
@@ -40,6 +53,32 @@ type OpsServiceClient interface {
 	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*common.Response, error)
 	// CancelTask cancels a running task
 	CancelTask(ctx context.Context, in *CancelTaskRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// RollbackTask approves the rollback of a failed task and runs its compensations
+	RollbackTask(ctx context.Context, in *RollbackTaskRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// ApproveTask approves the plan of a task held by policy rules requiring approval and executes it
+	ApproveTask(ctx context.Context, in *ApproveTaskRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// GetArtifact returns an artifact of a task and its content
+	GetArtifact(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// ReloadSkills re-parses the skills directory and applies the changes
+	ReloadSkills(ctx context.Context, in *ReloadSkillsRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// ExplainSkillSelection shows how the skills are ranked for a query and which ones the planner gets
+	ExplainSkillSelection(ctx context.Context, in *ExplainSkillSelectionRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// CreateSchedule creates a schedule running a task on a cron expression
+	CreateSchedule(ctx context.Context, in *CreateScheduleRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// ListSchedules lists the schedules and their latest runs
+	ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// DeleteSchedule deletes a schedule, its running task is left to end
+	DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// CreateRunbook saves a runbook from its definition
+	CreateRunbook(ctx context.Context, in *CreateRunbookRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// ListRunbooks lists the runbooks
+	ListRunbooks(ctx context.Context, in *ListRunbooksRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// DeleteRunbook deletes a runbook
+	DeleteRunbook(ctx context.Context, in *DeleteRunbookRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// RunRunbook submits a task running the steps of a runbook with its inputs, without planning
+	RunRunbook(ctx context.Context, in *RunRunbookRequest, opts ...grpc.CallOption) (*common.Response, error)
+	// PromoteTask saves the plan of a completed task as a runbook
+	PromoteTask(ctx context.Context, in *PromoteTaskRequest, opts ...grpc.CallOption) (*common.Response, error)
 }
 
 type opsServiceClient struct {
@@ -90,6 +129,136 @@ func (c *opsServiceClient) CancelTask(ctx context.Context, in *CancelTaskRequest
 	return out, nil
 }
 
+func (c *opsServiceClient) RollbackTask(ctx context.Context, in *RollbackTaskRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_RollbackTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) ApproveTask(ctx context.Context, in *ApproveTaskRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_ApproveTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) GetArtifact(ctx context.Context, in *GetArtifactRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_GetArtifact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) ReloadSkills(ctx context.Context, in *ReloadSkillsRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_ReloadSkills_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) ExplainSkillSelection(ctx context.Context, in *ExplainSkillSelectionRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_ExplainSkillSelection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) CreateSchedule(ctx context.Context, in *CreateScheduleRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_CreateSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_ListSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_DeleteSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) CreateRunbook(ctx context.Context, in *CreateRunbookRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_CreateRunbook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) ListRunbooks(ctx context.Context, in *ListRunbooksRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_ListRunbooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) DeleteRunbook(ctx context.Context, in *DeleteRunbookRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_DeleteRunbook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) RunRunbook(ctx context.Context, in *RunRunbookRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_RunRunbook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *opsServiceClient) PromoteTask(ctx context.Context, in *PromoteTaskRequest, opts ...grpc.CallOption) (*common.Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Response)
+	err := c.cc.Invoke(ctx, OpsService_PromoteTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OpsServiceServer is the server API for OpsService service.
 // All implementations must embed UnimplementedOpsServiceServer
 // for forward compatibility.
@@ -104,6 +273,32 @@ type OpsServiceServer interface {
 	ListTasks(context.Context, *ListTasksRequest) (*common.Response, error)
 	// CancelTask cancels a running task
 	CancelTask(context.Context, *CancelTaskRequest) (*common.Response, error)
+	// RollbackTask approves the rollback of a failed task and runs its compensations
+	RollbackTask(context.Context, *RollbackTaskRequest) (*common.Response, error)
+	// ApproveTask approves the plan of a task held by policy rules requiring approval and executes it
+	ApproveTask(context.Context, *ApproveTaskRequest) (*common.Response, error)
+	// GetArtifact returns an artifact of a task and its content
+	GetArtifact(context.Context, *GetArtifactRequest) (*common.Response, error)
+	// ReloadSkills re-parses the skills directory and applies the changes
+	ReloadSkills(context.Context, *ReloadSkillsRequest) (*common.Response, error)
+	// ExplainSkillSelection shows how the skills are ranked for a query and which ones the planner gets
+	ExplainSkillSelection(context.Context, *ExplainSkillSelectionRequest) (*common.Response, error)
+	// CreateSchedule creates a schedule running a task on a cron expression
+	CreateSchedule(context.Context, *CreateScheduleRequest) (*common.Response, error)
+	// ListSchedules lists the schedules and their latest runs
+	ListSchedules(context.Context, *ListSchedulesRequest) (*common.Response, error)
+	// DeleteSchedule deletes a schedule, its running task is left to end
+	DeleteSchedule(context.Context, *DeleteScheduleRequest) (*common.Response, error)
+	// CreateRunbook saves a runbook from its definition
+	CreateRunbook(context.Context, *CreateRunbookRequest) (*common.Response, error)
+	// ListRunbooks lists the runbooks
+	ListRunbooks(context.Context, *ListRunbooksRequest) (*common.Response, error)
+	// DeleteRunbook deletes a runbook
+	DeleteRunbook(context.Context, *DeleteRunbookRequest) (*common.Response, error)
+	// RunRunbook submits a task running the steps of a runbook with its inputs, without planning
+	RunRunbook(context.Context, *RunRunbookRequest) (*common.Response, error)
+	// PromoteTask saves the plan of a completed task as a runbook
+	PromoteTask(context.Context, *PromoteTaskRequest) (*common.Response, error)
 	mustEmbedUnimplementedOpsServiceServer()
 }
 
@@ -126,6 +321,45 @@ func (UnimplementedOpsServiceServer) ListTasks(context.Context, *ListTasksReques
 func (UnimplementedOpsServiceServer) CancelTask(context.Context, *CancelTaskRequest) (*common.Response, error) {
 	return nil, status.Error(codes.Unimplemented, "method CancelTask not implemented")
 }
+func (UnimplementedOpsServiceServer) RollbackTask(context.Context, *RollbackTaskRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method RollbackTask not implemented")
+}
+func (UnimplementedOpsServiceServer) ApproveTask(context.Context, *ApproveTaskRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApproveTask not implemented")
+}
+func (UnimplementedOpsServiceServer) GetArtifact(context.Context, *GetArtifactRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetArtifact not implemented")
+}
+func (UnimplementedOpsServiceServer) ReloadSkills(context.Context, *ReloadSkillsRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReloadSkills not implemented")
+}
+func (UnimplementedOpsServiceServer) ExplainSkillSelection(context.Context, *ExplainSkillSelectionRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExplainSkillSelection not implemented")
+}
+func (UnimplementedOpsServiceServer) CreateSchedule(context.Context, *CreateScheduleRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateSchedule not implemented")
+}
+func (UnimplementedOpsServiceServer) ListSchedules(context.Context, *ListSchedulesRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (UnimplementedOpsServiceServer) DeleteSchedule(context.Context, *DeleteScheduleRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteSchedule not implemented")
+}
+func (UnimplementedOpsServiceServer) CreateRunbook(context.Context, *CreateRunbookRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateRunbook not implemented")
+}
+func (UnimplementedOpsServiceServer) ListRunbooks(context.Context, *ListRunbooksRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRunbooks not implemented")
+}
+func (UnimplementedOpsServiceServer) DeleteRunbook(context.Context, *DeleteRunbookRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteRunbook not implemented")
+}
+func (UnimplementedOpsServiceServer) RunRunbook(context.Context, *RunRunbookRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunRunbook not implemented")
+}
+func (UnimplementedOpsServiceServer) PromoteTask(context.Context, *PromoteTaskRequest) (*common.Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method PromoteTask not implemented")
+}
 func (UnimplementedOpsServiceServer) mustEmbedUnimplementedOpsServiceServer() {}
 func (UnimplementedOpsServiceServer) testEmbeddedByValue()                    {}
 
@@ -219,6 +453,240 @@ func _OpsService_CancelTask_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OpsService_RollbackTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).RollbackTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_RollbackTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).RollbackTask(ctx, req.(*RollbackTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_ApproveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).ApproveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_ApproveTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).ApproveTask(ctx, req.(*ApproveTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_GetArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArtifactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).GetArtifact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_GetArtifact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).GetArtifact(ctx, req.(*GetArtifactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_ReloadSkills_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadSkillsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).ReloadSkills(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_ReloadSkills_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).ReloadSkills(ctx, req.(*ReloadSkillsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_ExplainSkillSelection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainSkillSelectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).ExplainSkillSelection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_ExplainSkillSelection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).ExplainSkillSelection(ctx, req.(*ExplainSkillSelectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_CreateSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).CreateSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_CreateSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).CreateSchedule(ctx, req.(*CreateScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_ListSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).ListSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_DeleteSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).DeleteSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_DeleteSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).DeleteSchedule(ctx, req.(*DeleteScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_CreateRunbook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRunbookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).CreateRunbook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_CreateRunbook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).CreateRunbook(ctx, req.(*CreateRunbookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_ListRunbooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunbooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).ListRunbooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_ListRunbooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).ListRunbooks(ctx, req.(*ListRunbooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_DeleteRunbook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRunbookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).DeleteRunbook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_DeleteRunbook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).DeleteRunbook(ctx, req.(*DeleteRunbookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_RunRunbook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRunbookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).RunRunbook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_RunRunbook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).RunRunbook(ctx, req.(*RunRunbookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpsService_PromoteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpsServiceServer).PromoteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpsService_PromoteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpsServiceServer).PromoteTask(ctx, req.(*PromoteTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OpsService_ServiceDesc is the grpc.ServiceDesc for OpsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -242,6 +710,58 @@ var OpsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelTask",
 			Handler:    _OpsService_CancelTask_Handler,
 		},
+		{
+			MethodName: "RollbackTask",
+			Handler:    _OpsService_RollbackTask_Handler,
+		},
+		{
+			MethodName: "ApproveTask",
+			Handler:    _OpsService_ApproveTask_Handler,
+		},
+		{
+			MethodName: "GetArtifact",
+			Handler:    _OpsService_GetArtifact_Handler,
+		},
+		{
+			MethodName: "ReloadSkills",
+			Handler:    _OpsService_ReloadSkills_Handler,
+		},
+		{
+			MethodName: "ExplainSkillSelection",
+			Handler:    _OpsService_ExplainSkillSelection_Handler,
+		},
+		{
+			MethodName: "CreateSchedule",
+			Handler:    _OpsService_CreateSchedule_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _OpsService_ListSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteSchedule",
+			Handler:    _OpsService_DeleteSchedule_Handler,
+		},
+		{
+			MethodName: "CreateRunbook",
+			Handler:    _OpsService_CreateRunbook_Handler,
+		},
+		{
+			MethodName: "ListRunbooks",
+			Handler:    _OpsService_ListRunbooks_Handler,
+		},
+		{
+			MethodName: "DeleteRunbook",
+			Handler:    _OpsService_DeleteRunbook_Handler,
+		},
+		{
+			MethodName: "RunRunbook",
+			Handler:    _OpsService_RunRunbook_Handler,
+		},
+		{
+			MethodName: "PromoteTask",
+			Handler:    _OpsService_PromoteTask_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/ops/ops.proto",
@@ -6,7 +6,27 @@
 
 set -e
 
-if [ $# -eq 0 ]; then
+CONFIG_FILE="${SKILL_PARAM_config:-}"
+
+# Parse arguments: the executor passes params as --<name> <value> pairs, except
+# those holding secrets; every param is also in its SKILL_PARAM_<name> variable
+while [[ $# -gt 0 ]]; do
+    case $1 in
+        --config)
+            CONFIG_FILE="$2"
+            shift 2
+            ;;
+        --*)
+            shift 2
+            ;;
+        *)
+            CONFIG_FILE="$1"
+            shift
+            ;;
+    esac
+done
+
+if [ -z "$CONFIG_FILE" ]; then
     echo "Usage: $0 <config-file>"
     echo "Example: $0 ../examples/cluster-config.yaml"
     echo ""
@@ -19,8 +39,6 @@ if [ $# -eq 0 ]; then
     exit 1
 fi
 
-CONFIG_FILE="$1"
-
 if [ ! -f "$CONFIG_FILE" ]; then
     echo "Error: Configuration file not found: $CONFIG_FILE"
     exit 1
@@ -41,6 +59,12 @@ echo "Current cluster status:"
 kubectl get nodes 2>/dev/null || echo "Warning: Could not get cluster status"
 echo ""
 
+# Dry run: the checks above ran, stop before changing the cluster
+if [ "${SKILL_DRY_RUN:-}" = "1" ]; then
+    echo "[dry run] Would run: kk add nodes -f \"$CONFIG_FILE\""
+    exit 0
+fi
+
 # Add nodes
 echo "Starting to add nodes..."
 echo "This may take several minutes..."
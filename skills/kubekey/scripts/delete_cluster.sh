@@ -0,0 +1,79 @@
+#!/bin/bash
+
+# Delete a Kubernetes cluster created by KubeKey
+# Usage: ./delete_cluster.sh <config-file>
+# Note: This removes Kubernetes from every host of the configuration file.
+
+set -e
+
+CONFIG_FILE="${SKILL_PARAM_config:-}"
+
+# Parse arguments: the executor passes params as --<name> <value> pairs, except
+# those holding secrets; every param is also in its SKILL_PARAM_<name> variable
+while [[ $# -gt 0 ]]; do
+    case $1 in
+        --config)
+            CONFIG_FILE="$2"
+            shift 2
+            ;;
+        --*)
+            shift 2
+            ;;
+        *)
+            CONFIG_FILE="$1"
+            shift
+            ;;
+    esac
+done
+
+if [ -z "$CONFIG_FILE" ]; then
+    echo "Usage: $0 <config-file>"
+    echo "Example: $0 ../examples/cluster-config.yaml"
+    exit 1
+fi
+
+if [ ! -f "$CONFIG_FILE" ]; then
+    echo "Error: Configuration file not found: $CONFIG_FILE"
+    exit 1
+fi
+
+# Check if KubeKey is installed
+if ! command -v kk &> /dev/null; then
+    echo "Error: KubeKey is not installed"
+    echo "Please install it first: ./scripts/install_kubekey.sh"
+    exit 1
+fi
+
+echo "Deleting Kubernetes cluster with configuration: $CONFIG_FILE"
+echo ""
+
+# Dry run: the checks above ran, stop before changing the cluster
+if [ "${SKILL_DRY_RUN:-}" = "1" ]; then
+    echo "[dry run] Would run: kk delete cluster -f \"$CONFIG_FILE\""
+    exit 0
+fi
+
+# Confirm deletion, unless confirmed by the yes param (e.g. when rolling back)
+if [ "${SKILL_PARAM_yes:-}" != "true" ]; then
+    read -p "Are you sure you want to delete the cluster? (yes/no): " CONFIRM
+    if [ "$CONFIRM" != "yes" ]; then
+        echo "Deletion cancelled"
+        exit 0
+    fi
+fi
+
+echo "Deleting cluster..."
+echo "This may take several minutes..."
+echo ""
+
+kk delete cluster -f "$CONFIG_FILE"
+
+if [ $? -eq 0 ]; then
+    echo ""
+    echo "✓ Cluster deleted successfully!"
+else
+    echo ""
+    echo "✗ Cluster deletion failed"
+    echo "Please check the error messages above"
+    exit 1
+fi
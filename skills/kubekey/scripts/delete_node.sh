@@ -6,7 +6,27 @@
 
 set -e
 
-if [ $# -eq 0 ]; then
+NODE_NAME="${SKILL_PARAM_node:-}"
+
+# Parse arguments: the executor passes params as --<name> <value> pairs, except
+# those holding secrets; every param is also in its SKILL_PARAM_<name> variable
+while [[ $# -gt 0 ]]; do
+    case $1 in
+        --node)
+            NODE_NAME="$2"
+            shift 2
+            ;;
+        --*)
+            shift 2
+            ;;
+        *)
+            NODE_NAME="$1"
+            shift
+            ;;
+    esac
+done
+
+if [ -z "$NODE_NAME" ]; then
     echo "Usage: $0 <node-name>"
     echo "Example: $0 worker1"
     echo ""
@@ -17,8 +37,6 @@ if [ $# -eq 0 ]; then
     exit 1
 fi
 
-NODE_NAME="$1"
-
 # Check if KubeKey is installed
 if ! command -v kk &> /dev/null; then
     echo "Error: KubeKey is not installed"
@@ -43,13 +61,21 @@ echo "Current cluster status:"
 kubectl get nodes
 echo ""
 
-# Confirm deletion
-read -p "Are you sure you want to delete node '$NODE_NAME'? (yes/no): " CONFIRM
-if [ "$CONFIRM" != "yes" ]; then
-    echo "Deletion cancelled"
+# Dry run: the checks above ran, stop before changing the cluster
+if [ "${SKILL_DRY_RUN:-}" = "1" ]; then
+    echo "[dry run] Would run: kk delete node \"$NODE_NAME\""
     exit 0
 fi
 
+# Confirm deletion, unless confirmed by the yes param (e.g. when rolling back)
+if [ "${SKILL_PARAM_yes:-}" != "true" ]; then
+    read -p "Are you sure you want to delete node '$NODE_NAME'? (yes/no): " CONFIRM
+    if [ "$CONFIRM" != "yes" ]; then
+        echo "Deletion cancelled"
+        exit 0
+    fi
+fi
+
 # Delete node
 echo "Deleting node..."
 echo "This may take several minutes..."
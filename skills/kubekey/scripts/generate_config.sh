@@ -181,6 +181,11 @@ if [ -n "$CRI" ] && [ "$CRI" != "containerd" ]; then
     echo "Refer to KubeKey documentation for CRI configuration."
 fi
 
+# Keep the configuration as an artifact of the task
+if [ -n "$SKILL_ARTIFACTS" ]; then
+    echo "$OUTPUT_FILE" >> "$SKILL_ARTIFACTS"
+fi
+
 echo ""
 echo "✓ Configuration file generated: $OUTPUT_FILE"
 echo ""
@@ -5,14 +5,32 @@
 
 set -e
 
-if [ $# -eq 0 ]; then
+CONFIG_FILE="${SKILL_PARAM_config:-}"
+
+# Parse arguments: the executor passes params as --<name> <value> pairs, except
+# those holding secrets; every param is also in its SKILL_PARAM_<name> variable
+while [[ $# -gt 0 ]]; do
+    case $1 in
+        --config)
+            CONFIG_FILE="$2"
+            shift 2
+            ;;
+        --*)
+            shift 2
+            ;;
+        *)
+            CONFIG_FILE="$1"
+            shift
+            ;;
+    esac
+done
+
+if [ -z "$CONFIG_FILE" ]; then
     echo "Usage: $0 <config-file>"
     echo "Example: $0 ../examples/cluster-config.yaml"
     exit 1
 fi
 
-CONFIG_FILE="$1"
-
 if [ ! -f "$CONFIG_FILE" ]; then
     echo "Error: Configuration file not found: $CONFIG_FILE"
     exit 1
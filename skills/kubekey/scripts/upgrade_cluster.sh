@@ -12,10 +12,11 @@ set -e
 
 K8S_VERSION=""
 KS_VERSION=""
-CONFIG_FILE=""
+CONFIG_FILE="${SKILL_PARAM_config:-}"
 WITH_KS=false
 
-# Parse arguments
+# Parse arguments: the executor passes params as --<name> <value> pairs, except
+# those holding secrets; every param is also in its SKILL_PARAM_<name> variable
 while [[ $# -gt 0 ]]; do
     case $1 in
         --k8s-version)